@@ -0,0 +1,258 @@
+// Package ci detects the CI provider running the tool, and the metadata of its build: the pipeline, the job, the
+// build number, the URL of the build and the OS of the runner, from the environment variables of each provider
+package ci
+
+import (
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// BuildNumber the number of the build, or of the pipeline run, in the CI provider
+	BuildNumber = "ci.build.number"
+	// BuildURL the URL of the build, or of the job, in the CI provider
+	BuildURL = "ci.build.url"
+	// JobName the name of the job of the pipeline running the tool
+	JobName = "ci.job.name"
+	// PipelineName the name of the pipeline, or of the workflow, running the tool
+	PipelineName = "ci.pipeline.name"
+	// Provider the name of the CI provider: GitHub Actions, GitLab, Jenkins, etc.
+	Provider = "ci.provider"
+	// RunnerOS the OS of the runner, as named by the CI provider: Linux, macOS, Windows, etc.
+	RunnerOS = "ci.runner.os"
+)
+
+// Build the metadata of the CI build running the tool. The fields not provided by the CI provider are empty
+type Build struct {
+	BuildNumber  string
+	BuildURL     string
+	JobName      string
+	PipelineName string
+	Provider     string
+	RunnerOS     string
+}
+
+// Attributes returns the ci.* attributes of the build, skipping the empty ones
+func (b *Build) Attributes() []attribute.KeyValue {
+	values := []struct {
+		key   string
+		value string
+	}{
+		{Provider, b.Provider},
+		{PipelineName, b.PipelineName},
+		{JobName, b.JobName},
+		{BuildNumber, b.BuildNumber},
+		{BuildURL, b.BuildURL},
+		{RunnerOS, b.RunnerOS},
+	}
+
+	attrs := []attribute.KeyValue{}
+	for _, v := range values {
+		if v.value != "" {
+			attrs = append(attrs, attribute.Key(v.key).String(v.value))
+		}
+	}
+
+	return attrs
+}
+
+// providers detect the build of each CI provider from their environment variables. They return nil when the tool
+// does not run in their provider
+var providers = []func() *Build{
+	fromGithub,
+	fromGitlab,
+	fromJenkins,
+	fromBuildkite,
+	fromCircleCI,
+	fromAzurePipelines,
+	fromTravis,
+	fromDrone,
+	fromBitbucket,
+	fromTeamCity,
+	fromBamboo,
+}
+
+// Detect returns the build of the first CI provider detected in the environment, or nil outside of a CI provider
+func Detect() *Build {
+	for _, provider := range providers {
+		if build := provider(); build != nil {
+			return build
+		}
+	}
+
+	return nil
+}
+
+func fromGithub() *Build {
+	if os.Getenv("GITHUB_ACTIONS") != "true" {
+		return nil
+	}
+
+	return &Build{
+		BuildNumber:  os.Getenv("GITHUB_RUN_NUMBER"),
+		BuildURL:     githubRunURL(),
+		JobName:      os.Getenv("GITHUB_JOB"),
+		PipelineName: os.Getenv("GITHUB_WORKFLOW"),
+		Provider:     "GitHub Actions",
+		RunnerOS:     os.Getenv("RUNNER_OS"),
+	}
+}
+
+func fromGitlab() *Build {
+	if os.Getenv("GITLAB_CI") == "" {
+		return nil
+	}
+
+	// the pipelines are not named unless the workflow defines a name, so the project identifies them
+	pipelineName := os.Getenv("CI_PIPELINE_NAME")
+	if pipelineName == "" {
+		pipelineName = os.Getenv("CI_PROJECT_PATH")
+	}
+
+	// the architecture of the runner includes its OS, i.e. linux/amd64
+	runnerOS, _, _ := strings.Cut(os.Getenv("CI_RUNNER_EXECUTABLE_ARCH"), "/")
+
+	return &Build{
+		BuildNumber:  os.Getenv("CI_PIPELINE_IID"),
+		BuildURL:     gitlabJobURL(),
+		JobName:      os.Getenv("CI_JOB_NAME"),
+		PipelineName: pipelineName,
+		Provider:     "GitLab",
+		RunnerOS:     runnerOS,
+	}
+}
+
+func fromJenkins() *Build {
+	if os.Getenv("JENKINS_URL") == "" {
+		return nil
+	}
+
+	return &Build{
+		BuildNumber:  os.Getenv("BUILD_NUMBER"),
+		BuildURL:     jenkinsBuildURL(),
+		JobName:      os.Getenv("STAGE_NAME"), // only present in the stages of the declarative pipelines
+		PipelineName: os.Getenv("JOB_NAME"),
+		Provider:     "Jenkins",
+	}
+}
+
+func fromBuildkite() *Build {
+	if os.Getenv("BUILDKITE") != "true" {
+		return nil
+	}
+
+	return &Build{
+		BuildNumber:  os.Getenv("BUILDKITE_BUILD_NUMBER"),
+		BuildURL:     buildkiteJobURL(),
+		JobName:      os.Getenv("BUILDKITE_LABEL"),
+		PipelineName: os.Getenv("BUILDKITE_PIPELINE_SLUG"),
+		Provider:     "Buildkite",
+	}
+}
+
+func fromCircleCI() *Build {
+	if os.Getenv("CIRCLECI") != "true" {
+		return nil
+	}
+
+	return &Build{
+		BuildNumber:  os.Getenv("CIRCLE_BUILD_NUM"),
+		BuildURL:     circleciJobURL(),
+		JobName:      os.Getenv("CIRCLE_JOB"),
+		PipelineName: os.Getenv("CIRCLE_PROJECT_REPONAME"),
+		Provider:     "CircleCI",
+	}
+}
+
+func fromAzurePipelines() *Build {
+	if os.Getenv("TF_BUILD") == "" {
+		return nil
+	}
+
+	return &Build{
+		BuildNumber:  os.Getenv("BUILD_BUILDNUMBER"),
+		BuildURL:     azurePipelinesJobURL(),
+		JobName:      os.Getenv("SYSTEM_JOBDISPLAYNAME"),
+		PipelineName: os.Getenv("BUILD_DEFINITIONNAME"),
+		Provider:     "Azure Pipelines",
+		RunnerOS:     os.Getenv("AGENT_OS"),
+	}
+}
+
+func fromTravis() *Build {
+	if os.Getenv("TRAVIS") != "true" {
+		return nil
+	}
+
+	return &Build{
+		BuildNumber:  os.Getenv("TRAVIS_BUILD_NUMBER"),
+		BuildURL:     os.Getenv("TRAVIS_JOB_WEB_URL"),
+		JobName:      os.Getenv("TRAVIS_JOB_NAME"),
+		PipelineName: os.Getenv("TRAVIS_REPO_SLUG"),
+		Provider:     "Travis CI",
+		RunnerOS:     os.Getenv("TRAVIS_OS_NAME"),
+	}
+}
+
+func fromDrone() *Build {
+	if os.Getenv("DRONE") != "true" {
+		return nil
+	}
+
+	return &Build{
+		BuildNumber:  os.Getenv("DRONE_BUILD_NUMBER"),
+		BuildURL:     os.Getenv("DRONE_BUILD_LINK"),
+		JobName:      os.Getenv("DRONE_STEP_NAME"),
+		PipelineName: os.Getenv("DRONE_REPO"),
+		Provider:     "Drone",
+		RunnerOS:     os.Getenv("DRONE_STAGE_OS"),
+	}
+}
+
+func fromBitbucket() *Build {
+	buildNumber := os.Getenv("BITBUCKET_BUILD_NUMBER")
+	if buildNumber == "" {
+		return nil
+	}
+
+	buildURL := ""
+	if origin := os.Getenv("BITBUCKET_GIT_HTTP_ORIGIN"); origin != "" {
+		buildURL = strings.TrimSuffix(origin, "/") + "/addon/pipelines/home#!/results/" + buildNumber
+	}
+
+	return &Build{
+		BuildNumber:  buildNumber,
+		BuildURL:     buildURL,
+		PipelineName: os.Getenv("BITBUCKET_REPO_FULL_NAME"),
+		Provider:     "Bitbucket Pipelines",
+	}
+}
+
+func fromTeamCity() *Build {
+	if os.Getenv("TEAMCITY_VERSION") == "" {
+		return nil
+	}
+
+	return &Build{
+		BuildNumber:  os.Getenv("BUILD_NUMBER"),
+		JobName:      os.Getenv("TEAMCITY_BUILDCONF_NAME"),
+		PipelineName: os.Getenv("TEAMCITY_PROJECT_NAME"),
+		Provider:     "TeamCity",
+	}
+}
+
+func fromBamboo() *Build {
+	if os.Getenv("bamboo_buildKey") == "" {
+		return nil
+	}
+
+	return &Build{
+		BuildNumber:  os.Getenv("bamboo_buildNumber"),
+		BuildURL:     os.Getenv("bamboo_resultsUrl"),
+		JobName:      os.Getenv("bamboo_shortJobName"),
+		PipelineName: os.Getenv("bamboo_planName"),
+		Provider:     "Bamboo",
+	}
+}
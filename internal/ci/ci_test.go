@@ -0,0 +1,113 @@
+package ci
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// providersEnv the environment variables detecting each CI provider, unset by the tests so they do not depend on
+// the CI provider running them
+var providersEnv = []string{
+	"GITHUB_ACTIONS", "GITLAB_CI", "JENKINS_URL", "BUILDKITE", "CIRCLECI", "TF_BUILD", "TRAVIS", "DRONE",
+	"BITBUCKET_BUILD_NUMBER", "TEAMCITY_VERSION", "bamboo_buildKey",
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected *Build
+	}{
+		{
+			name: "Github Actions",
+			env: map[string]string{
+				"GITHUB_ACTIONS": "true", "GITHUB_WORKFLOW": "CI", "GITHUB_JOB": "test", "GITHUB_RUN_NUMBER": "42",
+				"GITHUB_REPOSITORY": "octocat/hello-world", "GITHUB_RUN_ID": "123", "GITHUB_RUN_ATTEMPT": "", "GITHUB_SERVER_URL": "",
+				"RUNNER_OS": "Linux",
+			},
+			expected: &Build{
+				BuildNumber:  "42",
+				BuildURL:     "https://github.com/octocat/hello-world/actions/runs/123",
+				JobName:      "test",
+				PipelineName: "CI",
+				Provider:     "GitHub Actions",
+				RunnerOS:     "Linux",
+			},
+		},
+		{
+			name: "Gitlab",
+			env: map[string]string{
+				"GITLAB_CI": "true", "CI_PIPELINE_NAME": "", "CI_PROJECT_PATH": "group/project", "CI_JOB_NAME": "unit tests",
+				"CI_PIPELINE_IID": "7", "CI_JOB_URL": "https://gitlab.com/group/project/-/jobs/456", "CI_RUNNER_EXECUTABLE_ARCH": "linux/amd64",
+			},
+			expected: &Build{
+				BuildNumber:  "7",
+				BuildURL:     "https://gitlab.com/group/project/-/jobs/456",
+				JobName:      "unit tests",
+				PipelineName: "group/project",
+				Provider:     "GitLab",
+				RunnerOS:     "linux",
+			},
+		},
+		{
+			name: "Jenkins",
+			env: map[string]string{
+				"JENKINS_URL": "https://jenkins.example.com/", "JOB_NAME": "project/main", "STAGE_NAME": "Test",
+				"BUILD_NUMBER": "7", "BUILD_URL": "https://jenkins.example.com/job/project/7/",
+			},
+			expected: &Build{
+				BuildNumber:  "7",
+				BuildURL:     "https://jenkins.example.com/job/project/7/",
+				JobName:      "Test",
+				PipelineName: "project/main",
+				Provider:     "Jenkins",
+			},
+		},
+		{
+			name: "Bitbucket Pipelines",
+			env: map[string]string{
+				"BITBUCKET_BUILD_NUMBER": "12", "BITBUCKET_REPO_FULL_NAME": "acme/project",
+				"BITBUCKET_GIT_HTTP_ORIGIN": "http://bitbucket.org/acme/project",
+			},
+			expected: &Build{
+				BuildNumber:  "12",
+				BuildURL:     "http://bitbucket.org/acme/project/addon/pipelines/home#!/results/12",
+				PipelineName: "acme/project",
+				Provider:     "Bitbucket Pipelines",
+			},
+		},
+		{
+			name: "Outside of a CI provider",
+			env:  map[string]string{"BUILD_NUMBER": "7"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range providersEnv {
+				t.Setenv(key, "")
+			}
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			require.Equal(t, tt.expected, Detect())
+		})
+	}
+}
+
+func TestBuildAttributes(t *testing.T) {
+	build := &Build{
+		BuildNumber:  "7",
+		PipelineName: "project/main",
+		Provider:     "Jenkins",
+	}
+
+	require.Equal(t, []attribute.KeyValue{
+		attribute.Key(Provider).String("Jenkins"),
+		attribute.Key(PipelineName).String("project/main"),
+		attribute.Key(BuildNumber).String("7"),
+	}, build.Attributes())
+}
@@ -0,0 +1,95 @@
+package ci
+
+import (
+	"os"
+	"strings"
+)
+
+// runURLs build the URL of the CI job running the tool from the environment variables of each CI provider.
+// They return an empty string when the tool does not run in their provider
+var runURLs = []func() string{
+	githubRunURL,
+	gitlabJobURL,
+	jenkinsBuildURL,
+	buildkiteJobURL,
+	circleciJobURL,
+	azurePipelinesJobURL,
+}
+
+// RunURL returns the URL of the CI job running the tool, from the first CI provider detected in the environment.
+// It returns an empty string outside of a CI provider
+func RunURL() string {
+	for _, runURL := range runURLs {
+		if u := runURL(); u != "" {
+			return u
+		}
+	}
+
+	return ""
+}
+
+// githubRunURL returns the URL of the attempt of the workflow run, as the ID of the job is not available
+func githubRunURL() string {
+	repository := os.Getenv("GITHUB_REPOSITORY")
+	runID := os.Getenv("GITHUB_RUN_ID")
+	if repository == "" || runID == "" {
+		return ""
+	}
+
+	serverURL := os.Getenv("GITHUB_SERVER_URL")
+	if serverURL == "" {
+		serverURL = "https://github.com"
+	}
+
+	runURL := strings.TrimSuffix(serverURL, "/") + "/" + repository + "/actions/runs/" + runID
+	if attempt := os.Getenv("GITHUB_RUN_ATTEMPT"); attempt != "" {
+		runURL += "/attempts/" + attempt
+	}
+
+	return runURL
+}
+
+func gitlabJobURL() string {
+	return os.Getenv("CI_JOB_URL")
+}
+
+func jenkinsBuildURL() string {
+	if os.Getenv("JENKINS_URL") == "" {
+		return ""
+	}
+
+	return os.Getenv("BUILD_URL")
+}
+
+func buildkiteJobURL() string {
+	buildURL := os.Getenv("BUILDKITE_BUILD_URL")
+	if buildURL == "" {
+		return ""
+	}
+
+	if jobID := os.Getenv("BUILDKITE_JOB_ID"); jobID != "" {
+		return buildURL + "#" + jobID
+	}
+
+	return buildURL
+}
+
+func circleciJobURL() string {
+	return os.Getenv("CIRCLE_BUILD_URL")
+}
+
+func azurePipelinesJobURL() string {
+	collectionURI := os.Getenv("SYSTEM_COLLECTIONURI")
+	project := os.Getenv("SYSTEM_TEAMPROJECT")
+	buildID := os.Getenv("BUILD_BUILDID")
+	if collectionURI == "" || project == "" || buildID == "" {
+		return ""
+	}
+
+	jobURL := strings.TrimSuffix(collectionURI, "/") + "/" + project + "/_build/results?buildId=" + buildID
+	if jobID := os.Getenv("SYSTEM_JOBID"); jobID != "" {
+		jobURL += "&view=logs&j=" + jobID
+	}
+
+	return jobURL
+}
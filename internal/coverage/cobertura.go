@@ -0,0 +1,60 @@
+package coverage
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+)
+
+type coberturaReport struct {
+	Packages []coberturaPackage `xml:"packages>package"`
+}
+
+type coberturaPackage struct {
+	Name    string           `xml:"name,attr"`
+	Classes []coberturaClass `xml:"classes>class"`
+}
+
+type coberturaClass struct {
+	Filename string          `xml:"filename,attr"`
+	Lines    []coberturaLine `xml:"lines>line"`
+}
+
+type coberturaLine struct {
+	Number int    `xml:"number,attr"`
+	Hits   string `xml:"hits,attr"`
+}
+
+// parseCobertura returns the line coverage of each package of a Cobertura report, counting the lines of its
+// classes once, as the lines of the methods repeat them
+func parseCobertura(data []byte) (*Report, error) {
+	var cobertura coberturaReport
+	if err := xml.Unmarshal(data, &cobertura); err != nil {
+		return nil, fmt.Errorf("failed to parse the Cobertura report: %w", err)
+	}
+
+	report := &Report{Format: Cobertura, Packages: []Package{}}
+	for _, p := range cobertura.Packages {
+		pkg := Package{Name: p.Name}
+
+		covered := map[string]bool{}
+		for _, class := range p.Classes {
+			for _, line := range class.Lines {
+				key := class.Filename + ":" + strconv.Itoa(line.Number)
+				hits, _ := strconv.ParseInt(line.Hits, 10, 64)
+				covered[key] = covered[key] || hits > 0
+			}
+		}
+
+		for _, c := range covered {
+			pkg.LinesValid++
+			if c {
+				pkg.LinesCovered++
+			}
+		}
+
+		report.Packages = append(report.Packages, pkg)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,144 @@
+package coverage
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	// Cobertura the XML format of Cobertura, also produced by coverage.py, gocover-cobertura or Istanbul
+	Cobertura = "cobertura"
+	// JaCoCo the XML format of JaCoCo
+	JaCoCo = "jacoco"
+	// LCOV the tracefile format of LCOV, also produced by Istanbul, c8 or grcov
+	LCOV = "lcov"
+)
+
+// Package the line coverage of a package, or of a directory for the formats without packages
+type Package struct {
+	Name         string
+	LinesCovered int
+	LinesValid   int
+}
+
+// Percentage the percentage of the lines covered, from 0 to 100. It's zero when there are no lines
+func (p Package) Percentage() float64 {
+	if p.LinesValid == 0 {
+		return 0
+	}
+
+	return float64(p.LinesCovered) * 100 / float64(p.LinesValid)
+}
+
+// Report the line coverage of each package of a coverage file
+type Report struct {
+	Format   string
+	Packages []Package
+}
+
+// Total returns the line coverage of all the packages
+func (r *Report) Total() Package {
+	total := Package{}
+	for _, p := range r.Packages {
+		total.LinesCovered += p.LinesCovered
+		total.LinesValid += p.LinesValid
+	}
+
+	return total
+}
+
+// Lookup returns the package matching the name of a test suite or its package: the package with the same name,
+// or the longest one containing it, i.e. com.example for com.example.FooTest. The separators of the names, dots,
+// slashes or backslashes, are equivalent
+func (r *Report) Lookup(name string) (Package, bool) {
+	name = normalizeName(name)
+	if name == "" {
+		return Package{}, false
+	}
+
+	var match Package
+	found := false
+	matchLen := -1
+	for _, p := range r.Packages {
+		key := normalizeName(p.Name)
+		if key == "" || len(key) <= matchLen {
+			continue
+		}
+
+		if name == key || strings.HasPrefix(name, key+".") {
+			match = p
+			found = true
+			matchLen = len(key)
+		}
+	}
+
+	return match, found
+}
+
+func normalizeName(name string) string {
+	return strings.Trim(strings.NewReplacer("/", ".", `\`, ".").Replace(name), ".")
+}
+
+// ParseFile reads and parses the coverage file at path
+func ParseFile(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the coverage file: %w", err)
+	}
+
+	report, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the coverage file %s: %w", path, err)
+	}
+
+	return report, nil
+}
+
+// Parse parses the coverage report, detecting its format from the content: the root element of the XML
+// documents, coverage for Cobertura and report for JaCoCo, or the records of LCOV
+func Parse(data []byte) (*Report, error) {
+	trimmed := bytes.TrimSpace(data)
+	if !bytes.HasPrefix(trimmed, []byte("<")) {
+		if bytes.HasPrefix(trimmed, []byte("TN:")) || bytes.Contains(trimmed, []byte("SF:")) {
+			return parseLCOV(data)
+		}
+
+		return nil, fmt.Errorf("unknown coverage format. Supported formats: %s, %s, %s", Cobertura, JaCoCo, LCOV)
+	}
+
+	root, err := rootElement(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch root {
+	case "coverage":
+		return parseCobertura(data)
+	case "report":
+		return parseJaCoCo(data)
+	default:
+		return nil, fmt.Errorf("unknown coverage format with the root element %s. Supported formats: %s, %s, %s", root, Cobertura, JaCoCo, LCOV)
+	}
+}
+
+// rootElement returns the name of the root element of the XML document
+func rootElement(data []byte) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return "", fmt.Errorf("the coverage file has no root element")
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to parse the coverage file: %w", err)
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package coverage
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFile(t *testing.T) {
+	t.Run("Cobertura", func(t *testing.T) {
+		report, err := ParseFile(filepath.Join("testdata", "cobertura.xml"))
+		require.NoError(t, err)
+
+		require.Equal(t, Cobertura, report.Format)
+		require.Equal(t, []Package{
+			{Name: "github.com/octocat/hello-world/config", LinesCovered: 3, LinesValid: 4},
+			{Name: "github.com/octocat/hello-world", LinesCovered: 0, LinesValid: 1},
+		}, report.Packages)
+		require.Equal(t, 60.0, report.Total().Percentage())
+	})
+
+	t.Run("JaCoCo", func(t *testing.T) {
+		report, err := ParseFile(filepath.Join("testdata", "jacoco.xml"))
+		require.NoError(t, err)
+
+		require.Equal(t, JaCoCo, report.Format)
+		require.Equal(t, []Package{
+			{Name: "com.example.calculator", LinesCovered: 9, LinesValid: 10},
+			{Name: "com.example.parsers", LinesCovered: 1, LinesValid: 4},
+		}, report.Packages)
+	})
+
+	t.Run("LCOV", func(t *testing.T) {
+		report, err := ParseFile(filepath.Join("testdata", "lcov.info"))
+		require.NoError(t, err)
+
+		require.Equal(t, LCOV, report.Format)
+		require.Equal(t, []Package{
+			{Name: "src/utils", LinesCovered: 3, LinesValid: 5},
+			{Name: "src", LinesCovered: 0, LinesValid: 1},
+		}, report.Packages)
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		_, err := ParseFile(filepath.Join(t.TempDir(), "coverage.xml"))
+		require.ErrorContains(t, err, "failed to read the coverage file")
+	})
+}
+
+func TestParse_UnknownFormat(t *testing.T) {
+	_, err := Parse([]byte(`<testsuites></testsuites>`))
+	require.ErrorContains(t, err, "unknown coverage format with the root element testsuites")
+
+	_, err = Parse([]byte("mode: set\n"))
+	require.ErrorContains(t, err, "unknown coverage format")
+}
+
+func TestReport_Lookup(t *testing.T) {
+	report := &Report{Packages: []Package{
+		{Name: "com.example", LinesCovered: 1, LinesValid: 2},
+		{Name: "com.example.calculator", LinesCovered: 9, LinesValid: 10},
+		{Name: "github.com/octocat/hello-world", LinesCovered: 0, LinesValid: 1},
+		{Name: "src/utils", LinesCovered: 3, LinesValid: 5},
+	}}
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{name: "com.example.calculator.CalculatorTest", expected: "com.example.calculator"},
+		{name: "com.example.FooTest", expected: "com.example"},
+		{name: "github.com/octocat/hello-world", expected: "github.com/octocat/hello-world"},
+		{name: `src\utils\math.test.js`, expected: "src/utils"},
+		{name: "com.examples.FooTest"},
+		{name: ""},
+	}
+
+	for _, tt := range tests {
+		pkg, ok := report.Lookup(tt.name)
+		require.Equal(t, tt.expected != "", ok, tt.name)
+		require.Equal(t, tt.expected, pkg.Name, tt.name)
+	}
+}
+
+func TestPackage_Percentage(t *testing.T) {
+	require.Equal(t, 75.0, Package{LinesCovered: 3, LinesValid: 4}.Percentage())
+	require.Equal(t, 0.0, Package{}.Percentage())
+}
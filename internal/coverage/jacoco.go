@@ -0,0 +1,62 @@
+package coverage
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+type jacocoReport struct {
+	Packages []jacocoPackage `xml:"package"`
+	Groups   []jacocoGroup   `xml:"group"`
+}
+
+// jacocoGroup the groups of the reports of multi-module projects, which can be nested
+type jacocoGroup struct {
+	Packages []jacocoPackage `xml:"package"`
+	Groups   []jacocoGroup   `xml:"group"`
+}
+
+type jacocoPackage struct {
+	Name     string          `xml:"name,attr"`
+	Counters []jacocoCounter `xml:"counter"`
+}
+
+type jacocoCounter struct {
+	Type    string `xml:"type,attr"`
+	Missed  int    `xml:"missed,attr"`
+	Covered int    `xml:"covered,attr"`
+}
+
+// parseJaCoCo returns the line coverage of each package of a JaCoCo report, from their LINE counters. The names
+// of the packages use dots, as the Java packages, instead of the slashes of the report
+func parseJaCoCo(data []byte) (*Report, error) {
+	var jacoco jacocoReport
+	if err := xml.Unmarshal(data, &jacoco); err != nil {
+		return nil, fmt.Errorf("failed to parse the JaCoCo report: %w", err)
+	}
+
+	packages := jacoco.Packages
+	groups := jacoco.Groups
+	for len(groups) > 0 {
+		group := groups[0]
+		groups = append(groups[1:], group.Groups...)
+		packages = append(packages, group.Packages...)
+	}
+
+	report := &Report{Format: JaCoCo, Packages: []Package{}}
+	for _, p := range packages {
+		pkg := Package{Name: strings.ReplaceAll(p.Name, "/", ".")}
+
+		for _, counter := range p.Counters {
+			if counter.Type == "LINE" {
+				pkg.LinesCovered = counter.Covered
+				pkg.LinesValid = counter.Covered + counter.Missed
+			}
+		}
+
+		report.Packages = append(report.Packages, pkg)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,96 @@
+package coverage
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// parseLCOV returns the line coverage of each directory of an LCOV tracefile, as it has no packages. The LF and
+// LH records of each file are used when present, otherwise its DA records are counted
+func parseLCOV(data []byte) (*Report, error) {
+	type lcovFile struct {
+		dir                        string
+		found, hit, daFound, daHit int
+		hasSummary                 bool
+	}
+
+	dirs := map[string]*Package{}
+	order := []string{}
+
+	var current *lcovFile
+	flush := func() {
+		if current == nil {
+			return
+		}
+
+		pkg, ok := dirs[current.dir]
+		if !ok {
+			pkg = &Package{Name: current.dir}
+			dirs[current.dir] = pkg
+			order = append(order, current.dir)
+		}
+
+		if current.hasSummary {
+			pkg.LinesValid += current.found
+			pkg.LinesCovered += current.hit
+		} else {
+			pkg.LinesValid += current.daFound
+			pkg.LinesCovered += current.daHit
+		}
+
+		current = nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		record, value, _ := strings.Cut(line, ":")
+
+		switch record {
+		case "SF":
+			flush()
+			current = &lcovFile{dir: path.Dir(strings.ReplaceAll(value, `\`, "/"))}
+		case "DA":
+			if current == nil {
+				continue
+			}
+			fields := strings.Split(value, ",")
+			if len(fields) < 2 {
+				continue
+			}
+			hits, _ := strconv.ParseInt(fields[1], 10, 64)
+			current.daFound++
+			if hits > 0 {
+				current.daHit++
+			}
+		case "LF":
+			if current != nil {
+				current.found, _ = strconv.Atoi(value)
+				current.hasSummary = true
+			}
+		case "LH":
+			if current != nil {
+				current.hit, _ = strconv.Atoi(value)
+				current.hasSummary = true
+			}
+		case "end_of_record":
+			flush()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to parse the LCOV tracefile: %w", err)
+	}
+	flush()
+
+	report := &Report{Format: LCOV, Packages: []Package{}}
+	for _, dir := range order {
+		report.Packages = append(report.Packages, *dirs[dir])
+	}
+
+	return report, nil
+}
@@ -0,0 +1,165 @@
+package formats
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joshdk/go-junit"
+)
+
+// ctestDefaultSuiteName name of the suites of the reports without build name
+const ctestDefaultSuiteName = "CTest"
+
+// ctestLabels name of the property holding the labels of a test
+const ctestLabels = "Labels"
+
+// ctestBuiltinMeasurements measurements added by CTest to every test, which are not properties of the test
+var ctestBuiltinMeasurements = map[string]bool{
+	"Command Line":      true,
+	"Completion Status": true,
+	"Environment":       true,
+	"Execution Time":    true,
+	"Exit Code":         true,
+	"Exit Value":        true,
+	"Fail Reason":       true,
+	"Pass Reason":       true,
+	"Processors":        true,
+}
+
+type ctestSite struct {
+	XMLName   xml.Name    `xml:"Site"`
+	Name      string      `xml:"Name,attr"`
+	BuildName string      `xml:"BuildName,attr"`
+	StartTime int64       `xml:"Testing>StartTestTime"`
+	Tests     []ctestTest `xml:"Testing>Test"`
+}
+
+type ctestTest struct {
+	Status       string             `xml:"Status,attr"`
+	Name         string             `xml:"Name"`
+	Path         string             `xml:"Path"`
+	Measurements []ctestMeasurement `xml:"Results>NamedMeasurement"`
+	Output       ctestValue         `xml:"Results>Measurement>Value"`
+	Labels       []string           `xml:"Labels>Label"`
+}
+
+type ctestMeasurement struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"Value"`
+}
+
+type ctestValue struct {
+	Encoding    string `xml:"encoding,attr"`
+	Compression string `xml:"compression,attr"`
+	Text        string `xml:",chardata"`
+}
+
+// ingestCTest converts the Test.xml report of CTest, written in the Testing directory of the build by ctest -T Test,
+// into a jUnit test suite, named after the build. The classname of the test cases is their path in the build tree,
+// and their output, compressed by default, is their standard output. The tests not run, i.e. the disabled ones,
+// are skipped, and the failed ones have the exit code, or the reason of the failure, as message. The labels of the
+// tests, and the measurements they print with the DartMeasurement tags, are properties of the test cases
+func ingestCTest(data []byte) ([]junit.Suite, error) {
+	var site ctestSite
+	if err := xml.Unmarshal(data, &site); err != nil {
+		return nil, fmt.Errorf("failed to parse the CTest report: %w", err)
+	}
+
+	name := site.BuildName
+	if name == "" {
+		name = ctestDefaultSuiteName
+	}
+
+	suite := junit.Suite{
+		Name:    name,
+		Package: site.Name,
+	}
+
+	for _, t := range site.Tests {
+		suite.Tests = append(suite.Tests, ctestToTest(t))
+	}
+
+	suite.Aggregate()
+
+	return []junit.Suite{suite}, nil
+}
+
+func ctestToTest(t ctestTest) junit.Test {
+	measurements := map[string]string{}
+	properties := map[string]string{}
+	for _, m := range t.Measurements {
+		value := strings.TrimSpace(m.Value)
+		if ctestBuiltinMeasurements[m.Name] {
+			measurements[m.Name] = value
+			continue
+		}
+		properties[m.Name] = value
+	}
+	if len(t.Labels) > 0 {
+		properties[ctestLabels] = strings.Join(t.Labels, ",")
+	}
+	if len(properties) == 0 {
+		properties = nil
+	}
+
+	seconds, _ := strconv.ParseFloat(measurements["Execution Time"], 64)
+
+	test := junit.Test{
+		Name:       t.Name,
+		Classname:  t.Path,
+		Duration:   time.Duration(seconds * float64(time.Second)),
+		Status:     junit.StatusPassed,
+		Properties: properties,
+		SystemOut:  strings.TrimSpace(ctestOutput(t.Output)),
+	}
+
+	switch t.Status {
+	case "failed":
+		message := measurements["Fail Reason"]
+		if message == "" {
+			message = measurements["Exit Code"]
+		}
+
+		test.Status = junit.StatusFailed
+		test.Message = message
+		test.Error = junit.Error{Message: message, Body: test.SystemOut}
+	case "notrun":
+		test.Status = junit.StatusSkipped
+		test.Message = measurements["Completion Status"]
+	}
+
+	return test
+}
+
+// ctestOutput returns the output of a test, decoding it if it's compressed. CTest labels the compressed outputs as
+// gzip, although they are zlib streams
+func ctestOutput(value ctestValue) string {
+	if value.Encoding != "base64" {
+		return value.Text
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value.Text))
+	if err != nil || value.Compression == "" {
+		return string(data)
+	}
+
+	var r io.Reader
+	if zr, err := zlib.NewReader(bytes.NewReader(data)); err == nil {
+		r = zr
+	} else if gr, err := gzip.NewReader(bytes.NewReader(data)); err == nil {
+		r = gr
+	} else {
+		return ""
+	}
+
+	output, _ := io.ReadAll(r)
+	return string(output)
+}
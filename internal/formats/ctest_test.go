@@ -0,0 +1,59 @@
+package formats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIngestCTest(t *testing.T) {
+	t.Run("Valid report", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join("testdata", "ctest-Test.xml"))
+		require.NoError(t, err)
+
+		suites, err := Parse(CTest, data)
+		require.NoError(t, err)
+		require.Len(t, suites, 1)
+
+		suite := suites[0]
+		require.Equal(t, "Linux-c++", suite.Name)
+		require.Equal(t, "ci-runner", suite.Package)
+		require.Equal(t, 3, suite.Totals.Tests)
+		require.Equal(t, 1, suite.Totals.Passed)
+		require.Equal(t, 1, suite.Totals.Failed)
+		require.Equal(t, 1, suite.Totals.Skipped)
+
+		add := suite.Tests[0]
+		require.Equal(t, "add", add.Name)
+		require.Equal(t, "./tests", add.Classname)
+		require.Equal(t, 12*time.Millisecond, add.Duration)
+		require.Equal(t, "all tests passed", add.SystemOut)
+		require.Equal(t, map[string]string{"Labels": "unit,math", "allocations": "42"}, add.Properties)
+
+		divide := suite.Tests[1]
+		require.Equal(t, junit.StatusFailed, divide.Status)
+		require.Equal(t, "Failed", divide.Message)
+		require.Contains(t, divide.SystemOut, "[  FAILED  ] AddTest.Negative") // the compressed output
+		require.Nil(t, divide.Properties)
+
+		slow := suite.Tests[2]
+		require.Equal(t, junit.StatusSkipped, slow.Status)
+		require.Equal(t, "Disabled", slow.Message)
+	})
+
+	t.Run("Timestamps", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join("testdata", "ctest-Test.xml"))
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"2023-09-12T08:30:00Z"}, SuiteTimestamps(CTest, data))
+	})
+
+	t.Run("Invalid report", func(t *testing.T) {
+		_, err := Parse(CTest, []byte("<testsuite>"))
+		require.ErrorContains(t, err, "failed to parse the CTest report")
+	})
+}
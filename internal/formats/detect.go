@@ -0,0 +1,131 @@
+package formats
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"path/filepath"
+	"strings"
+)
+
+// Auto detects the format of each report from its content, falling back to JUnit
+const Auto = "auto"
+
+// sniffLines maximum number of lines read to detect the formats written line by line, i.e. TAP
+const sniffLines = 20
+
+// xmlRootFormats formats of the XML reports, by the name of their root element
+var xmlRootFormats = map[string]string{
+	"Site":           CTest,
+	"TestRun":        TRX,
+	"test-run":       NUnit,
+	"testng-results": TestNG,
+	"testsuite":      JUnit,
+	"testsuites":     JUnit,
+}
+
+// ForReport returns the format of the report with the name and the content. The formats other than auto are
+// returned as they are, so they override the detection. The auto format is the one of the extension of the name,
+// if it identifies a format, i.e. .trx, or it's detected from the content. The auto format is returned as it is
+// when the extension does not identify a format and the content is nil, i.e. before reading the report
+func ForReport(format string, name string, data []byte) string {
+	if format != Auto {
+		return format
+	}
+
+	if detected, ok := extensions[strings.ToLower(filepath.Ext(name))]; ok {
+		return detected
+	}
+
+	if data != nil {
+		return Detect(data)
+	}
+
+	return format
+}
+
+// Detect returns the format of the report from its content: the root element of the XML reports, the keys of the
+// JSON documents of Jest and mocha, the events of go test -json, or the test points of TAP. It returns JUnit if the
+// format is not recognized, so the errors are the ones of the JUnit parser
+func Detect(data []byte) string {
+	data = bytes.TrimSpace(bytes.TrimPrefix(data, utf8BOM))
+
+	switch {
+	case bytes.HasPrefix(data, []byte("<")):
+		return detectXML(data)
+	case bytes.HasPrefix(data, []byte("{")):
+		return detectJSON(data)
+	}
+
+	if detectTAP(data) {
+		return TAP
+	}
+
+	// the build output printed before the first event by the old versions of go test -json
+	if bytes.Contains(data, []byte(`"Action":`)) {
+		return GoTest
+	}
+
+	return JUnit
+}
+
+// detectXML returns the format of the root element of the XML report
+func detectXML(data []byte) string {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return JUnit
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			if format, ok := xmlRootFormats[start.Name.Local]; ok {
+				return format
+			}
+
+			return JUnit
+		}
+	}
+}
+
+// detectJSON returns the format of the first JSON document of the report: an event of go test -json, whose
+// documents are one per line, or the output of Jest or mocha
+func detectJSON(data []byte) string {
+	var document map[string]json.RawMessage
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&document); err != nil {
+		return JUnit
+	}
+
+	switch {
+	case document["Action"] != nil:
+		return GoTest
+	case document["testResults"] != nil:
+		return Jest
+	case document["stats"] != nil && document["tests"] != nil:
+		return Mocha
+	default:
+		return JUnit
+	}
+}
+
+// detectTAP reports whether the first lines of the report are a TAP stream: a version, a plan or a test point
+func detectTAP(data []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for i := 0; i < sniffLines && scanner.Scan(); i++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case strings.HasPrefix(line, "TAP version"), tapPlan.MatchString(line), tapTestPoint.MatchString(line):
+			return true
+		case line == "", strings.HasPrefix(line, "#"), tapProveHeader.MatchString(line):
+			continue
+		default:
+			return false
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,62 @@
+package formats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		name   string
+		path   string
+		format string
+	}{
+		{name: "JUnit", path: filepath.Join("..", "..", "TEST-sample.xml"), format: JUnit},
+		{name: "TestNG", path: filepath.Join("testdata", "testng-results.xml"), format: TestNG},
+		{name: "NUnit", path: filepath.Join("testdata", "TestResult.xml"), format: NUnit},
+		{name: "TRX", path: filepath.Join("testdata", "results.trx"), format: TRX},
+		{name: "CTest", path: filepath.Join("testdata", "ctest-Test.xml"), format: CTest},
+		{name: "Go test", path: filepath.Join("testdata", "gotest.json"), format: GoTest},
+		{name: "Jest", path: filepath.Join("testdata", "jest.json"), format: Jest},
+		{name: "Mocha", path: filepath.Join("testdata", "mocha.json"), format: Mocha},
+		{name: "TAP", path: filepath.Join("testdata", "prove.tap"), format: TAP},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := os.ReadFile(tc.path)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.format, Detect(data))
+		})
+	}
+
+	t.Run("Bats", func(t *testing.T) {
+		require.Equal(t, TAP, Detect([]byte("1..2\nok 1 adds\nnot ok 2 divides\n")))
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		require.Equal(t, JUnit, Detect([]byte("plain text")))
+		require.Equal(t, JUnit, Detect([]byte(`{"unknown": true}`)))
+	})
+
+	t.Run("Auto", func(t *testing.T) {
+		suites, err := Parse(Auto, []byte("TAP version 13\n1..1\nok 1 adds\n"))
+		require.NoError(t, err)
+		require.Len(t, suites, 1)
+		require.Equal(t, "adds", suites[0].Tests[0].Name)
+	})
+}
+
+func TestForReport(t *testing.T) {
+	data := []byte("TAP version 13\nok 1 adds\n")
+
+	require.Equal(t, TRX, ForReport(Auto, filepath.Join("TestResults", "results.TRX"), nil))
+	require.Equal(t, JUnit, ForReport(JUnit, filepath.Join("TestResults", "results.trx"), nil)) // the format overrides the extension
+	require.Equal(t, JUnit, ForReport(JUnit, "report.tap", data))                               // the format overrides the detection
+	require.Equal(t, TAP, ForReport(Auto, "report.tap", data))
+	require.Equal(t, Auto, ForReport(Auto, "-", nil))
+}
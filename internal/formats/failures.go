@@ -0,0 +1,46 @@
+package formats
+
+import (
+	"strings"
+
+	"github.com/joshdk/go-junit"
+)
+
+// Failures the failure and error elements of a test case with more than one of them, i.e. the soft assertions of
+// some frameworks, in the order of the report. It's the error of the test case, and errors.As finds the first one
+type Failures []junit.Error
+
+// Error returns the textual descriptions of the failures, one after the other
+func (f Failures) Error() string {
+	descriptions := make([]string, 0, len(f))
+	for _, failure := range f {
+		descriptions = append(descriptions, failure.Error())
+	}
+
+	return strings.Join(descriptions, "\n")
+}
+
+// Unwrap returns the failures, so errors.As finds the first one
+func (f Failures) Unwrap() []error {
+	errs := make([]error, 0, len(f))
+	for _, failure := range f {
+		errs = append(errs, failure)
+	}
+
+	return errs
+}
+
+// TestFailures returns the failures of the test case, in the order of the report. It's empty if the test case has
+// no error, and the errors which are neither a junit.Error nor Failures are returned as the body of a junit.Error
+func TestFailures(test junit.Test) []junit.Error {
+	switch err := test.Error.(type) {
+	case nil:
+		return nil
+	case Failures:
+		return err
+	case junit.Error:
+		return []junit.Error{err}
+	default:
+		return []junit.Error{{Body: err.Error()}}
+	}
+}
@@ -0,0 +1,84 @@
+package formats
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/joshdk/go-junit"
+)
+
+const (
+	// CTest the Test.xml format of the dashboard submissions of CTest
+	CTest = "ctest"
+	// GoTest the JSON output of go test -json, or of go tool test2json
+	GoTest = "gotest"
+	// Jest the JSON output of jest --json
+	Jest = "jest"
+	// JUnit the JUnit XML format, also produced by most of the test runners
+	JUnit = "junit"
+	// Mocha the JSON output of mocha --reporter json
+	Mocha = "mocha"
+	// NUnit the TestResult.xml format of NUnit 3
+	NUnit = "nunit"
+	// TAP the Test Anything Protocol, produced by prove, Bats and node-tap
+	TAP = "tap"
+	// TRX the .trx format of MSTest and VSTest
+	TRX = "trx"
+	// TestNG the testng-results.xml format produced by TestNG
+	TestNG = "testng"
+)
+
+// Parser converts a report in a given format into jUnit test suites
+type Parser func(data []byte) ([]junit.Suite, error)
+
+var parsers = map[string]Parser{
+	CTest:  ingestCTest,
+	GoTest: ingestGoTest,
+	Jest:   ingestJest,
+	JUnit:  ingestJUnit,
+	Mocha:  ingestMocha,
+	NUnit:  ingestNUnit,
+	TAP:    ingestTAP,
+	TestNG: ingestTestNG,
+	TRX:    ingestTRX,
+}
+
+// extensions formats of the reports with a file extension that identifies them, whatever the configured format
+var extensions = map[string]string{
+	".trx": TRX,
+}
+
+// Supported returns the sorted list of the supported formats
+func Supported() []string {
+	formats := make([]string, 0, len(parsers))
+	for format := range parsers {
+		formats = append(formats, format)
+	}
+
+	slices.Sort(formats)
+
+	return formats
+}
+
+// Validate returns an error listing the supported formats if the format is neither supported nor auto
+func Validate(format string) error {
+	if _, ok := parsers[format]; !ok && format != Auto {
+		return fmt.Errorf("unsupported format: %s. Supported formats: %s", format, strings.Join(Supported(), ", "))
+	}
+
+	return nil
+}
+
+// Parse converts the report into jUnit test suites, using the parser for the format, or for the detected one
+func Parse(format string, data []byte) ([]junit.Suite, error) {
+	if err := Validate(format); err != nil {
+		return nil, err
+	}
+
+	if format == Auto {
+		format = Detect(data)
+	}
+
+	return parsers[format](data)
+}
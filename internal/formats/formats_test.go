@@ -0,0 +1,33 @@
+package formats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("JUnit", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join("..", "..", "TEST-sample.xml"))
+		require.NoError(t, err)
+
+		suites, err := Parse(JUnit, data)
+		require.NoError(t, err)
+		require.NotEmpty(t, suites)
+	})
+
+	t.Run("Unsupported format", func(t *testing.T) {
+		_, err := Parse("foo", []byte{})
+		require.ErrorContains(t, err, "unsupported format: foo. Supported formats: ctest, gotest, jest, junit, mocha, nunit, tap, testng, trx")
+	})
+}
+
+func TestValidate(t *testing.T) {
+	for _, format := range Supported() {
+		require.NoError(t, Validate(format))
+	}
+
+	require.Error(t, Validate(""))
+}
@@ -0,0 +1,215 @@
+package formats
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/joshdk/go-junit"
+)
+
+// goTestMainName name of the test case added to the packages failing without a failed test, i.e. a build
+// failure or a panic in TestMain, as gotestsum does
+const goTestMainName = "TestMain"
+
+// utf8BOM the byte order mark PowerShell writes at the start of the piped output
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// goTestEvent a line of the go test -json output, as described by go doc test2json
+type goTestEvent struct {
+	Time    time.Time `json:"Time"`
+	Action  string    `json:"Action"`
+	Package string    `json:"Package"`
+	Test    string    `json:"Test"`
+	Elapsed float64   `json:"Elapsed"`
+	Output  string    `json:"Output"`
+}
+
+// goTestPackage the events of a package received so far
+type goTestPackage struct {
+	start  time.Time
+	output strings.Builder
+	order  []string // names of the tests, in the order they started
+	tests  map[string]*goTestCase
+}
+
+type goTestCase struct {
+	action  string // the last action of the test: pass, fail, skip or bench, or empty if it did not finish
+	elapsed float64
+	output  strings.Builder
+}
+
+// ingestGoTest parses the go test -json output, returning a suite per package
+func ingestGoTest(data []byte) ([]junit.Suite, error) {
+	suites := []junit.Suite{}
+
+	err := streamGoTest(bytes.NewReader(data), func(suite junit.Suite, _ string) error {
+		suites = append(suites, suite)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return suites, nil
+}
+
+// streamGoTest reads the go test -json output line by line, yielding the suite of each package as soon as the
+// package finishes, so the packages are exported while the rest are still running. The lines which are not
+// JSON events, i.e. the output of the build, are skipped. The packages not finished when the output ends are
+// yielded too, in the order they started, with their unfinished tests as failed
+func streamGoTest(r io.Reader, yield Yield) error {
+	packages := map[string]*goTestPackage{}
+	order := []string{}
+
+	scanner := bufio.NewScanner(r)
+	// the output of a test can be a very long line, i.e. a JSON document
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(bytes.TrimPrefix(scanner.Bytes(), utf8BOM))
+		if !bytes.HasPrefix(line, []byte("{")) {
+			continue
+		}
+
+		var event goTestEvent
+		if err := json.Unmarshal(line, &event); err != nil || event.Package == "" {
+			continue
+		}
+
+		pkg, ok := packages[event.Package]
+		if !ok {
+			pkg = &goTestPackage{start: event.Time, tests: map[string]*goTestCase{}}
+			packages[event.Package] = pkg
+			order = append(order, event.Package)
+		}
+
+		if event.Test == "" {
+			switch event.Action {
+			case "output", "build-output":
+				pkg.output.WriteString(event.Output)
+			case "pass", "fail", "skip":
+				delete(packages, event.Package)
+
+				// the packages without test files
+				if event.Action == "skip" && len(pkg.order) == 0 {
+					continue
+				}
+
+				suite := pkg.suite(event.Package, event.Action, event.Elapsed)
+				if err := yield(suite, pkg.timestamp()); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		test, ok := pkg.tests[event.Test]
+		if !ok {
+			test = &goTestCase{}
+			pkg.tests[event.Test] = test
+			pkg.order = append(pkg.order, event.Test)
+		}
+
+		switch event.Action {
+		case "output":
+			test.output.WriteString(event.Output)
+		case "pass", "fail", "skip", "bench":
+			test.action = event.Action
+			test.elapsed = event.Elapsed
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	// the output ended before these packages finished, i.e. the go command was interrupted
+	for _, name := range order {
+		pkg, ok := packages[name]
+		if !ok {
+			continue
+		}
+
+		if err := yield(pkg.suite(name, "fail", 0), pkg.timestamp()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// suite returns the suite of the package, which finished with the action. The elapsed time of the package is the
+// duration of the suite, as the durations of the subtests are already included in their parent tests. The
+// benchmarks only get the bench action when they log, so the ones without an action in a passed package passed
+func (p *goTestPackage) suite(name string, action string, elapsed float64) junit.Suite {
+	suite := junit.Suite{
+		Name:      name,
+		Package:   name,
+		SystemOut: p.output.String(),
+	}
+
+	failed := false
+	for _, testName := range p.order {
+		test := p.tests[testName]
+
+		testcase := junit.Test{
+			Name:      testName,
+			Classname: name,
+			Duration:  goTestDuration(test.elapsed),
+			Status:    junit.StatusPassed,
+			SystemOut: test.output.String(),
+		}
+
+		if test.action == "" && action == "pass" && strings.HasPrefix(testName, "Benchmark") {
+			test.action = "bench"
+		}
+
+		switch test.action {
+		case "skip":
+			testcase.Status = junit.StatusSkipped
+			testcase.Message = "Skipped"
+		case "fail", "":
+			failed = true
+			testcase.Status = junit.StatusFailed
+			testcase.Message = "Failed"
+			testcase.Error = junit.Error{Message: "Failed", Body: testcase.SystemOut}
+		}
+
+		suite.Tests = append(suite.Tests, testcase)
+	}
+
+	if action == "fail" && !failed {
+		suite.Tests = append(suite.Tests, junit.Test{
+			Name:      goTestMainName,
+			Classname: name,
+			Status:    junit.StatusFailed,
+			Message:   "Failed",
+			Error:     junit.Error{Message: "Failed", Body: suite.SystemOut},
+		})
+	}
+
+	suite.Aggregate()
+	if elapsed > 0 {
+		suite.Totals.Duration = goTestDuration(elapsed)
+	}
+
+	return suite
+}
+
+// timestamp returns the time of the first event of the package, which is when it started
+func (p *goTestPackage) timestamp() string {
+	if p.start.IsZero() {
+		return ""
+	}
+
+	return p.start.Format(time.RFC3339Nano)
+}
+
+func goTestDuration(elapsed float64) time.Duration {
+	return time.Duration(elapsed * float64(time.Second))
+}
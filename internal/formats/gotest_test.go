@@ -0,0 +1,229 @@
+package formats
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestIngestGoTest(t *testing.T) {
+	t.Run("Valid output", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join("testdata", "gotest.json"))
+		require.NoError(t, err)
+
+		suites, err := Parse(GoTest, data)
+		require.NoError(t, err)
+		require.Len(t, suites, 1) // the package without test files is skipped
+
+		suite := suites[0]
+		require.Equal(t, "example.com/calc", suite.Name)
+		require.Equal(t, "example.com/calc", suite.Package)
+		require.Equal(t, "FAIL\n", suite.SystemOut)
+		require.Equal(t, 400*time.Millisecond, suite.Totals.Duration)
+		require.Equal(t, 4, suite.Totals.Tests)
+		require.Equal(t, 1, suite.Totals.Passed)
+		require.Equal(t, 2, suite.Totals.Failed)
+		require.Equal(t, 1, suite.Totals.Skipped)
+
+		names := []string{}
+		for _, test := range suite.Tests {
+			names = append(names, test.Name)
+		}
+		require.Equal(t, []string{"TestAdd", "TestDiv", "TestDiv/by_zero", "TestMul"}, names)
+
+		failed := suite.Tests[2]
+		require.Equal(t, junit.StatusFailed, failed.Status)
+		require.Equal(t, "example.com/calc", failed.Classname)
+		require.Equal(t, 100*time.Millisecond, failed.Duration)
+		require.Equal(t, junit.Error{Message: "Failed", Body: "    calc_test.go:20: expected an error\n"}, failed.Error)
+
+		require.Equal(t, junit.StatusSkipped, suite.Tests[3].Status)
+	})
+
+	t.Run("Benchmarks", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join("testdata", "gotest-bench.json"))
+		require.NoError(t, err)
+
+		suites, err := Parse(GoTest, data)
+		require.NoError(t, err)
+		require.Len(t, suites, 1)
+
+		suite := suites[0]
+		require.Equal(t, 3, suite.Totals.Tests)
+		require.Equal(t, 3, suite.Totals.Passed)
+		require.Equal(t, 0, suite.Totals.Failed)
+
+		names := []string{}
+		for _, test := range suite.Tests {
+			require.Equal(t, junit.StatusPassed, test.Status, test.Name)
+			names = append(names, test.Name)
+		}
+		require.Equal(t, []string{"TestAdd", "BenchmarkAdd", "BenchmarkMul"}, names)
+		require.Contains(t, suite.Tests[2].SystemOut, "564.1 ns/op")
+	})
+
+	t.Run("Benchmarks of unfinished packages", func(t *testing.T) {
+		data := `{"Action":"run","Package":"example.com/slow","Test":"BenchmarkSlow"}`
+
+		suites, err := Parse(GoTest, []byte(data))
+		require.NoError(t, err)
+		require.Len(t, suites, 1)
+		require.Equal(t, junit.StatusFailed, suites[0].Tests[0].Status)
+	})
+
+	t.Run("Parallel tests", func(t *testing.T) {
+		data := `{"Action":"run","Package":"example.com/calc","Test":"TestAdd"}
+{"Action":"output","Package":"example.com/calc","Test":"TestAdd","Output":"=== RUN   TestAdd\n"}
+{"Action":"output","Package":"example.com/calc","Test":"TestAdd","Output":"=== PAUSE TestAdd\n"}
+{"Action":"pause","Package":"example.com/calc","Test":"TestAdd"}
+{"Action":"run","Package":"example.com/calc","Test":"TestSub"}
+{"Action":"output","Package":"example.com/calc","Test":"TestSub","Output":"=== RUN   TestSub\n"}
+{"Action":"output","Package":"example.com/calc","Test":"TestSub","Output":"=== PAUSE TestSub\n"}
+{"Action":"pause","Package":"example.com/calc","Test":"TestSub"}
+{"Action":"cont","Package":"example.com/calc","Test":"TestAdd"}
+{"Action":"output","Package":"example.com/calc","Test":"TestAdd","Output":"=== CONT  TestAdd\n"}
+{"Action":"cont","Package":"example.com/calc","Test":"TestSub"}
+{"Action":"output","Package":"example.com/calc","Test":"TestSub","Output":"=== CONT  TestSub\n"}
+{"Action":"output","Package":"example.com/calc","Test":"TestSub","Output":"    calc_test.go:30: expected -1\n"}
+{"Action":"output","Package":"example.com/calc","Test":"TestAdd","Output":"    calc_test.go:12: adding\n"}
+{"Action":"output","Package":"example.com/calc","Test":"TestSub","Output":"--- FAIL: TestSub (0.10s)\n"}
+{"Action":"fail","Package":"example.com/calc","Test":"TestSub","Elapsed":0.1}
+{"Action":"output","Package":"example.com/calc","Test":"TestAdd","Output":"--- PASS: TestAdd (0.20s)\n"}
+{"Action":"pass","Package":"example.com/calc","Test":"TestAdd","Elapsed":0.2}
+{"Action":"fail","Package":"example.com/calc","Elapsed":0.3}`
+
+		suites, err := Parse(GoTest, []byte(data))
+		require.NoError(t, err)
+		require.Len(t, suites, 1)
+		require.Len(t, suites[0].Tests, 2)
+
+		add := suites[0].Tests[0]
+		require.Equal(t, "TestAdd", add.Name)
+		require.Equal(t, junit.StatusPassed, add.Status)
+		require.Equal(t, 200*time.Millisecond, add.Duration)
+		require.Equal(t, "=== RUN   TestAdd\n=== PAUSE TestAdd\n=== CONT  TestAdd\n    calc_test.go:12: adding\n--- PASS: TestAdd (0.20s)\n", add.SystemOut)
+
+		sub := suites[0].Tests[1]
+		require.Equal(t, "TestSub", sub.Name)
+		require.Equal(t, junit.StatusFailed, sub.Status)
+		require.Equal(t, "=== RUN   TestSub\n=== PAUSE TestSub\n=== CONT  TestSub\n    calc_test.go:30: expected -1\n--- FAIL: TestSub (0.10s)\n", sub.Error.Error())
+	})
+
+	t.Run("Package failing without failed tests", func(t *testing.T) {
+		data := `{"Action":"output","Package":"example.com/broken","Output":"panic: boom\n"}
+{"Action":"fail","Package":"example.com/broken","Elapsed":0.1}`
+
+		suites, err := Parse(GoTest, []byte(data))
+		require.NoError(t, err)
+		require.Len(t, suites, 1)
+		require.Len(t, suites[0].Tests, 1)
+		require.Equal(t, goTestMainName, suites[0].Tests[0].Name)
+		require.Equal(t, junit.StatusFailed, suites[0].Tests[0].Status)
+		require.Equal(t, "panic: boom\n", suites[0].Tests[0].Error.Error())
+	})
+
+	t.Run("Unfinished packages and lines which are not events", func(t *testing.T) {
+		data := "# example.com/slow\nbuild output\n" + `{"Action":"run","Package":"example.com/slow","Test":"TestSlow"}`
+
+		suites, err := Parse(GoTest, []byte(data))
+		require.NoError(t, err)
+		require.Len(t, suites, 1)
+		require.Equal(t, junit.StatusFailed, suites[0].Tests[0].Status)
+	})
+}
+
+func TestStreamReader(t *testing.T) {
+	t.Run("Suites are yielded as the packages finish", func(t *testing.T) {
+		r, w := io.Pipe()
+
+		received := make(chan junit.Suite)
+		done := make(chan error)
+		go func() {
+			done <- StreamReader(GoTest, r, func(suite junit.Suite, timestamp string) error {
+				received <- suite
+				return nil
+			})
+		}()
+
+		_, err := io.WriteString(w, `{"Time":"2024-05-06T10:00:00Z","Action":"run","Package":"a","Test":"TestA"}
+{"Action":"pass","Package":"a","Test":"TestA","Elapsed":0.1}
+{"Action":"pass","Package":"a","Elapsed":0.1}
+`)
+		require.NoError(t, err)
+
+		// the first package is received while the output is still open
+		require.Equal(t, "a", (<-received).Name)
+
+		_, err = io.WriteString(w, `{"Action":"run","Package":"b","Test":"TestB"}
+{"Action":"pass","Package":"b","Test":"TestB","Elapsed":0.1}
+{"Action":"pass","Package":"b","Elapsed":0.1}
+`)
+		require.NoError(t, err)
+		require.Equal(t, "b", (<-received).Name)
+
+		require.NoError(t, w.Close())
+		require.NoError(t, <-done)
+	})
+
+	t.Run("JUnit suites are yielded as they end", func(t *testing.T) {
+		r, w := io.Pipe()
+
+		received := make(chan junit.Suite)
+		done := make(chan error)
+		go func() {
+			done <- StreamReader(JUnit, r, func(suite junit.Suite, timestamp string) error {
+				received <- suite
+				return nil
+			})
+		}()
+
+		_, err := io.WriteString(w, `<testsuites><testsuite name="a"><testcase name="t"><system-out><![CDATA[out]]></system-out></testcase></testsuite>`)
+		require.NoError(t, err)
+
+		// the first suite is received while the report is still open
+		a := <-received
+		require.Equal(t, "a", a.Name)
+		require.Equal(t, "out", a.Tests[0].SystemOut)
+
+		_, err = io.WriteString(w, `<testsuite name="b"><testcase name="u"/></testsuite></testsuites>`)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		require.Equal(t, "b", (<-received).Name)
+		require.NoError(t, <-done)
+	})
+
+	t.Run("JUnit report encoded as UTF-16", func(t *testing.T) {
+		report := `<?xml version="1.0" encoding="UTF-16"?><testsuite name="ünicode"><testcase name="t"/></testsuite>`
+		encoded, err := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().String(report)
+		require.NoError(t, err)
+
+		suites := []junit.Suite{}
+		err = StreamReader(JUnit, strings.NewReader(encoded), func(suite junit.Suite, timestamp string) error {
+			suites = append(suites, suite)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, suites, 1)
+		require.Equal(t, "ünicode", suites[0].Name)
+	})
+
+	t.Run("Formats which are not incremental", func(t *testing.T) {
+		suites := []junit.Suite{}
+		err := StreamReader(TAP, strings.NewReader("1..2\nok\nnot ok 2\n"), func(suite junit.Suite, timestamp string) error {
+			suites = append(suites, suite)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, suites, 1)
+		require.False(t, IsIncremental(TAP))
+		require.True(t, IsIncremental(GoTest))
+		require.True(t, IsIncremental(JUnit))
+	})
+}
@@ -0,0 +1,249 @@
+package formats
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/joshdk/go-junit"
+)
+
+// jsDescribeSeparator separator of the titles of the nested describe blocks in the names of the suites
+const jsDescribeSeparator = " > "
+
+// ansiEscape the color escape sequences of the failure messages of Jest
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+type jestResults struct {
+	TestResults []jestFile `json:"testResults"`
+}
+
+type jestFile struct {
+	Name             string          `json:"name"`
+	StartTime        int64           `json:"startTime"`
+	Message          string          `json:"message"`
+	Status           string          `json:"status"`
+	AssertionResults []jestAssertion `json:"assertionResults"`
+}
+
+type jestAssertion struct {
+	AncestorTitles  []string `json:"ancestorTitles"`
+	Title           string   `json:"title"`
+	Status          string   `json:"status"`
+	Duration        *float64 `json:"duration"`
+	FailureMessages []string `json:"failureMessages"`
+}
+
+type mochaResults struct {
+	Stats struct {
+		Start string `json:"start"`
+	} `json:"stats"`
+	Tests   []mochaTest `json:"tests"`
+	Pending []mochaTest `json:"pending"`
+}
+
+type mochaTest struct {
+	Title     string          `json:"title"`
+	FullTitle string          `json:"fullTitle"`
+	File      string          `json:"file"`
+	Duration  *float64        `json:"duration"`
+	Err       json.RawMessage `json:"err"`
+}
+
+type mochaError struct {
+	Message string `json:"message"`
+	Stack   string `json:"stack"`
+	Name    string `json:"name"`
+}
+
+// jsSuites groups the test cases of a test file by their describe block, in the order of their first test case
+type jsSuites struct {
+	suites []junit.Suite
+	index  map[string]int
+}
+
+func (s *jsSuites) add(file string, describe string, test junit.Test) {
+	name := describe
+	if name == "" {
+		// the test cases outside of any describe block
+		name = filepath.Base(file)
+	}
+	test.Classname = name
+
+	key := file + "\x00" + name
+	i, ok := s.index[key]
+	if !ok {
+		i = len(s.suites)
+		s.index[key] = i
+		s.suites = append(s.suites, junit.Suite{Name: name, Package: file})
+	}
+
+	s.suites[i].Tests = append(s.suites[i].Tests, test)
+}
+
+// yield aggregates the suites, yielding them with the timestamp
+func (s *jsSuites) yield(timestamp string, yield Yield) error {
+	for _, suite := range s.suites {
+		suite.Aggregate()
+		if err := yield(suite, timestamp); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ingestJest converts the output of jest --json into jUnit test suites
+func ingestJest(data []byte) ([]junit.Suite, error) {
+	return collect(data, streamJest)
+}
+
+// streamJest converts the output of jest --json into jUnit test suites, one per describe block of each test file,
+// named after the titles of the nested describe blocks, i.e. Calculator > add, or after the test file for the
+// test cases outside of any describe block. The package of the suites is the test file. The pending, skipped,
+// disabled and todo tests are skipped test cases, and a test file failing without a failed test, i.e. because
+// it does not compile, gets an errored test case with its message
+func streamJest(data []byte, yield Yield) error {
+	var results jestResults
+	if err := json.Unmarshal(data, &results); err != nil {
+		return fmt.Errorf("failed to parse the Jest report: %w", err)
+	}
+
+	for _, file := range results.TestResults {
+		suites := &jsSuites{index: map[string]int{}}
+
+		failed := false
+		for _, assertion := range file.AssertionResults {
+			test := junit.Test{
+				Name:     assertion.Title,
+				Duration: jsDuration(assertion.Duration),
+				Status:   junit.StatusPassed,
+			}
+
+			switch assertion.Status {
+			case "failed":
+				failed = true
+				body := ansiEscape.ReplaceAllString(strings.Join(assertion.FailureMessages, "\n"), "")
+				test.Status = junit.StatusFailed
+				test.Message = firstLine(body)
+				test.Error = junit.Error{Message: test.Message, Body: body}
+			case "pending", "skipped", "disabled", "todo":
+				test.Status = junit.StatusSkipped
+				test.Message = assertion.Status
+			}
+
+			suites.add(file.Name, strings.Join(assertion.AncestorTitles, jsDescribeSeparator), test)
+		}
+
+		if file.Status == "failed" && !failed {
+			message := ansiEscape.ReplaceAllString(strings.TrimSpace(file.Message), "")
+			suites.add(file.Name, "", junit.Test{
+				Name:    filepath.Base(file.Name),
+				Status:  junit.StatusError,
+				Message: firstLine(message),
+				Error:   junit.Error{Message: firstLine(message), Body: message},
+			})
+		}
+
+		timestamp := ""
+		if file.StartTime > 0 {
+			timestamp = time.UnixMilli(file.StartTime).UTC().Format(time.RFC3339Nano)
+		}
+
+		if err := suites.yield(timestamp, yield); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ingestMocha converts the output of mocha --reporter json into jUnit test suites
+func ingestMocha(data []byte) ([]junit.Suite, error) {
+	return collect(data, streamMocha)
+}
+
+// streamMocha converts the output of mocha --reporter json into jUnit test suites, one per describe block of each
+// test file, as the ones of Jest. The titles of the describe blocks are the full title of the test without its
+// own title, as the report does not include them separately. The pending tests are skipped test cases
+func streamMocha(data []byte, yield Yield) error {
+	var results mochaResults
+	if err := json.Unmarshal(data, &results); err != nil {
+		return fmt.Errorf("failed to parse the mocha report: %w", err)
+	}
+
+	// the pending tests are listed in the tests too, without any mark
+	pending := map[string]bool{}
+	for _, t := range results.Pending {
+		pending[t.File+"\x00"+t.FullTitle] = true
+	}
+
+	suites := &jsSuites{index: map[string]int{}}
+	for _, t := range results.Tests {
+		test := junit.Test{
+			Name:     t.Title,
+			Duration: jsDuration(t.Duration),
+			Status:   junit.StatusPassed,
+		}
+
+		var failure mochaError
+		if len(t.Err) > 0 {
+			// the passed tests have an empty object as error
+			_ = json.Unmarshal(t.Err, &failure)
+		}
+
+		switch {
+		case pending[t.File+"\x00"+t.FullTitle]:
+			test.Status = junit.StatusSkipped
+			test.Message = "pending"
+		case failure.Message != "" || failure.Stack != "":
+			test.Status = junit.StatusFailed
+			test.Message = failure.Message
+			test.Error = junit.Error{Message: failure.Message, Type: failure.Name, Body: failure.Stack}
+		}
+
+		describe := strings.TrimSpace(strings.TrimSuffix(t.FullTitle, t.Title))
+		suites.add(t.File, describe, test)
+	}
+
+	return suites.yield(results.Stats.Start, yield)
+}
+
+// collect parses the whole report with the streamer, returning all of its suites
+func collect(data []byte, stream func(data []byte, yield Yield) error) ([]junit.Suite, error) {
+	suites := []junit.Suite{}
+
+	err := stream(data, func(suite junit.Suite, _ string) error {
+		suites = append(suites, suite)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return suites, nil
+}
+
+// jsDuration converts the durations in milliseconds of the JavaScript test runners, which are null when the test
+// did not run
+func jsDuration(ms *float64) time.Duration {
+	if ms == nil {
+		return 0
+	}
+
+	return time.Duration(*ms * float64(time.Millisecond))
+}
+
+// firstLine returns the first non empty line of the text, trimmed
+func firstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+
+	return ""
+}
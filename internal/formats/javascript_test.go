@@ -0,0 +1,99 @@
+package formats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIngestJest(t *testing.T) {
+	t.Run("Valid report", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join("testdata", "jest.json"))
+		require.NoError(t, err)
+
+		suites, err := Parse(Jest, data)
+		require.NoError(t, err)
+		require.Len(t, suites, 4)
+
+		add := suites[0]
+		require.Equal(t, "Calculator > add", add.Name)
+		require.Equal(t, "/src/calculator.test.js", add.Package)
+		require.Len(t, add.Tests, 2)
+		require.Equal(t, "adds two numbers", add.Tests[0].Name)
+		require.Equal(t, "Calculator > add", add.Tests[0].Classname)
+		require.Equal(t, 3*time.Millisecond, add.Tests[0].Duration)
+
+		failed := add.Tests[1]
+		require.Equal(t, junit.StatusFailed, failed.Status)
+		require.Equal(t, "Error: expect(received).toBe(expected)", failed.Message)
+		require.ErrorContains(t, failed.Error, "Received: 2")
+
+		calculator := suites[1]
+		require.Equal(t, "Calculator", calculator.Name)
+		require.Equal(t, 2, calculator.Totals.Skipped)
+		require.Equal(t, "todo", calculator.Tests[1].Message)
+
+		// the test outside of any describe block
+		require.Equal(t, "calculator.test.js", suites[2].Name)
+
+		parser := suites[3]
+		require.Equal(t, "parser.test.js", parser.Name)
+		require.Equal(t, junit.StatusError, parser.Tests[0].Status)
+		require.Equal(t, "● Test suite failed to run", parser.Tests[0].Message)
+	})
+
+	t.Run("Timestamps", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join("testdata", "jest.json"))
+		require.NoError(t, err)
+
+		timestamps := []string{}
+		err = Stream(Jest, data, func(_ junit.Suite, timestamp string) error {
+			timestamps = append(timestamps, timestamp)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, "2023-09-12T08:30:00.01Z", timestamps[0])
+		require.Equal(t, "2023-09-12T08:30:00.02Z", timestamps[3])
+	})
+
+	t.Run("Invalid report", func(t *testing.T) {
+		_, err := Parse(Jest, []byte("<testsuite>"))
+		require.ErrorContains(t, err, "failed to parse the Jest report")
+	})
+}
+
+func TestIngestMocha(t *testing.T) {
+	t.Run("Valid report", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join("testdata", "mocha.json"))
+		require.NoError(t, err)
+
+		suites, err := Parse(Mocha, data)
+		require.NoError(t, err)
+		require.Len(t, suites, 2)
+
+		add := suites[0]
+		require.Equal(t, "Calculator add", add.Name)
+		require.Equal(t, "/src/test/calculator.spec.js", add.Package)
+		require.Equal(t, junit.StatusPassed, add.Tests[0].Status)
+		require.Equal(t, 2*time.Millisecond, add.Tests[0].Duration)
+
+		calculator := suites[1]
+		require.Equal(t, "Calculator", calculator.Name)
+		require.Equal(t, 1, calculator.Totals.Failed)
+		require.Equal(t, 1, calculator.Totals.Skipped)
+
+		divide := calculator.Tests[0]
+		require.Equal(t, "divides by zero", divide.Name)
+		require.Contains(t, divide.Message, "Expected values to be strictly equal")
+		require.ErrorContains(t, divide.Error, "calculator.spec.js:14:14")
+	})
+
+	t.Run("Invalid report", func(t *testing.T) {
+		_, err := Parse(Mocha, []byte("<testsuite>"))
+		require.ErrorContains(t, err, "failed to parse the mocha report")
+	})
+}
@@ -0,0 +1,402 @@
+package formats
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joshdk/go-junit"
+)
+
+var (
+	cdataStart = []byte("<![CDATA[")
+	cdataEnd   = []byte("]]>")
+)
+
+// SurefireReruns name of the property with the number of reruns of a test case reported by Surefire with the
+// rerunFailingTestsCount option: its flakyFailure and flakyError elements, the failed runs of a test case passing
+// in the end, and its rerunFailure and rerunError elements, the failed reruns of a test case failing in the end
+const SurefireReruns = "surefire.reruns"
+
+// ingestJUnit parses the JUnit report, with the same semantics as go-junit, except for the properties of the
+// test cases, which go-junit drops, and the test cases with several failure or error elements, whose error is
+// the Failures of all of them instead of the last one
+func ingestJUnit(data []byte) ([]junit.Suite, error) {
+	suites := []junit.Suite{}
+
+	err := streamJUnit(bytes.NewReader(data), func(suite junit.Suite, _ string) error {
+		suites = append(suites, suite)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return suites, nil
+}
+
+// streamJUnit decodes the testsuite elements which are not nested in another testsuite, as go-junit does,
+// building each suite straight from the XML tokens while the report is read, so only one suite is held in
+// memory at a time and there is no intermediate tree of nodes. The contents of the outputs and the errors
+// are read from the raw bytes of the report, so CDATA sections and entities are handled as in go-junit
+func streamJUnit(r io.Reader, yield Yield) error {
+	raw := &rawReader{r: bufio.NewReader(r)}
+	decoder := xml.NewDecoder(raw)
+	decoder.CharsetReader = decodedCharsetReader
+
+	d := &junitDecoder{raw: raw, decoder: decoder}
+
+	for {
+		token, err := d.token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// the testsuite elements are searched in any other element, i.e. testsuites
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "testsuite" {
+			continue
+		}
+
+		suite, err := d.suite(start)
+		if err != nil {
+			return err
+		}
+
+		if err := yield(suite, attr(start, "timestamp")); err != nil {
+			return err
+		}
+	}
+}
+
+// junitDecoder reads the elements of a JUnit report from the tokens of the decoder
+type junitDecoder struct {
+	raw     *rawReader
+	decoder *xml.Decoder
+}
+
+// token returns the next token, discarding the raw bytes before it, as the contents are read after
+// the start of their element
+func (d *junitDecoder) token() (xml.Token, error) {
+	token, err := d.decoder.Token()
+	d.raw.discard(d.decoder.InputOffset())
+
+	return token, err
+}
+
+// children calls fn with each child element of the current element, until its end. fn must consume
+// the whole child element
+func (d *junitDecoder) children(fn func(start xml.StartElement) error) error {
+	for {
+		token, err := d.token()
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if err := fn(t); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+func (d *junitDecoder) suite(start xml.StartElement) (junit.Suite, error) {
+	suite := junit.Suite{
+		Name:       attr(start, "name"),
+		Package:    attr(start, "package"),
+		Properties: attrMap(start.Attr),
+	}
+
+	err := d.children(func(child xml.StartElement) error {
+		var err error
+
+		switch child.Name.Local {
+		case "testsuite":
+			var testsuite junit.Suite
+			testsuite, err = d.suite(child)
+			suite.Suites = append(suite.Suites, testsuite)
+		case "testcase":
+			var testcase junit.Test
+			testcase, err = d.testcase(child)
+			suite.Tests = append(suite.Tests, testcase)
+		case "properties":
+			suite.Properties, err = d.properties()
+		case "system-out":
+			suite.SystemOut, err = d.content()
+		case "system-err":
+			suite.SystemErr, err = d.content()
+		default:
+			err = d.decoder.Skip()
+		}
+
+		return err
+	})
+	if err != nil {
+		return junit.Suite{}, err
+	}
+
+	suite.Aggregate()
+
+	return suite, nil
+}
+
+func (d *junitDecoder) testcase(start xml.StartElement) (junit.Test, error) {
+	test := junit.Test{
+		Name:       attr(start, "name"),
+		Classname:  attr(start, "classname"),
+		Duration:   duration(attr(start, "time")),
+		Status:     junit.StatusPassed,
+		Properties: attrMap(start.Attr),
+	}
+
+	// the failure and error elements, as some frameworks report each failed soft assertion in its own one
+	var failures []junit.Error
+	reruns := 0
+
+	err := d.children(func(child xml.StartElement) error {
+		var err error
+
+		switch child.Name.Local {
+		case "skipped":
+			test.Status = junit.StatusSkipped
+			test.Message = attr(child, "message")
+			err = d.decoder.Skip()
+		case "failure", "error":
+			// an error outranks the failures, and the first element is the message of the test case
+			if test.Status != junit.StatusError {
+				test.Status = junit.StatusFailed
+				if child.Name.Local == "error" {
+					test.Status = junit.StatusError
+				}
+			}
+			if len(failures) == 0 {
+				test.Message = attr(child, "message")
+			}
+
+			var body string
+			body, err = d.content()
+			failures = append(failures, junit.Error{
+				Body:    body,
+				Type:    attr(child, "type"),
+				Message: attr(child, "message"),
+			})
+		case "flakyFailure", "flakyError", "rerunFailure", "rerunError":
+			// the failed runs of the test case rerun by Surefire, whose outcome is the one of the test case
+			reruns++
+			err = d.decoder.Skip()
+		case "properties":
+			var props map[string]string
+			props, err = d.properties()
+			if test.Properties == nil && len(props) > 0 {
+				test.Properties = make(map[string]string, len(props))
+			}
+			// the attributes of the test case take precedence, as they identify it
+			for k, v := range props {
+				if _, ok := test.Properties[k]; !ok {
+					test.Properties[k] = v
+				}
+			}
+		case "system-out":
+			test.SystemOut, err = d.content()
+		case "system-err":
+			test.SystemErr, err = d.content()
+		default:
+			err = d.decoder.Skip()
+		}
+
+		return err
+	})
+	if err != nil {
+		return junit.Test{}, err
+	}
+
+	if len(failures) == 1 {
+		test.Error = failures[0]
+	} else if len(failures) > 1 {
+		test.Error = Failures(failures)
+	}
+
+	if reruns > 0 {
+		if test.Properties == nil {
+			test.Properties = map[string]string{}
+		}
+		test.Properties[SurefireReruns] = strconv.Itoa(reruns)
+	}
+
+	return test, nil
+}
+
+// properties reads the property elements of the properties element, ignoring any other element
+func (d *junitDecoder) properties() (map[string]string, error) {
+	props := map[string]string{}
+
+	err := d.children(func(child xml.StartElement) error {
+		if child.Name.Local == "property" {
+			props[attr(child, "name")] = attr(child, "value")
+		}
+
+		return d.decoder.Skip()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return props, nil
+}
+
+// content reads the raw inner XML of the current element, which is consumed, extracting its text
+func (d *junitDecoder) content() (string, error) {
+	begin := d.decoder.InputOffset()
+	depth := 0
+
+	for {
+		// the offset before reading the end token is the end of the inner XML
+		end := d.decoder.InputOffset()
+
+		token, err := d.decoder.Token()
+		if err != nil {
+			return "", err
+		}
+
+		switch token.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			if depth == 0 {
+				return extractContent(d.raw.bytes(begin, end))
+			}
+			depth--
+		}
+	}
+}
+
+// rawReader reads the report for the XML decoder, byte by byte so the decoder does not read ahead, keeping
+// the raw bytes read since the last discarded offset, so the contents are extracted without holding the
+// whole report
+type rawReader struct {
+	r    *bufio.Reader
+	base int64
+	buf  []byte
+}
+
+func (r *rawReader) ReadByte() (byte, error) {
+	b, err := r.r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	r.buf = append(r.buf, b)
+	return b, nil
+}
+
+func (r *rawReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	p[0] = b
+	return 1, nil
+}
+
+// bytes returns the raw bytes between the offsets of the report, which must not be discarded
+func (r *rawReader) bytes(begin int64, end int64) []byte {
+	return r.buf[begin-r.base : end-r.base]
+}
+
+// discard drops the raw bytes before the offset of the report
+func (r *rawReader) discard(offset int64) {
+	if offset <= r.base {
+		return
+	}
+
+	r.buf = append(r.buf[:0], r.buf[offset-r.base:]...)
+	r.base = offset
+}
+
+// decodedCharsetReader accepts the UTF-16 encodings declared by the reports already decoded to UTF-8, as
+// the XML decoder rejects the encodings other than UTF-8
+func decodedCharsetReader(label string, input io.Reader) (io.Reader, error) {
+	if strings.HasPrefix(strings.ToLower(label), "utf-16") {
+		return input, nil
+	}
+
+	return nil, fmt.Errorf("unsupported encoding: %s", label)
+}
+
+// extractContent unescapes the text outside the CDATA sections, keeping the text inside them verbatim
+func extractContent(data []byte) (string, error) {
+	var output strings.Builder
+
+	for {
+		offset := bytes.Index(data, cdataStart)
+		if offset == -1 {
+			if bytes.Contains(data, cdataEnd) {
+				return "", errors.New("unmatched CDATA end tag")
+			}
+
+			output.WriteString(html.UnescapeString(string(data)))
+
+			return output.String(), nil
+		}
+
+		output.WriteString(html.UnescapeString(string(data[:offset])))
+		data = data[offset+len(cdataStart):]
+
+		offset = bytes.Index(data, cdataEnd)
+		if offset == -1 {
+			return "", errors.New("unmatched CDATA start tag")
+		}
+
+		output.Write(data[:offset])
+		data = data[offset+len(cdataEnd):]
+	}
+}
+
+// attrMap returns the attributes of an element by their local name, or nil if there are none
+func attrMap(attrs []xml.Attr) map[string]string {
+	if len(attrs) == 0 {
+		return nil
+	}
+
+	attributes := make(map[string]string, len(attrs))
+	for _, a := range attrs {
+		attributes[a.Name.Local] = a.Value
+	}
+
+	return attributes
+}
+
+// duration parses the time of a test case, in seconds with an optional thousands separator, or
+// as a Go duration. It's zero if the time cannot be parsed
+func duration(t string) time.Duration {
+	t = strings.ReplaceAll(t, ",", "")
+
+	if s, err := strconv.ParseFloat(t, 64); err == nil {
+		return time.Duration(s*1000000) * time.Microsecond
+	}
+
+	if d, err := time.ParseDuration(t); err == nil {
+		return d
+	}
+
+	return 0
+}
@@ -0,0 +1,199 @@
+package formats
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joshdk/go-junit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIngestJUnit(t *testing.T) {
+	t.Run("Same suites as go-junit", func(t *testing.T) {
+		paths, err := filepath.Glob(filepath.Join("..", "..", "TEST-*.xml"))
+		require.NoError(t, err)
+		require.NotEmpty(t, paths)
+
+		for _, path := range paths {
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+
+			expected, err := junit.Ingest(data)
+			require.NoError(t, err)
+
+			suites, err := ingestJUnit(data)
+			require.NoError(t, err)
+			require.Equal(t, expected, suites, path)
+		}
+	})
+
+	reports := map[string]string{
+		"CDATA and entities": `<testsuite name="a &amp; b"><testcase name="t"><failure message="boom" type="AssertionError">expected &lt;1&gt;<![CDATA[ but was <2> & more]]></failure><system-out><![CDATA[out]]> &amp; more</system-out></testcase></testsuite>`,
+		"Nested suites":      `<testsuites><testsuite name="parent"><testsuite name="child"><testcase name="t" time="1,234.5"/></testsuite><testcase name="u" time="1s"><skipped message="not today"/></testcase></testsuite></testsuites>`,
+		"Properties":         `<testsuite name="a" hostname="host"><properties><property name="go.version" value="1.23"/><other/></properties><testcase name="t" classname="c" file="f.go"><error message="panic">stack</error></testcase></testsuite>`,
+		"Namespaces":         `<x:testsuites xmlns:x="urn:x"><x:testsuite name="a"><x:testcase name="t"><x:system-err>err</x:system-err></x:testcase></x:testsuite></x:testsuites>`,
+		"Nested elements":    `<testsuite name="a"><system-out>line <b>bold</b> end</system-out><testcase name="t"><unknown><testsuite name="ignored"/></unknown></testcase></testsuite>`,
+		"Multiple roots":     `<?xml version="1.0"?><testsuite name="a"/><testsuite name="b"><testcase name="t"/></testsuite>`,
+		"Empty report":       `<testsuites></testsuites>`,
+		"Windows line ends":  "<testsuite name=\"a\"><system-out>one\r\ntwo</system-out></testsuite>",
+	}
+
+	for name, report := range reports {
+		t.Run(name, func(t *testing.T) {
+			expected, err := junit.Ingest([]byte(report))
+			require.NoError(t, err)
+
+			suites, err := ingestJUnit([]byte(report))
+			require.NoError(t, err)
+			require.Equal(t, expected, suites)
+		})
+	}
+
+	t.Run("Test case properties", func(t *testing.T) {
+		report := `<testsuite name="a"><testcase name="t" file="f.go"><properties><property name="tag" value="slow"/><property name="requirement" value="REQ-1"/><property name="file" value="other.go"/></properties></testcase><testcase><properties><property name="tag" value="fast"/></properties></testcase></testsuite>`
+
+		suites, err := ingestJUnit([]byte(report))
+		require.NoError(t, err)
+		require.Len(t, suites, 1)
+		require.Len(t, suites[0].Tests, 2)
+
+		// the attributes of the test case are not overridden by its properties
+		require.Equal(t, map[string]string{"name": "t", "file": "f.go", "tag": "slow", "requirement": "REQ-1"}, suites[0].Tests[0].Properties)
+		require.Equal(t, map[string]string{"tag": "fast"}, suites[0].Tests[1].Properties)
+	})
+
+	t.Run("Surefire reruns", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join("testdata", "TEST-surefire-rerun.xml"))
+		require.NoError(t, err)
+
+		suites, err := ingestJUnit(data)
+		require.NoError(t, err)
+		require.Len(t, suites, 1)
+		require.Len(t, suites[0].Tests, 3)
+
+		// the reruns are not test cases of their own, so the totals are the ones of the report
+		require.Equal(t, 3, suites[0].Totals.Tests)
+		require.Equal(t, 2, suites[0].Totals.Passed)
+		require.Equal(t, 1, suites[0].Totals.Failed)
+
+		require.NotContains(t, suites[0].Tests[0].Properties, SurefireReruns)
+
+		flaky := suites[0].Tests[1]
+		require.Equal(t, junit.StatusPassed, flaky.Status)
+		require.Equal(t, "2", flaky.Properties[SurefireReruns])
+
+		failed := suites[0].Tests[2]
+		require.Equal(t, junit.StatusFailed, failed.Status)
+		require.Equal(t, "expected: <REFUNDED> but was: <PENDING>", failed.Message)
+		require.Equal(t, "2", failed.Properties[SurefireReruns])
+	})
+
+	t.Run("Multiple failures", func(t *testing.T) {
+		report := `<testsuite name="a"><testcase name="t"><failure message="first" type="AssertionError">at a.go:1</failure><error message="second" type="IOException">at a.go:2</error></testcase><testcase name="u"><failure message="only"/></testcase></testsuite>`
+
+		suites, err := ingestJUnit([]byte(report))
+		require.NoError(t, err)
+		require.Len(t, suites[0].Tests, 2)
+
+		soft := suites[0].Tests[0]
+		require.Equal(t, junit.StatusError, soft.Status)
+		require.Equal(t, "first", soft.Message)
+		require.Equal(t, []junit.Error{
+			{Message: "first", Type: "AssertionError", Body: "at a.go:1"},
+			{Message: "second", Type: "IOException", Body: "at a.go:2"},
+		}, TestFailures(soft))
+
+		var first junit.Error
+		require.ErrorAs(t, soft.Error, &first)
+		require.Equal(t, "first", first.Message)
+
+		// a single failure is a junit.Error, as in go-junit
+		require.Equal(t, junit.Error{Message: "only"}, suites[0].Tests[1].Error)
+	})
+
+	malformed := map[string]string{
+		"Mismatched elements":  `<testsuite name="a"><testcase name="t"></testsuite>`,
+		"Unterminated element": `<testsuite name="a"><testcase name="t">`,
+		"Unmatched CDATA end":  `<testsuite name="a"><system-out>out]]></system-out></testsuite>`,
+	}
+
+	for name, report := range malformed {
+		t.Run(name, func(t *testing.T) {
+			_, expected := junit.Ingest([]byte(report))
+			require.Error(t, expected)
+
+			_, err := ingestJUnit([]byte(report))
+			require.Error(t, err)
+		})
+	}
+}
+
+// generateJUnit returns a report with the given number of suites of 100 test cases each
+func generateJUnit(suites int) []byte {
+	var sb strings.Builder
+
+	sb.WriteString("<testsuites>")
+	for s := 0; s < suites; s++ {
+		fmt.Fprintf(&sb, `<testsuite name="suite-%d" tests="100"><properties><property name="go.version" value="1.23"/></properties>`, s)
+		for c := 0; c < 100; c++ {
+			if c%10 == 0 {
+				fmt.Fprintf(&sb, `<testcase name="test-%d" classname="pkg.Suite%d" time="0.01"><failure message="boom" type="AssertionError"><![CDATA[expected 1 but was 2]]></failure><system-out>output &amp; more</system-out></testcase>`, c, s)
+				continue
+			}
+			fmt.Fprintf(&sb, `<testcase name="test-%d" classname="pkg.Suite%d" time="0.01"/>`, c, s)
+		}
+		sb.WriteString("</testsuite>")
+	}
+	sb.WriteString("</testsuites>")
+
+	return []byte(sb.String())
+}
+
+func BenchmarkIngestJUnit(b *testing.B) {
+	data := generateJUnit(100)
+
+	b.Run("go-junit", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			if _, err := junit.Ingest(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("stream", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			if _, err := ingestJUnit(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	// the reports read from the input, where go-junit reads the whole report before parsing it
+	b.Run("go-junit reader", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			if _, err := junit.IngestReader(bytes.NewReader(data)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("stream reader", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			err := StreamReader(JUnit, bytes.NewReader(data), func(junit.Suite, string) error { return nil })
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,195 @@
+package formats
+
+import (
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/joshdk/go-junit"
+)
+
+// junitAttributes attributes of the testsuite and testcase elements, which go-junit also exposes as properties
+// when the element has no properties, so they are not written as properties
+var junitAttributes = []string{
+	"assertions", "classname", "disabled", "errors", "failures", "file", "hostname", "id",
+	"line", "name", "package", "skipped", "tests", "time", "timestamp",
+}
+
+type xmlTestSuites struct {
+	XMLName  xml.Name       `xml:"testsuites"`
+	Tests    int            `xml:"tests,attr"`
+	Failures int            `xml:"failures,attr"`
+	Errors   int            `xml:"errors,attr"`
+	Skipped  int            `xml:"skipped,attr"`
+	Time     string         `xml:"time,attr"`
+	Suites   []xmlTestSuite `xml:"testsuite"`
+}
+
+type xmlTestSuite struct {
+	Name       string         `xml:"name,attr"`
+	Package    string         `xml:"package,attr,omitempty"`
+	Tests      int            `xml:"tests,attr"`
+	Failures   int            `xml:"failures,attr"`
+	Errors     int            `xml:"errors,attr"`
+	Skipped    int            `xml:"skipped,attr"`
+	Time       string         `xml:"time,attr"`
+	Properties []xmlProperty  `xml:"properties>property,omitempty"`
+	Suites     []xmlTestSuite `xml:"testsuite,omitempty"`
+	TestCases  []xmlTestCase  `xml:"testcase"`
+	SystemOut  string         `xml:"system-out,omitempty"`
+	SystemErr  string         `xml:"system-err,omitempty"`
+}
+
+type xmlTestCase struct {
+	Name       string        `xml:"name,attr"`
+	Classname  string        `xml:"classname,attr,omitempty"`
+	Time       string        `xml:"time,attr"`
+	File       string        `xml:"file,attr,omitempty"`
+	Line       string        `xml:"line,attr,omitempty"`
+	Properties []xmlProperty `xml:"properties>property,omitempty"`
+	Failures   []xmlResult   `xml:"failure,omitempty"`
+	Errors     []xmlResult   `xml:"error,omitempty"`
+	Skipped    *xmlResult    `xml:"skipped,omitempty"`
+	SystemOut  string        `xml:"system-out,omitempty"`
+	SystemErr  string        `xml:"system-err,omitempty"`
+}
+
+type xmlProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type xmlResult struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Body    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the suites as a normalized JUnit XML report, with a single testsuites root element
+// and the totals recalculated from the test cases
+func WriteJUnit(w io.Writer, suites []junit.Suite) error {
+	root := xmlTestSuites{}
+
+	var duration time.Duration
+	for _, suite := range suites {
+		s := toXMLTestSuite(suite)
+
+		root.Suites = append(root.Suites, s)
+		root.Tests += s.Tests
+		root.Failures += s.Failures
+		root.Errors += s.Errors
+		root.Skipped += s.Skipped
+		duration += suite.Totals.Duration
+	}
+	root.Time = formatSeconds(duration)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(root); err != nil {
+		return fmt.Errorf("failed to write the JUnit report: %w", err)
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
+
+func toXMLTestSuite(suite junit.Suite) xmlTestSuite {
+	suite.Aggregate()
+
+	s := xmlTestSuite{
+		Name:       suite.Name,
+		Package:    suite.Package,
+		Tests:      suite.Totals.Tests,
+		Failures:   suite.Totals.Failed,
+		Errors:     suite.Totals.Error,
+		Skipped:    suite.Totals.Skipped,
+		Time:       formatSeconds(suite.Totals.Duration),
+		Properties: toXMLProperties(suite.Properties),
+		SystemOut:  suite.SystemOut,
+		SystemErr:  suite.SystemErr,
+	}
+
+	for _, nested := range suite.Suites {
+		s.Suites = append(s.Suites, toXMLTestSuite(nested))
+	}
+
+	for _, test := range suite.Tests {
+		s.TestCases = append(s.TestCases, toXMLTestCase(test))
+	}
+
+	return s
+}
+
+func toXMLTestCase(test junit.Test) xmlTestCase {
+	tc := xmlTestCase{
+		Name:       test.Name,
+		Classname:  test.Classname,
+		Time:       formatSeconds(test.Duration),
+		File:       test.Properties["file"],
+		Line:       test.Properties["line"],
+		Properties: toXMLProperties(test.Properties),
+		SystemOut:  test.SystemOut,
+		SystemErr:  test.SystemErr,
+	}
+
+	// a result per failure, so the soft assertions are written back as several elements
+	results := []xmlResult{}
+	for i, failure := range TestFailures(test) {
+		result := xmlResult{Message: failure.Message, Type: failure.Type, Body: failure.Body}
+		if i == 0 && test.Message != "" {
+			result.Message = test.Message
+		}
+		results = append(results, result)
+	}
+
+	switch test.Status {
+	case junit.StatusFailed:
+		tc.Failures = resultsOrMessage(results, test.Message)
+	case junit.StatusError:
+		tc.Errors = resultsOrMessage(results, test.Message)
+	case junit.StatusSkipped:
+		tc.Skipped = &xmlResult{Message: test.Message}
+	}
+
+	return tc
+}
+
+// resultsOrMessage returns the results, or a result with the message of the test case if it has no failures
+func resultsOrMessage(results []xmlResult, message string) []xmlResult {
+	if len(results) == 0 {
+		return []xmlResult{{Message: message}}
+	}
+
+	return results
+}
+
+// toXMLProperties converts the properties, sorted by name, skipping the attributes of the element
+func toXMLProperties(props map[string]string) []xmlProperty {
+	properties := []xmlProperty{}
+	for k, v := range props {
+		if slices.Contains(junitAttributes, k) {
+			continue
+		}
+
+		properties = append(properties, xmlProperty{Name: k, Value: v})
+	}
+
+	slices.SortFunc(properties, func(a, b xmlProperty) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return properties
+}
+
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
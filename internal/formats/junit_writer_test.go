@@ -0,0 +1,89 @@
+package formats
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshdk/go-junit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	t.Run("Round trip", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join("..", "..", "TEST-sample.xml"))
+		require.NoError(t, err)
+
+		suites, err := Parse(JUnit, data)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, WriteJUnit(&buf, suites))
+		require.NoError(t, Check(JUnit, "converted", buf.Bytes()))
+
+		converted, err := Parse(JUnit, buf.Bytes())
+		require.NoError(t, err)
+		require.Len(t, converted, len(suites))
+
+		for i := range suites {
+			require.Equal(t, suites[i].Name, converted[i].Name)
+			require.Equal(t, suites[i].Totals.Tests, converted[i].Totals.Tests)
+			require.Equal(t, suites[i].Totals.Failed, converted[i].Totals.Failed)
+			require.Equal(t, suites[i].Totals.Skipped, converted[i].Totals.Skipped)
+			require.Len(t, converted[i].Tests, len(suites[i].Tests))
+		}
+	})
+
+	t.Run("Multiple failures", func(t *testing.T) {
+		failures := Failures{{Message: "first", Body: "at a.go:1"}, {Message: "second", Body: "at a.go:2"}}
+		suites := []junit.Suite{{Name: "a", Tests: []junit.Test{{Name: "t", Status: junit.StatusFailed, Message: "first", Error: failures}}}}
+
+		var buf bytes.Buffer
+		require.NoError(t, WriteJUnit(&buf, suites))
+
+		converted, err := Parse(JUnit, buf.Bytes())
+		require.NoError(t, err)
+		require.Equal(t, []junit.Error(failures), TestFailures(converted[0].Tests[0]))
+	})
+
+	t.Run("From TestNG", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join("testdata", "testng-results.xml"))
+		require.NoError(t, err)
+
+		suites, err := Parse(TestNG, data)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, WriteJUnit(&buf, suites))
+
+		xml := buf.String()
+		require.Contains(t, xml, `<testsuites tests="4" failures="1" errors="0" skipped="1" time="0.039">`)
+		require.Contains(t, xml, `<failure message="expected [2] but found [3]" type="java.lang.AssertionError">`)
+		require.Contains(t, xml, `<skipped></skipped>`)
+	})
+
+	t.Run("Properties", func(t *testing.T) {
+		suites := []junit.Suite{{
+			Name: "suite",
+			Properties: map[string]string{
+				"name":       "suite",
+				"go.version": "go1.23",
+			},
+			Tests: []junit.Test{{
+				Name:       "test",
+				Status:     junit.StatusPassed,
+				Properties: map[string]string{"name": "test", "file": "foo_test.go", "owner": "platform"},
+			}},
+		}}
+
+		var buf bytes.Buffer
+		require.NoError(t, WriteJUnit(&buf, suites))
+
+		xml := buf.String()
+		require.Contains(t, xml, `<property name="go.version" value="go1.23"></property>`)
+		require.Contains(t, xml, `<testcase name="test" time="0.000" file="foo_test.go">`)
+		require.Contains(t, xml, `<property name="owner" value="platform"></property>`)
+		require.NotContains(t, xml, `<property name="name"`)
+	})
+}
@@ -0,0 +1,231 @@
+package formats
+
+import (
+	"encoding/xml"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joshdk/go-junit"
+)
+
+// nunitCategory name of the NUnit property holding the categories of a test, one property per category
+const nunitCategory = "Category"
+
+type nunitRun struct {
+	XMLName xml.Name     `xml:"test-run"`
+	Suites  []nunitSuite `xml:"test-suite"`
+}
+
+type nunitSuite struct {
+	Type       string          `xml:"type,attr"`
+	Name       string          `xml:"name,attr"`
+	FullName   string          `xml:"fullname,attr"`
+	StartTime  string          `xml:"start-time,attr"`
+	Properties []nunitProperty `xml:"properties>property"`
+	Output     string          `xml:"output"`
+	Suites     []nunitSuite    `xml:"test-suite"`
+	Cases      []nunitCase     `xml:"test-case"`
+}
+
+type nunitCase struct {
+	Name       string          `xml:"name,attr"`
+	ClassName  string          `xml:"classname,attr"`
+	Result     string          `xml:"result,attr"`
+	Label      string          `xml:"label,attr"`
+	Duration   string          `xml:"duration,attr"`
+	Properties []nunitProperty `xml:"properties>property"`
+	Failure    *nunitFailure   `xml:"failure"`
+	Reason     string          `xml:"reason>message"`
+	Output     string          `xml:"output"`
+}
+
+type nunitFailure struct {
+	Message    string `xml:"message"`
+	StackTrace string `xml:"stack-trace"`
+}
+
+type nunitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// nunitFixture a test-suite element ingested as a suite, with the properties inherited from its ancestors
+type nunitFixture struct {
+	suite      nunitSuite
+	assembly   string
+	categories []string
+}
+
+// ingestNUnit converts a NUnit 3 TestResult.xml report into jUnit test suites, one per test fixture, that is,
+// per test-suite element with test cases, including the ones of its parameterized methods and theories. The
+// package of the suites is the assembly. The properties of the fixtures and of the test cases are their
+// properties, and the categories, set as repeated Category properties on the test cases and on any of their
+// ancestors, are joined into one comma separated Category property of the test case. The internal properties of
+// NUnit, whose names start with an underscore, are skipped
+func ingestNUnit(data []byte) ([]junit.Suite, error) {
+	var run nunitRun
+	if err := xml.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("failed to parse the NUnit report: %w", err)
+	}
+
+	suites := []junit.Suite{}
+	for _, fixture := range nunitFixtures(run.Suites, "", nil) {
+		s := fixture.suite
+
+		name := s.FullName
+		if name == "" {
+			name = s.Name
+		}
+
+		suite := junit.Suite{
+			Name:       name,
+			Package:    fixture.assembly,
+			Properties: nunitProperties(s.Properties, nil),
+			SystemOut:  strings.TrimSpace(s.Output),
+		}
+
+		for _, c := range nunitCases(s) {
+			suite.Tests = append(suite.Tests, nunitToTest(name, c, fixture.categories))
+		}
+
+		suite.Aggregate()
+		suites = append(suites, suite)
+	}
+
+	return suites, nil
+}
+
+// nunitFixtures returns the test-suite elements with test cases, in document order, walking their descendants
+// with the assembly and the categories they inherit
+func nunitFixtures(suites []nunitSuite, assembly string, categories []string) []nunitFixture {
+	fixtures := []nunitFixture{}
+	for _, s := range suites {
+		if nunitIsMethod(s) {
+			continue
+		}
+
+		suiteAssembly := assembly
+		if s.Type == "Assembly" {
+			suiteAssembly = s.Name
+		}
+		inherited := append(slices.Clip(categories), nunitCategories(s.Properties)...)
+
+		if len(nunitCases(s)) > 0 {
+			fixtures = append(fixtures, nunitFixture{suite: s, assembly: suiteAssembly, categories: inherited})
+		}
+
+		fixtures = append(fixtures, nunitFixtures(s.Suites, suiteAssembly, inherited)...)
+	}
+
+	return fixtures
+}
+
+// nunitIsMethod reports whether the test-suite element groups the test cases of a method, i.e. the ones of a
+// parameterized test, which belong to the fixture of the method
+func nunitIsMethod(s nunitSuite) bool {
+	switch s.Type {
+	case "ParameterizedMethod", "Theory", "GenericMethod":
+		return true
+	default:
+		return false
+	}
+}
+
+// nunitCases returns the test cases of the fixture, including the ones of its methods
+func nunitCases(s nunitSuite) []nunitCase {
+	cases := slices.Clone(s.Cases)
+	for _, child := range s.Suites {
+		if nunitIsMethod(child) {
+			cases = append(cases, nunitCases(child)...)
+		}
+	}
+
+	return cases
+}
+
+// nunitCategories returns the values of the Category properties
+func nunitCategories(props []nunitProperty) []string {
+	categories := []string{}
+	for _, p := range props {
+		if p.Name == nunitCategory {
+			categories = append(categories, p.Value)
+		}
+	}
+
+	return categories
+}
+
+// nunitProperties converts the properties into a map, joining the repeated ones with commas, and adding the
+// inherited categories to the Category property. It returns nil if there are no properties
+func nunitProperties(props []nunitProperty, categories []string) map[string]string {
+	values := map[string][]string{}
+	for _, p := range props {
+		if strings.HasPrefix(p.Name, "_") || p.Name == nunitCategory {
+			continue
+		}
+		values[p.Name] = append(values[p.Name], p.Value)
+	}
+
+	categories = append(slices.Clip(categories), nunitCategories(props)...)
+	slices.Sort(categories)
+	if categories = slices.Compact(categories); len(categories) > 0 {
+		values[nunitCategory] = categories
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	properties := make(map[string]string, len(values))
+	for name, v := range values {
+		properties[name] = strings.Join(v, ",")
+	}
+
+	return properties
+}
+
+func nunitToTest(fixture string, c nunitCase, categories []string) junit.Test {
+	classname := c.ClassName
+	if classname == "" {
+		classname = fixture
+	}
+
+	seconds, _ := strconv.ParseFloat(c.Duration, 64)
+
+	test := junit.Test{
+		Name:       c.Name,
+		Classname:  classname,
+		Duration:   time.Duration(seconds * float64(time.Second)),
+		Properties: nunitProperties(c.Properties, categories),
+		SystemOut:  strings.TrimSpace(c.Output),
+	}
+
+	switch c.Result {
+	case "Failed":
+		test.Status = junit.StatusFailed
+		// the exceptions thrown by the test, and the invalid tests, are errors instead of assertion failures
+		if c.Label == "Error" || c.Label == "Invalid" || c.Label == "Cancelled" {
+			test.Status = junit.StatusError
+		}
+	case "Skipped", "Inconclusive":
+		test.Status = junit.StatusSkipped
+		test.Message = strings.TrimSpace(c.Reason)
+	default:
+		test.Status = junit.StatusPassed
+	}
+
+	if test.Status == junit.StatusFailed || test.Status == junit.StatusError {
+		failure := junit.Error{Type: c.Label}
+		if c.Failure != nil {
+			test.Message = strings.TrimSpace(c.Failure.Message)
+			failure.Message = test.Message
+			failure.Body = strings.TrimSpace(c.Failure.StackTrace)
+		}
+		test.Error = failure
+	}
+
+	return test
+}
@@ -0,0 +1,69 @@
+package formats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIngestNUnit(t *testing.T) {
+	t.Run("Valid report", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join("testdata", "TestResult.xml"))
+		require.NoError(t, err)
+
+		suites, err := Parse(NUnit, data)
+		require.NoError(t, err)
+		require.Len(t, suites, 2)
+
+		calculator := suites[0]
+		require.Equal(t, "Example.CalculatorTests", calculator.Name)
+		require.Equal(t, "Example.Tests.dll", calculator.Package)
+		require.Equal(t, map[string]string{"Author": "jane", "Category": "Math"}, calculator.Properties)
+		require.Len(t, calculator.Tests, 4) // the test case of the parameterized method belongs to the fixture
+		require.Equal(t, 2, calculator.Totals.Passed)
+		require.Equal(t, 1, calculator.Totals.Error)
+		require.Equal(t, 1, calculator.Totals.Skipped)
+
+		add := calculator.Tests[0]
+		require.Equal(t, "Add", add.Name)
+		require.Equal(t, "Example.CalculatorTests", add.Classname)
+		require.Equal(t, junit.StatusPassed, add.Status)
+		require.Equal(t, 12*time.Millisecond, add.Duration)
+		require.Equal(t, "adding 1 and 2", add.SystemOut)
+		require.Equal(t, map[string]string{"Category": "Fast,Math", "Description": "adds two numbers"}, add.Properties)
+
+		divide := calculator.Tests[1]
+		require.Equal(t, junit.StatusError, divide.Status)
+		require.Equal(t, "System.DivideByZeroException : Attempted to divide by zero.", divide.Message)
+		require.ErrorContains(t, divide.Error, "Calculator.cs:line 12")
+
+		subtract := calculator.Tests[2]
+		require.Equal(t, junit.StatusSkipped, subtract.Status)
+		require.Equal(t, "not implemented yet", subtract.Message)
+		require.Equal(t, map[string]string{"Category": "Math"}, subtract.Properties) // the internal property is skipped
+
+		require.Equal(t, "Multiply(2,3)", calculator.Tests[3].Name)
+
+		parser := suites[1]
+		require.Equal(t, "Example.ParserTests", parser.Name)
+		require.Nil(t, parser.Properties)
+		require.Equal(t, junit.StatusFailed, parser.Tests[0].Status)
+		require.Contains(t, parser.Tests[0].Message, "Expected: 3")
+	})
+
+	t.Run("Timestamps", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join("testdata", "TestResult.xml"))
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"2023-09-12 08:30:00.1234567Z", "2023-09-12 08:30:01Z"}, SuiteTimestamps(NUnit, data))
+	})
+
+	t.Run("Invalid report", func(t *testing.T) {
+		_, err := Parse(NUnit, []byte("<testsuite>"))
+		require.ErrorContains(t, err, "failed to parse the NUnit report")
+	})
+}
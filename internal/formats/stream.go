@@ -0,0 +1,99 @@
+package formats
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/joshdk/go-junit"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// Yield receives each suite of a report as soon as it's parsed, together with its raw start timestamp,
+// which is empty if the report does not include it. Returning an error stops the parsing
+type Yield func(suite junit.Suite, timestamp string) error
+
+// streamers parse the reports one suite at a time, for the formats that can be split into suites
+// without reading the whole document
+var streamers = map[string]func(data []byte, yield Yield) error{
+	GoTest: func(data []byte, yield Yield) error {
+		return streamGoTest(bytes.NewReader(data), yield)
+	},
+	Jest: streamJest,
+	JUnit: func(data []byte, yield Yield) error {
+		return streamJUnit(bytes.NewReader(data), yield)
+	},
+	Mocha: streamMocha,
+}
+
+// readerStreamers parse the reports while they are read, for the formats whose suites can be complete
+// before the end of the input, i.e. the packages of go test -json, or the test suites of JUnit
+var readerStreamers = map[string]func(r io.Reader, yield Yield) error{
+	GoTest: streamGoTest,
+	JUnit: func(r io.Reader, yield Yield) error {
+		// the reports piped by PowerShell may be UTF-16 encoded, detected by their byte order mark
+		return streamJUnit(transform.NewReader(r, unicode.BOMOverride(transform.Nop)), yield)
+	},
+}
+
+// Stream parses the report, calling yield with each suite in the same order as Parse returns them, so that
+// the suites can be processed while the rest of the report is parsed. The formats that cannot be split are
+// fully parsed first
+func Stream(format string, data []byte, yield Yield) error {
+	if err := Validate(format); err != nil {
+		return err
+	}
+
+	if format == Auto {
+		format = Detect(data)
+	}
+
+	if streamer, ok := streamers[format]; ok {
+		return streamer(data, yield)
+	}
+
+	suites, err := parsers[format](data)
+	if err != nil {
+		return err
+	}
+
+	timestamps := SuiteTimestamps(format, data)
+	for i, suite := range suites {
+		timestamp := ""
+		if len(timestamps) == len(suites) {
+			timestamp = timestamps[i]
+		}
+
+		if err := yield(suite, timestamp); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// IsIncremental returns true if the format can be parsed while the report is read, yielding the suites
+// before the end of the input
+func IsIncremental(format string) bool {
+	_, ok := readerStreamers[format]
+	return ok
+}
+
+// StreamReader parses the report while it's read from r, calling yield with each suite as soon as it's
+// complete. The formats which are not incremental are read until the end first, and parsed with Stream
+func StreamReader(format string, r io.Reader, yield Yield) error {
+	if err := Validate(format); err != nil {
+		return err
+	}
+
+	if streamer, ok := readerStreamers[format]; ok {
+		return streamer(r, yield)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	return Stream(format, data, yield)
+}
@@ -0,0 +1,96 @@
+package formats
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshdk/go-junit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStream(t *testing.T) {
+	collect := func(t *testing.T, format string, data []byte) ([]junit.Suite, []string) {
+		t.Helper()
+
+		suites := []junit.Suite{}
+		timestamps := []string{}
+		err := Stream(format, data, func(suite junit.Suite, timestamp string) error {
+			suites = append(suites, suite)
+			timestamps = append(timestamps, timestamp)
+			return nil
+		})
+		require.NoError(t, err)
+
+		return suites, timestamps
+	}
+
+	t.Run("JUnit is equivalent to Parse", func(t *testing.T) {
+		for _, name := range []string{"TEST-sample.xml", "TEST-sample2.xml", "TEST-sample3.xml"} {
+			data, err := os.ReadFile(filepath.Join("..", "..", name))
+			require.NoError(t, err)
+
+			expected, err := Parse(JUnit, data)
+			require.NoError(t, err)
+
+			suites, timestamps := collect(t, JUnit, data)
+			require.Equal(t, expected, suites, name)
+			require.Equal(t, SuiteTimestamps(JUnit, data), timestamps, name)
+		}
+	})
+
+	t.Run("JUnit with nested and concatenated suites", func(t *testing.T) {
+		data := []byte(`<?xml version="1.0"?>
+<testsuites>
+  <testsuite name="parent" timestamp="2024-01-02T10:00:00">
+    <testsuite name="child"><testcase name="a"/></testsuite>
+  </testsuite>
+</testsuites>
+<?xml version="1.0"?>
+<testsuite name="other"><testcase name="b"/></testsuite>`)
+
+		expected, err := Parse(JUnit, data)
+		require.NoError(t, err)
+
+		suites, timestamps := collect(t, JUnit, data)
+		require.Equal(t, expected, suites)
+		require.Equal(t, []string{"2024-01-02T10:00:00", ""}, timestamps)
+	})
+
+	t.Run("Formats without streamer", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join("testdata", "testng-results.xml"))
+		require.NoError(t, err)
+
+		expected, err := Parse(TestNG, data)
+		require.NoError(t, err)
+
+		suites, timestamps := collect(t, TestNG, data)
+		require.Equal(t, expected, suites)
+		require.Equal(t, SuiteTimestamps(TestNG, data), timestamps)
+	})
+
+	t.Run("Yield error stops the parsing", func(t *testing.T) {
+		data := []byte(`<testsuites><testsuite name="a"/><testsuite name="b"/></testsuites>`)
+
+		calls := 0
+		err := Stream(JUnit, data, func(junit.Suite, string) error {
+			calls++
+			return errors.New("stop")
+		})
+		require.EqualError(t, err, "stop")
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("Invalid report", func(t *testing.T) {
+		err := Stream(JUnit, []byte(`<testsuite name="a"><testcase name="b">`), func(junit.Suite, string) error {
+			return nil
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("Unsupported format", func(t *testing.T) {
+		err := Stream("unknown", nil, func(junit.Suite, string) error { return nil })
+		require.ErrorContains(t, err, "unsupported format: unknown")
+	})
+}
@@ -0,0 +1,132 @@
+package formats
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// checkers validate the reports in strict mode, for the formats whose parser silently skips
+// the content it does not understand
+var checkers = map[string]func(name string, data []byte) error{
+	JUnit: checkJUnit,
+}
+
+// Check validates the report in strict mode, returning every problem that would make the parser skip
+// content, prefixed by the name of the source and the line in it, i.e. TEST-sample.xml:12
+func Check(format string, name string, data []byte) error {
+	if err := Validate(format); err != nil {
+		return err
+	}
+
+	if format == Auto {
+		format = Detect(data)
+	}
+
+	checker, ok := checkers[format]
+	if !ok {
+		// the parser for the format already fails on any error
+		return nil
+	}
+
+	return checker(name, data)
+}
+
+// checkJUnit walks the XML document, failing on syntax errors, test cases outside of a test suite,
+// test suites and test cases without a name, invalid durations, and documents without test suites
+func checkJUnit(name string, data []byte) error {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = true
+
+	errs := []error{}
+	stack := []string{}
+	suites := 0
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var syntaxErr *xml.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				errs = append(errs, fmt.Errorf("%s:%d: %s", name, syntaxErr.Line, syntaxErr.Msg))
+			} else {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			}
+			break
+		}
+
+		line, _ := decoder.InputPos()
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "testsuite":
+				suites++
+				errs = append(errs, checkJUnitElement(name, line, t)...)
+			case "testcase":
+				if !slices.Contains(stack, "testsuite") {
+					errs = append(errs, fmt.Errorf("%s:%d: testcase %q is outside of a testsuite", name, line, attr(t, "name")))
+				}
+				errs = append(errs, checkJUnitElement(name, line, t)...)
+			}
+
+			stack = append(stack, t.Name.Local)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	if len(errs) == 0 && suites == 0 {
+		errs = append(errs, fmt.Errorf("%s: there are no test suites in the report", name))
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkJUnitElement validates the name and the duration of a testsuite or testcase element
+func checkJUnitElement(name string, line int, element xml.StartElement) []error {
+	errs := []error{}
+
+	if attr(element, "name") == "" {
+		errs = append(errs, fmt.Errorf("%s:%d: %s without name", name, line, element.Name.Local))
+	}
+
+	if duration := attr(element, "time"); duration != "" {
+		// the same formats accepted by go-junit, i.e. 1,234.5 or 1m30s
+		if !isDuration(duration) {
+			errs = append(errs, fmt.Errorf("%s:%d: %s %q has an invalid time: %s", name, line, element.Name.Local, attr(element, "name"), duration))
+		}
+	}
+
+	return errs
+}
+
+func isDuration(duration string) bool {
+	if _, err := strconv.ParseFloat(strings.ReplaceAll(duration, ",", ""), 64); err == nil {
+		return true
+	}
+
+	_, err := time.ParseDuration(duration)
+
+	return err == nil
+}
+
+func attr(element xml.StartElement, name string) string {
+	for _, a := range element.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,61 @@
+package formats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	t.Run("Valid reports", func(t *testing.T) {
+		for _, report := range []string{"TEST-sample.xml", "TEST-sample2.xml", "TEST-sample3.xml"} {
+			data, err := os.ReadFile(filepath.Join("..", "..", report))
+			require.NoError(t, err)
+
+			require.NoError(t, Check(JUnit, report, data))
+		}
+	})
+
+	t.Run("Concatenated reports", func(t *testing.T) {
+		data := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<testsuite name="first"><testcase name="a" time="0.1"/></testsuite>
+<?xml version="1.0" encoding="UTF-8"?>
+<testsuite name="second"><testcase name="b" time="1m30s"/></testsuite>
+`)
+		require.NoError(t, Check(JUnit, "tar:-", data))
+	})
+
+	t.Run("Malformed XML", func(t *testing.T) {
+		data := []byte("<testsuites>\n<testsuite name=\"foo\">\n<testcase name=\"bar\">\n</testsuite>\n")
+
+		err := Check(JUnit, "TEST-broken.xml", data)
+		require.ErrorContains(t, err, "TEST-broken.xml:4: element <testcase> closed by </testsuite>")
+	})
+
+	t.Run("Content skipped by the parser", func(t *testing.T) {
+		data := []byte(`<testsuites>
+  <testcase name="orphan"/>
+  <testsuite>
+    <testcase time="abc"/>
+  </testsuite>
+</testsuites>
+`)
+
+		err := Check(JUnit, "TEST-skipped.xml", data)
+		require.ErrorContains(t, err, `TEST-skipped.xml:2: testcase "orphan" is outside of a testsuite`)
+		require.ErrorContains(t, err, "TEST-skipped.xml:3: testsuite without name")
+		require.ErrorContains(t, err, "TEST-skipped.xml:4: testcase without name")
+		require.ErrorContains(t, err, `TEST-skipped.xml:4: testcase "" has an invalid time: abc`)
+	})
+
+	t.Run("Without test suites", func(t *testing.T) {
+		err := Check(JUnit, "-", []byte("<results></results>"))
+		require.ErrorContains(t, err, "-: there are no test suites in the report")
+	})
+
+	t.Run("Formats without checker", func(t *testing.T) {
+		require.NoError(t, Check(TestNG, "testng-results.xml", []byte("")))
+	})
+}
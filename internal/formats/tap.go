@@ -0,0 +1,275 @@
+package formats
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joshdk/go-junit"
+)
+
+// tapDefaultSuiteName name of the suites of the TAP streams without a name, i.e. the ones of Bats
+const tapDefaultSuiteName = "TAP"
+
+var (
+	// tapTestPoint ok or not ok, the optional test number, and the description with the optional directive
+	tapTestPoint = regexp.MustCompile(`^(not ok|ok)\b\s*(\d+)?\s*(?:-\s*)?(.*)$`)
+	// tapPlan the plan, with the optional directive of the skipped streams, i.e. 1..0 # SKIP no database
+	tapPlan = regexp.MustCompile(`^1\.\.(\d+)\s*(?:#\s*(.*))?$`)
+	// tapDirective the SKIP and TODO directives at the end of the description of a test point
+	tapDirective = regexp.MustCompile(`(?i)(?:^|\s)#\s*(skip\S*|todo)\b\s*(.*)$`)
+	// tapProveHeader the name of the test file printed by prove -v before its stream, i.e. t/basic.t ..
+	tapProveHeader = regexp.MustCompile(`^(\S+)\s+\.{2,}\s*$`)
+)
+
+// tapSubtestIndent indentation of the lines of the subtests, per level
+const tapSubtestIndent = "    "
+
+// ingestTAP converts a Test Anything Protocol stream, as produced by prove, Bats or node-tap, into jUnit test suites.
+// Each stream is a suite, named after the header printed by prove -v, and a new TAP version line starts a new
+// one. The test points of the subtests are test cases whose classname is the name of the subtest, replacing the
+// test point summarizing the subtest. The SKIP directives, and the TODO ones of the failed test points, which are
+// expected failures, are skipped test cases. The YAML diagnostics of a test point, and the comments following it,
+// are the body of its failure, and its duration is read from the duration_ms key of the YAML diagnostics
+func ingestTAP(data []byte) ([]junit.Suite, error) {
+	p := &tapParser{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		p.line(strings.TrimRight(scanner.Text(), "\r"))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the TAP stream: %w", err)
+	}
+
+	p.endSuite()
+
+	return p.suites, nil
+}
+
+// tapParser builds the suites of a TAP stream, line by line
+type tapParser struct {
+	suites []junit.Suite
+	suite  *junit.Suite
+	// name of the next suite, from the header of prove
+	nextName string
+	// subtests names of the subtests being parsed, by level
+	subtests []string
+	// subtestTests number of test points of the subtests since the last one started, so their summary points are dropped
+	subtestTests int
+	// pending index of the last test case, which receives the diagnostics following it
+	pending int
+	// yaml lines of the YAML diagnostics being read, and their indentation
+	yaml       []string
+	yamlIndent string
+	inYAML     bool
+}
+
+func (p *tapParser) line(line string) {
+	if p.inYAML {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "..." {
+			p.inYAML = false
+			p.applyYAML()
+			return
+		}
+
+		p.yaml = append(p.yaml, strings.TrimPrefix(line, p.yamlIndent))
+		return
+	}
+
+	level := 0
+	for strings.HasPrefix(line, tapSubtestIndent) {
+		line = line[len(tapSubtestIndent):]
+		level++
+	}
+	trimmed := strings.TrimSpace(line)
+
+	switch {
+	case trimmed == "":
+		return
+	case strings.HasPrefix(trimmed, "TAP version"):
+		if level == 0 && p.suite != nil && len(p.suite.Tests) > 0 {
+			p.endSuite()
+		}
+		p.startSuite()
+	case trimmed == "---" && p.pending >= 0 && p.suite != nil:
+		p.inYAML = true
+		p.yaml = nil
+		p.yamlIndent = strings.Repeat(tapSubtestIndent, level) + line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	case strings.HasPrefix(trimmed, "# Subtest:"):
+		p.startSuite()
+		p.enterSubtest(level, strings.TrimSpace(strings.TrimPrefix(trimmed, "# Subtest:")))
+	case strings.HasPrefix(trimmed, "Bail out!"):
+		p.startSuite()
+		reason := strings.TrimSpace(strings.TrimPrefix(trimmed, "Bail out!"))
+		p.suite.Tests = append(p.suite.Tests, junit.Test{
+			Name:    "Bail out!",
+			Status:  junit.StatusError,
+			Message: reason,
+			Error:   junit.Error{Message: reason},
+		})
+		p.pending = -1
+	case strings.HasPrefix(trimmed, "#"):
+		p.comment(strings.TrimSpace(strings.TrimPrefix(trimmed, "#")))
+	default:
+		if match := tapTestPoint.FindStringSubmatch(trimmed); match != nil {
+			p.startSuite()
+			p.testPoint(level, match[1] == "ok", match[2], match[3])
+			return
+		}
+
+		if match := tapPlan.FindStringSubmatch(trimmed); match != nil {
+			p.startSuite()
+			return
+		}
+
+		if match := tapProveHeader.FindStringSubmatch(trimmed); match != nil && level == 0 {
+			p.endSuite()
+			p.nextName = match[1]
+		}
+	}
+}
+
+// startSuite starts a suite if there is none
+func (p *tapParser) startSuite() {
+	if p.suite != nil {
+		return
+	}
+
+	name := p.nextName
+	if name == "" {
+		name = tapDefaultSuiteName
+	}
+	p.nextName = ""
+
+	p.suite = &junit.Suite{Name: name}
+	p.subtests = nil
+	p.pending = -1
+}
+
+// endSuite adds the suite being parsed, if any, to the suites
+func (p *tapParser) endSuite() {
+	if p.inYAML {
+		p.inYAML = false
+		p.applyYAML()
+	}
+
+	if p.suite == nil {
+		return
+	}
+
+	p.suite.Aggregate()
+	p.suites = append(p.suites, *p.suite)
+	p.suite = nil
+}
+
+func (p *tapParser) enterSubtest(level int, name string) {
+	if level < len(p.subtests) {
+		p.subtests = p.subtests[:level]
+	}
+	p.subtests = append(p.subtests, name)
+	p.subtestTests = 0
+}
+
+func (p *tapParser) testPoint(level int, ok bool, number string, description string) {
+	// the test point closing a subtest summarizes its test points, which are already test cases
+	if level < len(p.subtests) {
+		p.subtests = p.subtests[:level]
+		if p.subtestTests > 0 {
+			p.pending = -1
+			return
+		}
+	}
+
+	directive, reason := "", ""
+	if match := tapDirective.FindStringSubmatchIndex(description); match != nil {
+		directive = strings.ToLower(description[match[2]:match[3]])
+		reason = strings.TrimSpace(description[match[4]:match[5]])
+		description = strings.TrimSpace(description[:match[0]])
+	}
+
+	name := description
+	if name == "" {
+		name = "test " + number
+		if number == "" {
+			name = "test " + strconv.Itoa(len(p.suite.Tests)+1)
+		}
+	}
+
+	test := junit.Test{
+		Name:      name,
+		Classname: strings.Join(p.subtests, "."),
+		Status:    junit.StatusPassed,
+	}
+
+	switch {
+	case strings.HasPrefix(directive, "skip"), directive == "todo" && !ok:
+		test.Status = junit.StatusSkipped
+		test.Message = reason
+	case !ok:
+		test.Status = junit.StatusFailed
+		test.Error = junit.Error{}
+	}
+
+	p.suite.Tests = append(p.suite.Tests, test)
+	p.pending = len(p.suite.Tests) - 1
+	if len(p.subtests) > 0 {
+		p.subtestTests++
+	}
+}
+
+// comment appends the diagnostics to the failure of the last test case, as prove prints them after it
+func (p *tapParser) comment(text string) {
+	if p.suite == nil || p.pending < 0 {
+		return
+	}
+
+	test := &p.suite.Tests[p.pending]
+	failure, ok := test.Error.(junit.Error)
+	if !ok {
+		return
+	}
+
+	failure.Body = strings.TrimLeft(failure.Body+"\n"+text, "\n")
+	test.Error = failure
+}
+
+// applyYAML sets the message and the body of the failure, and the duration, of the last test case from its YAML
+// diagnostics. Only the top-level scalar keys are read
+func (p *tapParser) applyYAML() {
+	if p.suite == nil || p.pending < 0 {
+		return
+	}
+
+	test := &p.suite.Tests[p.pending]
+	for _, line := range p.yaml {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.HasPrefix(key, " ") {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+
+		switch strings.TrimSpace(key) {
+		case "duration_ms":
+			if ms, err := strconv.ParseFloat(value, 64); err == nil {
+				test.Duration = time.Duration(ms * float64(time.Millisecond))
+			}
+		case "message":
+			if test.Status == junit.StatusFailed {
+				test.Message = value
+			}
+		}
+	}
+
+	if failure, ok := test.Error.(junit.Error); ok {
+		failure.Message = test.Message
+		failure.Body = strings.TrimLeft(failure.Body+"\n"+strings.Join(p.yaml, "\n"), "\n")
+		test.Error = failure
+	}
+}
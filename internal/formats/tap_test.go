@@ -0,0 +1,75 @@
+package formats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIngestTAP(t *testing.T) {
+	t.Run("Prove and node-tap streams", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join("testdata", "prove.tap"))
+		require.NoError(t, err)
+
+		suites, err := Parse(TAP, data)
+		require.NoError(t, err)
+		require.Len(t, suites, 2)
+
+		basic := suites[0]
+		require.Equal(t, "t/basic.t", basic.Name)
+		require.Len(t, basic.Tests, 4)
+		require.Equal(t, 1, basic.Totals.Passed)
+		require.Equal(t, 1, basic.Totals.Failed)
+		require.Equal(t, 2, basic.Totals.Skipped)
+
+		divide := basic.Tests[1]
+		require.Equal(t, "divides the numbers", divide.Name)
+		require.Equal(t, junit.StatusFailed, divide.Status)
+		require.ErrorContains(t, divide.Error, "at t/basic.t line 12.")
+		require.NotContains(t, divide.Error.Error(), "Looks like you failed")
+
+		require.Equal(t, "parses the input", basic.Tests[2].Name)
+		require.Equal(t, "no parser on this platform", basic.Tests[2].Message)
+		// a failed test point with a TODO directive is an expected failure
+		require.Equal(t, junit.StatusSkipped, basic.Tests[3].Status)
+
+		node := suites[1]
+		require.Equal(t, "t/node.t", node.Name)
+		require.Len(t, node.Tests, 4) // the test point summarizing the subtest is dropped
+
+		require.Equal(t, "adds", node.Tests[0].Name)
+		require.Equal(t, "calculator", node.Tests[0].Classname)
+
+		subtract := node.Tests[1]
+		require.Equal(t, junit.StatusFailed, subtract.Status)
+		require.Equal(t, "expected 1, got 2", subtract.Message)
+		require.Equal(t, 12500*time.Microsecond, subtract.Duration)
+		require.ErrorContains(t, subtract.Error, "line: 21")
+
+		require.Equal(t, "standalone", node.Tests[2].Name)
+		require.Empty(t, node.Tests[2].Classname)
+
+		bailOut := node.Tests[3]
+		require.Equal(t, junit.StatusError, bailOut.Status)
+		require.Equal(t, "database is down", bailOut.Message)
+	})
+
+	t.Run("Without name", func(t *testing.T) {
+		suites, err := Parse(TAP, []byte("1..2\nok\nnot ok 2\n"))
+		require.NoError(t, err)
+		require.Len(t, suites, 1)
+		require.Equal(t, tapDefaultSuiteName, suites[0].Name)
+		require.Equal(t, "test 1", suites[0].Tests[0].Name)
+		require.Equal(t, "test 2", suites[0].Tests[1].Name)
+	})
+
+	t.Run("Empty stream", func(t *testing.T) {
+		suites, err := Parse(TAP, []byte("not a TAP stream\n"))
+		require.NoError(t, err)
+		require.Empty(t, suites)
+	})
+}
@@ -0,0 +1,108 @@
+package formats
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/joshdk/go-junit"
+)
+
+type testngResults struct {
+	XMLName xml.Name      `xml:"testng-results"`
+	Suites  []testngSuite `xml:"suite"`
+}
+
+type testngSuite struct {
+	Name  string       `xml:"name,attr"`
+	Tests []testngTest `xml:"test"`
+}
+
+type testngTest struct {
+	Name    string        `xml:"name,attr"`
+	Classes []testngClass `xml:"class"`
+}
+
+type testngClass struct {
+	Name    string         `xml:"name,attr"`
+	Methods []testngMethod `xml:"test-method"`
+}
+
+type testngMethod struct {
+	Name       string           `xml:"name,attr"`
+	Status     string           `xml:"status,attr"`
+	DurationMs int64            `xml:"duration-ms,attr"`
+	IsConfig   bool             `xml:"is-config,attr"`
+	Exception  *testngException `xml:"exception"`
+	Output     []string         `xml:"reporter-output>line"`
+}
+
+type testngException struct {
+	Class          string `xml:"class,attr"`
+	Message        string `xml:"message"`
+	FullStacktrace string `xml:"full-stacktrace"`
+}
+
+// ingestTestNG converts a testng-results.xml report into jUnit test suites, one per TestNG test,
+// skipping the configuration methods, such as @BeforeClass or @AfterMethod
+func ingestTestNG(data []byte) ([]junit.Suite, error) {
+	var results testngResults
+	if err := xml.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("failed to parse the TestNG report: %w", err)
+	}
+
+	suites := []junit.Suite{}
+	for _, s := range results.Suites {
+		for _, t := range s.Tests {
+			suite := junit.Suite{
+				Name:    t.Name,
+				Package: s.Name,
+			}
+
+			for _, c := range t.Classes {
+				for _, m := range c.Methods {
+					if m.IsConfig {
+						continue
+					}
+
+					suite.Tests = append(suite.Tests, testngToTest(c.Name, m))
+				}
+			}
+
+			suite.Aggregate()
+			suites = append(suites, suite)
+		}
+	}
+
+	return suites, nil
+}
+
+func testngToTest(className string, m testngMethod) junit.Test {
+	test := junit.Test{
+		Name:      m.Name,
+		Classname: className,
+		Duration:  time.Duration(m.DurationMs) * time.Millisecond,
+		SystemOut: strings.TrimSpace(strings.Join(m.Output, "\n")),
+	}
+
+	switch m.Status {
+	case "FAIL":
+		test.Status = junit.StatusFailed
+	case "SKIP":
+		test.Status = junit.StatusSkipped
+	default:
+		test.Status = junit.StatusPassed
+	}
+
+	if m.Exception != nil {
+		test.Message = strings.TrimSpace(m.Exception.Message)
+		test.Error = junit.Error{
+			Message: test.Message,
+			Type:    m.Exception.Class,
+			Body:    strings.TrimSpace(m.Exception.FullStacktrace),
+		}
+	}
+
+	return test
+}
@@ -0,0 +1,52 @@
+package formats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIngestTestNG(t *testing.T) {
+	t.Run("Valid report", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join("testdata", "testng-results.xml"))
+		require.NoError(t, err)
+
+		suites, err := Parse(TestNG, data)
+		require.NoError(t, err)
+		require.Len(t, suites, 1)
+
+		suite := suites[0]
+		require.Equal(t, "Calculator tests", suite.Name)
+		require.Equal(t, "Default Suite", suite.Package)
+		require.Len(t, suite.Tests, 4) // the configuration method is skipped
+		require.Equal(t, 4, suite.Totals.Tests)
+		require.Equal(t, 2, suite.Totals.Passed)
+		require.Equal(t, 1, suite.Totals.Failed)
+		require.Equal(t, 1, suite.Totals.Skipped)
+
+		add := suite.Tests[0]
+		require.Equal(t, "testAdd", add.Name)
+		require.Equal(t, "com.example.CalculatorTest", add.Classname)
+		require.Equal(t, junit.StatusPassed, add.Status)
+		require.Equal(t, 12*time.Millisecond, add.Duration)
+		require.Equal(t, "adding 1 and 2", add.SystemOut)
+
+		divide := suite.Tests[1]
+		require.Equal(t, junit.StatusFailed, divide.Status)
+		require.Equal(t, "expected [2] but found [3]", divide.Message)
+		require.ErrorContains(t, divide.Error, "expected [2] but found [3]")
+
+		skipped := suite.Tests[3]
+		require.Equal(t, "com.example.ParserTest", skipped.Classname)
+		require.Equal(t, junit.StatusSkipped, skipped.Status)
+	})
+
+	t.Run("Invalid report", func(t *testing.T) {
+		_, err := Parse(TestNG, []byte("<testsuite>"))
+		require.ErrorContains(t, err, "failed to parse the TestNG report")
+	})
+}
@@ -0,0 +1,145 @@
+package formats
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"time"
+)
+
+// timestampExtractors extract the raw start timestamps of the suites, in the same order as the parser
+// of the format returns them
+var timestampExtractors = map[string]func(data []byte) []string{
+	CTest:  ctestTimestamps,
+	JUnit:  junitTimestamps,
+	NUnit:  nunitTimestamps,
+	TestNG: testngTimestamps,
+	TRX:    trxTimestamps,
+}
+
+// SuiteTimestamps returns the raw start timestamp of each suite in the report, in the same order as Parse
+// returns the suites, using an empty string for the suites without timestamp. The timestamps are returned
+// as they are in the report, because most of them lack the timezone. It returns nil if the format does not
+// include timestamps, or the report cannot be read
+func SuiteTimestamps(format string, data []byte) []string {
+	if format == Auto {
+		format = Detect(data)
+	}
+
+	extractor, ok := timestampExtractors[format]
+	if !ok {
+		return nil
+	}
+
+	return extractor(data)
+}
+
+// junitTimestamps returns the timestamp attribute of the testsuite elements which are not nested
+// in another testsuite, as go-junit ingests the nested ones as part of their parent
+func junitTimestamps(data []byte) []string {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	timestamps := []string{}
+	depth := 0 // depth of nested testsuite elements
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Local != "testsuite" {
+				continue
+			}
+
+			if depth == 0 {
+				timestamps = append(timestamps, attr(t, "timestamp"))
+			}
+			depth++
+		case xml.EndElement:
+			if t.Name.Local == "testsuite" {
+				depth--
+			}
+		}
+	}
+
+	return timestamps
+}
+
+// testngTimestamps returns the started-at attribute of the test elements, which are ingested as suites
+func testngTimestamps(data []byte) []string {
+	var results struct {
+		Suites []struct {
+			Tests []struct {
+				StartedAt string `xml:"started-at,attr"`
+			} `xml:"test"`
+		} `xml:"suite"`
+	}
+	if err := xml.Unmarshal(data, &results); err != nil {
+		return nil
+	}
+
+	timestamps := []string{}
+	for _, s := range results.Suites {
+		for _, t := range s.Tests {
+			timestamps = append(timestamps, t.StartedAt)
+		}
+	}
+
+	return timestamps
+}
+
+// nunitTimestamps returns the start-time attribute of the test fixtures, which are ingested as suites
+func nunitTimestamps(data []byte) []string {
+	var run nunitRun
+	if err := xml.Unmarshal(data, &run); err != nil {
+		return nil
+	}
+
+	timestamps := []string{}
+	for _, fixture := range nunitFixtures(run.Suites, "", nil) {
+		timestamps = append(timestamps, fixture.suite.StartTime)
+	}
+
+	return timestamps
+}
+
+// trxTimestamps returns the startTime attribute of the first result of each test class, which are ingested as suites
+func trxTimestamps(data []byte) []string {
+	var run trxRun
+	if err := xml.Unmarshal(data, &run); err != nil {
+		return nil
+	}
+
+	definitions := make(map[string]trxDefinition, len(run.Definitions))
+	for _, definition := range run.Definitions {
+		definitions[definition.ID] = definition
+	}
+
+	timestamps := []string{}
+	for _, class := range trxClasses(run.Results, definitions) {
+		timestamps = append(timestamps, class.startTime)
+	}
+
+	return timestamps
+}
+
+// ctestTimestamps returns the StartTestTime element of the report, a Unix time, which is ingested as one suite
+func ctestTimestamps(data []byte) []string {
+	var site ctestSite
+	if err := xml.Unmarshal(data, &site); err != nil {
+		return nil
+	}
+
+	if site.StartTime <= 0 {
+		return []string{""}
+	}
+
+	return []string{time.Unix(site.StartTime, 0).UTC().Format(time.RFC3339)}
+}
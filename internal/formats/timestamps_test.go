@@ -0,0 +1,50 @@
+package formats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuiteTimestamps(t *testing.T) {
+	t.Run("JUnit", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join("..", "..", "TEST-sample.xml"))
+		require.NoError(t, err)
+
+		suites, err := Parse(JUnit, data)
+		require.NoError(t, err)
+
+		timestamps := SuiteTimestamps(JUnit, data)
+		require.Len(t, timestamps, len(suites))
+		require.Equal(t, "2021-11-15T05:16:16Z", timestamps[0])
+	})
+
+	t.Run("JUnit with nested suites", func(t *testing.T) {
+		data := []byte(`<testsuites>
+  <testsuite name="parent" timestamp="2021-11-15T05:16:16">
+    <testsuite name="child" timestamp="2021-11-15T05:16:17"></testsuite>
+  </testsuite>
+  <testsuite name="sibling"></testsuite>
+</testsuites>`)
+
+		suites, err := Parse(JUnit, data)
+		require.NoError(t, err)
+
+		timestamps := SuiteTimestamps(JUnit, data)
+		require.Len(t, timestamps, len(suites))
+		require.Equal(t, []string{"2021-11-15T05:16:16", ""}, timestamps)
+	})
+
+	t.Run("TestNG", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join("testdata", "testng-results.xml"))
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"2021-05-21T10:00:00 CEST"}, SuiteTimestamps(TestNG, data))
+	})
+
+	t.Run("Invalid report", func(t *testing.T) {
+		require.Nil(t, SuiteTimestamps(JUnit, []byte("<testsuite>")))
+	})
+}
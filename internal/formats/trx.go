@@ -0,0 +1,183 @@
+package formats
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joshdk/go-junit"
+)
+
+// trxCategory name of the property holding the test categories of a test case, as in the NUnit reports
+const trxCategory = "Category"
+
+type trxRun struct {
+	XMLName     xml.Name        `xml:"TestRun"`
+	Results     []trxResult     `xml:"Results>UnitTestResult"`
+	Definitions []trxDefinition `xml:"TestDefinitions>UnitTest"`
+}
+
+type trxResult struct {
+	TestID    string `xml:"testId,attr"`
+	TestName  string `xml:"testName,attr"`
+	Duration  string `xml:"duration,attr"`
+	StartTime string `xml:"startTime,attr"`
+	Outcome   string `xml:"outcome,attr"`
+	StdOut    string `xml:"Output>StdOut"`
+	StdErr    string `xml:"Output>StdErr"`
+	Message   string `xml:"Output>ErrorInfo>Message"`
+	Stack     string `xml:"Output>ErrorInfo>StackTrace"`
+}
+
+type trxDefinition struct {
+	ID         string `xml:"id,attr"`
+	Storage    string `xml:"storage,attr"`
+	Categories []struct {
+		Name string `xml:"TestCategory,attr"`
+	} `xml:"TestCategory>TestCategoryItem"`
+	Method struct {
+		ClassName string `xml:"className,attr"`
+	} `xml:"TestMethod"`
+}
+
+// trxClass the results of a test class, ingested as a suite
+type trxClass struct {
+	name      string
+	storage   string
+	startTime string
+	results   []trxResult
+}
+
+// ingestTRX converts a TRX report, produced by MSTest, VSTest and dotnet test --logger trx, into jUnit test
+// suites, one per test class, in the order of their first result. The package of the suites is the test
+// assembly, and the test categories are the Category property of the test cases. The results not executed,
+// or inconclusive, are skipped test cases, and the ones which timed out or were aborted are failed
+func ingestTRX(data []byte) ([]junit.Suite, error) {
+	var run trxRun
+	if err := xml.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("failed to parse the TRX report: %w", err)
+	}
+
+	definitions := make(map[string]trxDefinition, len(run.Definitions))
+	for _, definition := range run.Definitions {
+		definitions[definition.ID] = definition
+	}
+
+	suites := []junit.Suite{}
+	for _, class := range trxClasses(run.Results, definitions) {
+		suite := junit.Suite{
+			Name:    class.name,
+			Package: class.storage,
+		}
+
+		for _, result := range class.results {
+			suite.Tests = append(suite.Tests, trxToTest(class.name, result, definitions[result.TestID]))
+		}
+
+		suite.Aggregate()
+		suites = append(suites, suite)
+	}
+
+	return suites, nil
+}
+
+// trxClasses groups the results by the class of their test definition, in the order of their first result.
+// The start time of a class is the one of its first result
+func trxClasses(results []trxResult, definitions map[string]trxDefinition) []*trxClass {
+	classes := []*trxClass{}
+	byName := map[string]*trxClass{}
+	for _, result := range results {
+		definition := definitions[result.TestID]
+
+		// the class names of the old MSTest reports are qualified with the assembly, i.e. Example.Tests, Example, Version=1.0.0.0
+		name, _, _ := strings.Cut(definition.Method.ClassName, ",")
+		name = strings.TrimSpace(name)
+
+		class, ok := byName[name]
+		if !ok {
+			class = &trxClass{name: name, storage: trxAssembly(definition.Storage)}
+			byName[name] = class
+			classes = append(classes, class)
+		}
+
+		if class.startTime == "" {
+			class.startTime = result.StartTime
+		}
+		class.results = append(class.results, result)
+	}
+
+	return classes
+}
+
+// trxAssembly returns the file name of the assembly of a test, whose path is usually a Windows one
+func trxAssembly(storage string) string {
+	if storage == "" {
+		return ""
+	}
+
+	return path.Base(strings.ReplaceAll(storage, `\`, "/"))
+}
+
+func trxToTest(className string, result trxResult, definition trxDefinition) junit.Test {
+	test := junit.Test{
+		Name:      result.TestName,
+		Classname: className,
+		Duration:  trxDuration(result.Duration),
+		SystemOut: strings.TrimSpace(result.StdOut),
+		SystemErr: strings.TrimSpace(result.StdErr),
+	}
+
+	categories := []string{}
+	for _, category := range definition.Categories {
+		categories = append(categories, category.Name)
+	}
+	slices.Sort(categories)
+	if categories = slices.Compact(categories); len(categories) > 0 {
+		test.Properties = map[string]string{trxCategory: strings.Join(categories, ",")}
+	}
+
+	switch result.Outcome {
+	case "Passed", "PassedButRunAborted", "Warning", "Completed":
+		test.Status = junit.StatusPassed
+	case "Error":
+		test.Status = junit.StatusError
+	case "Failed", "Timeout", "Aborted":
+		test.Status = junit.StatusFailed
+	default:
+		// NotExecuted, Inconclusive, NotRunnable and the results of the interrupted runs
+		test.Status = junit.StatusSkipped
+		test.Message = strings.TrimSpace(result.Message)
+	}
+
+	if test.Status == junit.StatusFailed || test.Status == junit.StatusError {
+		test.Message = strings.TrimSpace(result.Message)
+		test.Error = junit.Error{
+			Message: test.Message,
+			Type:    result.Outcome,
+			Body:    strings.TrimSpace(result.Stack),
+		}
+	}
+
+	return test
+}
+
+// trxDuration parses the durations of the TRX reports, formatted as hh:mm:ss.fffffff
+func trxDuration(value string) time.Duration {
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return 0
+	}
+
+	hours, errH := strconv.Atoi(parts[0])
+	minutes, errM := strconv.Atoi(parts[1])
+	seconds, errS := strconv.ParseFloat(parts[2], 64)
+	if errH != nil || errM != nil || errS != nil {
+		return 0
+	}
+
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds*float64(time.Second))
+}
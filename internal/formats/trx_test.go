@@ -0,0 +1,65 @@
+package formats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIngestTRX(t *testing.T) {
+	t.Run("Valid report", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join("testdata", "results.trx"))
+		require.NoError(t, err)
+
+		suites, err := Parse(TRX, data)
+		require.NoError(t, err)
+		require.Len(t, suites, 2)
+
+		calculator := suites[0]
+		require.Equal(t, "Example.CalculatorTests", calculator.Name)
+		require.Equal(t, "example.tests.dll", calculator.Package)
+		require.Len(t, calculator.Tests, 3)
+		require.Equal(t, 1, calculator.Totals.Passed)
+		require.Equal(t, 1, calculator.Totals.Failed)
+		require.Equal(t, 1, calculator.Totals.Skipped)
+
+		add := calculator.Tests[0]
+		require.Equal(t, "Add", add.Name)
+		require.Equal(t, "Example.CalculatorTests", add.Classname)
+		require.Equal(t, junit.StatusPassed, add.Status)
+		require.Equal(t, 12*time.Millisecond, add.Duration)
+		require.Equal(t, "adding 1 and 2", add.SystemOut)
+		require.Equal(t, map[string]string{"Category": "Fast,Math"}, add.Properties)
+
+		divide := calculator.Tests[1]
+		require.Equal(t, junit.StatusFailed, divide.Status)
+		require.Equal(t, "Assert.AreEqual failed. Expected:<2>. Actual:<3>.", divide.Message)
+		require.ErrorContains(t, divide.Error, "CalculatorTests.cs:line 21")
+
+		subtract := calculator.Tests[2]
+		require.Equal(t, junit.StatusSkipped, subtract.Status)
+		require.Equal(t, "not implemented yet", subtract.Message)
+
+		// the class name qualified with the assembly
+		parser := suites[1]
+		require.Equal(t, "Example.ParserTests", parser.Name)
+		require.Equal(t, junit.StatusFailed, parser.Tests[0].Status)
+		require.Equal(t, 1500*time.Millisecond, parser.Tests[0].Duration)
+	})
+
+	t.Run("Timestamps", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join("testdata", "results.trx"))
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"2023-09-12T08:30:00.1234567+00:00", "2023-09-12T08:30:00.3000000+00:00"}, SuiteTimestamps(TRX, data))
+	})
+
+	t.Run("Invalid report", func(t *testing.T) {
+		_, err := Parse(TRX, []byte("<testsuite>"))
+		require.ErrorContains(t, err, "failed to parse the TRX report")
+	})
+}
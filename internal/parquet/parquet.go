@@ -0,0 +1,143 @@
+// Package parquet writes the results of the tests as Parquet files with parquet-go, from columns only known at
+// run time, i.e. the attributes of the resource, so they are read by pandas, DuckDB or Spark as any other table.
+package parquet
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	parquetgo "github.com/parquet-go/parquet-go"
+)
+
+// ColumnType the type of the values of a column
+type ColumnType int
+
+const (
+	// String UTF-8 strings
+	String ColumnType = iota
+	// Int64 signed 64-bit integers
+	Int64
+	// Timestamp instants, stored in milliseconds since the Unix epoch, in UTC
+	Timestamp
+	// JSON JSON documents, stored as strings
+	JSON
+)
+
+// Column a column of the file
+type Column struct {
+	Name string
+	Type ColumnType
+	// Optional the values of the column can be nil
+	Optional bool
+}
+
+func (c Column) node() parquetgo.Node {
+	var node parquetgo.Node
+	switch c.Type {
+	case Int64:
+		node = parquetgo.Int(64)
+	case Timestamp:
+		node = parquetgo.Timestamp(parquetgo.Millisecond)
+	case JSON:
+		node = parquetgo.JSON()
+	default:
+		node = parquetgo.String()
+	}
+
+	if c.Optional {
+		return parquetgo.Optional(node)
+	}
+
+	return node
+}
+
+// value returns the value of the column, at the definition level of a defined value
+func (c Column) value(value interface{}) (parquetgo.Value, bool) {
+	switch v := value.(type) {
+	case string:
+		if c.Type == String || c.Type == JSON {
+			return parquetgo.ByteArrayValue([]byte(v)), true
+		}
+	case int64:
+		if c.Type == Int64 {
+			return parquetgo.Int64Value(v), true
+		}
+	case time.Time:
+		if c.Type == Timestamp {
+			return parquetgo.Int64Value(v.UnixMilli()), true
+		}
+	}
+
+	return parquetgo.Value{}, false
+}
+
+// orderedGroup the root of the schema, whose fields keep the order of the columns instead of being sorted by
+// name, as the ones of a parquet-go Group are
+type orderedGroup struct {
+	parquetgo.Group
+	names []string
+}
+
+func (g orderedGroup) Fields() []parquetgo.Field {
+	byName := map[string]parquetgo.Field{}
+	for _, field := range g.Group.Fields() {
+		byName[field.Name()] = field
+	}
+
+	fields := make([]parquetgo.Field, len(g.names))
+	for i, name := range g.names {
+		fields[i] = byName[name]
+	}
+
+	return fields
+}
+
+// Write writes the rows as a Parquet file to w. Each row has a value per column: a string for the String and
+// JSON columns, an int64 for the Int64 columns and a time.Time for the Timestamp columns, or nil for the
+// optional columns
+func Write(w io.Writer, columns []Column, rows [][]interface{}) error {
+	root := orderedGroup{Group: parquetgo.Group{}, names: make([]string, len(columns))}
+	for i, column := range columns {
+		if _, ok := root.Group[column.Name]; ok {
+			return fmt.Errorf("duplicated column %s", column.Name)
+		}
+
+		root.Group[column.Name] = column.node()
+		root.names[i] = column.Name
+	}
+
+	values := make([]parquetgo.Row, len(rows))
+	for r, row := range rows {
+		values[r] = make(parquetgo.Row, len(columns))
+
+		for i, column := range columns {
+			if row[i] == nil {
+				if !column.Optional {
+					return fmt.Errorf("missing value of the required column %s in row %d", column.Name, r)
+				}
+
+				values[r][i] = parquetgo.NullValue().Level(0, 0, i)
+				continue
+			}
+
+			value, ok := column.value(row[i])
+			if !ok {
+				return fmt.Errorf("invalid value of the column %s in row %d: %T", column.Name, r, row[i])
+			}
+
+			definitionLevel := 0
+			if column.Optional {
+				definitionLevel = 1
+			}
+			values[r][i] = value.Level(0, definitionLevel, i)
+		}
+	}
+
+	writer := parquetgo.NewWriter(w, parquetgo.NewSchema("schema", root))
+	if _, err := writer.WriteRows(values); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
@@ -0,0 +1,87 @@
+package parquet
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	parquetgo "github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	columns := []Column{
+		{Name: "timestamp", Type: Timestamp},
+		{Name: "name", Type: String},
+		{Name: "duration_ms", Type: Int64},
+		{Name: "attributes", Type: JSON},
+		{Name: "host.arch", Type: String, Optional: true},
+	}
+	now := time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC)
+	rows := [][]interface{}{
+		{now, "first", int64(1500), `{"a":1}`, "amd64"},
+		{now.Add(time.Second), "second", int64(0), `{}`, nil},
+		{now.Add(2 * time.Second), "third", int64(-1), `{}`, nil},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, columns, rows))
+
+	file, err := parquetgo.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Equal(t, int64(len(rows)), file.NumRows())
+
+	names := []string{}
+	for _, path := range file.Schema().Columns() {
+		names = append(names, path[0])
+	}
+	require.Equal(t, []string{"timestamp", "name", "duration_ms", "attributes", "host.arch"}, names)
+
+	arch, ok := file.Schema().Lookup("host.arch")
+	require.True(t, ok)
+	require.True(t, arch.Node.Optional())
+
+	require.Len(t, file.RowGroups(), 1)
+	reader := file.RowGroups()[0].Rows()
+	defer reader.Close()
+
+	read := make([]parquetgo.Row, len(rows)+1)
+	n, err := reader.ReadRows(read)
+	if !errors.Is(err, io.EOF) {
+		require.NoError(t, err)
+	}
+	require.Equal(t, len(rows), n)
+
+	for i, row := range read[:n] {
+		require.Len(t, row, len(columns))
+		require.Equal(t, rows[i][0].(time.Time).UnixMilli(), row[0].Int64())
+		require.Equal(t, rows[i][1], string(row[1].ByteArray()))
+		require.Equal(t, rows[i][2], row[2].Int64())
+		require.Equal(t, rows[i][3], string(row[3].ByteArray()))
+
+		if rows[i][4] == nil {
+			require.True(t, row[4].IsNull())
+			continue
+		}
+		require.Equal(t, rows[i][4], string(row[4].ByteArray()))
+	}
+}
+
+func TestWrite_InvalidValues(t *testing.T) {
+	t.Run("Missing required value", func(t *testing.T) {
+		err := Write(io.Discard, []Column{{Name: "name", Type: String}}, [][]interface{}{{nil}})
+		require.EqualError(t, err, "missing value of the required column name in row 0")
+	})
+
+	t.Run("Wrong type", func(t *testing.T) {
+		err := Write(io.Discard, []Column{{Name: "duration_ms", Type: Int64}}, [][]interface{}{{"1s"}})
+		require.EqualError(t, err, "invalid value of the column duration_ms in row 0: string")
+	})
+
+	t.Run("Duplicated column", func(t *testing.T) {
+		err := Write(io.Discard, []Column{{Name: "name", Type: String}, {Name: "name", Type: String}}, nil)
+		require.EqualError(t, err, "duplicated column name")
+	})
+}
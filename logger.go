@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"go.opentelemetry.io/otel"
+)
+
+const (
+	// LogFormatJSON writes the log records as JSON objects, one per line
+	LogFormatJSON = "json"
+	// LogFormatText writes the log records as key=value pairs, one per line
+	LogFormatText = "text"
+)
+
+// newLogger returns a structured logger writing to w, using the level and format from the configuration
+func newLogger(w io.Writer, cfg *config.Config) (*slog.Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+		return nil, fmt.Errorf("invalid log level: %s. Supported levels: debug, info, warn, error", cfg.LogLevel)
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+
+	switch strings.ToLower(cfg.LogFormat) {
+	case LogFormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	case LogFormatText:
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("invalid log format: %s. Supported formats: %s, %s", cfg.LogFormat, LogFormatText, LogFormatJSON)
+	}
+}
+
+// initLogger sets the structured logger as the default one, also handling the errors
+// produced by the OpenTelemetry SDK, such as export failures. The log records are written to w,
+// unless a log file is set in the configuration
+func initLogger(w io.Writer, cfg *config.Config) error {
+	if cfg.LogFile != "" {
+		// the file is never closed, as it's used until the process exits, and the writes are not buffered
+		f, err := os.OpenFile(cfg.LogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to open the log file: %w", err)
+		}
+
+		w = f
+	}
+
+	logger, err := newLogger(w, cfg)
+	if err != nil {
+		return err
+	}
+
+	slog.SetDefault(logger)
+
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		slog.Error("OpenTelemetry SDK error", "error", err)
+	}))
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLogger(t *testing.T) {
+	t.Run("JSON format", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.LogFormat = LogFormatJSON
+
+		var buf bytes.Buffer
+		logger, err := newLogger(&buf, cfg)
+		require.NoError(t, err)
+
+		logger.Info("a message", "key", "value")
+
+		record := map[string]string{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		require.Equal(t, "INFO", record["level"])
+		require.Equal(t, "a message", record["msg"])
+		require.Equal(t, "value", record["key"])
+	})
+
+	t.Run("Text format filters by level", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.LogLevel = "warn"
+
+		var buf bytes.Buffer
+		logger, err := newLogger(&buf, cfg)
+		require.NoError(t, err)
+
+		logger.Info("an info message")
+		require.Empty(t, buf.String())
+
+		logger.Warn("a warn message")
+		require.Contains(t, buf.String(), `level=WARN msg="a warn message"`)
+	})
+
+	t.Run("Invalid level", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.LogLevel = "verbose"
+
+		_, err := newLogger(&bytes.Buffer{}, cfg)
+		require.ErrorContains(t, err, "invalid log level: verbose")
+	})
+
+	t.Run("Invalid format", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.LogFormat = "xml"
+
+		_, err := newLogger(&bytes.Buffer{}, cfg)
+		require.ErrorContains(t, err, "invalid log format: xml")
+	})
+}
+
+func TestInitLogger(t *testing.T) {
+	defaultLogger := slog.Default()
+	t.Cleanup(func() {
+		slog.SetDefault(defaultLogger)
+	})
+
+	t.Run("Log file", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.LogFile = filepath.Join(t.TempDir(), "junit2otlp.log")
+
+		var buf bytes.Buffer
+		require.NoError(t, initLogger(&buf, cfg))
+
+		slog.Info("a message to the file")
+
+		b, err := os.ReadFile(cfg.LogFile)
+		require.NoError(t, err)
+		require.Contains(t, string(b), `msg="a message to the file"`)
+		require.Empty(t, buf.String())
+	})
+
+	t.Run("Invalid log file", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.LogFile = filepath.Join(t.TempDir(), "missing", "junit2otlp.log")
+
+		err := initLogger(&bytes.Buffer{}, cfg)
+		require.ErrorContains(t, err, "failed to open the log file")
+	})
+}
@@ -0,0 +1,143 @@
+package junit2otlp
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/joshdk/go-junit"
+)
+
+// allureResult a test result of the allure-results directory, as written by the Allure integrations
+type allureResult struct {
+	UUID          string             `json:"uuid"`
+	HistoryID     string             `json:"historyId"`
+	TestCaseID    string             `json:"testCaseId"`
+	FullName      string             `json:"fullName"`
+	Name          string             `json:"name"`
+	Status        string             `json:"status"`
+	StatusDetails *allureDetails     `json:"statusDetails,omitempty"`
+	Stage         string             `json:"stage"`
+	Start         int64              `json:"start"`
+	Stop          int64              `json:"stop"`
+	Labels        []allureLabel      `json:"labels"`
+	Attachments   []allureAttachment `json:"attachments"`
+}
+
+type allureDetails struct {
+	Message string `json:"message,omitempty"`
+	Trace   string `json:"trace,omitempty"`
+}
+
+type allureLabel struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type allureAttachment struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	Type   string `json:"type"`
+}
+
+// allureStatus returns the status of Allure for the status of the test case: the errored tests are broken
+func allureStatus(status junit.Status) string {
+	switch status {
+	case junit.StatusFailed:
+		return "failed"
+	case junit.StatusError:
+		return "broken"
+	case junit.StatusSkipped:
+		return "skipped"
+	default:
+		return "passed"
+	}
+}
+
+// writeAllureResults writes a result file per test case of the suites in the dir, creating it if it does not
+// exist, so an Allure report can be generated from it, i.e. with allure generate. The console outputs are text
+// attachments. As the reports have no start time per test case, the tests are laid out one after the other,
+// ending at end
+func writeAllureResults(dir string, suites []junit.Suite, end time.Time) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create the Allure results directory: %w", err)
+	}
+
+	var total time.Duration
+	for _, suite := range suites {
+		for _, test := range suite.Tests {
+			total += test.Duration
+		}
+	}
+	start := end.Add(-total)
+
+	for _, suite := range suites {
+		for _, test := range suite.Tests {
+			fullName := test.Name
+			if test.Classname != "" {
+				fullName = test.Classname + "." + test.Name
+			}
+			hash := md5.Sum([]byte(suite.Name + "#" + fullName))
+
+			result := allureResult{
+				UUID:       newUUID(),
+				HistoryID:  hex.EncodeToString(hash[:]),
+				TestCaseID: hex.EncodeToString(hash[:]),
+				FullName:   fullName,
+				Name:       test.Name,
+				Status:     allureStatus(test.Status),
+				Stage:      "finished",
+				Start:      start.UnixMilli(),
+				Stop:       start.Add(test.Duration).UnixMilli(),
+				Labels: []allureLabel{
+					{Name: "suite", Value: suite.Name},
+					{Name: "framework", Value: "junit"},
+				},
+				Attachments: []allureAttachment{},
+			}
+			start = start.Add(test.Duration)
+
+			if suite.Package != "" {
+				result.Labels = append(result.Labels, allureLabel{Name: "package", Value: suite.Package})
+			}
+			if test.Classname != "" {
+				result.Labels = append(result.Labels, allureLabel{Name: "testClass", Value: test.Classname})
+			}
+
+			if test.Message != "" || test.Error != nil {
+				result.StatusDetails = &allureDetails{Message: test.Message}
+				if test.Error != nil {
+					result.StatusDetails.Trace = test.Error.Error()
+				}
+			}
+
+			for _, output := range []struct{ name, content string }{{"system-out", test.SystemOut}, {"system-err", test.SystemErr}} {
+				if output.content == "" {
+					continue
+				}
+
+				source := newUUID() + "-attachment.txt"
+				if err := os.WriteFile(filepath.Join(dir, source), []byte(output.content), 0o644); err != nil {
+					return fmt.Errorf("failed to write the Allure attachment: %w", err)
+				}
+
+				result.Attachments = append(result.Attachments, allureAttachment{Name: output.name, Source: source, Type: "text/plain"})
+			}
+
+			b, err := json.Marshal(result)
+			if err != nil {
+				return fmt.Errorf("failed to marshal the Allure result of the test case %s: %w", test.Name, err)
+			}
+
+			if err := os.WriteFile(filepath.Join(dir, result.UUID+"-result.json"), b, 0o644); err != nil {
+				return fmt.Errorf("failed to write the Allure result: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package junit2otlp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteAllureResults(t *testing.T) {
+	suites := []junit.Suite{
+		{
+			Name:    "suite",
+			Package: "pkg",
+			Tests: []junit.Test{
+				{Name: "passed", Classname: "pkg.Class", Status: junit.StatusPassed, Duration: time.Second, SystemOut: "hello"},
+				{Name: "errored", Classname: "pkg.Class", Status: junit.StatusError, Message: "boom", Error: junit.Error{Message: "boom", Body: "stack"}},
+			},
+		},
+	}
+	end := time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC)
+
+	dir := filepath.Join(t.TempDir(), "allure-results")
+	require.NoError(t, writeAllureResults(dir, suites, end))
+
+	results := map[string]allureResult{}
+	attachments := []string{}
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name()) == ".txt" {
+			attachments = append(attachments, entry.Name())
+			continue
+		}
+
+		b, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		require.NoError(t, err)
+
+		var result allureResult
+		require.NoError(t, json.Unmarshal(b, &result))
+		require.Equal(t, result.UUID+"-result.json", entry.Name())
+		results[result.Name] = result
+	}
+	require.Len(t, results, 2)
+
+	passed := results["passed"]
+	require.Equal(t, "pkg.Class.passed", passed.FullName)
+	require.Equal(t, "passed", passed.Status)
+	require.Equal(t, "finished", passed.Stage)
+	require.Equal(t, end.Add(-time.Second).UnixMilli(), passed.Start)
+	require.Equal(t, end.UnixMilli(), passed.Stop)
+	require.Contains(t, passed.Labels, allureLabel{Name: "suite", Value: "suite"})
+	require.Contains(t, passed.Labels, allureLabel{Name: "testClass", Value: "pkg.Class"})
+	require.Nil(t, passed.StatusDetails)
+
+	require.Len(t, passed.Attachments, 1)
+	require.Equal(t, []string{passed.Attachments[0].Source}, attachments)
+	content, err := os.ReadFile(filepath.Join(dir, passed.Attachments[0].Source))
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(content))
+
+	errored := results["errored"]
+	require.Equal(t, "broken", errored.Status)
+	require.Equal(t, &allureDetails{Message: "boom", Trace: "stack"}, errored.StatusDetails)
+	require.NotEqual(t, passed.HistoryID, errored.HistoryID)
+}
@@ -0,0 +1,101 @@
+package junit2otlp
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/internal/formats"
+)
+
+// fileLineRegex matches the file:line references in the failure messages and stack traces,
+// i.e. foo_test.go:42 for Go, or (CalculatorTest.java:21) for Java
+var fileLineRegex = regexp.MustCompile(`([\w./\\-]+\.[A-Za-z]\w*):(\d+)`)
+
+// printGithubAnnotations writes an error workflow command for each failed or errored test, so that
+// Github Actions shows the failures inline on the pull request diff. The file and line are added
+// when they can be resolved from the test case
+func printGithubAnnotations(w io.Writer, suites []junit.Suite) error {
+	for _, suite := range suites {
+		for _, test := range suite.Tests {
+			if test.Status != junit.StatusFailed && test.Status != junit.StatusError {
+				continue
+			}
+
+			params := []string{}
+
+			file, line := resolveFileLine(test)
+			if file != "" {
+				params = append(params, "file="+escapeAnnotationProperty(file))
+				if line > 0 {
+					params = append(params, "line="+strconv.Itoa(line))
+				}
+			}
+
+			title := test.Name
+			if test.Classname != "" {
+				title = test.Classname + "." + test.Name
+			}
+			params = append(params, "title="+escapeAnnotationProperty(title))
+
+			message := test.Message
+			if message == "" && test.Error != nil {
+				message = test.Error.Error()
+			}
+			if message == "" {
+				message = fmt.Sprintf("%s %s", title, test.Status)
+			}
+
+			if _, err := fmt.Fprintf(w, "::error %s::%s\n", strings.Join(params, ","), escapeAnnotationData(message)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveFileLine returns the file and line of a test case, from its file and line attributes, as produced
+// by pytest or jest-junit, or from the first file:line reference in its failure. It returns an empty file if
+// they cannot be resolved
+func resolveFileLine(test junit.Test) (string, int) {
+	if file := test.Properties["file"]; file != "" {
+		line, _ := strconv.Atoi(test.Properties["line"])
+		return file, line
+	}
+
+	candidates := []string{test.Message}
+	for _, failure := range formats.TestFailures(test) {
+		candidates = append(candidates, failure.Body)
+	}
+	candidates = append(candidates, test.SystemOut, test.SystemErr)
+
+	for _, candidate := range candidates {
+		matches := fileLineRegex.FindStringSubmatch(candidate)
+		if len(matches) == 3 {
+			line, _ := strconv.Atoi(matches[2])
+			return strings.ReplaceAll(matches[1], `\`, "/"), line
+		}
+	}
+
+	return "", 0
+}
+
+// escapeAnnotationData escapes the message of a workflow command, as done by the @actions/core toolkit
+func escapeAnnotationData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+
+	return strings.ReplaceAll(s, "\n", "%0A")
+}
+
+// escapeAnnotationProperty escapes the value of a property of a workflow command, as done by the @actions/core toolkit
+func escapeAnnotationProperty(s string) string {
+	s = escapeAnnotationData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+
+	return strings.ReplaceAll(s, ",", "%2C")
+}
@@ -0,0 +1,66 @@
+package junit2otlp
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/joshdk/go-junit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintGithubAnnotations(t *testing.T) {
+	suites := []junit.Suite{{
+		Name: "suite",
+		Tests: []junit.Test{
+			{Name: "TestPassed", Status: junit.StatusPassed},
+			{
+				Name:       "test_attributes",
+				Classname:  "tests.test_foo",
+				Status:     junit.StatusFailed,
+				Message:    "assert 1 == 2",
+				Properties: map[string]string{"file": "tests/test_foo.py", "line": "12"},
+			},
+			{
+				Name:    "TestStackTrace",
+				Status:  junit.StatusFailed,
+				Message: "Failed",
+				Error:   junit.Error{Message: "Failed", Body: "    foo_test.go:42: expected 100%,\n got 0"},
+			},
+			{Name: "TestUnresolved", Status: junit.StatusError, Message: "panic: boom"},
+		},
+	}}
+
+	var buf bytes.Buffer
+	err := printGithubAnnotations(&buf, suites)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	require.Equal(t, "::error file=tests/test_foo.py,line=12,title=tests.test_foo.test_attributes::assert 1 == 2", lines[0])
+	require.Equal(t, "::error file=foo_test.go,line=42,title=TestStackTrace::Failed", lines[1])
+	require.Equal(t, "::error title=TestUnresolved::panic: boom", lines[2])
+}
+
+func TestResolveFileLine(t *testing.T) {
+	t.Run("Java stack trace", func(t *testing.T) {
+		test := junit.Test{Error: junit.Error{Body: "java.lang.AssertionError\n\tat com.example.CalculatorTest.testDivide(CalculatorTest.java:21)"}}
+
+		file, line := resolveFileLine(test)
+		require.Equal(t, "CalculatorTest.java", file)
+		require.Equal(t, 21, line)
+	})
+
+	t.Run("Windows paths", func(t *testing.T) {
+		test := junit.Test{Message: `src\foo\bar.test.ts:7`}
+
+		file, line := resolveFileLine(test)
+		require.Equal(t, "src/foo/bar.test.ts", file)
+		require.Equal(t, 7, line)
+	})
+}
+
+func TestEscapeAnnotation(t *testing.T) {
+	require.Equal(t, "100%25 done%0Anext", escapeAnnotationData("100% done\nnext"))
+	require.Equal(t, "a%3Ab%2Cc", escapeAnnotationProperty("a:b,c"))
+}
@@ -0,0 +1,120 @@
+package junit2otlp
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+)
+
+// minAnomalySamples minimum number of durations needed to tell whether a duration is an outlier
+const minAnomalySamples = 3
+
+// durationStats the number, the mean and the standard deviation of a set of durations, in milliseconds
+type durationStats struct {
+	count  int
+	mean   float64
+	stddev float64
+}
+
+func newDurationStats(durations []float64) durationStats {
+	stats := durationStats{count: len(durations)}
+	if stats.count == 0 {
+		return stats
+	}
+
+	for _, d := range durations {
+		stats.mean += d
+	}
+	stats.mean /= float64(stats.count)
+
+	var variance float64
+	for _, d := range durations {
+		variance += (d - stats.mean) * (d - stats.mean)
+	}
+	stats.stddev = math.Sqrt(variance / float64(stats.count))
+
+	return stats
+}
+
+// isAnomaly returns true if the duration is more than threshold standard deviations above the mean. There are no
+// anomalies when there are not enough durations, or when all of them are equal
+func (s durationStats) isAnomaly(durationMs float64, threshold float64) bool {
+	return s.count >= minAnomalySamples && s.stddev > 0 && durationMs > s.mean+threshold*s.stddev
+}
+
+// anomalyDetector flags the test cases whose duration is an outlier, compared with their own durations in the
+// history store, or with the durations of the rest of the test cases of their suite
+type anomalyDetector struct {
+	threshold float64
+	history   map[historyKey]durationStats
+}
+
+// newAnomalyDetector returns the detector for the threshold of the configuration, reading the baselines of the
+// test cases from the history store if it's set. It returns nil if the threshold is not positive. The errors
+// reading the history store are logged, comparing the test cases with their suites only
+func newAnomalyDetector(ctx context.Context, cfg *config.Config) *anomalyDetector {
+	if cfg.AnomalyThreshold <= 0 {
+		return nil
+	}
+
+	detector := &anomalyDetector{threshold: cfg.AnomalyThreshold, history: map[historyKey]durationStats{}}
+
+	if cfg.HistoryDB != "" {
+		store, err := openHistory(cfg.HistoryDB)
+		if err != nil {
+			slog.Warn("not using the history store to detect the slow tests", "error", err)
+			return detector
+		}
+		defer store.close()
+
+		baselines, err := store.durationBaselines(ctx)
+		if err != nil {
+			slog.Warn("not using the history store to detect the slow tests", "error", err)
+			return detector
+		}
+		detector.history = baselines
+	}
+
+	return detector
+}
+
+// detect returns whether each test case of the suite, in order, is an anomaly. The test cases with enough runs in
+// the history store are compared with them, and the rest with the test cases of the suite. The skipped test
+// cases are never anomalies
+func (a *anomalyDetector) detect(suite junit.Suite) []bool {
+	anomalies := make([]bool, len(suite.Tests))
+	if a == nil {
+		return anomalies
+	}
+
+	durations := make([]float64, 0, len(suite.Tests))
+	for _, test := range suite.Tests {
+		if test.Status != junit.StatusSkipped {
+			durations = append(durations, durationMs(test.Duration))
+		}
+	}
+	suiteStats := newDurationStats(durations)
+
+	for i, test := range suite.Tests {
+		if test.Status == junit.StatusSkipped {
+			continue
+		}
+
+		stats := suiteStats
+		if baseline, ok := a.history[historyKey{suite: suite.Name, classname: test.Classname, name: test.Name}]; ok && baseline.count >= minAnomalySamples {
+			stats = baseline
+		}
+
+		anomalies[i] = stats.isAnomaly(durationMs(test.Duration), a.threshold)
+	}
+
+	return anomalies
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
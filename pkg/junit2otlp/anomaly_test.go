@@ -0,0 +1,71 @@
+package junit2otlp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDurationStats(t *testing.T) {
+	stats := newDurationStats([]float64{10, 20, 30, 40})
+	require.Equal(t, 4, stats.count)
+	require.Equal(t, 25.0, stats.mean)
+	require.InDelta(t, 11.18, stats.stddev, 0.01)
+
+	require.True(t, stats.isAnomaly(50, 2))
+	require.False(t, stats.isAnomaly(40, 2))
+
+	// not enough durations, or all of them equal
+	require.False(t, newDurationStats([]float64{10, 1000}).isAnomaly(1000, 0.5))
+	require.False(t, newDurationStats([]float64{10, 10, 10}).isAnomaly(10, 0))
+	require.Equal(t, durationStats{}, newDurationStats(nil))
+}
+
+func TestAnomalyDetector(t *testing.T) {
+	suite := junit.Suite{
+		Name: "suite",
+		Tests: []junit.Test{
+			{Name: "a", Status: junit.StatusPassed, Duration: 10 * time.Millisecond},
+			{Name: "b", Status: junit.StatusPassed, Duration: 11 * time.Millisecond},
+			{Name: "c", Status: junit.StatusPassed, Duration: 9 * time.Millisecond},
+			{Name: "d", Status: junit.StatusPassed, Duration: 10 * time.Millisecond},
+			{Name: "e", Status: junit.StatusFailed, Duration: 10 * time.Millisecond},
+			{Name: "slow", Status: junit.StatusPassed, Duration: 200 * time.Millisecond},
+			{Name: "skipped", Status: junit.StatusSkipped, Duration: 10 * time.Second},
+		},
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		detector := newAnomalyDetector(context.Background(), config.NewConfigFromDefaults())
+		require.Nil(t, detector)
+		require.Equal(t, make([]bool, len(suite.Tests)), detector.detect(suite))
+	})
+
+	t.Run("Compared with the suite", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.AnomalyThreshold = 2
+
+		detector := newAnomalyDetector(context.Background(), cfg)
+		require.Equal(t, []bool{false, false, false, false, false, true, false}, detector.detect(suite))
+	})
+
+	t.Run("Compared with the history", func(t *testing.T) {
+		detector := &anomalyDetector{
+			threshold: 2,
+			history: map[historyKey]durationStats{
+				// usually faster than the rest
+				{suite: "suite", name: "c"}: {count: 10, mean: 2, stddev: 1},
+				// usually as slow as in this run
+				{suite: "suite", name: "slow"}: {count: 10, mean: 195, stddev: 10},
+				// not enough runs, so compared with the suite
+				{suite: "suite", name: "a"}: {count: 2, mean: 1, stddev: 0.1},
+			},
+		}
+
+		require.Equal(t, []bool{false, false, true, false, false, false, false}, detector.detect(suite))
+	})
+}
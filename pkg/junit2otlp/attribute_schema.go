@@ -0,0 +1,67 @@
+package junit2otlp
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/joshdk/go-junit"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// AttributeSchemaLegacy emits the tests.* attributes of the suites and test cases
+	AttributeSchemaLegacy = "legacy"
+	// AttributeSchemaOtel emits the test.* attributes from the incubating OpenTelemetry semantic conventions too,
+	// keeping the tests.* attributes, so the dashboards can be migrated
+	AttributeSchemaOtel = "otel"
+)
+
+var attributeSchemas = []string{AttributeSchemaLegacy, AttributeSchemaOtel}
+
+// validateAttributeSchema returns an error if the schema is not one of the supported ones
+func validateAttributeSchema(schema string) error {
+	if !slices.Contains(attributeSchemas, schema) {
+		return fmt.Errorf("invalid attribute schema: %s. Supported schemas: %s", schema, strings.Join(attributeSchemas, ", "))
+	}
+
+	return nil
+}
+
+// otelSuiteAttributes returns the test.suite.* attributes of the OpenTelemetry conventions for the suite. The suites
+// with failed or errored test cases fail, and the ones with all their test cases skipped are skipped
+func otelSuiteAttributes(suite junit.Suite) []attribute.KeyValue {
+	status := "success"
+	switch {
+	case suite.Totals.Failed+suite.Totals.Error > 0:
+		status = "failure"
+	case suite.Totals.Tests > 0 && suite.Totals.Skipped == suite.Totals.Tests:
+		status = "skipped"
+	}
+
+	return []attribute.KeyValue{
+		attribute.Key(TestSuiteName).String(suite.Name),
+		attribute.Key(TestSuiteRunStatus).String(status),
+	}
+}
+
+// otelTestAttributes returns the test.case.* attributes of the OpenTelemetry conventions for the test case. Its name
+// is qualified with its classname, and the result is omitted for the skipped ones, as the conventions only define
+// pass and fail
+func otelTestAttributes(test junit.Test) []attribute.KeyValue {
+	name := test.Name
+	if test.Classname != "" {
+		name = test.Classname + "." + test.Name
+	}
+
+	attributes := []attribute.KeyValue{attribute.Key(TestCaseName).String(name)}
+
+	switch test.Status {
+	case junit.StatusPassed:
+		attributes = append(attributes, attribute.Key(TestCaseResultStatus).String("pass"))
+	case junit.StatusFailed, junit.StatusError:
+		attributes = append(attributes, attribute.Key(TestCaseResultStatus).String("fail"))
+	}
+
+	return attributes
+}
@@ -0,0 +1,73 @@
+package junit2otlp
+
+import (
+	"testing"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestValidateAttributeSchema(t *testing.T) {
+	require.NoError(t, validateAttributeSchema(AttributeSchemaLegacy))
+	require.NoError(t, validateAttributeSchema(AttributeSchemaOtel))
+	require.ErrorContains(t, validateAttributeSchema("ecs"), "invalid attribute schema: ecs")
+}
+
+func TestOtelSuiteAttributes(t *testing.T) {
+	tests := []struct {
+		totals   junit.Totals
+		expected string
+	}{
+		{totals: junit.Totals{Tests: 2, Passed: 1, Skipped: 1}, expected: "success"},
+		{totals: junit.Totals{Tests: 2, Passed: 1, Failed: 1}, expected: "failure"},
+		{totals: junit.Totals{Tests: 1, Error: 1}, expected: "failure"},
+		{totals: junit.Totals{Tests: 2, Skipped: 2}, expected: "skipped"},
+		{totals: junit.Totals{}, expected: "success"},
+	}
+
+	for _, tt := range tests {
+		attributes := otelSuiteAttributes(junit.Suite{Name: "suite", Totals: tt.totals})
+		require.Equal(t, []attribute.KeyValue{
+			attribute.Key(TestSuiteName).String("suite"),
+			attribute.Key(TestSuiteRunStatus).String(tt.expected),
+		}, attributes)
+	}
+}
+
+func TestGetTestAttributes_OtelSchema(t *testing.T) {
+	suite := junit.Suite{
+		Name: "suite",
+		Tests: []junit.Test{
+			{Name: "testAdd", Classname: "org.example.CalculatorTest", Status: junit.StatusFailed},
+			{Name: "TestSkipped", Status: junit.StatusSkipped},
+		},
+	}
+	suite.Aggregate()
+
+	cfg := config.NewConfigFromDefaults()
+	cfg.AttributePrefix = "ci."
+	cfg.AttributeSchema = AttributeSchemaOtel
+
+	sharedAttributes := getSharedSuiteAttributes(cfg, getSuiteAttributes(cfg, suite, nil))
+
+	// the conventions are not prefixed, and the legacy attributes are kept
+	failed := getTestAttributes(cfg, suite.Tests[0], sharedAttributes)
+	require.True(t, keyExistsWithValue(t, failed, TestCaseName, "org.example.CalculatorTest.testAdd"))
+	require.True(t, keyExistsWithValue(t, failed, TestCaseResultStatus, "fail"))
+	require.True(t, keyExistsWithValue(t, failed, TestSuiteName, "suite"))
+	require.True(t, keyExistsWithValue(t, failed, TestSuiteRunStatus, "failure"))
+	require.True(t, keyExistsWithValue(t, failed, "ci."+TestStatus, "failed"))
+
+	skipped := getTestAttributes(cfg, suite.Tests[1], sharedAttributes)
+	require.True(t, keyExistsWithValue(t, skipped, TestCaseName, "TestSkipped"))
+	for _, kv := range skipped {
+		require.NotEqual(t, attribute.Key(TestCaseResultStatus), kv.Key)
+	}
+
+	cfg.AttributeSchema = AttributeSchemaLegacy
+	for _, kv := range getTestAttributes(cfg, suite.Tests[0], nil) {
+		require.NotEqual(t, attribute.Key(TestCaseName), kv.Key)
+	}
+}
@@ -0,0 +1,242 @@
+package junit2otlp
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/internal/formats"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// semconvNamespaces namespaces of the attributes defined by the OpenTelemetry semantic conventions,
+// which are never prefixed
+var semconvNamespaces = []string{"cicd.", "code.", "exception.", "host.", "os.", "test.", "vcs."}
+
+// getRuntimeAttributes returns the attributes describing the runtime where the tool is executed
+func getRuntimeAttributes() []attribute.KeyValue {
+	return []attribute.KeyValue{
+		semconv.HostArchKey.String(runtime.GOARCH),
+		semconv.OSNameKey.String(runtime.GOOS),
+	}
+}
+
+// resolveRuntimeAttributes returns the attributes shared by every span and metric: the runtime attributes,
+// the URL and the metadata of the CI job, the SCM attributes, the SCM provider API attributes if enabled, the attributes of
+// the registered contributors, and the additional attributes
+func resolveRuntimeAttributes(ctx context.Context, cfg *config.Config) []attribute.KeyValue {
+	runtimeAttributes := getRuntimeAttributes()
+	runtimeAttributes = append(runtimeAttributes, getCICDAttributes()...)
+	runtimeAttributes = append(runtimeAttributes, getCIAttributes()...)
+
+	scm := GetScm(cfg.RepositoryPath, cfg.ScmAttributesSchema)
+	if scm != nil {
+		scmAttributes := scm.contributeAttributes()
+		runtimeAttributes = append(runtimeAttributes, scmAttributes...)
+	} else {
+		slog.Debug("no SCM context detected, not contributing SCM attributes", "repositoryPath", cfg.RepositoryPath)
+	}
+
+	if cfg.ScmAPIEnrichment {
+		scmAPI := getScmAPIContributor()
+		if scmAPI != nil {
+			runtimeAttributes = append(runtimeAttributes, scmAPI.contributeAttributes()...)
+		}
+	}
+
+	runtimeAttributes = append(runtimeAttributes, contributeAttributes(ctx, cfg)...)
+
+	// add additional attributes if provided to the runtime attributes, sorted so the output is reproducible
+	for _, k := range slices.Sorted(maps.Keys(cfg.AdditionalAttributes)) {
+		runtimeAttributes = append(runtimeAttributes, attribute.Key(k).String(cfg.AdditionalAttributes[k]))
+	}
+
+	return prefixAttributes(cfg.AttributePrefix, runtimeAttributes)
+}
+
+// getSuiteAttributes returns the attributes for a test suite, including the runtime attributes
+func getSuiteAttributes(cfg *config.Config, suite junit.Suite, runtimeAttributes []attribute.KeyValue) []attribute.KeyValue {
+	suiteAttributes := make([]attribute.KeyValue, 0, 7+len(suite.Properties)+len(runtimeAttributes))
+	suiteAttributes = append(suiteAttributes,
+		semconv.CodeNamespaceKey.String(suite.Package),
+		attribute.Key(TestsSuiteName).String(suite.Name),
+		outputAttribute(cfg, TestsSystemErr, suite.SystemErr),
+		outputAttribute(cfg, TestsSystemOut, suite.SystemOut),
+		attribute.Key(TestsDuration).Int64(suite.Totals.Duration.Milliseconds()),
+	)
+
+	if cfg.AttributeSchema == AttributeSchemaOtel {
+		suiteAttributes = append(suiteAttributes, otelSuiteAttributes(suite)...)
+	}
+
+	suiteAttributes = appendProps(cfg, suiteAttributes, suite.Properties)
+	suiteAttributes = prefixAttributes(cfg.AttributePrefix, suiteAttributes)
+
+	// the runtime attributes are already prefixed
+	suiteAttributes = append(suiteAttributes, runtimeAttributes...)
+
+	return suiteAttributes
+}
+
+// getSharedSuiteAttributes returns the attributes of a test suite inherited by its test cases, built once per suite.
+// The logs of the suite are excluded, as they can be huge and they are already in the span of the suite
+func getSharedSuiteAttributes(cfg *config.Config, suiteAttributes []attribute.KeyValue) []attribute.KeyValue {
+	logKeys := prefixAttributes(cfg.AttributePrefix, []attribute.KeyValue{
+		attribute.Key(TestsSystemErr).String(""),
+		attribute.Key(TestsSystemOut).String(""),
+	})
+
+	shared := make([]attribute.KeyValue, 0, len(suiteAttributes))
+	for _, kv := range suiteAttributes {
+		key := strings.TrimSuffix(string(kv.Key), ".chunks")
+		if key != string(logKeys[0].Key) && key != string(logKeys[1].Key) {
+			shared = append(shared, kv)
+		}
+	}
+
+	return shared
+}
+
+// addFailureEvents adds an event to the span of the test case per failure or error element, in the order of the
+// report, so every failed soft assertion of the test case is visible in the trace. If configured, each failure is
+// also recorded as an exception event of the semantic conventions, shown by the tracing backends with its stack trace.
+// The options, i.e. the timestamp of the end of the test case, apply to all the events
+func addFailureEvents(cfg *config.Config, span trace.Span, test junit.Test, options ...trace.EventOption) {
+	for i, failure := range formats.TestFailures(test) {
+		eventAttributes := []attribute.KeyValue{
+			attribute.Key(TestFailureMessage).String(failure.Message),
+			attribute.Key(TestFailureOrdinal).Int(i + 1),
+		}
+		if failure.Type != "" {
+			eventAttributes = append(eventAttributes, attribute.Key(TestFailureType).String(failure.Type))
+		}
+
+		span.AddEvent(TestFailureEvent, append(options, trace.WithAttributes(limitSpanAttributes(cfg, prefixAttributes(cfg.AttributePrefix, eventAttributes))...))...)
+
+		if cfg.ExceptionEvents {
+			span.AddEvent(semconv.ExceptionEventName, append(options, trace.WithAttributes(limitSpanAttributes(cfg, exceptionAttributes(failure))...))...)
+		}
+	}
+}
+
+// exceptionAttributes returns the attributes of the exception event of the failure, skipping the empty ones
+func exceptionAttributes(failure junit.Error) []attribute.KeyValue {
+	attributes := make([]attribute.KeyValue, 0, 3)
+	if failure.Type != "" {
+		attributes = append(attributes, semconv.ExceptionTypeKey.String(failure.Type))
+	}
+	if failure.Message != "" {
+		attributes = append(attributes, semconv.ExceptionMessageKey.String(failure.Message))
+	}
+	if failure.Body != "" {
+		attributes = append(attributes, semconv.ExceptionStacktraceKey.String(failure.Body))
+	}
+
+	return attributes
+}
+
+// getTestAttributes returns the attributes for a test case, including the shared attributes of its suite,
+// allocating the resulting slice only once
+func getTestAttributes(cfg *config.Config, test junit.Test, sharedAttributes []attribute.KeyValue) []attribute.KeyValue {
+	testAttributes := make([]attribute.KeyValue, 0, 11+len(test.Properties)+len(sharedAttributes))
+	testAttributes = append(testAttributes,
+		semconv.CodeFunctionKey.String(test.Name),
+		attribute.Key(TestDuration).Int64(test.Duration.Milliseconds()),
+		attribute.Key(TestClassName).String(test.Classname),
+		attribute.Key(TestMessage).String(test.Message),
+		attribute.Key(TestStatus).String(string(test.Status)),
+		outputAttribute(cfg, TestSystemErr, test.SystemErr),
+		outputAttribute(cfg, TestSystemOut, test.SystemOut),
+	)
+
+	if cfg.AttributeSchema == AttributeSchemaOtel {
+		testAttributes = append(testAttributes, otelTestAttributes(test)...)
+	}
+
+	testAttributes = appendProps(cfg, testAttributes, test.Properties)
+
+	if test.Error != nil {
+		testAttributes = append(testAttributes, attribute.Key(TestError).String(test.Error.Error()))
+
+		// the type of the failure or error element, i.e. the class of the exception
+		var junitErr junit.Error
+		if errors.As(test.Error, &junitErr) && junitErr.Type != "" {
+			testAttributes = append(testAttributes, attribute.Key(TestFailureType).String(junitErr.Type))
+		}
+	}
+
+	testAttributes = prefixAttributes(cfg.AttributePrefix, testAttributes)
+
+	// the suite attributes are already prefixed
+	testAttributes = append(testAttributes, sharedAttributes...)
+
+	return testAttributes
+}
+
+// propsToLabels converts the properties into attributes, skipping the properties not allowed or denied by the configuration
+func propsToLabels(cfg *config.Config, props map[string]string) []attribute.KeyValue {
+	return appendProps(cfg, []attribute.KeyValue{}, props)
+}
+
+// appendProps appends the properties allowed by the configuration to the attributes, as propsToLabels does,
+// without allocating an intermediate slice. They are sorted by name, so the output is reproducible
+func appendProps(cfg *config.Config, attributes []attribute.KeyValue, props map[string]string) []attribute.KeyValue {
+	for _, k := range slices.Sorted(maps.Keys(props)) {
+		if !isPropertyAllowed(cfg, k) {
+			continue
+		}
+
+		attributes = append(attributes, attribute.Key(k).String(props[k]))
+	}
+
+	return attributes
+}
+
+// isPropertyAllowed returns true if the property is allowed and not denied by the configuration
+func isPropertyAllowed(cfg *config.Config, name string) bool {
+	// if there is an allowed list (all properties by default) and the key is not in it, skip it
+	if len(cfg.PropertiesAllowed) > 0 && !slices.Contains(cfg.PropertiesAllowed, name) {
+		return false
+	}
+
+	// the denied list takes precedence over the allowed list
+	return !slices.Contains(cfg.PropertiesDenied, name)
+}
+
+// prefixAttributes prepends the prefix to the key of every attribute not defined by the OpenTelemetry
+// semantic conventions, in a new slice, as the attributes may be shared, i.e. the matrix dimensions of the suites
+// of a report. It returns the attributes untouched if the prefix is empty
+func prefixAttributes(prefix string, attributes []attribute.KeyValue) []attribute.KeyValue {
+	if prefix == "" {
+		return attributes
+	}
+
+	prefixed := make([]attribute.KeyValue, len(attributes))
+	for i, kv := range attributes {
+		prefixed[i] = kv
+		if !isSemconvKey(string(kv.Key)) {
+			prefixed[i].Key = attribute.Key(prefix + string(kv.Key))
+		}
+	}
+
+	return prefixed
+}
+
+// isSemconvKey returns true if the key belongs to a namespace of the OpenTelemetry semantic conventions
+func isSemconvKey(key string) bool {
+	for _, namespace := range semconvNamespaces {
+		if strings.HasPrefix(key, namespace) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,193 @@
+package junit2otlp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/internal/formats"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+)
+
+func TestPropsToLabels(t *testing.T) {
+	props := map[string]string{
+		"go.os":      "linux",
+		"go.version": "go1.16.3 linux/amd64",
+		"secret":     "s3cr3t",
+	}
+
+	t.Run("All properties allowed by default", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+
+		atts := propsToLabels(cfg, props)
+		require.Len(t, atts, 3)
+	})
+
+	t.Run("Sorted by name", func(t *testing.T) {
+		atts := propsToLabels(config.NewConfigFromDefaults(), props)
+		require.Equal(t, []attribute.Key{"go.os", "go.version", "secret"}, []attribute.Key{atts[0].Key, atts[1].Key, atts[2].Key})
+	})
+
+	t.Run("Allowed properties", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.PropertiesAllowed = []string{"go.os", "go.version"}
+
+		atts := propsToLabels(cfg, props)
+		require.Len(t, atts, 2)
+		require.True(t, keyExistsWithValue(t, atts, "go.os", "linux"))
+		require.True(t, keyExistsWithValue(t, atts, "go.version", "go1.16.3 linux/amd64"))
+	})
+
+	t.Run("Denied properties", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.PropertiesDenied = []string{"secret"}
+
+		atts := propsToLabels(cfg, props)
+		require.Len(t, atts, 2)
+		require.False(t, keyExistsWithValue(t, atts, "secret", "s3cr3t"))
+	})
+
+	t.Run("Denied properties take precedence", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.PropertiesAllowed = []string{"go.os", "secret"}
+		cfg.PropertiesDenied = []string{"secret"}
+
+		atts := propsToLabels(cfg, props)
+		require.Len(t, atts, 1)
+		require.True(t, keyExistsWithValue(t, atts, "go.os", "linux"))
+	})
+}
+
+func TestGetTestAttributes(t *testing.T) {
+	suite := junit.Suite{
+		Name:      "suite",
+		SystemOut: "a huge log",
+		SystemErr: "another huge log",
+		Tests: []junit.Test{
+			{Name: "TestFoo", Status: junit.StatusPassed, Properties: map[string]string{"go.os": "linux"}},
+		},
+	}
+
+	for _, prefix := range []string{"", "ci."} {
+		t.Run("Prefix "+prefix, func(t *testing.T) {
+			cfg := config.NewConfigFromDefaults()
+			cfg.AttributePrefix = prefix
+
+			runtimeAttributes := []attribute.KeyValue{semconv.HostArchKey.String("amd64")}
+
+			suiteAttributes := getSuiteAttributes(cfg, suite, runtimeAttributes)
+			require.True(t, keyExistsWithValue(t, suiteAttributes, prefix+TestsSystemOut, "a huge log"))
+			require.True(t, keyExistsWithValue(t, suiteAttributes, prefix+TestsSystemErr, "another huge log"))
+
+			sharedAttributes := getSharedSuiteAttributes(cfg, suiteAttributes)
+			require.Len(t, sharedAttributes, len(suiteAttributes)-2)
+
+			testAttributes := getTestAttributes(cfg, suite.Tests[0], sharedAttributes)
+			require.True(t, keyExistsWithValue(t, testAttributes, string(semconv.CodeFunctionKey), "TestFoo"))
+			require.True(t, keyExistsWithValue(t, testAttributes, prefix+"go.os", "linux"))
+			require.True(t, keyExistsWithValue(t, testAttributes, prefix+TestsSuiteName, "suite"))
+			require.True(t, keyExistsWithValue(t, testAttributes, string(semconv.HostArchKey), "amd64"))
+			require.False(t, keyExistsWithValue(t, testAttributes, prefix+TestsSystemOut, "a huge log"))
+			require.False(t, keyExistsWithValue(t, testAttributes, prefix+TestsSystemErr, "another huge log"))
+		})
+	}
+}
+
+func TestGetTestAttributes_FailureType(t *testing.T) {
+	cfg := config.NewConfigFromDefaults()
+
+	failed := junit.Test{Name: "testAdd", Status: junit.StatusFailed, Error: junit.Error{Message: "expected 2", Type: "java.lang.AssertionError"}}
+	require.True(t, keyExistsWithValue(t, getTestAttributes(cfg, failed, nil), TestFailureType, "java.lang.AssertionError"))
+
+	// without type, i.e. the go test failures
+	untyped := junit.Test{Name: "TestAdd", Status: junit.StatusFailed, Error: junit.Error{Message: "Failed"}}
+	for _, kv := range getTestAttributes(cfg, untyped, nil) {
+		require.NotEqual(t, attribute.Key(TestFailureType), kv.Key)
+	}
+}
+
+func TestAddFailureEvents(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	test := junit.Test{Name: "testAdd", Status: junit.StatusFailed, Error: formats.Failures{
+		{Message: "expected 2", Type: "AssertionError", Body: "at CalculatorTest.testAdd(CalculatorTest.java:12)"},
+		{Message: "expected 3"},
+	}}
+
+	t.Run("With exception events", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.AttributePrefix = "ci."
+
+		_, span := tracerProvider.Tracer("test").Start(context.Background(), test.Name)
+		addFailureEvents(cfg, span, test)
+		span.End()
+
+		events := recorder.Ended()[0].Events()
+		require.Len(t, events, 4)
+		for i, event := range []sdktrace.Event{events[0], events[2]} {
+			require.Equal(t, TestFailureEvent, event.Name)
+			require.Contains(t, event.Attributes, attribute.Key("ci."+TestFailureOrdinal).Int(i+1))
+		}
+		require.True(t, keyExistsWithValue(t, events[0].Attributes, "ci."+TestFailureMessage, "expected 2"))
+		require.True(t, keyExistsWithValue(t, events[0].Attributes, "ci."+TestFailureType, "AssertionError"))
+		require.True(t, keyExistsWithValue(t, events[2].Attributes, "ci."+TestFailureMessage, "expected 3"))
+
+		// the attributes of the semantic conventions are never prefixed
+		exception := events[1]
+		require.Equal(t, semconv.ExceptionEventName, exception.Name)
+		require.Equal(t, []attribute.KeyValue{
+			semconv.ExceptionTypeKey.String("AssertionError"),
+			semconv.ExceptionMessageKey.String("expected 2"),
+			semconv.ExceptionStacktraceKey.String("at CalculatorTest.testAdd(CalculatorTest.java:12)"),
+		}, exception.Attributes)
+		require.Equal(t, []attribute.KeyValue{semconv.ExceptionMessageKey.String("expected 3")}, events[3].Attributes)
+	})
+
+	t.Run("Without exception events", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.ExceptionEvents = false
+
+		_, span := tracerProvider.Tracer("test").Start(context.Background(), test.Name)
+		addFailureEvents(cfg, span, test)
+		span.End()
+
+		events := recorder.Ended()[1].Events()
+		require.Len(t, events, 2)
+		for _, event := range events {
+			require.Equal(t, TestFailureEvent, event.Name)
+		}
+	})
+}
+
+func TestPrefixAttributes(t *testing.T) {
+	atts := []attribute.KeyValue{
+		semconv.CodeFunctionKey.String("TestFoo"),
+		semconv.HostArchKey.String("amd64"),
+		attribute.Key(VcsRefHeadName).String("main"),
+		attribute.Key(TestStatus).String("passed"),
+		attribute.Key("go.os").String("linux"),
+	}
+
+	t.Run("Without prefix", func(t *testing.T) {
+		require.Equal(t, atts, prefixAttributes("", atts))
+	})
+
+	t.Run("With prefix", func(t *testing.T) {
+		prefixed := prefixAttributes("ci.", atts)
+		require.Len(t, prefixed, len(atts))
+		require.True(t, keyExistsWithValue(t, prefixed, string(semconv.CodeFunctionKey), "TestFoo"))
+		require.True(t, keyExistsWithValue(t, prefixed, string(semconv.HostArchKey), "amd64"))
+		require.True(t, keyExistsWithValue(t, prefixed, VcsRefHeadName, "main"))
+		require.True(t, keyExistsWithValue(t, prefixed, "ci."+TestStatus, "passed"))
+		require.True(t, keyExistsWithValue(t, prefixed, "ci.go.os", "linux"))
+
+		// the attributes may be shared, so they are not rewritten
+		require.True(t, keyExistsWithValue(t, atts, "go.os", "linux"))
+	})
+}
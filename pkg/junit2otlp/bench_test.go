@@ -0,0 +1,132 @@
+package junit2otlp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// perfBudget maximum time to read and convert a report with perfBudgetTests test cases into spans.
+// It's generous on purpose, to catch regressions of an order of magnitude without flaky failures on slow runners
+const (
+	perfBudget      = 10 * time.Second
+	perfBudgetTests = 100_000
+)
+
+// generateReport returns a JUnit report with the given number of suites and test cases per suite,
+// where one in ten test cases fails
+func generateReport(suites int, testsPerSuite int) []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n<testsuites>\n")
+	for s := range suites {
+		fmt.Fprintf(&buf, `<testsuite name="suite-%d" package="github.com/example/suite%d" tests="%d" timestamp="2024-01-02T10:00:00">`+"\n", s, s, testsPerSuite)
+		buf.WriteString(`<properties><property name="go.os" value="linux"/><property name="go.version" value="go1.23"/></properties>` + "\n")
+
+		for t := range testsPerSuite {
+			fmt.Fprintf(&buf, `<testcase name="TestCase%d" classname="github.com/example/suite%d" time="0.%03d">`, t, s, t%1000)
+			if t%10 == 0 {
+				buf.WriteString(`<failure message="expected 1, got 2" type="assertion">suite_test.go:42: expected 1, got 2</failure>`)
+			}
+			buf.WriteString("</testcase>\n")
+		}
+
+		buf.WriteString("<system-out>suite output</system-out>\n</testsuite>\n")
+	}
+	buf.WriteString("</testsuites>\n")
+
+	return buf.Bytes()
+}
+
+// convertReport reads the report and creates its spans, discarding them
+func convertReport(tb testing.TB, cfg *config.Config, data []byte) {
+	tb.Helper()
+
+	stream, err := streamReport(context.Background(), cfg, &bytesReader{data: data})
+	require.NoError(tb, err)
+
+	// no span processors, so the spans are created and discarded, measuring only the tool
+	tracerProvider := sdktrace.NewTracerProvider()
+
+	_, err = createTracesAndSpans(context.Background(), cfg, "bench", Providers{TracerProvider: tracerProvider}, resolveRuntimeAttributes(context.Background(), cfg), nil, stream.suites)
+	require.NoError(tb, err)
+
+	_, err = stream.wait()
+	require.NoError(tb, err)
+}
+
+func benchmarkConfig(tb testing.TB) *config.Config {
+	cfg := config.NewConfigFromDefaults()
+	cfg.RepositoryPath = tb.TempDir()
+
+	return cfg
+}
+
+func BenchmarkReadReport(b *testing.B) {
+	data := generateReport(100, 100)
+	cfg := benchmarkConfig(b)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+
+	for range b.N {
+		_, _, err := readReport(cfg, &bytesReader{data: data})
+		require.NoError(b, err)
+	}
+}
+
+func BenchmarkConvertReport(b *testing.B) {
+	for _, tests := range []int{1_000, 10_000, 100_000} {
+		b.Run(fmt.Sprintf("%d tests", tests), func(b *testing.B) {
+			data := generateReport(tests/100, 100)
+			cfg := benchmarkConfig(b)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for range b.N {
+				convertReport(b, cfg, data)
+			}
+		})
+	}
+}
+
+func BenchmarkGetTestAttributes(b *testing.B) {
+	suites, _, err := readReport(benchmarkConfig(b), &bytesReader{data: generateReport(1, 100)})
+	require.NoError(b, err)
+
+	cfg := benchmarkConfig(b)
+	cfg.AttributePrefix = "ci."
+	runtimeAttributes := resolveRuntimeAttributes(context.Background(), cfg)
+	sharedAttributes := getSharedSuiteAttributes(cfg, getSuiteAttributes(cfg, suites[0], runtimeAttributes))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := range b.N {
+		getTestAttributes(cfg, suites[0].Tests[i%len(suites[0].Tests)], sharedAttributes)
+	}
+}
+
+func TestPerformanceBudget(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the performance budget in short mode")
+	}
+
+	data := generateReport(perfBudgetTests/100, 100)
+	cfg := benchmarkConfig(t)
+
+	start := time.Now()
+	convertReport(t, cfg, data)
+	elapsed := time.Since(start)
+
+	t.Logf("%d test cases converted in %s", perfBudgetTests, elapsed)
+	require.Less(t, elapsed, perfBudget, "converting %d test cases exceeded the performance budget", perfBudgetTests)
+}
@@ -0,0 +1,309 @@
+package junit2otlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// bigqueryAccessTokenEnv environment variable with the OAuth access token sent to BigQuery, i.e. the output of
+	// gcloud auth print-access-token. If not set, the token of the service account is read from the metadata server
+	bigqueryAccessTokenEnv = "GOOGLE_OAUTH_ACCESS_TOKEN"
+
+	// bigqueryInsertSize maximum number of rows sent in an insertAll request, as recommended by BigQuery
+	bigqueryInsertSize = 500
+
+	// defaultBigQueryTimeout the maximum time to wait for a request to BigQuery
+	defaultBigQueryTimeout = 30 * time.Second
+)
+
+var (
+	// bigqueryEndpoint base URL of the BigQuery REST API
+	bigqueryEndpoint = "https://bigquery.googleapis.com/bigquery/v2"
+	// gcpMetadataTokenURL URL of the metadata server returning the token of the default service account, i.e. on GKE
+	gcpMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+)
+
+// bigqueryTableSchema the managed schema of the table, created when the table does not exist. The attributes are a
+// JSON column, so the schema does not change with the properties of the reports
+var bigqueryTableSchema = map[string]interface{}{
+	"fields": bigqueryTableFields,
+}
+
+// bigqueryTableFields the columns of the managed schema, which are added to the existing tables missing them
+var bigqueryTableFields = []map[string]string{
+	{"name": "timestamp", "type": "TIMESTAMP", "mode": "REQUIRED"},
+	{"name": "trace_id", "type": "STRING"},
+	{"name": "suite", "type": "STRING"},
+	{"name": "classname", "type": "STRING"},
+	{"name": "name", "type": "STRING"},
+	{"name": "status", "type": "STRING"},
+	{"name": "message", "type": "STRING"},
+	{"name": "duration_ms", "type": "INTEGER"},
+	{"name": "attributes", "type": "JSON"},
+}
+
+// bigqueryTypeAliases the standard SQL names of the types of the columns, returned for the tables created with DDL,
+// and their legacy names used by the managed schema
+var bigqueryTypeAliases = map[string]string{
+	"INT64": "INTEGER",
+}
+
+// bigqueryTable a table of BigQuery, parsed from project.dataset.table
+type bigqueryTable struct {
+	project string
+	dataset string
+	table   string
+}
+
+func parseBigQueryTable(s string) (bigqueryTable, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return bigqueryTable{}, fmt.Errorf("invalid BigQuery table: %s. It must be project.dataset.table", s)
+	}
+
+	return bigqueryTable{project: parts[0], dataset: parts[1], table: parts[2]}, nil
+}
+
+func (t bigqueryTable) url() string {
+	return fmt.Sprintf("%s/projects/%s/datasets/%s/tables", bigqueryEndpoint, t.project, t.dataset)
+}
+
+// bigqueryClient calls the BigQuery REST API with an OAuth access token
+type bigqueryClient struct {
+	client *http.Client
+	token  string
+}
+
+// newBigQueryClient returns a client with the access token of the environment, or of the service account
+func newBigQueryClient(ctx context.Context) (*bigqueryClient, error) {
+	c := &bigqueryClient{client: &http.Client{Timeout: defaultBigQueryTimeout}}
+
+	c.token = os.Getenv(bigqueryAccessTokenEnv)
+	if c.token != "" {
+		return c, nil
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := c.do(ctx, http.MethodGet, gcpMetadataTokenURL, nil, &token); err != nil {
+		return nil, fmt.Errorf("failed to get the access token from the metadata server, set %s instead: %w", bigqueryAccessTokenEnv, err)
+	}
+
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("the metadata server returned no access token, set %s instead", bigqueryAccessTokenEnv)
+	}
+
+	c.token = token.AccessToken
+
+	return c, nil
+}
+
+// errNotFound returned by the client when the resource does not exist
+var errNotFound = errors.New("not found")
+
+// do sends the request, encoding the body and decoding the response into v, if they are not nil
+func (c *bigqueryClient) do(ctx context.Context, method string, url string, body interface{}, v interface{}) error {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, r)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	} else {
+		req.Header.Set("Metadata-Flavor", "Google")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status code calling %s: %d: %s", url, resp.StatusCode, b)
+	}
+
+	if v == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// ensureTable creates the table with the managed schema, if it does not exist. The existing table gets the columns of
+// the managed schema it's missing, as nullable columns, failing if any of its columns has another type
+func (c *bigqueryClient) ensureTable(ctx context.Context, t bigqueryTable) error {
+	var existing struct {
+		Schema struct {
+			Fields []map[string]interface{} `json:"fields"`
+		} `json:"schema"`
+	}
+	err := c.do(ctx, http.MethodGet, t.url()+"/"+t.table, nil, &existing)
+	if err == nil {
+		return c.patchTable(ctx, t, existing.Schema.Fields)
+	}
+	if !errors.Is(err, errNotFound) {
+		return err
+	}
+
+	table := map[string]interface{}{
+		"tableReference": map[string]string{
+			"projectId": t.project,
+			"datasetId": t.dataset,
+			"tableId":   t.table,
+		},
+		"schema":           bigqueryTableSchema,
+		"timePartitioning": map[string]string{"type": "DAY", "field": "timestamp"},
+	}
+
+	return c.do(ctx, http.MethodPost, t.url(), table, nil)
+}
+
+// patchTable adds the columns of the managed schema missing in the fields of the existing table, which are kept as
+// they are, as BigQuery replaces the whole schema
+func (c *bigqueryClient) patchTable(ctx context.Context, t bigqueryTable, fields []map[string]interface{}) error {
+	types := map[string]string{}
+	for _, field := range fields {
+		name, _ := field["name"].(string)
+		typ, _ := field["type"].(string)
+		if alias, ok := bigqueryTypeAliases[typ]; ok {
+			typ = alias
+		}
+		types[name] = typ
+	}
+
+	missing := 0
+	for _, field := range bigqueryTableFields {
+		typ, ok := types[field["name"]]
+		if !ok {
+			// the columns added to an existing table must be nullable
+			fields = append(fields, map[string]interface{}{"name": field["name"], "type": field["type"]})
+			missing++
+			continue
+		}
+
+		if typ != field["type"] {
+			return fmt.Errorf("the column %s is %s instead of %s", field["name"], typ, field["type"])
+		}
+	}
+
+	if missing == 0 {
+		return nil
+	}
+
+	table := map[string]interface{}{"schema": map[string]interface{}{"fields": fields}}
+
+	return c.do(ctx, http.MethodPatch, t.url()+"/"+t.table, table, nil)
+}
+
+// insertRows streams the rows into the table, returning an error if any row was not inserted. The insert IDs
+// make the retries of a run idempotent
+func (c *bigqueryClient) insertRows(ctx context.Context, t bigqueryTable, rows []testRow) error {
+	for start := 0; start < len(rows); start += bigqueryInsertSize {
+		end := min(start+bigqueryInsertSize, len(rows))
+
+		request := map[string]interface{}{"rows": bigqueryRows(rows[start:end], start)}
+
+		var response struct {
+			InsertErrors []struct {
+				Index  int `json:"index"`
+				Errors []struct {
+					Reason  string `json:"reason"`
+					Message string `json:"message"`
+				} `json:"errors"`
+			} `json:"insertErrors"`
+		}
+		if err := c.do(ctx, http.MethodPost, t.url()+"/"+t.table+"/insertAll", request, &response); err != nil {
+			return err
+		}
+
+		if len(response.InsertErrors) > 0 {
+			first := response.InsertErrors[0]
+			reason := ""
+			if len(first.Errors) > 0 {
+				reason = first.Errors[0].Reason + ": " + first.Errors[0].Message
+			}
+
+			return fmt.Errorf("failed to insert %d rows: %s", len(response.InsertErrors), reason)
+		}
+	}
+
+	return nil
+}
+
+// bigqueryRows returns the rows of the insertAll request, where offset is the position of the first row in the run
+func bigqueryRows(rows []testRow, offset int) []map[string]interface{} {
+	requestRows := make([]map[string]interface{}, 0, len(rows))
+
+	for i, row := range rows {
+		// the JSON columns are sent as strings
+		attributes, _ := json.Marshal(row.Attributes)
+
+		r := map[string]interface{}{
+			"json": map[string]interface{}{
+				"timestamp":   row.Timestamp.Format(time.RFC3339Nano),
+				"trace_id":    row.TraceID,
+				"suite":       row.Suite,
+				"classname":   row.Classname,
+				"name":        row.Name,
+				"status":      row.Status,
+				"message":     row.Message,
+				"duration_ms": row.DurationMs,
+				"attributes":  string(attributes),
+			},
+		}
+		if row.TraceID != "" {
+			r["insertId"] = fmt.Sprintf("%s-%d", row.TraceID, offset+i)
+		}
+
+		requestRows = append(requestRows, r)
+	}
+
+	return requestRows
+}
+
+// writeBigQuery streams a row per test case into the table of BigQuery, creating it with the managed schema
+// if it does not exist
+func writeBigQuery(ctx context.Context, table string, rows []testRow) error {
+	t, err := parseBigQueryTable(table)
+	if err != nil {
+		return err
+	}
+
+	client, err := newBigQueryClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := client.ensureTable(ctx, t); err != nil {
+		return fmt.Errorf("failed to prepare the table %s: %w", table, err)
+	}
+
+	return client.insertRows(ctx, t, rows)
+}
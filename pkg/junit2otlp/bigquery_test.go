@@ -0,0 +1,195 @@
+package junit2otlp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// bigqueryServer a fake of the BigQuery REST API, with a single table, which can exist or not
+type bigqueryServer struct {
+	tableExists bool
+	// schema the schema of the existing table, as returned by BigQuery
+	schema   string
+	created  map[string]interface{}
+	patched  map[string]interface{}
+	inserted []map[string]interface{}
+	auth     string
+	// token the response of the metadata server
+	token string
+}
+
+func (s *bigqueryServer) start(t *testing.T) {
+	const tables = "/projects/my-project/datasets/ci/tables"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+tables+"/tests", func(w http.ResponseWriter, r *http.Request) {
+		if !s.tableExists {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"schema":` + s.schema + `}`))
+	})
+	mux.HandleFunc("PATCH "+tables+"/tests", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&s.patched))
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("POST "+tables, func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&s.created))
+		s.tableExists = true
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("POST "+tables+"/tests/insertAll", func(w http.ResponseWriter, r *http.Request) {
+		s.auth = r.Header.Get("Authorization")
+
+		var request struct {
+			Rows []map[string]interface{} `json:"rows"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		s.inserted = append(s.inserted, request.Rows...)
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("GET /token", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Google", r.Header.Get("Metadata-Flavor"))
+		_, _ = w.Write([]byte(s.token))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	endpoint, tokenURL := bigqueryEndpoint, gcpMetadataTokenURL
+	bigqueryEndpoint, gcpMetadataTokenURL = server.URL, server.URL+"/token"
+	t.Cleanup(func() {
+		bigqueryEndpoint, gcpMetadataTokenURL = endpoint, tokenURL
+	})
+}
+
+// managedSchema returns the schema of a table created with the managed schema, as returned by BigQuery
+func managedSchema(t *testing.T) string {
+	b, err := json.Marshal(bigqueryTableSchema)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func testRows() []testRow {
+	suites := []junit.Suite{
+		{
+			Name: "suite",
+			Tests: []junit.Test{
+				{Name: "passed", Classname: "pkg.Class", Status: junit.StatusPassed, Duration: 1500 * time.Millisecond},
+				{Name: "failed", Classname: "pkg.Class", Status: junit.StatusFailed, Message: "boom"},
+			},
+		},
+	}
+
+	return newTestRows(config.NewConfigFromDefaults(), []attribute.KeyValue{attribute.String("host.arch", "amd64")}, suites, trace.TraceID{0x01}, time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC))
+}
+
+func TestNewTestRows(t *testing.T) {
+	rows := testRows()
+	require.Len(t, rows, 2)
+
+	require.Equal(t, "suite", rows[0].Suite)
+	require.Equal(t, "passed", rows[0].Name)
+	require.Equal(t, int64(1500), rows[0].DurationMs)
+	require.Equal(t, trace.TraceID{0x01}.String(), rows[0].TraceID)
+	require.Equal(t, "amd64", rows[0].Attributes["host.arch"])
+	require.Equal(t, "boom", rows[1].Message)
+}
+
+func TestWriteBigQuery(t *testing.T) {
+	t.Run("The table is created with the managed schema", func(t *testing.T) {
+		t.Setenv(bigqueryAccessTokenEnv, "secret")
+
+		server := &bigqueryServer{}
+		server.start(t)
+
+		err := writeBigQuery(context.Background(), "my-project.ci.tests", testRows())
+		require.NoError(t, err)
+
+		require.NotNil(t, server.created["schema"])
+		require.Equal(t, "Bearer secret", server.auth)
+		require.Len(t, server.inserted, 2)
+
+		row := server.inserted[1]
+		require.Equal(t, trace.TraceID{0x01}.String()+"-1", row["insertId"])
+
+		columns := row["json"].(map[string]interface{})
+		require.Equal(t, "failed", columns["name"])
+		require.Equal(t, "2024-05-06T10:00:00Z", columns["timestamp"])
+		require.Contains(t, columns["attributes"], `"host.arch":"amd64"`)
+	})
+
+	t.Run("The token is read from the metadata server", func(t *testing.T) {
+		t.Setenv(bigqueryAccessTokenEnv, "")
+
+		server := &bigqueryServer{tableExists: true, schema: managedSchema(t), token: `{"access_token":"from-metadata"}`}
+		server.start(t)
+
+		err := writeBigQuery(context.Background(), "my-project.ci.tests", testRows())
+		require.NoError(t, err)
+
+		require.Nil(t, server.created)
+		require.Nil(t, server.patched)
+		require.Equal(t, "Bearer from-metadata", server.auth)
+	})
+
+	t.Run("The metadata server returns no token", func(t *testing.T) {
+		t.Setenv(bigqueryAccessTokenEnv, "")
+
+		server := &bigqueryServer{tableExists: true, schema: managedSchema(t), token: `{}`}
+		server.start(t)
+
+		err := writeBigQuery(context.Background(), "my-project.ci.tests", testRows())
+		require.ErrorContains(t, err, "the metadata server returned no access token, set GOOGLE_OAUTH_ACCESS_TOKEN instead")
+		require.Empty(t, server.inserted)
+	})
+
+	t.Run("The missing columns are added to the existing table", func(t *testing.T) {
+		t.Setenv(bigqueryAccessTokenEnv, "secret")
+
+		server := &bigqueryServer{tableExists: true, schema: `{"fields":[
+			{"name":"timestamp","type":"TIMESTAMP","mode":"REQUIRED"},
+			{"name":"name","type":"STRING","description":"the name of the test"},
+			{"name":"duration_ms","type":"INT64"},
+			{"name":"team","type":"STRING"}
+		]}`}
+		server.start(t)
+
+		err := writeBigQuery(context.Background(), "my-project.ci.tests", testRows())
+		require.NoError(t, err)
+		require.Len(t, server.inserted, 2)
+
+		fields := server.patched["schema"].(map[string]interface{})["fields"].([]interface{})
+		require.Len(t, fields, len(bigqueryTableFields)+1)
+		require.Equal(t, map[string]interface{}{"name": "name", "type": "STRING", "description": "the name of the test"}, fields[1])
+		require.Equal(t, map[string]interface{}{"name": "team", "type": "STRING"}, fields[3])
+		require.Equal(t, map[string]interface{}{"name": "attributes", "type": "JSON"}, fields[len(fields)-1])
+	})
+
+	t.Run("A column of the existing table has another type", func(t *testing.T) {
+		t.Setenv(bigqueryAccessTokenEnv, "secret")
+
+		server := &bigqueryServer{tableExists: true, schema: `{"fields":[{"name":"duration_ms","type":"FLOAT"}]}`}
+		server.start(t)
+
+		err := writeBigQuery(context.Background(), "my-project.ci.tests", testRows())
+		require.EqualError(t, err, "failed to prepare the table my-project.ci.tests: the column duration_ms is FLOAT instead of INTEGER")
+		require.Nil(t, server.patched)
+		require.Empty(t, server.inserted)
+	})
+
+	t.Run("Invalid table", func(t *testing.T) {
+		err := writeBigQuery(context.Background(), "tests", testRows())
+		require.ErrorContains(t, err, "invalid BigQuery table: tests")
+	})
+}
@@ -0,0 +1,180 @@
+package junit2otlp
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// buildkiteAnalyticsTokenEnv environment variable with the token of the Test Analytics suite
+	buildkiteAnalyticsTokenEnv = "BUILDKITE_ANALYTICS_TOKEN"
+
+	// buildkiteUploadSize maximum number of test cases in an upload, as the API allows
+	buildkiteUploadSize = 5000
+
+	// defaultBuildkiteTimeout the maximum time to wait for an upload
+	defaultBuildkiteTimeout = 30 * time.Second
+)
+
+// buildkiteAnalyticsURL URL of the uploads endpoint of the Buildkite Test Analytics API
+var buildkiteAnalyticsURL = "https://analytics-api.buildkite.com/v1/uploads"
+
+// buildkiteUpload the body of an upload of the JSON format of Test Analytics
+type buildkiteUpload struct {
+	Format string            `json:"format"`
+	RunEnv map[string]string `json:"run_env"`
+	Data   []buildkiteTest   `json:"data"`
+}
+
+type buildkiteTest struct {
+	ID            string           `json:"id"`
+	Scope         string           `json:"scope"`
+	Name          string           `json:"name"`
+	Identifier    string           `json:"identifier"`
+	Result        string           `json:"result"`
+	FailureReason string           `json:"failure_reason,omitempty"`
+	History       buildkiteHistory `json:"history"`
+}
+
+type buildkiteHistory struct {
+	Section  string  `json:"section"`
+	StartAt  float64 `json:"start_at"`
+	EndAt    float64 `json:"end_at"`
+	Duration float64 `json:"duration"`
+}
+
+// buildkiteRunEnv returns the environment of the run: the build of Buildkite when running on it, or a generic
+// one keyed by the trace, so the uploads of the same run are grouped
+func buildkiteRunEnv(traceID trace.TraceID) map[string]string {
+	if os.Getenv("BUILDKITE_BUILD_ID") != "" {
+		return map[string]string{
+			"CI":         "buildkite",
+			"key":        os.Getenv("BUILDKITE_BUILD_ID"),
+			"url":        os.Getenv("BUILDKITE_BUILD_URL"),
+			"branch":     os.Getenv("BUILDKITE_BRANCH"),
+			"commit_sha": os.Getenv("BUILDKITE_COMMIT"),
+			"number":     os.Getenv("BUILDKITE_BUILD_NUMBER"),
+			"job_id":     os.Getenv("BUILDKITE_JOB_ID"),
+			"message":    os.Getenv("BUILDKITE_MESSAGE"),
+		}
+	}
+
+	key := newUUID()
+	if traceID.IsValid() {
+		key = traceID.String()
+	}
+
+	return map[string]string{
+		"CI":  "generic",
+		"key": key,
+	}
+}
+
+// buildkiteTests returns the test cases of the suites in the format of Test Analytics. The tests of a suite are
+// laid out one after the other, from the start of the run, as the reports have no start time per test case
+func buildkiteTests(suites []junit.Suite) []buildkiteTest {
+	tests := []buildkiteTest{}
+
+	var offset time.Duration
+	for _, suite := range suites {
+		for _, test := range suite.Tests {
+			scope := test.Classname
+			if scope == "" {
+				scope = suite.Name
+			}
+
+			result := "passed"
+			failureReason := ""
+			switch test.Status {
+			case junit.StatusFailed, junit.StatusError:
+				result = "failed"
+				failureReason = test.Message
+			case junit.StatusSkipped:
+				result = "skipped"
+			}
+
+			tests = append(tests, buildkiteTest{
+				ID:            newUUID(),
+				Scope:         scope,
+				Name:          test.Name,
+				Identifier:    scope + " " + test.Name,
+				Result:        result,
+				FailureReason: failureReason,
+				History: buildkiteHistory{
+					Section:  "top",
+					StartAt:  offset.Seconds(),
+					EndAt:    (offset + test.Duration).Seconds(),
+					Duration: test.Duration.Seconds(),
+				},
+			})
+
+			offset += test.Duration
+		}
+	}
+
+	return tests
+}
+
+// uploadBuildkiteAnalytics uploads the test cases of the suites to Buildkite Test Analytics, in batches of the
+// maximum size of an upload, with the token of the BUILDKITE_ANALYTICS_TOKEN environment variable
+func uploadBuildkiteAnalytics(ctx context.Context, suites []junit.Suite, traceID trace.TraceID) error {
+	token := os.Getenv(buildkiteAnalyticsTokenEnv)
+	if token == "" {
+		return fmt.Errorf("the %s environment variable is required to upload to Buildkite Test Analytics", buildkiteAnalyticsTokenEnv)
+	}
+
+	runEnv := buildkiteRunEnv(traceID)
+	tests := buildkiteTests(suites)
+	client := &http.Client{Timeout: defaultBuildkiteTimeout}
+
+	for start := 0; start < len(tests); start += buildkiteUploadSize {
+		end := min(start+buildkiteUploadSize, len(tests))
+
+		body, err := json.Marshal(buildkiteUpload{Format: "json", RunEnv: runEnv, Data: tests[start:end]})
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, buildkiteAnalyticsURL, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("Token token=%q", token))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to upload to Buildkite Test Analytics: %w", err)
+		}
+
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+			return fmt.Errorf("unexpected status code uploading to Buildkite Test Analytics: %d: %s", resp.StatusCode, b)
+		}
+	}
+
+	return nil
+}
+
+// newUUID returns a random UUID, version 4
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,106 @@
+package junit2otlp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestUploadBuildkiteAnalytics(t *testing.T) {
+	suites := []junit.Suite{
+		{
+			Name: "suite",
+			Tests: []junit.Test{
+				{Name: "passed", Classname: "pkg.Class", Status: junit.StatusPassed, Duration: time.Second},
+				{Name: "failed", Status: junit.StatusFailed, Message: "boom", Duration: 500 * time.Millisecond},
+				{Name: "skipped", Classname: "pkg.Class", Status: junit.StatusSkipped},
+			},
+		},
+	}
+
+	// uploadServer returns a server for the uploads endpoint, recording the upload and its authorization
+	uploadServer := func(t *testing.T, status int, upload *buildkiteUpload, auth *string) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*auth = r.Header.Get("Authorization")
+			require.NoError(t, json.NewDecoder(r.Body).Decode(upload))
+			w.WriteHeader(status)
+		}))
+		t.Cleanup(server.Close)
+
+		url := buildkiteAnalyticsURL
+		buildkiteAnalyticsURL = server.URL
+		t.Cleanup(func() { buildkiteAnalyticsURL = url })
+	}
+
+	t.Run("Uploads the test cases", func(t *testing.T) {
+		t.Setenv(buildkiteAnalyticsTokenEnv, "secret")
+		t.Setenv("BUILDKITE_BUILD_ID", "")
+
+		var upload buildkiteUpload
+		var auth string
+		uploadServer(t, http.StatusAccepted, &upload, &auth)
+
+		traceID := trace.TraceID{0x01}
+		require.NoError(t, uploadBuildkiteAnalytics(context.Background(), suites, traceID))
+
+		require.Equal(t, `Token token="secret"`, auth)
+		require.Equal(t, "json", upload.Format)
+		require.Equal(t, map[string]string{"CI": "generic", "key": traceID.String()}, upload.RunEnv)
+		require.Len(t, upload.Data, 3)
+
+		require.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), upload.Data[0].ID)
+		require.Equal(t, "pkg.Class", upload.Data[0].Scope)
+		require.Equal(t, "passed", upload.Data[0].Result)
+		require.Equal(t, 1.0, upload.Data[0].History.Duration)
+
+		// the scope falls back to the suite
+		require.Equal(t, "suite", upload.Data[1].Scope)
+		require.Equal(t, "failed", upload.Data[1].Result)
+		require.Equal(t, "boom", upload.Data[1].FailureReason)
+		require.Equal(t, 1.0, upload.Data[1].History.StartAt)
+		require.Equal(t, 1.5, upload.Data[1].History.EndAt)
+
+		require.Equal(t, "skipped", upload.Data[2].Result)
+	})
+
+	t.Run("Buildkite run environment", func(t *testing.T) {
+		t.Setenv(buildkiteAnalyticsTokenEnv, "secret")
+		t.Setenv("BUILDKITE_BUILD_ID", "build-id")
+		t.Setenv("BUILDKITE_BRANCH", "main")
+
+		var upload buildkiteUpload
+		var auth string
+		uploadServer(t, http.StatusOK, &upload, &auth)
+
+		require.NoError(t, uploadBuildkiteAnalytics(context.Background(), suites, trace.TraceID{}))
+		require.Equal(t, "buildkite", upload.RunEnv["CI"])
+		require.Equal(t, "build-id", upload.RunEnv["key"])
+		require.Equal(t, "main", upload.RunEnv["branch"])
+	})
+
+	t.Run("Rejected upload", func(t *testing.T) {
+		t.Setenv(buildkiteAnalyticsTokenEnv, "invalid")
+
+		var upload buildkiteUpload
+		var auth string
+		uploadServer(t, http.StatusUnauthorized, &upload, &auth)
+
+		err := uploadBuildkiteAnalytics(context.Background(), suites, trace.TraceID{})
+		require.ErrorContains(t, err, "unexpected status code uploading to Buildkite Test Analytics: 401")
+	})
+
+	t.Run("Missing token", func(t *testing.T) {
+		t.Setenv(buildkiteAnalyticsTokenEnv, "")
+
+		err := uploadBuildkiteAnalytics(context.Background(), suites, trace.TraceID{})
+		require.ErrorContains(t, err, "the BUILDKITE_ANALYTICS_TOKEN environment variable is required")
+	})
+}
@@ -0,0 +1,27 @@
+package junit2otlp
+
+import (
+	"github.com/mdelapenya/junit2otlp/internal/ci"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// getCICDAttributes returns the URL of the CI job running the tool, from the first CI provider detected in the
+// environment, so the spans link back to its logs. It returns no attributes outside of a CI provider
+func getCICDAttributes() []attribute.KeyValue {
+	if u := ci.RunURL(); u != "" {
+		return []attribute.KeyValue{attribute.Key(CicdPipelineRunURLFull).String(u)}
+	}
+
+	return []attribute.KeyValue{}
+}
+
+// getCIAttributes returns the ci.* attributes of the build of the CI provider running the tool: the pipeline, the
+// job, the build number and URL, and the OS of the runner. It returns no attributes outside of a CI provider
+func getCIAttributes() []attribute.KeyValue {
+	build := ci.Detect()
+	if build == nil {
+		return []attribute.KeyValue{}
+	}
+
+	return build.Attributes()
+}
@@ -0,0 +1,75 @@
+package junit2otlp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestGetCICDAttributes(t *testing.T) {
+	ciEnv := []string{
+		"GITHUB_REPOSITORY", "GITHUB_RUN_ID", "GITHUB_RUN_ATTEMPT", "GITHUB_SERVER_URL",
+		"CI_JOB_URL",
+		"JENKINS_URL", "BUILD_URL",
+		"BUILDKITE_BUILD_URL", "BUILDKITE_JOB_ID",
+		"CIRCLE_BUILD_URL",
+		"SYSTEM_COLLECTIONURI", "SYSTEM_TEAMPROJECT", "BUILD_BUILDID", "SYSTEM_JOBID",
+	}
+
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected string
+	}{
+		{
+			name:     "Github Actions",
+			env:      map[string]string{"GITHUB_REPOSITORY": "octocat/hello-world", "GITHUB_RUN_ID": "123", "GITHUB_RUN_ATTEMPT": "2", "GITHUB_SERVER_URL": "https://github.com"},
+			expected: "https://github.com/octocat/hello-world/actions/runs/123/attempts/2",
+		},
+		{
+			name:     "Gitlab",
+			env:      map[string]string{"CI_JOB_URL": "https://gitlab.com/group/project/-/jobs/456"},
+			expected: "https://gitlab.com/group/project/-/jobs/456",
+		},
+		{
+			name:     "Jenkins",
+			env:      map[string]string{"JENKINS_URL": "https://jenkins.example.com/", "BUILD_URL": "https://jenkins.example.com/job/project/7/"},
+			expected: "https://jenkins.example.com/job/project/7/",
+		},
+		{
+			name:     "Buildkite",
+			env:      map[string]string{"BUILDKITE_BUILD_URL": "https://buildkite.com/acme/project/builds/8", "BUILDKITE_JOB_ID": "job-1"},
+			expected: "https://buildkite.com/acme/project/builds/8#job-1",
+		},
+		{
+			name:     "CircleCI",
+			env:      map[string]string{"CIRCLE_BUILD_URL": "https://circleci.com/gh/acme/project/9"},
+			expected: "https://circleci.com/gh/acme/project/9",
+		},
+		{
+			name:     "Azure Pipelines",
+			env:      map[string]string{"SYSTEM_COLLECTIONURI": "https://dev.azure.com/acme/", "SYSTEM_TEAMPROJECT": "project", "BUILD_BUILDID": "10", "SYSTEM_JOBID": "job-2"},
+			expected: "https://dev.azure.com/acme/project/_build/results?buildId=10&view=logs&j=job-2",
+		},
+		{
+			name: "Outside of a CI provider",
+			env:  map[string]string{"BUILD_URL": "https://jenkins.example.com/job/project/7/"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range ciEnv {
+				t.Setenv(key, tt.env[key])
+			}
+
+			if tt.expected == "" {
+				require.Empty(t, getCICDAttributes())
+				return
+			}
+
+			require.Equal(t, []attribute.KeyValue{attribute.Key(CicdPipelineRunURLFull).String(tt.expected)}, getCICDAttributes())
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package junit2otlp
+
+import (
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+)
+
+// Config the configuration of a conversion, the same one of the command line, whose flags, yaml keys and
+// JUNIT2OTLP_* environment variables are described in the README. It's defined in the config package
+type Config = config.Config
+
+// ExporterConfig the settings of the OTLP exporters of the configuration
+type ExporterConfig = config.ExporterConfig
+
+// PluginConfig an external executable adding attributes to each suite
+type PluginConfig = config.PluginConfig
+
+// NewConfig returns the configuration with the default values of the command line
+func NewConfig() *Config {
+	return config.NewConfigFromDefaults()
+}
+
+// NewConfigFromArgs returns the configuration from the command line arguments, the configuration file
+// set in them, and the JUNIT2OTLP_* environment variables. The invalid arguments are returned as errors
+func NewConfigFromArgs(args []string) (*Config, error) {
+	return config.NewConfigFromArgs(args)
+}
+
+// NewConvertConfigFromArgs returns the configuration of the convert command from the command line arguments, where
+// -output is the path of the converted report
+func NewConvertConfigFromArgs(args []string) (*Config, error) {
+	return config.NewConvertConfigFromArgs(args)
+}
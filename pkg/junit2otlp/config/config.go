@@ -0,0 +1,771 @@
+// Package config reads the configuration of junit2otlp, merging the defaults, the YAML file, validated against
+// its JSON Schema, the JUNIT2OTLP_* environment variables and the command line flags
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultElasticsearchIndex = "junit2otlp-tests"
+	defaultFormat             = "auto"
+	defaultInput              = "-"
+	defaultLogFormat          = "text"
+	defaultLogLevel           = "info"
+	defaultMaxBatchSize       = 10
+	defaultMaxOutputSize      = 32 * 1024
+	defaultSpanProcessor      = "batch"
+	defaultTimezone           = "Local"
+	defaultTraceName          = "junit2otlp"
+	defaultWatchInterval      = 2 * time.Second
+	defaultScanPattern        = "TEST-*.xml"
+	defaultWatchPattern       = "*.xml"
+	defaultWatchSentinel      = "done"
+
+	propertiesAllowAll = "all"
+
+	// envVarPrefix prefix of the environment variables overriding the configuration
+	envVarPrefix = "JUNIT2OTLP_"
+
+	// ScmAttributesSchemaLegacy is the default schema for the SCM attributes
+	ScmAttributesSchemaLegacy = "legacy"
+
+	// AttributeSchemaLegacy is the default schema for the attributes of the suites and test cases
+	AttributeSchemaLegacy = "legacy"
+)
+
+// flagAliases names used by other OpenTelemetry tools, such as otel-cli or telemetrygen, accepted
+// as aliases for the flags of the tool
+var flagAliases = map[string]string{
+	"endpoint": "otlp-endpoint",
+	"insecure": "otlp-insecure",
+	"protocol": "otlp-protocol",
+	"service":  "service-name",
+}
+
+// Config represents the configuration of the tool, which can be read from the command line flags
+// or from a YAML file
+type Config struct {
+	// AdditionalAttributes attributes to be added to the jUnit report
+	AdditionalAttributes map[string]string `yaml:"additional-attributes"`
+	// AllureResults path of the directory where an Allure result is written per test case. If empty, they are not written
+	AllureResults string `yaml:"allure-results"`
+	// AnomalyThreshold number of standard deviations above the mean duration of the suite, or of the test case in the
+	// history store, from which a test case is flagged as an anomaly. If zero, the test cases are not flagged
+	AnomalyThreshold float64 `yaml:"anomaly-threshold"`
+	// AssumeTimezone IANA timezone of the timestamps in the report without zone information, i.e. Europe/Madrid
+	AssumeTimezone string `yaml:"assume-timezone"`
+	// AttributePrefix prefix for every attribute not defined by the OpenTelemetry semantic conventions
+	AttributePrefix string `yaml:"attribute-prefix"`
+	// AttributeSchema schema for the attributes of the suites and test cases: legacy, or otel to add the test.* ones
+	AttributeSchema string `yaml:"attribute-schema"`
+	// BaggageResourceAttributes adds the members of the W3C baggage of the BAGGAGE environment variable as resource
+	// attributes, named after their keys
+	BaggageResourceAttributes bool `yaml:"baggage-resource-attributes"`
+	// BatchSize maximum export batch size allowed when creating a BatchSpanProcessor
+	BatchSize int `yaml:"batch-size"`
+	// BatchTimeout maximum delay before the BatchSpanProcessor exports a batch, even if it's not full. If zero, the SDK default
+	BatchTimeout time.Duration `yaml:"batch-timeout"`
+	// BigQueryTable table of BigQuery, as project.dataset.table, where a row per test case is streamed. It's created
+	// with the managed schema if it does not exist, or gets the columns it's missing. If empty, the test cases are not streamed
+	BigQueryTable string `yaml:"bigquery-table"`
+	// BuildkiteAnalytics uploads the test cases to Buildkite Test Analytics too, with the token of BUILDKITE_ANALYTICS_TOKEN
+	BuildkiteAnalytics bool `yaml:"buildkite-analytics"`
+	// CoverageFile path of the Cobertura, JaCoCo or LCOV coverage report, whose line coverage is added to the suites
+	// of the packages it covers. If empty, the coverage is not added
+	CoverageFile string `yaml:"coverage-file"`
+	// ElasticsearchIndex index of Elasticsearch or OpenSearch where the test cases are indexed
+	ElasticsearchIndex string `yaml:"elasticsearch-index"`
+	// ElasticsearchURL URL of Elasticsearch or OpenSearch, where a document per test case is indexed with the bulk API.
+	// If empty, the test cases are not indexed
+	ElasticsearchURL string `yaml:"elasticsearch-url"`
+	// ExceptionEvents records an exception event, with the attributes of the semantic conventions, per failure of the
+	// failed or errored test cases
+	ExceptionEvents bool `yaml:"exception-events"`
+	// ExportFile path of the file where a row per test case is written, with all the attributes, as CSV or Parquet
+	// depending on its extension. If empty, it's not written
+	ExportFile string `yaml:"export-file"`
+	// ExportSpoolDir directory where the spans failing to be exported are written, to be resent with the flush command
+	ExportSpoolDir string `yaml:"export-spool-dir"`
+	// Exporter settings for the OTLP exporters. If empty, the OTEL_EXPORTER_OTLP_* environment variables are used
+	Exporter ExporterConfig `yaml:"exporter"`
+	// FailOnError return an error when the report contains more failed or errored tests than the threshold
+	FailOnError bool `yaml:"fail-on-error"`
+	// FailThreshold maximum number of failed or errored tests allowed when FailOnError is set
+	FailThreshold int `yaml:"fail-threshold"`
+	// Format format of the reports: auto, junit, testng, nunit, trx, gotest, jest, mocha, ctest or tap. The auto format
+	// detects the format of each report from its content. With auto, the .trx files are read as trx
+	Format string `yaml:"format"`
+	// GithubAnnotations prints a Github Actions error annotation for each failed or errored test
+	GithubAnnotations bool `yaml:"github-annotations"`
+	// GithubChecks creates a Github check run for the commit, with the summary of the run, a link to the trace and a
+	// failure annotation for each failed test, with the token of GITHUB_TOKEN
+	GithubChecks bool `yaml:"github-checks"`
+	// GithubOutput sets the trace-id and traceparent outputs of the Github Actions step, with the trace of the run
+	GithubOutput bool `yaml:"github-output"`
+	// GroupByClass adds a span per classname between each suite and its test cases
+	GroupByClass bool `yaml:"group-by-class"`
+	// HistogramBuckets explicit bucket boundaries, in milliseconds, of the histograms of the durations of the suites
+	// and the test cases. If empty, the default boundaries of the OpenTelemetry SDK are used
+	HistogramBuckets []float64 `yaml:"histogram-buckets"`
+	// HistoryDB path of the SQLite database where the outcomes and the durations of the test cases of each run are
+	// recorded. If empty, the runs are not recorded
+	HistoryDB string `yaml:"history-db"`
+	// Input source of the jUnit reports: '-' for the standard input, 'tar:-' and 'tar:<path>' for a tar archive, the path to a
+	// report, or a glob pattern of the paths of the reports
+	Input string `yaml:"input"`
+	// Inputs paths or glob patterns of more reports, read in the same run as the input, i.e. the positional arguments
+	// of the command line. When set, the standard input is not read
+	Inputs []string `yaml:"inputs"`
+	// LogFile path of the file where the log records are appended. If empty, they are written to stderr
+	LogFile string `yaml:"log-file"`
+	// LogFormat format of the log records written by the tool: text or json
+	LogFormat string `yaml:"log-format"`
+	// LogLevel minimum level of the log records written by the tool: debug, info, warn or error
+	LogLevel string `yaml:"log-level"`
+	// LogsSkipSending skips the OTLP log records of the oversized console outputs and of the failures, so the
+	// outputs are always span attributes
+	LogsSkipSending bool `yaml:"logs-skip-sending"`
+	// MatrixPattern pattern of the names of the reports of a CI matrix build, where each {dimension} captures the value
+	// of a dimension of their matrix cell, aggregating them into one run. If empty, the reports are not aggregated
+	MatrixPattern string `yaml:"matrix-pattern"`
+	// MaxAttributeLength maximum length in bytes of the string attributes of the spans. The longer ones are truncated,
+	// ending with a truncation marker. If zero, there is no limit
+	MaxAttributeLength int `yaml:"max-attribute-length"`
+	// MaxInputSize maximum size in MiB of each report. The larger reports fail with an error, instead of being
+	// truncated. If zero, there is no limit
+	MaxInputSize int `yaml:"max-input-size"`
+	// MaxOutputSize maximum size in bytes of the console output of a suite or a test case sent as a span attribute.
+	// The bigger ones are sent as log records, split in chunks of this size. If zero, they are always span attributes
+	MaxOutputSize int `yaml:"max-output-size"`
+	// MaxQueueSize maximum number of spans buffered by the BatchSpanProcessor before dropping them. If zero, the SDK default
+	MaxQueueSize int `yaml:"max-queue-size"`
+	// MaxSpans maximum number of test spans to be created. If zero, there is no limit
+	MaxSpans int `yaml:"max-spans"`
+	// MemoryLimit soft limit in MiB of the memory used by the tool. Under memory pressure, the suites retained for the
+	// outputs processed after the spans are created are spilled to a temporary file. If zero, there is no limit
+	MemoryLimit int `yaml:"memory-limit"`
+	// MergeSuites merges the suites with the same name of all the reports into one suite, with a child span per
+	// report, i.e. the shards of a CI job
+	MergeSuites bool `yaml:"merge-suites"`
+	// Output path of the file where the convert command writes the report. If empty or '-', it's written to stdout
+	Output string `yaml:"output"`
+	// OutputFormat format of the summary of the run printed after the export: text or json. It's set with the -output
+	// flag of the commands exporting the reports
+	OutputFormat string `yaml:"output-format"`
+	// Parallelism maximum number of reports parsed at the same time. If zero, the number of CPUs
+	Parallelism int `yaml:"parallelism"`
+	// Plugins external executables adding attributes to each suite. They receive the suite as JSON in their
+	// standard input, and write a JSON object with the attributes to their standard output
+	Plugins []PluginConfig `yaml:"plugins"`
+	// PrintAttributes prints the resolved attributes as JSON to stdout, without exporting anything
+	PrintAttributes bool `yaml:"print-attributes"`
+	// PropertiesAllowed properties to be allowed in the jUnit report. If empty, all properties are allowed
+	PropertiesAllowed []string `yaml:"properties-allowed"`
+	// PropertiesDenied properties to be excluded from the jUnit report. It takes precedence over PropertiesAllowed
+	PropertiesDenied []string `yaml:"properties-denied"`
+	// RepositoryPath path to the SCM repository to be read
+	RepositoryPath string `yaml:"repository-path"`
+	// ResourceAttributes attributes added to the OpenTelemetry resource, over the ones of OTEL_RESOURCE_ATTRIBUTES
+	ResourceAttributes map[string]string `yaml:"resource-attributes"`
+	// ScanDir directory walked recursively for the reports whose name matches the scan pattern, read in the same run,
+	// i.e. the root of a multi-module build. If empty, it's not walked
+	ScanDir string `yaml:"scan-dir"`
+	// ScanPattern glob pattern of the names of the reports in the scanned directory
+	ScanPattern string `yaml:"scan-pattern"`
+	// ScmAPIEnrichment call the API of the SCM provider to enrich the SCM attributes
+	ScmAPIEnrichment bool `yaml:"scm-api-enrichment"`
+	// ScmAttributesSchema schema for the SCM attributes: legacy, vcs or both
+	ScmAttributesSchema string `yaml:"scm-attributes-schema"`
+	// SelfTelemetry emits spans and metrics about the conversion process itself, under a separate instrumentation scope
+	SelfTelemetry bool `yaml:"self-telemetry"`
+	// ServiceMapping service names of the suites, by the prefix of their package, or of their name for the reports
+	// without packages. The suites not matching any prefix belong to the service of the run, unless ServicePerSuite
+	ServiceMapping map[string]string `yaml:"service-mapping"`
+	// ServiceName OpenTelemetry Service Name to be used when sending traces and metrics
+	ServiceName string `yaml:"service-name"`
+	// ServicePerSuite derives the service name of each suite from its package, or its name, if it's not mapped
+	ServicePerSuite bool `yaml:"service-per-suite"`
+	// ServiceVersion OpenTelemetry Service Version to be used when sending traces and metrics
+	ServiceVersion string `yaml:"service-version"`
+	// SkipRootSpan omits the root span, creating the suites as top-level spans, or children of the TRACEPARENT
+	SkipRootSpan bool `yaml:"skip-root-span"`
+	// SkipSystemOutput drops the system-out and system-err of the suites and the test cases from the spans, and from
+	// the log records
+	SkipSystemOutput bool `yaml:"skip-system-output"`
+	// SpanProcessor span processor used to export the spans: batch or simple
+	SpanProcessor string `yaml:"span-processor"`
+	// StrictParse fails when any test suite or test case in the report cannot be parsed, instead of skipping it
+	StrictParse bool `yaml:"strict-parse"`
+	// Summary prints the summary of the run to stdout after the conversion, in the format of the output: a table with
+	// the results of each suite by default
+	Summary bool `yaml:"summary"`
+	// SummaryJSON path of the file where the summary of the run is written as JSON, or '-' for stdout. If empty, it's
+	// not written
+	SummaryJSON string `yaml:"summary-json"`
+	// SummaryMarkdown path of the file where the summary of the run is appended as Markdown. If empty, it's not written
+	SummaryMarkdown string `yaml:"summary-markdown"`
+	// TimestampLayouts Go layouts of the timestamps in the report, tried before the built-in ones
+	TimestampLayouts []string `yaml:"timestamp-layouts"`
+	// TraceName OpenTelemetry Trace Name to be used when sending traces and metrics
+	TraceName string `yaml:"trace-name"`
+	// TraceURLTemplate URL of the trace in the tracing UI, where {traceId} is replaced by the ID of the trace
+	TraceURLTemplate string `yaml:"trace-url-template"`
+	// TraceparentOut path of the file where the W3C traceparent of the run is written, so the next steps of the
+	// pipeline continue its trace. If empty, it's not written
+	TraceparentOut string `yaml:"traceparent-out"`
+	// WatchDir directory watched for reports, converting each one as it appears, until the sentinel file is written.
+	// If empty, the input is converted once
+	WatchDir string `yaml:"watch-dir"`
+	// WatchInterval interval between the scans of the watched directory
+	WatchInterval time.Duration `yaml:"watch-interval"`
+	// WatchPattern glob pattern of the names of the reports in the watched directory
+	WatchPattern string `yaml:"watch-pattern"`
+	// WatchSentinel name of the file which, once written in the watched directory, stops the watch
+	WatchSentinel string `yaml:"watch-sentinel"`
+
+	// explicit names of the settings explicitly set with a flag or an environment variable
+	explicit map[string]bool
+}
+
+// ExporterConfig represents the settings for the OTLP exporters
+type ExporterConfig struct {
+	// Endpoint URL of the OTLP endpoint, i.e. http://localhost:4317
+	Endpoint string `yaml:"endpoint"`
+	// File path of the file where the file exporter writes the spans as OTLP JSON
+	File string `yaml:"file"`
+	// Headers headers to be sent with each export request
+	Headers map[string]string `yaml:"headers"`
+	// Insecure disables client transport security for the exporters
+	Insecure bool `yaml:"insecure"`
+	// Protocol protocol of the exporters: grpc or http/protobuf. If empty, it's read from OTEL_EXPORTER_OTLP_PROTOCOL
+	Protocol string `yaml:"protocol"`
+	// Type exporter of all the signals: otlp, stdout, file or none. If empty, each signal uses the one of its
+	// OTEL_*_EXPORTER environment variable
+	Type string `yaml:"type"`
+}
+
+// PluginConfig represents an external executable adding attributes to each suite
+type PluginConfig struct {
+	// Name of the plugin, used in the logs. If empty, the name of the executable
+	Name string `yaml:"name"`
+	// Command executable and arguments of the plugin, i.e. [wasmtime, run, plugin.wasm] for a WASI module
+	Command []string `yaml:"command"`
+	// Timeout maximum time to wait for the plugin for each suite. If zero, 10 seconds
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// NewConfigFromDefaults returns the configuration with the default values
+func NewConfigFromDefaults() *Config {
+	return &Config{
+		AdditionalAttributes: map[string]string{},
+		AssumeTimezone:       defaultTimezone,
+		AttributeSchema:      AttributeSchemaLegacy,
+		BatchSize:            defaultMaxBatchSize,
+		ElasticsearchIndex:   defaultElasticsearchIndex,
+		ExceptionEvents:      true,
+		Format:               defaultFormat,
+		Input:                defaultInput,
+		LogFormat:            defaultLogFormat,
+		LogLevel:             defaultLogLevel,
+		MaxOutputSize:        defaultMaxOutputSize,
+		PropertiesAllowed:    []string{},
+		PropertiesDenied:     []string{},
+		RepositoryPath:       getDefaultwd(),
+		ScanPattern:          defaultScanPattern,
+		ScmAttributesSchema:  ScmAttributesSchemaLegacy,
+		SpanProcessor:        defaultSpanProcessor,
+		Summary:              true,
+		TimestampLayouts:     []string{},
+		TraceName:            defaultTraceName,
+		WatchInterval:        defaultWatchInterval,
+		WatchPattern:         defaultWatchPattern,
+		WatchSentinel:        defaultWatchSentinel,
+	}
+}
+
+// NewConfigFromArgs returns the configuration merging, from lowest to highest precedence: the defaults,
+// the YAML file set with the -config flag, the JUNIT2OTLP_* environment variables and the command line flags.
+// Only the flags and environment variables explicitly set override the values of the lower layers. The invalid
+// arguments are returned as errors, and so is flag.ErrHelp for -h and -help, once the usage is printed
+func NewConfigFromArgs(args []string) (*Config, error) {
+	return newConfigFromArgs(args, false)
+}
+
+// NewConvertConfigFromArgs returns the configuration of the convert command, as NewConfigFromArgs does, where the
+// -output flag is the path of the converted report instead of the format of the summary of the run
+func NewConvertConfigFromArgs(args []string) (*Config, error) {
+	return newConfigFromArgs(args, true)
+}
+
+func newConfigFromArgs(args []string, convert bool) (*Config, error) {
+	// first pass, only to discover the configuration file, which is the base for the rest of the layers
+	var configFile string
+	if _, err := parseFlags(NewConfigFromDefaults(), &configFile, args, convert); err != nil {
+		return nil, err
+	}
+
+	cfg := NewConfigFromDefaults()
+	if configFile != "" {
+		if err := cfg.applyFile(configFile); err != nil {
+			return nil, err
+		}
+	}
+
+	explicit, err := parseFlags(cfg, &configFile, args, convert)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.explicit = explicit
+
+	return cfg, nil
+}
+
+// NewConfigFromFile returns the configuration reading the YAML file at path. The values
+// not present in the file are set to their defaults
+func NewConfigFromFile(path string) (*Config, error) {
+	cfg := NewConfigFromDefaults()
+	if err := cfg.applyFile(path); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// IsSet returns true if the setting was explicitly set with a command line flag or an environment
+// variable, using the name of the flag, i.e. service-name
+func (c *Config) IsSet(name string) bool {
+	return c.explicit[name]
+}
+
+// applyFile overrides the configuration with the values present in the YAML file at path
+func (c *Config) applyFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read the configuration file: %w", err)
+	}
+
+	var doc interface{}
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return fmt.Errorf("failed to parse the configuration file %s: %w", path, err)
+	}
+
+	if err := validate(doc); err != nil {
+		return fmt.Errorf("failed to validate the configuration file %s: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(content, c); err != nil {
+		return fmt.Errorf("failed to parse the configuration file %s: %w", path, err)
+	}
+
+	c.PropertiesAllowed = parsePropertiesAllowed(strings.Join(c.PropertiesAllowed, ","))
+	c.PropertiesDenied = parsePropertiesList(strings.Join(c.PropertiesDenied, ","))
+
+	return nil
+}
+
+// parseFlags overrides the configuration with the environment variables and the command line flags
+// explicitly set, in that order, returning the names of the flags that were set. The -output flag is the
+// path of the converted report for the convert command, and the format of the summary of the run otherwise
+func parseFlags(cfg *Config, configFile *string, args []string, convert bool) (map[string]bool, error) {
+	propertiesAllowed := strings.Join(cfg.PropertiesAllowed, ",")
+	if propertiesAllowed == "" {
+		propertiesAllowed = propertiesAllowAll
+	}
+	propertiesDenied := strings.Join(cfg.PropertiesDenied, ",")
+	var additionalAttributes string
+	var resourceAttributes string
+	var exporterHeaders string
+	var serviceMapping string
+	attrs := attributesFlag{}
+	timestampLayouts := strings.Join(cfg.TimestampLayouts, ";")
+	histogramBuckets := formatBuckets(cfg.HistogramBuckets)
+
+	// the errors are returned, so a library call never terminates the process on an invalid argument
+	fs := flag.NewFlagSet(defaultTraceName, flag.ContinueOnError)
+	fs.StringVar(configFile, "config", *configFile, "Path to a YAML configuration file. The flags and the environment variables take precedence over its values")
+	fs.StringVar(&cfg.Format, "format", cfg.Format, "Format of the reports: auto, to detect it from the content of each report, junit, testng, nunit, trx, gotest, jest, mocha, ctest or tap")
+	fs.BoolVar(&cfg.StrictParse, "strict-parse", cfg.StrictParse, "Fail when any test suite or test case in the report cannot be parsed, instead of skipping it")
+	fs.StringVar(&cfg.Input, "input", cfg.Input, "Source of the jUnit reports: '-' for the standard input, 'tar:-' and 'tar:<path>' for a tar archive with the reports, the path to a report, or a glob pattern of the paths of the reports, where ** matches any number of directories. More paths or patterns can be passed as positional arguments")
+	if convert {
+		fs.StringVar(&cfg.Output, "output", cfg.Output, "Path of the file where the JUnit report is written. If empty or '-', it's written to stdout")
+	} else {
+		fs.StringVar(&cfg.OutputFormat, "output", cfg.OutputFormat, "Format of the summary of the run printed after the export: text or json")
+	}
+	fs.StringVar(&cfg.MatrixPattern, "matrix-pattern", cfg.MatrixPattern, "Pattern of the names of the reports of a CI matrix build, i.e. '{os}/{go}/shard-{shard}/*.xml', aggregating them into one run, with a tests.matrix.<dimension> attribute per dimension on each suite. The reruns of a test case in the same matrix cell, but another shard, are deduplicated")
+	fs.BoolVar(&cfg.MergeSuites, "merge-suites", cfg.MergeSuites, "Merge the suites with the same name of all the reports into one suite, aggregating their totals, with a child span per report, so the shards of a CI job do not show as duplicated suites")
+	fs.StringVar(&cfg.ScanDir, "scan-dir", cfg.ScanDir, "Directory walked recursively for the reports whose name matches the scan pattern, i.e. the root of a Maven or Gradle multi-module build, reading all of them in the same run. The standard input is not read")
+	fs.StringVar(&cfg.ScanPattern, "scan-pattern", cfg.ScanPattern, "Glob pattern of the names of the reports in the scanned directory")
+	fs.StringVar(&cfg.WatchDir, "watch-dir", cfg.WatchDir, "Directory watched for reports, i.e. a volume shared with the test container, converting each report as it appears until the sentinel file is written. If empty, the input is converted once")
+	fs.DurationVar(&cfg.WatchInterval, "watch-interval", cfg.WatchInterval, "Interval between the scans of the watched directory")
+	fs.StringVar(&cfg.WatchPattern, "watch-pattern", cfg.WatchPattern, "Glob pattern of the names of the reports in the watched directory, i.e. '*.json' for the gotest format")
+	fs.StringVar(&cfg.WatchSentinel, "watch-sentinel", cfg.WatchSentinel, "Name of the file which, once written in the watched directory, converts the remaining reports and stops the watch")
+	fs.BoolVar(&cfg.GroupByClass, "group-by-class", cfg.GroupByClass, "Add a span per classname between each suite and its test cases, so the large suites are navigable trees in the trace viewers")
+	fs.BoolVar(&cfg.SkipRootSpan, "skip-root-span", cfg.SkipRootSpan, "Omit the root span, creating the suites as top-level spans, or children of the span in the TRACEPARENT environment variable")
+	fs.IntVar(&cfg.MaxInputSize, "max-input-size", cfg.MaxInputSize, "Maximum size in MiB of each report, failing on the larger ones instead of reading them. If zero, there is no limit")
+	fs.IntVar(&cfg.MaxOutputSize, "max-output-size", cfg.MaxOutputSize, "Maximum size in bytes of the system-out and system-err sent as span attributes. The bigger ones are sent as log records, in chunks of this size. If zero, they are always span attributes")
+	fs.IntVar(&cfg.MaxAttributeLength, "max-attribute-length", cfg.MaxAttributeLength, "Maximum length in bytes of the string attributes of the spans, truncating the longer ones with a '...[truncated]' marker, so the collectors enforcing attribute limits do not drop the spans. If zero, there is no limit")
+	fs.BoolVar(&cfg.SkipSystemOutput, "skip-system-output", cfg.SkipSystemOutput, "Drop the system-out and system-err of the suites and the test cases, sending them neither as span attributes nor as log records")
+	fs.StringVar(&histogramBuckets, "histogram-buckets", histogramBuckets, "Comma separated list of the bucket boundaries, in milliseconds, of the histograms of the durations of the suites and the test cases, i.e. 10,100,1000,10000. If empty, the default boundaries of the OpenTelemetry SDK are used")
+	fs.BoolVar(&cfg.LogsSkipSending, "logs-skip-sending", cfg.LogsSkipSending, "Skip the OTLP log records of the oversized console outputs and of the bodies of the failures, keeping the outputs as span attributes, i.e. for the collectors without a logs pipeline")
+	fs.IntVar(&cfg.MaxSpans, "max-spans", cfg.MaxSpans, "Maximum number of test spans to be created, as a safety valve for huge reports. If zero, there is no limit")
+	fs.IntVar(&cfg.MemoryLimit, "memory-limit", cfg.MemoryLimit, "Soft limit in MiB of the memory used by the tool, i.e. the memory of the CI container. Under memory pressure, the parsed suites needed by the summaries are spilled to a temporary file. If zero, there is no limit")
+	fs.IntVar(&cfg.Parallelism, "parallelism", cfg.Parallelism, "Maximum number of reports parsed at the same time, i.e. the files in a tar archive. If zero, the number of CPUs")
+	fs.StringVar(&cfg.SpanProcessor, "span-processor", cfg.SpanProcessor, "Span processor used to export the spans: 'batch', or 'simple' to export each span synchronously, for small runs where an immediate export matters more than the throughput")
+	fs.BoolVar(&cfg.BaggageResourceAttributes, "baggage-resource-attributes", cfg.BaggageResourceAttributes, "Add the members of the W3C baggage of the BAGGAGE environment variable as resource attributes, named after their keys, i.e. team=platform,pipeline.id=42")
+	fs.IntVar(&cfg.BatchSize, "batch-size", cfg.BatchSize, "Maximum export batch size allowed when creating a BatchSpanProcessor")
+	fs.DurationVar(&cfg.BatchTimeout, "batch-timeout", cfg.BatchTimeout, "Maximum delay before the BatchSpanProcessor exports a batch, even if it's not full, i.e. 10s. If zero, the SDK default (5s, or OTEL_BSP_SCHEDULE_DELAY)")
+	fs.IntVar(&cfg.MaxQueueSize, "max-queue-size", cfg.MaxQueueSize, "Maximum number of spans buffered by the BatchSpanProcessor before dropping them. If zero, the SDK default (2048, or OTEL_BSP_MAX_QUEUE_SIZE)")
+	fs.StringVar(&cfg.RepositoryPath, "repository-path", cfg.RepositoryPath, "Path to the SCM repository to be read")
+	fs.StringVar(&cfg.ServiceName, "service-name", cfg.ServiceName, "OpenTelemetry Service Name to be used when sending traces and metrics for the jUnit report")
+	fs.StringVar(&serviceMapping, "service-mapping", "", "Comma separated list of prefix=service pairs, sending the suites whose package, or name, starts with the prefix under the resource of the service")
+	fs.BoolVar(&cfg.ServicePerSuite, "service-per-suite", cfg.ServicePerSuite, "Send each suite not matched by -service-mapping under the resource of a service named after its package, or its name")
+	fs.StringVar(&cfg.ServiceVersion, "service-version", cfg.ServiceVersion, "OpenTelemetry Service Version to be used when sending traces and metrics for the jUnit report")
+	fs.StringVar(&cfg.AssumeTimezone, "assume-timezone", cfg.AssumeTimezone, "IANA timezone of the timestamps in the report without zone information, i.e. UTC, Local or Europe/Madrid")
+	fs.StringVar(&timestampLayouts, "timestamp-layouts", timestampLayouts, "Semicolon separated list of Go layouts of the timestamps in the report, tried before the built-in ones, i.e. '02/01/2006 15:04:05'")
+	fs.StringVar(&cfg.TraceName, "trace-name", cfg.TraceName, "OpenTelemetry Trace Name to be used when sending traces and metrics for the jUnit report")
+	fs.StringVar(&propertiesAllowed, "properties-allowed", propertiesAllowed, "Comma separated list of properties to be allowed in the jUnit report")
+	fs.StringVar(&propertiesDenied, "properties-denied", propertiesDenied, "Comma separated list of properties to be excluded from the jUnit report. It takes precedence over -properties-allowed")
+	fs.StringVar(&additionalAttributes, "additional-attributes", "", "Comma separated list of attributes to be added to the jUnit report")
+	fs.StringVar(&resourceAttributes, "resource-attributes", "", "Comma separated list of key=value attributes to be added to the OpenTelemetry resource, as the ones of OTEL_RESOURCE_ATTRIBUTES, which they override")
+	fs.Var(attrs, "attr", "Attribute to be added to the jUnit report, as key=value. It can be repeated, and it takes precedence over -additional-attributes")
+	fs.StringVar(&cfg.AttributePrefix, "attribute-prefix", cfg.AttributePrefix, "Prefix for every attribute not defined by the OpenTelemetry semantic conventions, i.e. 'ci.tests.'")
+	fs.BoolVar(&cfg.FailOnError, "fail-on-error", cfg.FailOnError, "Exit with a non-zero code when the report contains more failed or errored tests than the threshold")
+	fs.Float64Var(&cfg.AnomalyThreshold, "anomaly-threshold", cfg.AnomalyThreshold, "Number of standard deviations above the mean duration of the suite, or of the test case in the history store, from which a test case is flagged as an anomaly with tests.case.anomaly. If zero, the test cases are not flagged")
+	fs.IntVar(&cfg.FailThreshold, "fail-threshold", cfg.FailThreshold, "Maximum number of failed or errored tests allowed when -fail-on-error is set")
+	fs.StringVar(&cfg.LogLevel, "log-level", cfg.LogLevel, "Minimum level of the log records written by the tool: debug, info, warn or error")
+	fs.StringVar(&cfg.LogFile, "log-file", cfg.LogFile, "Path of the file where the log records are appended. If empty, they are written to stderr")
+	fs.StringVar(&cfg.LogFormat, "log-format", cfg.LogFormat, "Format of the log records written by the tool: text or json")
+	fs.BoolVar(&cfg.PrintAttributes, "print-attributes", cfg.PrintAttributes, "Print the resolved attributes as JSON to stdout, without exporting anything")
+	fs.StringVar(&cfg.AttributeSchema, "attribute-schema", cfg.AttributeSchema, "Schema for the attributes of the suites and test cases: 'legacy' (tests.*), or 'otel' to add the OpenTelemetry test.* ones")
+	fs.StringVar(&cfg.ScmAttributesSchema, "scm-attributes-schema", cfg.ScmAttributesSchema, "Schema for the SCM attributes: 'legacy' (scm.*), 'vcs' (OpenTelemetry vcs.*) or 'both'")
+	fs.BoolVar(&cfg.GithubAnnotations, "github-annotations", cfg.GithubAnnotations, "Print a Github Actions error annotation to stdout for each failed or errored test, so that they are shown inline on the pull request diff")
+	fs.BoolVar(&cfg.GithubChecks, "github-checks", cfg.GithubChecks, "Create a Github check run for the commit, with the summary of the run, a link to the trace and a failure annotation for each failed test. It requires the GITHUB_TOKEN, GITHUB_REPOSITORY and GITHUB_SHA environment variables")
+	fs.BoolVar(&cfg.Summary, "summary", cfg.Summary, "Print the summary of the run after the conversion, in the format of -output: a table with the results of each suite to stderr by default, colorized when attached to a terminal, or JSON to stdout. Use -summary=false to disable it")
+	fs.StringVar(&cfg.SummaryJSON, "summary-json", cfg.SummaryJSON, "Path of the file where the summary of the run is written as JSON: totals, failed tests, slowest tests, the trace ID and where it was exported. If '-', it's written to stdout")
+	fs.StringVar(&cfg.SummaryMarkdown, "summary-markdown", cfg.SummaryMarkdown, "Path of the file where the summary of the run is appended as Markdown, i.e. $GITHUB_STEP_SUMMARY")
+	fs.StringVar(&cfg.ElasticsearchURL, "elasticsearch-url", cfg.ElasticsearchURL, "URL of Elasticsearch or OpenSearch, where a document per test case is indexed with the bulk API, i.e. http://localhost:9200. The API key is read from ELASTICSEARCH_API_KEY")
+	fs.StringVar(&cfg.ElasticsearchIndex, "elasticsearch-index", cfg.ElasticsearchIndex, "Index of Elasticsearch or OpenSearch where the test cases are indexed")
+	fs.StringVar(&cfg.AllureResults, "allure-results", cfg.AllureResults, "Path of the directory where an Allure result is written per test case, i.e. allure-results, to generate an Allure report from it")
+	fs.BoolVar(&cfg.BuildkiteAnalytics, "buildkite-analytics", cfg.BuildkiteAnalytics, "Upload the test cases to Buildkite Test Analytics too, with the token of the BUILDKITE_ANALYTICS_TOKEN environment variable")
+	fs.StringVar(&cfg.HistoryDB, "history-db", cfg.HistoryDB, "Path of the SQLite database where the outcomes and the durations of the test cases of each run are recorded, and read by the history command")
+	fs.BoolVar(&cfg.ExceptionEvents, "exception-events", cfg.ExceptionEvents, "Record an exception event per failure of the failed or errored test cases, with the exception.type, exception.message and exception.stacktrace attributes, so the tracing backends show the stack trace of the failure")
+	fs.StringVar(&cfg.ExportSpoolDir, "export-spool-dir", cfg.ExportSpoolDir, "Directory where the spans failing to be exported to the OTLP endpoint, after the retries, are written instead of failing, to be resent with the flush command")
+	fs.StringVar(&cfg.ExportFile, "export-file", cfg.ExportFile, "Path of the file where a row per test case is written, with all the attributes, as CSV or Parquet depending on its extension, i.e. results.csv or results.parquet")
+	fs.StringVar(&cfg.BigQueryTable, "bigquery-table", cfg.BigQueryTable, "Table of BigQuery, as project.dataset.table, where a row per test case is streamed, created with the managed schema if it does not exist. The access token is read from GOOGLE_OAUTH_ACCESS_TOKEN, or from the metadata server")
+	fs.StringVar(&cfg.CoverageFile, "coverage-file", cfg.CoverageFile, "Path of the Cobertura, JaCoCo or LCOV coverage report, whose line coverage is added to the suites of the packages it covers")
+	fs.StringVar(&cfg.TraceparentOut, "traceparent-out", cfg.TraceparentOut, "Path of the file where the W3C traceparent of the run, i.e. the root span, is written, so the next steps of the pipeline continue its trace with the TRACEPARENT environment variable")
+	fs.BoolVar(&cfg.GithubOutput, "github-output", cfg.GithubOutput, "Set the trace-id and traceparent outputs of the Github Actions step, with the trace of the run. It requires the GITHUB_OUTPUT environment variable")
+	fs.StringVar(&cfg.TraceURLTemplate, "trace-url-template", cfg.TraceURLTemplate, "URL of the trace in the tracing UI, where {traceId} is replaced by the ID of the trace, i.e. http://localhost:16686/trace/{traceId}")
+	fs.BoolVar(&cfg.ScmAPIEnrichment, "scm-api-enrichment", cfg.ScmAPIEnrichment, "Call the API of the SCM provider to enrich the SCM attributes. It requires a token for the provider")
+	fs.BoolVar(&cfg.SelfTelemetry, "self-telemetry", cfg.SelfTelemetry, "Emit spans and metrics about the conversion process itself: parse duration, files processed, spans generated and export latency")
+	fs.StringVar(&cfg.Exporter.Type, "exporter", cfg.Exporter.Type, "Exporter of the traces, metrics and logs: otlp, stdout, to write them to stdout as JSON, file, to write the spans to the exporter-file as OTLP JSON, or none. If empty, it's read from the OTEL_TRACES_EXPORTER, OTEL_METRICS_EXPORTER and OTEL_LOGS_EXPORTER environment variables")
+	fs.StringVar(&cfg.Exporter.File, "exporter-file", cfg.Exporter.File, "Path of the file where the file exporter writes the spans as OTLP JSON, an export request per line")
+	fs.StringVar(&cfg.Exporter.Endpoint, "otlp-endpoint", cfg.Exporter.Endpoint, "URL of the OTLP endpoint, i.e. http://localhost:4317. If empty, it's read from OTEL_EXPORTER_OTLP_ENDPOINT")
+	fs.StringVar(&exporterHeaders, "otlp-headers", "", "Comma separated list of key=value headers to be sent with each export request. If empty, they are read from OTEL_EXPORTER_OTLP_HEADERS")
+	fs.BoolVar(&cfg.Exporter.Insecure, "otlp-insecure", cfg.Exporter.Insecure, "Disable client transport security for the exporters")
+	fs.StringVar(&cfg.Exporter.Protocol, "otlp-protocol", cfg.Exporter.Protocol, "Protocol of the exporters: grpc or http/protobuf, for the collectors only exposing the OTLP/HTTP port, usually 4318. If empty, it's read from OTEL_EXPORTER_OTLP_PROTOCOL")
+
+	for alias, name := range flagAliases {
+		f := fs.Lookup(name)
+		fs.Var(f.Value, alias, "Alias for -"+name)
+	}
+
+	// the environment variables are applied as if they were flags, so the command line flags override them
+	var envErr error
+	fs.VisitAll(func(f *flag.Flag) {
+		if _, isAlias := flagAliases[f.Name]; isAlias {
+			return
+		}
+
+		value, ok := os.LookupEnv(envVarName(f.Name))
+		if !ok || envErr != nil {
+			return
+		}
+
+		if err := fs.Set(f.Name, value); err != nil {
+			envErr = fmt.Errorf("invalid value %q for %s: %w", value, envVarName(f.Name), err)
+		}
+	})
+	if envErr != nil {
+		return nil, envErr
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	// the positional arguments are the paths or glob patterns of the reports
+	if fs.NArg() > 0 {
+		cfg.Inputs = fs.Args()
+	}
+
+	var explicit map[string]bool
+	fs.Visit(func(f *flag.Flag) {
+		if explicit == nil {
+			explicit = map[string]bool{}
+		}
+
+		name := f.Name
+		if canonical, ok := flagAliases[name]; ok {
+			name = canonical
+		}
+		explicit[name] = true
+	})
+
+	cfg.PropertiesAllowed = parsePropertiesAllowed(propertiesAllowed)
+	cfg.PropertiesDenied = parsePropertiesList(propertiesDenied)
+
+	if explicit["timestamp-layouts"] {
+		cfg.TimestampLayouts = []string{}
+		for _, layout := range strings.Split(timestampLayouts, ";") {
+			if layout != "" {
+				cfg.TimestampLayouts = append(cfg.TimestampLayouts, layout)
+			}
+		}
+	}
+
+	if explicit["histogram-buckets"] {
+		buckets, err := parseBuckets(histogramBuckets)
+		if err != nil {
+			return nil, err
+		}
+		cfg.HistogramBuckets = buckets
+	}
+
+	if explicit["additional-attributes"] {
+		attributes, err := parseAdditionalAttributes(additionalAttributes)
+		if err != nil {
+			return nil, err
+		}
+
+		// the attributes from the lower layers are kept, unless they are overridden
+		for k, v := range attributes {
+			cfg.AdditionalAttributes[k] = v
+		}
+	}
+
+	for k, v := range attrs {
+		cfg.AdditionalAttributes[k] = v
+	}
+
+	if explicit["resource-attributes"] {
+		attributes, err := parseAdditionalAttributes(resourceAttributes)
+		if err != nil {
+			return nil, err
+		}
+
+		if cfg.ResourceAttributes == nil {
+			cfg.ResourceAttributes = map[string]string{}
+		}
+		for k, v := range attributes {
+			cfg.ResourceAttributes[k] = v
+		}
+	}
+
+	if explicit["otlp-headers"] {
+		headers, err := parseAdditionalAttributes(exporterHeaders)
+		if err != nil {
+			return nil, err
+		}
+
+		if cfg.Exporter.Headers == nil {
+			cfg.Exporter.Headers = map[string]string{}
+		}
+		for k, v := range headers {
+			cfg.Exporter.Headers[k] = v
+		}
+	}
+
+	if explicit["service-mapping"] {
+		mapping, err := parseAdditionalAttributes(serviceMapping)
+		if err != nil {
+			return nil, err
+		}
+
+		if cfg.ServiceMapping == nil {
+			cfg.ServiceMapping = map[string]string{}
+		}
+		for k, v := range mapping {
+			cfg.ServiceMapping[k] = v
+		}
+	}
+
+	return explicit, nil
+}
+
+// envVarName returns the name of the environment variable for a flag, i.e. JUNIT2OTLP_SERVICE_NAME for service-name
+func envVarName(flagName string) string {
+	return envVarPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// getDefaultwd retrieves the current working dir, using '.' in the case an error occurs
+func getDefaultwd() string {
+	workingDir, err := os.Getwd()
+	if err != nil {
+		return "."
+	}
+
+	return workingDir
+}
+
+// parseAdditionalAttributes parses a comma separated list of key=value attributes. Only the first '='
+// separates the key from the value, and the values containing commas can be double quoted, or use
+// a backslash to escape them, i.e. url="http://example.com?a=b,c" or list=a\,b
+func parseAdditionalAttributes(additionalAttributes string) (map[string]string, error) {
+	attributes := map[string]string{}
+	if additionalAttributes == "" {
+		return attributes, nil
+	}
+
+	addAttrs, err := splitAttributes(additionalAttributes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add additional attributes: %w", err)
+	}
+
+	additionalAttrsErrors := []error{}
+
+	for _, attr := range addAttrs {
+		kv := strings.SplitN(attr, "=", 2)
+		if len(kv) == 2 && kv[0] != "" {
+			attributes[kv[0]] = kv[1]
+		} else {
+			additionalAttrsErrors = append(additionalAttrsErrors,
+				fmt.Errorf("invalid attribute: %s", attr))
+		}
+	}
+
+	if err := errors.Join(additionalAttrsErrors...); err != nil {
+		return nil, fmt.Errorf("failed to add additional attributes: %w", err)
+	}
+
+	return attributes, nil
+}
+
+// splitAttributes splits the attributes on the commas that are neither escaped nor double quoted,
+// removing the quotes and the escape characters from the result
+func splitAttributes(attributes string) ([]string, error) {
+	attrs := []string{}
+
+	var current strings.Builder
+	escaped := false
+	quoted := false
+
+	for _, r := range attributes {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			attrs = append(attrs, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	if escaped {
+		return nil, fmt.Errorf("unterminated escape sequence: %s", attributes)
+	}
+
+	if quoted {
+		return nil, fmt.Errorf("unterminated quoted value: %s", attributes)
+	}
+
+	return append(attrs, current.String()), nil
+}
+
+// attributesFlag a repeatable flag for key=value attributes. Only the first '=' separates the key
+// from the value, so the values can contain any character
+type attributesFlag map[string]string
+
+func (a attributesFlag) String() string {
+	attrs := make([]string, 0, len(a))
+	for k, v := range a {
+		attrs = append(attrs, k+"="+v)
+	}
+
+	slices.Sort(attrs)
+
+	return strings.Join(attrs, ",")
+}
+
+func (a attributesFlag) Set(value string) error {
+	kv := strings.SplitN(value, "=", 2)
+	if len(kv) != 2 || kv[0] == "" {
+		return fmt.Errorf("invalid attribute: %s, expected key=value", value)
+	}
+
+	a[kv[0]] = kv[1]
+
+	return nil
+}
+
+// parsePropertiesAllowed parses a comma separated list of properties, where "all" allows every property
+func parsePropertiesAllowed(propertiesAllowed string) []string {
+	if propertiesAllowed == propertiesAllowAll {
+		return []string{}
+	}
+
+	return parsePropertiesList(propertiesAllowed)
+}
+
+// parsePropertiesList parses a comma separated list of properties, trimming the spaces around them
+func parsePropertiesList(properties string) []string {
+	props := []string{}
+	if properties == "" {
+		return props
+	}
+
+	for _, prop := range strings.Split(properties, ",") {
+		props = append(props, strings.TrimSpace(prop))
+	}
+
+	return props
+}
+
+// formatBuckets formats the bucket boundaries as a comma separated list
+func formatBuckets(buckets []float64) string {
+	values := make([]string, 0, len(buckets))
+	for _, b := range buckets {
+		values = append(values, strconv.FormatFloat(b, 'f', -1, 64))
+	}
+
+	return strings.Join(values, ",")
+}
+
+// parseBuckets parses a comma separated list of increasing bucket boundaries
+func parseBuckets(buckets string) ([]float64, error) {
+	boundaries := []float64{}
+	for _, value := range strings.Split(buckets, ",") {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+
+		b, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid histogram bucket %q: %w", value, err)
+		}
+		if len(boundaries) > 0 && b <= boundaries[len(boundaries)-1] {
+			return nil, fmt.Errorf("invalid histogram buckets %q: the boundaries must be increasing", buckets)
+		}
+
+		boundaries = append(boundaries, b)
+	}
+
+	return boundaries, nil
+}
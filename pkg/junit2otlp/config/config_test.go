@@ -0,0 +1,529 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConfigFromDefaults(t *testing.T) {
+	cfg := NewConfigFromDefaults()
+
+	require.Equal(t, defaultMaxBatchSize, cfg.BatchSize)
+	require.Equal(t, defaultTraceName, cfg.TraceName)
+	require.Equal(t, defaultMaxOutputSize, cfg.MaxOutputSize)
+	require.Equal(t, ScmAttributesSchemaLegacy, cfg.ScmAttributesSchema)
+	require.Equal(t, AttributeSchemaLegacy, cfg.AttributeSchema)
+	require.Equal(t, getDefaultwd(), cfg.RepositoryPath)
+	require.Empty(t, cfg.PropertiesAllowed)
+	require.Empty(t, cfg.AdditionalAttributes)
+	require.Empty(t, cfg.ServiceName)
+}
+
+func TestNewConfigFromArgs(t *testing.T) {
+	t.Run("Without flags", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{})
+		require.NoError(t, err)
+		require.Equal(t, NewConfigFromDefaults(), cfg)
+	})
+
+	t.Run("With flags", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{
+			"--service-name", "my-service",
+			"--batch-size", "25",
+			"--properties-allowed", "go.version, go.os",
+			"--properties-denied", "go.os",
+			"--additional-attributes", "team=platform,pipeline=nightly",
+			"--attribute-prefix", "ci.tests.",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "my-service", cfg.ServiceName)
+		require.Equal(t, 25, cfg.BatchSize)
+		require.Equal(t, []string{"go.version", "go.os"}, cfg.PropertiesAllowed)
+		require.Equal(t, []string{"go.os"}, cfg.PropertiesDenied)
+		require.Equal(t, map[string]string{"team": "platform", "pipeline": "nightly"}, cfg.AdditionalAttributes)
+		require.Equal(t, "ci.tests.", cfg.AttributePrefix)
+	})
+
+	t.Run("With invalid additional attributes", func(t *testing.T) {
+		_, err := NewConfigFromArgs([]string{"--additional-attributes", "team=platform,pipeline"})
+		require.ErrorContains(t, err, "invalid attribute: pipeline")
+	})
+
+	t.Run("With quoted and escaped additional attributes", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--additional-attributes", `url="http://example.com/?a=b,c",list=a\,b,json={"k":"v"}`})
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{
+			"url":  "http://example.com/?a=b,c",
+			"list": "a,b",
+			"json": "{k:v}",
+		}, cfg.AdditionalAttributes)
+	})
+
+	t.Run("With escaped quotes in additional attributes", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--additional-attributes", `json={\"k\":\"v\"}`})
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"json": `{"k":"v"}`}, cfg.AdditionalAttributes)
+	})
+
+	t.Run("With unterminated quoted additional attributes", func(t *testing.T) {
+		_, err := NewConfigFromArgs([]string{"--additional-attributes", `url="http://example.com`})
+		require.ErrorContains(t, err, "unterminated quoted value")
+	})
+
+	t.Run("With repeated attr flags", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{
+			"--additional-attributes", "team=platform,pipeline=nightly",
+			"--attr", "url=http://example.com/?a=b,c",
+			"--attr", "team=observability",
+		})
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{
+			"pipeline": "nightly",
+			"team":     "observability",
+			"url":      "http://example.com/?a=b,c",
+		}, cfg.AdditionalAttributes)
+	})
+
+	t.Run("With timestamp flags", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{
+			"--assume-timezone", "Europe/Madrid",
+			"--timestamp-layouts", "02/01/2006 15:04:05;2006.01.02 15:04",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "Europe/Madrid", cfg.AssumeTimezone)
+		require.Equal(t, []string{"02/01/2006 15:04:05", "2006.01.02 15:04"}, cfg.TimestampLayouts)
+	})
+
+	t.Run("With OTLP flags", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{
+			"--otlp-endpoint", "http://localhost:4317",
+			"--otlp-headers", "authorization=Bearer secret-token",
+			"--otlp-insecure",
+			"--otlp-protocol", "http/protobuf",
+			"--exporter", "file",
+			"--exporter-file", "spans.jsonl",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "http://localhost:4317", cfg.Exporter.Endpoint)
+		require.Equal(t, map[string]string{"authorization": "Bearer secret-token"}, cfg.Exporter.Headers)
+		require.True(t, cfg.Exporter.Insecure)
+		require.Equal(t, "http/protobuf", cfg.Exporter.Protocol)
+		require.Equal(t, "file", cfg.Exporter.Type)
+		require.Equal(t, "spans.jsonl", cfg.Exporter.File)
+	})
+
+	t.Run("With flag aliases", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{
+			"--service", "my-service",
+			"--endpoint", "http://localhost:4317",
+			"--insecure",
+			"--protocol", "http/protobuf",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "http/protobuf", cfg.Exporter.Protocol)
+		require.Equal(t, "my-service", cfg.ServiceName)
+		require.Equal(t, "http://localhost:4317", cfg.Exporter.Endpoint)
+		require.True(t, cfg.Exporter.Insecure)
+		require.True(t, cfg.IsSet("service-name"))
+	})
+
+	t.Run("With resource attributes", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "junit2otlp.yaml")
+		writeFile(t, path, "resource-attributes:\n  team: platform\n  region: eu\n")
+
+		cfg, err := NewConfigFromArgs([]string{"--config", path, "--resource-attributes", `team=observability,url="http://example.com/?a=b,c"`})
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"team": "observability", "region": "eu", "url": "http://example.com/?a=b,c"}, cfg.ResourceAttributes)
+		require.Empty(t, cfg.AdditionalAttributes)
+	})
+
+	t.Run("Invalid flags are returned as errors", func(t *testing.T) {
+		_, err := NewConfigFromArgs([]string{"--otlp-attributes", "team=platform"})
+		require.ErrorContains(t, err, "flag provided but not defined: -otlp-attributes")
+
+		_, err = NewConfigFromArgs([]string{"--batch-size", "many"})
+		require.ErrorContains(t, err, "invalid value \"many\" for flag -batch-size")
+
+		_, err = NewConfigFromArgs([]string{"-h"})
+		require.ErrorIs(t, err, flag.ErrHelp)
+	})
+
+	t.Run("Alias environment variables are ignored", func(t *testing.T) {
+		t.Setenv("JUNIT2OTLP_SERVICE", "from-env")
+
+		cfg, err := NewConfigFromArgs([]string{})
+		require.NoError(t, err)
+		require.Empty(t, cfg.ServiceName)
+	})
+
+	t.Run("With span processor flags", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--batch-timeout", "10s", "--max-queue-size", "8192"})
+		require.NoError(t, err)
+		require.Equal(t, 10*time.Second, cfg.BatchTimeout)
+		require.Equal(t, 8192, cfg.MaxQueueSize)
+	})
+
+	t.Run("With max output size disabled", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--max-output-size", "0"})
+		require.NoError(t, err)
+		require.Equal(t, 0, cfg.MaxOutputSize)
+	})
+
+	t.Run("With max attribute length", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--max-attribute-length", "4096"})
+		require.NoError(t, err)
+		require.Equal(t, 4096, cfg.MaxAttributeLength)
+	})
+
+	t.Run("With skip system output", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--skip-system-output"})
+		require.NoError(t, err)
+		require.True(t, cfg.SkipSystemOutput)
+	})
+
+	t.Run("With max input size", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--max-input-size", "64"})
+		require.NoError(t, err)
+		require.Equal(t, 64, cfg.MaxInputSize)
+	})
+
+	t.Run("With merge suites", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--merge-suites"})
+		require.NoError(t, err)
+		require.True(t, cfg.MergeSuites)
+	})
+
+	t.Run("With memory limit", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--memory-limit", "256"})
+		require.NoError(t, err)
+		require.Equal(t, 256, cfg.MemoryLimit)
+	})
+
+	t.Run("With Elasticsearch flags", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--elasticsearch-url", "http://localhost:9200", "--elasticsearch-index", "tests"})
+		require.NoError(t, err)
+		require.Equal(t, "http://localhost:9200", cfg.ElasticsearchURL)
+		require.Equal(t, "tests", cfg.ElasticsearchIndex)
+	})
+
+	t.Run("With BigQuery table", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--bigquery-table", "my-project.ci.tests"})
+		require.NoError(t, err)
+		require.Equal(t, "my-project.ci.tests", cfg.BigQueryTable)
+	})
+
+	t.Run("With export file", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--export-file", "results.parquet"})
+		require.NoError(t, err)
+		require.Equal(t, "results.parquet", cfg.ExportFile)
+	})
+
+	t.Run("With history store", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--history-db", "history.db"})
+		require.NoError(t, err)
+		require.Equal(t, "history.db", cfg.HistoryDB)
+	})
+
+	t.Run("With Buildkite Test Analytics", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--buildkite-analytics"})
+		require.NoError(t, err)
+		require.True(t, cfg.BuildkiteAnalytics)
+	})
+
+	t.Run("With Allure results", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--allure-results", "allure-results"})
+		require.NoError(t, err)
+		require.Equal(t, "allure-results", cfg.AllureResults)
+	})
+
+	t.Run("With Github check run", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--github-checks"})
+		require.NoError(t, err)
+		require.True(t, cfg.GithubChecks)
+	})
+
+	t.Run("With baggage resource attributes", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--baggage-resource-attributes"})
+		require.NoError(t, err)
+		require.True(t, cfg.BaggageResourceAttributes)
+	})
+
+	t.Run("With trace context outputs", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--traceparent-out", "traceparent.txt", "--github-output"})
+		require.NoError(t, err)
+		require.Equal(t, "traceparent.txt", cfg.TraceparentOut)
+		require.True(t, cfg.GithubOutput)
+	})
+
+	t.Run("With output", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "junit2otlp.yaml")
+		writeFile(t, path, "output: TEST-converted.xml\noutput-format: text\n")
+
+		cfg, err := NewConfigFromArgs([]string{"--config", path, "--output", "json"})
+		require.NoError(t, err)
+		require.Equal(t, "TEST-converted.xml", cfg.Output)
+		require.Equal(t, "json", cfg.OutputFormat)
+
+		cfg, err = NewConvertConfigFromArgs([]string{"--config", path, "--output", "-"})
+		require.NoError(t, err)
+		require.Equal(t, "-", cfg.Output)
+		require.Equal(t, "text", cfg.OutputFormat)
+	})
+
+	t.Run("With coverage file", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--coverage-file", "coverage.xml"})
+		require.NoError(t, err)
+		require.Equal(t, "coverage.xml", cfg.CoverageFile)
+	})
+
+	t.Run("With anomaly threshold", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--anomaly-threshold", "2.5"})
+		require.NoError(t, err)
+		require.Equal(t, 2.5, cfg.AnomalyThreshold)
+	})
+
+	t.Run("With service per suite", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--service-per-suite", "--service-mapping", "github.com/acme/mono/billing=billing,github.com/acme/mono/users=users"})
+		require.NoError(t, err)
+		require.True(t, cfg.ServicePerSuite)
+		require.Equal(t, map[string]string{"github.com/acme/mono/billing": "billing", "github.com/acme/mono/users": "users"}, cfg.ServiceMapping)
+	})
+
+	t.Run("With logs skip sending", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--logs-skip-sending"})
+		require.NoError(t, err)
+		require.True(t, cfg.LogsSkipSending)
+	})
+
+	t.Run("With histogram buckets", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--histogram-buckets", "10, 100,1000.5"})
+		require.NoError(t, err)
+		require.Equal(t, []float64{10, 100, 1000.5}, cfg.HistogramBuckets)
+
+		_, err = NewConfigFromArgs([]string{"--histogram-buckets", "100,10"})
+		require.ErrorContains(t, err, "the boundaries must be increasing")
+
+		_, err = NewConfigFromArgs([]string{"--histogram-buckets", "10,ms"})
+		require.ErrorContains(t, err, "invalid histogram bucket")
+	})
+
+	t.Run("With group by class", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--group-by-class"})
+		require.NoError(t, err)
+		require.True(t, cfg.GroupByClass)
+	})
+
+	t.Run("With export spool dir", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--export-spool-dir", "/var/spool/junit2otlp"})
+		require.NoError(t, err)
+		require.Equal(t, "/var/spool/junit2otlp", cfg.ExportSpoolDir)
+	})
+
+	t.Run("With attribute schema", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--attribute-schema", "otel"})
+		require.NoError(t, err)
+		require.Equal(t, "otel", cfg.AttributeSchema)
+	})
+
+	t.Run("With matrix pattern", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--matrix-pattern", "{os}/shard-{shard}/*.xml"})
+		require.NoError(t, err)
+		require.Equal(t, "{os}/shard-{shard}/*.xml", cfg.MatrixPattern)
+	})
+
+	t.Run("With positional inputs", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--service-name", "foo", "TEST-a.xml", "**/surefire-reports/*.xml"})
+		require.NoError(t, err)
+		require.Equal(t, "foo", cfg.ServiceName)
+		require.Equal(t, []string{"TEST-a.xml", "**/surefire-reports/*.xml"}, cfg.Inputs)
+	})
+
+	t.Run("With scan flags", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--scan-dir", "/workspace"})
+		require.NoError(t, err)
+		require.Equal(t, "/workspace", cfg.ScanDir)
+		require.Equal(t, "TEST-*.xml", cfg.ScanPattern)
+
+		cfg, err = NewConfigFromArgs([]string{"--scan-dir", "/workspace", "--scan-pattern", "*.json"})
+		require.NoError(t, err)
+		require.Equal(t, "*.json", cfg.ScanPattern)
+	})
+
+	t.Run("With watch flags", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--watch-dir", "/reports", "--watch-interval", "500ms", "--watch-pattern", "*.json", "--watch-sentinel", "finished"})
+		require.NoError(t, err)
+		require.Equal(t, "/reports", cfg.WatchDir)
+		require.Equal(t, 500*time.Millisecond, cfg.WatchInterval)
+		require.Equal(t, "*.json", cfg.WatchPattern)
+		require.Equal(t, "finished", cfg.WatchSentinel)
+	})
+
+	t.Run("With config file", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{"--config", filepath.Join("testdata", "junit2otlp.yaml")})
+		require.NoError(t, err)
+		require.Equal(t, "my-service", cfg.ServiceName)
+		require.Equal(t, 25, cfg.BatchSize)
+	})
+
+	t.Run("Flags override the config file", func(t *testing.T) {
+		cfg, err := NewConfigFromArgs([]string{
+			"--config", filepath.Join("testdata", "junit2otlp.yaml"),
+			"--service-name", "overridden",
+			"--additional-attributes", "team=observability",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "overridden", cfg.ServiceName)
+		require.Equal(t, 25, cfg.BatchSize) // not set with a flag, so the value of the file is kept
+		require.Equal(t, "1.0.0", cfg.ServiceVersion)
+		require.Equal(t, map[string]string{"team": "observability", "pipeline": "nightly"}, cfg.AdditionalAttributes)
+	})
+
+	t.Run("Environment variables override the config file", func(t *testing.T) {
+		t.Setenv("JUNIT2OTLP_SERVICE_NAME", "from-env")
+		t.Setenv("JUNIT2OTLP_FAIL_ON_ERROR", "true")
+		t.Setenv("JUNIT2OTLP_CONFIG", filepath.Join("testdata", "junit2otlp.yaml"))
+
+		cfg, err := NewConfigFromArgs([]string{})
+		require.NoError(t, err)
+		require.Equal(t, "from-env", cfg.ServiceName)
+		require.True(t, cfg.FailOnError)
+		require.Equal(t, 25, cfg.BatchSize)
+	})
+
+	t.Run("Flags override the environment variables", func(t *testing.T) {
+		t.Setenv("JUNIT2OTLP_SERVICE_NAME", "from-env")
+
+		cfg, err := NewConfigFromArgs([]string{"--service-name", "from-flag"})
+		require.NoError(t, err)
+		require.Equal(t, "from-flag", cfg.ServiceName)
+	})
+
+	t.Run("Invalid environment variable", func(t *testing.T) {
+		t.Setenv("JUNIT2OTLP_BATCH_SIZE", "many")
+
+		_, err := NewConfigFromArgs([]string{})
+		require.ErrorContains(t, err, `invalid value "many" for JUNIT2OTLP_BATCH_SIZE`)
+	})
+
+	t.Run("Explicitly set", func(t *testing.T) {
+		t.Setenv("JUNIT2OTLP_TRACE_NAME", "from-env")
+
+		cfg, err := NewConfigFromArgs([]string{"--batch-size", "10"})
+		require.NoError(t, err)
+		require.True(t, cfg.IsSet("batch-size")) // even if it's the default value
+		require.True(t, cfg.IsSet("trace-name"))
+		require.False(t, cfg.IsSet("service-name"))
+	})
+}
+
+func TestNewConfigFromFile(t *testing.T) {
+	t.Run("Valid file", func(t *testing.T) {
+		cfg, err := NewConfigFromFile(filepath.Join("testdata", "junit2otlp.yaml"))
+		require.NoError(t, err)
+
+		require.Equal(t, "my-service", cfg.ServiceName)
+		require.Equal(t, "1.0.0", cfg.ServiceVersion)
+		require.Equal(t, "my-trace", cfg.TraceName)
+		require.Equal(t, 25, cfg.BatchSize)
+		require.Equal(t, "/opt/projectname", cfg.RepositoryPath)
+		require.Equal(t, []string{"go.version", "go.os"}, cfg.PropertiesAllowed)
+		require.Equal(t, map[string]string{"team": "platform", "pipeline": "nightly"}, cfg.AdditionalAttributes)
+		require.Equal(t, "vcs", cfg.ScmAttributesSchema)
+		require.True(t, cfg.ScmAPIEnrichment)
+		require.Equal(t, "http://localhost:4317", cfg.Exporter.Endpoint)
+		require.True(t, cfg.Exporter.Insecure)
+		require.Equal(t, map[string]string{"authorization": "Bearer secret-token"}, cfg.Exporter.Headers)
+	})
+
+	t.Run("Missing values use defaults", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "junit2otlp.yaml")
+		writeFile(t, path, "service-name: my-service\n")
+
+		cfg, err := NewConfigFromFile(path)
+		require.NoError(t, err)
+		require.Equal(t, "my-service", cfg.ServiceName)
+		require.Equal(t, defaultMaxBatchSize, cfg.BatchSize)
+		require.Equal(t, defaultTraceName, cfg.TraceName)
+	})
+
+	t.Run("Durations", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "junit2otlp.yaml")
+		writeFile(t, path, "batch-timeout: 1m30s\n")
+
+		cfg, err := NewConfigFromFile(path)
+		require.NoError(t, err)
+		require.Equal(t, 90*time.Second, cfg.BatchTimeout)
+	})
+
+	t.Run("Plugins", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "junit2otlp.yaml")
+		writeFile(t, path, "plugins:\n  - name: owners\n    command: [wasmtime, run, owners.wasm]\n    timeout: 30s\n  - command: [./enrich.sh]\n")
+
+		cfg, err := NewConfigFromFile(path)
+		require.NoError(t, err)
+		require.Equal(t, []PluginConfig{
+			{Name: "owners", Command: []string{"wasmtime", "run", "owners.wasm"}, Timeout: 30 * time.Second},
+			{Command: []string{"./enrich.sh"}},
+		}, cfg.Plugins)
+	})
+
+	t.Run("Plugin without command", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "junit2otlp.yaml")
+		writeFile(t, path, "plugins:\n  - name: owners\n")
+
+		_, err := NewConfigFromFile(path)
+		require.ErrorContains(t, err, "failed to validate the configuration file")
+	})
+
+	t.Run("Invalid duration", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "junit2otlp.yaml")
+		writeFile(t, path, "batch-timeout: soon\n")
+
+		_, err := NewConfigFromFile(path)
+		require.ErrorContains(t, err, "failed to validate the configuration file")
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		_, err := NewConfigFromFile(filepath.Join(t.TempDir(), "junit2otlp.yaml"))
+		require.Error(t, err)
+	})
+
+	t.Run("Invalid YAML", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "junit2otlp.yaml")
+		writeFile(t, path, "batch-size: [1, 2\n")
+
+		_, err := NewConfigFromFile(path)
+		require.ErrorContains(t, err, "failed to parse the configuration file")
+	})
+
+	t.Run("Invalid configuration", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "junit2otlp.yaml")
+		writeFile(t, path, "batch-size: [1, 2]\n")
+
+		_, err := NewConfigFromFile(path)
+		require.ErrorContains(t, err, "failed to validate the configuration file")
+	})
+}
+
+func TestAttributesFlag(t *testing.T) {
+	attrs := attributesFlag{}
+
+	require.NoError(t, attrs.Set(`json={"k":"v"}`))
+	require.NoError(t, attrs.Set("empty="))
+	require.Error(t, attrs.Set("team"))
+	require.Error(t, attrs.Set("=platform"))
+
+	require.Equal(t, `empty=,json={"k":"v"}`, attrs.String())
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	err := os.WriteFile(path, []byte(content), 0o600)
+	require.NoError(t, err)
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+const schemaURL = "https://github.com/mdelapenya/junit2otlp/junit2otlp.schema.json"
+
+// Schema the JSON Schema describing the configuration file
+//
+//go:embed junit2otlp.schema.json
+var Schema string
+
+var compiledSchema = jsonschema.MustCompileString(schemaURL, Schema)
+
+// validate validates the decoded YAML document of a configuration file against the JSON Schema,
+// returning an error listing every invalid path in the document
+func validate(doc interface{}) error {
+	// an empty file is a valid configuration
+	if doc == nil {
+		return nil
+	}
+
+	err := compiledSchema.Validate(doc)
+	if err == nil {
+		return nil
+	}
+
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+
+	messages := []string{}
+	for _, e := range validationErr.BasicOutput().Errors {
+		// only the leaves describe the actual problem, the rest are the path to them
+		if e.Error == "" || strings.HasPrefix(e.Error, "doesn't validate with") {
+			continue
+		}
+
+		location := e.InstanceLocation
+		if location == "" {
+			location = "/"
+		}
+
+		messages = append(messages, fmt.Sprintf("%s: %s", location, e.Error))
+	}
+	sort.Strings(messages)
+
+	return fmt.Errorf("invalid configuration:\n  %s", strings.Join(messages, "\n  "))
+}
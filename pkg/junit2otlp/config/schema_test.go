@@ -0,0 +1,58 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestValidate(t *testing.T) {
+	validateYAML := func(t *testing.T, content string) error {
+		t.Helper()
+
+		var doc interface{}
+		require.NoError(t, yaml.Unmarshal([]byte(content), &doc))
+
+		return validate(doc)
+	}
+
+	t.Run("Empty document", func(t *testing.T) {
+		require.NoError(t, validateYAML(t, ""))
+	})
+
+	t.Run("Valid document", func(t *testing.T) {
+		err := validateYAML(t, `
+service-name: my-service
+batch-size: 25
+log-level: debug
+exporter:
+  endpoint: http://localhost:4317
+  headers:
+    authorization: Bearer secret-token
+`)
+		require.NoError(t, err)
+	})
+
+	t.Run("Unknown keys", func(t *testing.T) {
+		err := validateYAML(t, `
+service-nam: my-service
+exporter:
+  endpiont: http://localhost:4317
+`)
+		require.ErrorContains(t, err, "/: additionalProperties 'service-nam' not allowed")
+		require.ErrorContains(t, err, "/exporter: additionalProperties 'endpiont' not allowed")
+	})
+
+	t.Run("Invalid types", func(t *testing.T) {
+		err := validateYAML(t, `
+batch-size: ten
+additional-attributes:
+  team: [platform]
+scm-attributes-schema: otel
+`)
+		require.ErrorContains(t, err, "/batch-size: expected integer, but got string")
+		require.ErrorContains(t, err, "/additional-attributes/team: expected string, but got array")
+		require.ErrorContains(t, err, "/scm-attributes-schema: value must be one of")
+	})
+}
@@ -0,0 +1,86 @@
+package junit2otlp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// AttributeContributor contributes attributes shared by every span and metric of a run, i.e. read from the build
+// metadata files of the workspace. The contributors run alongside the SCM one, when the runtime attributes are
+// resolved, so they must be safe for concurrent use. Their attributes are prefixed as the SCM ones, and the
+// additional attributes of the configuration take precedence over them
+type AttributeContributor interface {
+	ContributeAttributes(ctx context.Context, cfg *Config) ([]attribute.KeyValue, error)
+}
+
+// AttributeContributorFunc an ordinary function used as an AttributeContributor
+type AttributeContributorFunc func(ctx context.Context, cfg *Config) ([]attribute.KeyValue, error)
+
+// ContributeAttributes calls f(ctx, cfg)
+func (f AttributeContributorFunc) ContributeAttributes(ctx context.Context, cfg *Config) ([]attribute.KeyValue, error) {
+	return f(ctx, cfg)
+}
+
+// namedContributor a registered contributor and its name
+type namedContributor struct {
+	name        string
+	contributor AttributeContributor
+}
+
+var (
+	contributorsMu sync.RWMutex
+	contributors   []namedContributor
+)
+
+// RegisterAttributeContributor registers the contributor with the name, so it runs in every conversion, in the
+// order the contributors were registered. It's meant to be called from the init functions of the embedders, as
+// database/sql drivers are registered. It panics if the contributor is nil, or if the name is already registered
+func RegisterAttributeContributor(name string, contributor AttributeContributor) {
+	contributorsMu.Lock()
+	defer contributorsMu.Unlock()
+
+	if contributor == nil {
+		panic("junit2otlp: the attribute contributor is nil")
+	}
+
+	if slices.ContainsFunc(contributors, func(c namedContributor) bool { return c.name == name }) {
+		panic(fmt.Sprintf("junit2otlp: the attribute contributor %s is already registered", name))
+	}
+
+	contributors = append(contributors, namedContributor{name: name, contributor: contributor})
+}
+
+// unregisterAttributeContributor removes the contributor with the name, if it's registered
+func unregisterAttributeContributor(name string) {
+	contributorsMu.Lock()
+	defer contributorsMu.Unlock()
+
+	contributors = slices.DeleteFunc(contributors, func(c namedContributor) bool { return c.name == name })
+}
+
+// contributeAttributes returns the attributes of the registered contributors. The errors of a contributor are
+// logged, without failing the conversion, as with the SCM attributes
+func contributeAttributes(ctx context.Context, cfg *config.Config) []attribute.KeyValue {
+	contributorsMu.RLock()
+	registered := slices.Clone(contributors)
+	contributorsMu.RUnlock()
+
+	attributes := []attribute.KeyValue{}
+	for _, c := range registered {
+		contributed, err := c.contributor.ContributeAttributes(ctx, cfg)
+		if err != nil {
+			slog.Warn("failed to contribute the attributes", "contributor", c.name, "error", err)
+			continue
+		}
+
+		attributes = append(attributes, contributed...)
+	}
+
+	return attributes
+}
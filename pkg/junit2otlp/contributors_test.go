@@ -0,0 +1,56 @@
+package junit2otlp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestRegisterAttributeContributor(t *testing.T) {
+	t.Run("The contributors run with the runtime attributes", func(t *testing.T) {
+		RegisterAttributeContributor("build-info", AttributeContributorFunc(func(ctx context.Context, cfg *Config) ([]attribute.KeyValue, error) {
+			return []attribute.KeyValue{attribute.String("build.number", "42"), attribute.String("team", "contributor")}, nil
+		}))
+		RegisterAttributeContributor("broken", AttributeContributorFunc(func(ctx context.Context, cfg *Config) ([]attribute.KeyValue, error) {
+			return []attribute.KeyValue{attribute.String("broken", "true")}, errors.New("boom")
+		}))
+		t.Cleanup(func() {
+			unregisterAttributeContributor("build-info")
+			unregisterAttributeContributor("broken")
+		})
+
+		cfg := config.NewConfigFromDefaults()
+		cfg.AttributePrefix = "ci."
+		cfg.AdditionalAttributes = map[string]string{"team": "platform"}
+
+		attributes := attributesToMap(resolveRuntimeAttributes(context.Background(), cfg))
+		require.Equal(t, "42", attributes["ci.build.number"])
+		// the additional attributes take precedence
+		require.Equal(t, "platform", attributes["ci.team"])
+		// the attributes of a failed contributor are discarded
+		require.NotContains(t, attributes, "ci.broken")
+	})
+
+	t.Run("Duplicated name", func(t *testing.T) {
+		contributor := AttributeContributorFunc(func(ctx context.Context, cfg *Config) ([]attribute.KeyValue, error) {
+			return nil, nil
+		})
+
+		RegisterAttributeContributor("duplicated", contributor)
+		t.Cleanup(func() { unregisterAttributeContributor("duplicated") })
+
+		require.PanicsWithValue(t, "junit2otlp: the attribute contributor duplicated is already registered", func() {
+			RegisterAttributeContributor("duplicated", contributor)
+		})
+	})
+
+	t.Run("Nil contributor", func(t *testing.T) {
+		require.Panics(t, func() {
+			RegisterAttributeContributor("nil", nil)
+		})
+	})
+}
@@ -0,0 +1,71 @@
+package junit2otlp
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/internal/formats"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+)
+
+// junitElementAttributes the attributes of the testsuite and testcase elements, which go-junit exposes
+// as properties, and must never be filtered out
+var junitElementAttributes = []string{"classname", "file", "line", "name", "time"}
+
+// Convert reads the report, applies the properties filters of the configuration, and writes it back
+// as a normalized JUnit XML report to the output of the configuration
+func Convert(cfg *config.Config, reader InputReader) error {
+	suites, _, err := readReport(cfg, reader)
+	if err != nil {
+		return err
+	}
+
+	suites = filterSuites(cfg, suites)
+
+	var w io.Writer = os.Stdout
+	if cfg.Output != "" && cfg.Output != inputStdin {
+		f, err := os.Create(cfg.Output)
+		if err != nil {
+			return fmt.Errorf("failed to create the output file: %w", err)
+		}
+		defer f.Close()
+
+		w = f
+	}
+
+	return formats.WriteJUnit(w, suites)
+}
+
+// filterSuites returns a copy of the suites, removing the properties not allowed or denied by the configuration
+func filterSuites(cfg *config.Config, suites []junit.Suite) []junit.Suite {
+	filtered := make([]junit.Suite, 0, len(suites))
+	for _, suite := range suites {
+		suite.Properties = filterProperties(cfg, suite.Properties)
+		suite.Suites = filterSuites(cfg, suite.Suites)
+
+		tests := make([]junit.Test, 0, len(suite.Tests))
+		for _, test := range suite.Tests {
+			test.Properties = filterProperties(cfg, test.Properties)
+			tests = append(tests, test)
+		}
+		suite.Tests = tests
+
+		filtered = append(filtered, suite)
+	}
+
+	return filtered
+}
+
+func filterProperties(cfg *config.Config, props map[string]string) map[string]string {
+	filtered := map[string]string{}
+	for k, v := range props {
+		if slices.Contains(junitElementAttributes, k) || isPropertyAllowed(cfg, k) {
+			filtered[k] = v
+		}
+	}
+
+	return filtered
+}
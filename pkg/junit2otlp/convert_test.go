@@ -0,0 +1,57 @@
+package junit2otlp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert(t *testing.T) {
+	cfg := config.NewConfigFromDefaults()
+	cfg.Output = filepath.Join(t.TempDir(), "TEST-converted.xml")
+	cfg.PropertiesDenied = []string{"go.version"}
+
+	err := Convert(cfg, &TestReader{testFile: "../../TEST-sample.xml"})
+	require.NoError(t, err)
+
+	expected, err := junit.IngestFile("../../TEST-sample.xml")
+	require.NoError(t, err)
+
+	converted, err := junit.IngestFile(cfg.Output)
+	require.NoError(t, err)
+	require.Len(t, converted, len(expected))
+
+	for _, suite := range converted {
+		require.NotContains(t, suite.Properties, "go.version")
+	}
+
+	b, err := os.ReadFile(cfg.Output)
+	require.NoError(t, err)
+	require.NotContains(t, string(b), `name="go.version"`)
+	require.Contains(t, string(b), "<testsuites ")
+}
+
+func TestFilterSuites(t *testing.T) {
+	cfg := config.NewConfigFromDefaults()
+	cfg.PropertiesAllowed = []string{"owner"}
+
+	suites := []junit.Suite{{
+		Name:       "suite",
+		Properties: map[string]string{"owner": "platform", "secret": "s3cr3t"},
+		Tests: []junit.Test{{
+			Name:       "test",
+			Properties: map[string]string{"name": "test", "time": "0.1", "secret": "s3cr3t"},
+		}},
+	}}
+
+	filtered := filterSuites(cfg, suites)
+	require.Equal(t, map[string]string{"owner": "platform"}, filtered[0].Properties)
+	require.Equal(t, map[string]string{"name": "test", "time": "0.1"}, filtered[0].Tests[0].Properties)
+
+	// the original suites are not modified
+	require.Contains(t, suites[0].Properties, "secret")
+}
@@ -0,0 +1,49 @@
+package junit2otlp
+
+import (
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/internal/coverage"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// loadCoverage parses the coverage file of the configuration, returning nil if it's not set
+func loadCoverage(cfg *config.Config) (*coverage.Report, error) {
+	if cfg.CoverageFile == "" {
+		return nil, nil
+	}
+
+	return coverage.ParseFile(cfg.CoverageFile)
+}
+
+// suiteCoverage returns the coverage of the package of the suite, looked up by the package of the suite, or by
+// its name for the reports without packages
+func suiteCoverage(report *coverage.Report, suite junit.Suite) (coverage.Package, bool) {
+	if report == nil {
+		return coverage.Package{}, false
+	}
+
+	for _, name := range []string{suite.Package, suite.Name} {
+		if pkg, ok := report.Lookup(name); ok {
+			return pkg, true
+		}
+	}
+
+	return coverage.Package{}, false
+}
+
+// coverageAttributes returns the attributes describing the line coverage of a package. The name is omitted for
+// the totals of the run
+func coverageAttributes(pkg coverage.Package) []attribute.KeyValue {
+	attributes := []attribute.KeyValue{
+		attribute.Key(TestsCoverageLinesCovered).Int(pkg.LinesCovered),
+		attribute.Key(TestsCoverageLinesValid).Int(pkg.LinesValid),
+		attribute.Key(TestsCoveragePercentage).Float64(pkg.Percentage()),
+	}
+
+	if pkg.Name != "" {
+		attributes = append(attributes, attribute.Key(TestsCoveragePackage).String(pkg.Name))
+	}
+
+	return attributes
+}
@@ -0,0 +1,68 @@
+package junit2otlp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/internal/coverage"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func Test_CreateTracesAndSpans_Coverage(t *testing.T) {
+	suites, err := junit.IngestFile("../../TEST-sample.xml")
+	require.NoError(t, err)
+
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	cfg := config.NewConfigFromDefaults()
+	cfg.RepositoryPath = t.TempDir()
+
+	report := &coverage.Report{Packages: []coverage.Package{
+		{Name: "github.com/elastic/e2e-testing/cli", LinesCovered: 1, LinesValid: 4},
+		{Name: "github.com/elastic/e2e-testing/cli/config", LinesCovered: 3, LinesValid: 4},
+	}}
+
+	_, err = createTracesAndSpans(context.Background(), cfg, "test-service", Providers{TracerProvider: tracerProvider, MeterProvider: meterProvider}, resolveRuntimeAttributes(context.Background(), cfg), report, sendSuites(suites))
+	require.NoError(t, err)
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+
+	root := spans[cfg.TraceName].Attributes()
+	require.Contains(t, root, attribute.Key(TestsCoveragePercentage).Float64(50))
+	require.Contains(t, root, attribute.Key(TestsCoverageLinesValid).Int(8))
+
+	// the nested package matches its own coverage, and the other packages the closest parent
+	cfgSuite := spans["github.com/elastic/e2e-testing/cli/config"].Attributes()
+	require.Contains(t, cfgSuite, attribute.Key(TestsCoveragePackage).String("github.com/elastic/e2e-testing/cli/config"))
+	require.Contains(t, cfgSuite, attribute.Key(TestsCoveragePercentage).Float64(75))
+
+	cmd := spans["github.com/elastic/e2e-testing/cli/cmd"].Attributes()
+	require.Contains(t, cmd, attribute.Key(TestsCoveragePackage).String("github.com/elastic/e2e-testing/cli"))
+	require.Contains(t, cmd, attribute.Key(TestsCoverageLinesCovered).Int(1))
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	var gauge metricdata.Gauge[float64]
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == TestsCoveragePercentage {
+				gauge = m.Data.(metricdata.Gauge[float64])
+			}
+		}
+	}
+	require.Len(t, gauge.DataPoints, len(suites))
+}
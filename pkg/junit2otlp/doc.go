@@ -0,0 +1,18 @@
+// Package junit2otlp converts test reports, such as JUnit XML, into OpenTelemetry traces, metrics and logs,
+// so test frameworks and tools can embed the conversion instead of running the junit2otlp binary.
+//
+// Run converts the reports with the providers of the caller, which can be shared by concurrent conversions,
+// while Export creates the OTLP providers from the configuration, as the command line does:
+//
+//	cfg := junit2otlp.NewConfig()
+//	cfg.ServiceName = "my-service"
+//
+//	err := junit2otlp.Export(ctx, cfg, junit2otlp.NewFileReader("TEST-report.xml"))
+//
+// The reports already in memory, i.e. produced by the tool embedding the conversion, are read with
+// NewBytesReader, and the attributes of the run are extended with RegisterAttributeContributor.
+//
+// The package follows semantic versioning: the exported identifiers are not removed nor changed in an
+// incompatible way within a major version. The configuration may get new fields, with defaults keeping
+// the previous behaviour.
+package junit2otlp
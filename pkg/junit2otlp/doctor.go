@@ -0,0 +1,237 @@
+package junit2otlp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// doctorTimeout maximum time of each of the network checks of the doctor command
+const doctorTimeout = 10 * time.Second
+
+// doctorProbeSpan name of the span sent by the doctor command to check the endpoint accepts the spans
+const doctorProbeSpan = "junit2otlp.doctor"
+
+// doctor prints the result of each check, with a hint to fix the failed ones
+type doctor struct {
+	w      io.Writer
+	failed int
+}
+
+func (d *doctor) ok(check string, format string, args ...any) {
+	fmt.Fprintf(d.w, "[OK]   %s: %s\n", check, fmt.Sprintf(format, args...))
+}
+
+func (d *doctor) fail(check string, err error, hint string) {
+	d.failed++
+	fmt.Fprintf(d.w, "[FAIL] %s: %v\n", check, err)
+	if hint != "" {
+		fmt.Fprintf(d.w, "       %s\n", hint)
+	}
+}
+
+// Doctor checks the OTLP exporters of the configuration without reading any report, printing a diagnostic per
+// check to the writer: the effective settings, the connection to the endpoint, the TLS handshake and a probe span
+// sent to the endpoint. It returns an error if any check fails
+func Doctor(ctx context.Context, cfg *config.Config, w io.Writer) error {
+	d := &doctor{w: w}
+
+	d.ok("service", "%s %s", getOtlpServiceName(cfg), getOtlpServiceVersion(cfg))
+
+	tracesOTLP := true
+	for _, envVarKey := range []string{"OTEL_TRACES_EXPORTER", "OTEL_METRICS_EXPORTER", "OTEL_LOGS_EXPORTER"} {
+		selected, err := signalExporter(cfg, envVarKey)
+		if err != nil {
+			d.fail("exporters", err, "unset it, or set it to otlp")
+			continue
+		}
+
+		d.ok("exporters", "%s=%s", envVarKey, selected)
+		if envVarKey == "OTEL_TRACES_EXPORTER" && selected != exporterOTLP {
+			tracesOTLP = false
+		}
+	}
+
+	if !tracesOTLP {
+		d.ok("endpoint", "the spans are not sent with OTLP, skipping the connection checks")
+		return d.result()
+	}
+
+	for _, envVar := range signalExporterEnvVars {
+		if os.Getenv(envVar) != "" {
+			d.ok("endpoint", "%s is set, so each exporter opens its own connection. The checks below use the shared endpoint, but the probe span honours it", envVar)
+			break
+		}
+	}
+
+	protocol, err := otlpProtocol(cfg, signalTraces)
+	if err != nil {
+		d.fail("protocol", err, "unset it, or set it to grpc or http/protobuf")
+		return d.result()
+	}
+	d.ok("protocol", "%s", protocol)
+
+	defaultEndpoint := defaultCollectorGRPCEndpoint
+	if protocol == protocolHTTPProtobuf {
+		defaultEndpoint = defaultCollectorHTTPEndpoint
+	}
+
+	target, plaintext, ok := otlpTarget(cfg, defaultEndpoint)
+	if !ok {
+		d.fail("endpoint", fmt.Errorf("invalid endpoint: %s", getOtlpEnvVar(cfg.Exporter.Endpoint, "OTEL_EXPORTER_OTLP_ENDPOINT", "")), "use a URL with scheme, i.e. https://collector:4317, or http://collector:4317 for a plaintext collector")
+		return d.result()
+	}
+
+	security := "TLS"
+	if plaintext {
+		security = "plaintext"
+	}
+	d.ok("endpoint", "%s (%s)", target, security)
+
+	headers := cfg.Exporter.Headers
+	if len(headers) == 0 {
+		headers = parseHeadersEnv(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"))
+	}
+	if len(headers) > 0 {
+		// the values are never printed, as they are usually credentials
+		d.ok("headers", "%s", strings.Join(slices.Sorted(maps.Keys(headers)), ", "))
+	}
+
+	conn, err := net.DialTimeout("tcp", target, doctorTimeout)
+	if err != nil {
+		d.fail("connection", err, "check the host and the port of the endpoint, and that the collector is reachable from this machine")
+		return d.result()
+	}
+	conn.Close()
+	d.ok("connection", "connected to %s", target)
+
+	if !plaintext {
+		d.checkTLS(target)
+	}
+
+	d.sendProbe(ctx, cfg)
+
+	return d.result()
+}
+
+// checkTLS checks the TLS handshake with the endpoint, verifying its certificate
+func (d *doctor) checkTLS(target string) {
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: doctorTimeout}, "tcp", target, &tls.Config{})
+	if err != nil {
+		d.fail("tls", err, "if the collector does not use TLS, use an http:// endpoint or --otlp-insecure. Otherwise, check its certificate is trusted by this machine")
+		return
+	}
+	defer conn.Close()
+
+	state := conn.ConnectionState()
+	if len(state.PeerCertificates) > 0 {
+		cert := state.PeerCertificates[0]
+		d.ok("tls", "%s, certificate for %s valid until %s", tls.VersionName(state.Version), cert.Subject.CommonName, cert.NotAfter.Format(time.DateOnly))
+		return
+	}
+
+	d.ok("tls", "%s", tls.VersionName(state.Version))
+}
+
+// sendProbe sends a span to the endpoint, without retries, as the exporter of the spans does
+func (d *doctor) sendProbe(ctx context.Context, cfg *config.Config) {
+	var client otlptrace.Client
+	if protocol, _ := otlpProtocol(cfg, signalTraces); protocol == protocolHTTPProtobuf {
+		opts := append(traceHTTPExporterOptions(cfg), otlptracehttp.WithTimeout(doctorTimeout), otlptracehttp.WithRetry(otlptracehttp.RetryConfig{Enabled: false}))
+		client = otlptracehttp.NewClient(opts...)
+	} else {
+		opts := append(traceExporterOptions(cfg, nil), otlptracegrpc.WithTimeout(doctorTimeout), otlptracegrpc.WithRetry(otlptracegrpc.RetryConfig{Enabled: false}))
+		client = otlptracegrpc.NewClient(opts...)
+	}
+	if err := client.Start(ctx); err != nil {
+		d.fail("probe span", err, "")
+		return
+	}
+	defer client.Stop(ctx)
+
+	traceID := make([]byte, 16)
+	spanID := make([]byte, 8)
+	rand.Read(traceID)
+	rand.Read(spanID)
+
+	now := uint64(time.Now().UnixNano())
+	probe := []*tracepb.ResourceSpans{{
+		Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{{
+			Key:   "service.name",
+			Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: getOtlpServiceName(cfg)}},
+		}}},
+		ScopeSpans: []*tracepb.ScopeSpans{{
+			Scope: &commonpb.InstrumentationScope{Name: Junit2otlp},
+			Spans: []*tracepb.Span{{
+				TraceId:           traceID,
+				SpanId:            spanID,
+				Name:              doctorProbeSpan,
+				StartTimeUnixNano: now,
+				EndTimeUnixNano:   now,
+			}},
+		}},
+	}}
+
+	if err := client.UploadTraces(ctx, probe); err != nil {
+		d.fail("probe span", err, probeHint(err))
+		return
+	}
+
+	d.ok("probe span", "sent the %s span, in the trace %x", doctorProbeSpan, traceID)
+}
+
+// probeHint returns the actionable hint for the gRPC status of a failed probe span
+func probeHint(err error) string {
+	s, _ := status.FromError(err)
+
+	switch s.Code() {
+	case codes.Unauthenticated, codes.PermissionDenied:
+		return "check the authorization headers, in --otlp-headers or OTEL_EXPORTER_OTLP_HEADERS"
+	case codes.Unavailable:
+		return "the collector is not reachable, or the TLS settings do not match: use an http:// endpoint or --otlp-insecure for a plaintext collector"
+	case codes.DeadlineExceeded:
+		return "the collector did not answer in time: check the TLS settings, as a TLS client of a plaintext collector hangs"
+	case codes.Unimplemented:
+		return "the endpoint is not an OTLP gRPC receiver, i.e. it's the OTLP/HTTP port, usually 4318, instead of 4317"
+	default:
+		return ""
+	}
+}
+
+// parseHeadersEnv parses the key=value pairs of the OTEL_EXPORTER_OTLP_HEADERS environment variable
+func parseHeadersEnv(value string) map[string]string {
+	headers := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		if k, v, ok := strings.Cut(pair, "="); ok && strings.TrimSpace(k) != "" {
+			headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+
+	return headers
+}
+
+func (d *doctor) result() error {
+	if d.failed > 0 {
+		return fmt.Errorf("%d checks failed", d.failed)
+	}
+
+	return nil
+}
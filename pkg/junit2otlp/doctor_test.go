@@ -0,0 +1,70 @@
+package junit2otlp
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"github.com/stretchr/testify/require"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDoctor(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "")
+	t.Setenv("OTEL_TRACES_EXPORTER", "")
+
+	t.Run("Sends the probe span", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		collector := &testTraceCollector{}
+		server := grpc.NewServer()
+		coltracepb.RegisterTraceServiceServer(server, collector)
+		go server.Serve(listener)
+		defer server.Stop()
+
+		cfg := config.NewConfigFromDefaults()
+		cfg.Exporter.Endpoint = "http://" + listener.Addr().String()
+		cfg.Exporter.Headers = map[string]string{"authorization": "Bearer secret"}
+
+		var out bytes.Buffer
+		require.NoError(t, Doctor(context.Background(), cfg, &out))
+		require.Equal(t, []string{doctorProbeSpan}, collector.spans)
+		require.Contains(t, out.String(), "[OK]   headers: authorization")
+		require.NotContains(t, out.String(), "secret")
+	})
+
+	t.Run("Unreachable collector", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		endpoint := listener.Addr().String()
+		listener.Close()
+
+		cfg := config.NewConfigFromDefaults()
+		cfg.Exporter.Endpoint = "http://" + endpoint
+
+		var out bytes.Buffer
+		require.Error(t, Doctor(context.Background(), cfg, &out))
+		require.Contains(t, out.String(), "[FAIL] connection")
+	})
+
+	t.Run("Exporter not OTLP", func(t *testing.T) {
+		t.Setenv("OTEL_TRACES_EXPORTER", "console")
+
+		var out bytes.Buffer
+		require.NoError(t, Doctor(context.Background(), config.NewConfigFromDefaults(), &out))
+		require.Contains(t, out.String(), "skipping the connection checks")
+	})
+}
+
+func TestProbeHint(t *testing.T) {
+	require.Contains(t, probeHint(status.Error(codes.Unauthenticated, "missing token")), "authorization headers")
+	require.Contains(t, probeHint(status.Error(codes.Unimplemented, "unknown service")), "OTLP/HTTP")
+	require.Empty(t, probeHint(status.Error(codes.Internal, "boom")))
+}
@@ -0,0 +1,142 @@
+package junit2otlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// elasticsearchAPIKeyEnv environment variable with the API key sent to Elasticsearch or OpenSearch
+	elasticsearchAPIKeyEnv = "ELASTICSEARCH_API_KEY"
+
+	// elasticsearchBulkSize maximum number of documents sent in a bulk request
+	elasticsearchBulkSize = 1000
+
+	// defaultElasticsearchTimeout the maximum time to wait for a bulk request
+	defaultElasticsearchTimeout = 30 * time.Second
+)
+
+// bulkResponse the fields of the response of the bulk API needed to report the documents which were not indexed
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+		Error  struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+// writeElasticsearch indexes a document per test case in the index of the configuration, with the bulk API of
+// Elasticsearch or OpenSearch. The documents have the same attributes as the spans of the test cases, and the ID
+// of the trace to correlate them. The outputs are never split in log records, as the documents have no size limit
+func writeElasticsearch(ctx context.Context, cfg *config.Config, runtimeAttributes []attribute.KeyValue, suites []junit.Suite, traceID trace.TraceID) error {
+	docCfg := *cfg
+	docCfg.MaxOutputSize = 0
+
+	timestamp := time.Now().UTC().Format(time.RFC3339Nano)
+
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	action := map[string]interface{}{"create": map[string]string{"_index": cfg.ElasticsearchIndex}}
+
+	documents := 0
+	for _, suite := range suites {
+		sharedAttributes := getSharedSuiteAttributes(&docCfg, getSuiteAttributes(&docCfg, suite, runtimeAttributes))
+
+		for _, test := range suite.Tests {
+			doc := attributesToMap(getTestAttributes(&docCfg, test, sharedAttributes))
+			doc["@timestamp"] = timestamp
+			if traceID.IsValid() {
+				doc["trace.id"] = traceID.String()
+			}
+
+			if err := encoder.Encode(action); err != nil {
+				return err
+			}
+			if err := encoder.Encode(doc); err != nil {
+				return fmt.Errorf("failed to marshal the document of the test case %s: %w", test.Name, err)
+			}
+
+			documents++
+			if documents%elasticsearchBulkSize == 0 {
+				if err := postBulk(ctx, cfg.ElasticsearchURL, &body); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if body.Len() > 0 {
+		if err := postBulk(ctx, cfg.ElasticsearchURL, &body); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// postBulk sends the body to the bulk API at the URL, resetting it, and returns an error if any document was
+// not indexed. The credentials are read from the URL, as basic auth, or from the ELASTICSEARCH_API_KEY variable
+func postBulk(ctx context.Context, url string, body *bytes.Buffer) error {
+	defer body.Reset()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(url, "/")+"/_bulk", bytes.NewReader(body.Bytes()))
+	if err != nil {
+		return fmt.Errorf("failed to create the bulk request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if apiKey := os.Getenv(elasticsearchAPIKeyEnv); apiKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+apiKey)
+	}
+
+	client := &http.Client{Timeout: defaultElasticsearchTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send the bulk request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status code sending the bulk request: %d: %s", resp.StatusCode, b)
+	}
+
+	var bulk bulkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&bulk); err != nil {
+		return fmt.Errorf("failed to decode the bulk response: %w", err)
+	}
+
+	if !bulk.Errors {
+		return nil
+	}
+
+	failed := 0
+	reason := ""
+	for _, item := range bulk.Items {
+		for _, result := range item {
+			if result.Status >= 300 {
+				failed++
+				if reason == "" {
+					reason = result.Error.Type + ": " + result.Error.Reason
+				}
+			}
+		}
+	}
+
+	return fmt.Errorf("failed to index %d documents: %s", failed, reason)
+}
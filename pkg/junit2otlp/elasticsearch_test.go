@@ -0,0 +1,111 @@
+package junit2otlp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// bulkServer returns a server for the bulk API, recording the documents and the headers of the requests,
+// and answering with the response
+func bulkServer(t *testing.T, response string, docs *[]map[string]interface{}, headers *http.Header) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/_bulk", r.URL.Path)
+		*headers = r.Header.Clone()
+
+		scanner := bufio.NewScanner(r.Body)
+		for i := 0; scanner.Scan(); i++ {
+			// the action lines are not recorded
+			if i%2 == 0 {
+				continue
+			}
+
+			doc := map[string]interface{}{}
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &doc))
+			*docs = append(*docs, doc)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestWriteElasticsearch(t *testing.T) {
+	suites := []junit.Suite{
+		{
+			Name: "suite",
+			Tests: []junit.Test{
+				{Name: "passed", Classname: "pkg.Class", Status: junit.StatusPassed},
+				{Name: "failed", Classname: "pkg.Class", Status: junit.StatusFailed, Message: "boom"},
+			},
+		},
+	}
+	runtimeAttributes := []attribute.KeyValue{attribute.String("host.arch", "amd64")}
+	traceID := trace.TraceID{0x01}
+
+	t.Run("A document per test case", func(t *testing.T) {
+		t.Setenv(elasticsearchAPIKeyEnv, "secret")
+
+		docs := []map[string]interface{}{}
+		headers := http.Header{}
+		server := bulkServer(t, `{"errors":false,"items":[]}`, &docs, &headers)
+
+		cfg := config.NewConfigFromDefaults()
+		cfg.ElasticsearchURL = server.URL + "/"
+
+		err := writeElasticsearch(context.Background(), cfg, runtimeAttributes, suites, traceID)
+		require.NoError(t, err)
+
+		require.Equal(t, "ApiKey secret", headers.Get("Authorization"))
+		require.Equal(t, "application/x-ndjson", headers.Get("Content-Type"))
+
+		require.Len(t, docs, 2)
+		require.Equal(t, "failed", docs[1]["code.function"])
+		require.Equal(t, "boom", docs[1][TestMessage])
+		require.Equal(t, string(junit.StatusFailed), docs[1][TestStatus])
+		require.Equal(t, "suite", docs[1][TestsSuiteName])
+		require.Equal(t, "amd64", docs[1]["host.arch"])
+		require.Equal(t, traceID.String(), docs[1]["trace.id"])
+		require.NotEmpty(t, docs[1]["@timestamp"])
+	})
+
+	t.Run("Documents not indexed", func(t *testing.T) {
+		docs := []map[string]interface{}{}
+		headers := http.Header{}
+		server := bulkServer(t, `{"errors":true,"items":[
+			{"create":{"status":201}},
+			{"create":{"status":400,"error":{"type":"mapper_parsing_exception","reason":"failed to parse"}}}
+		]}`, &docs, &headers)
+
+		cfg := config.NewConfigFromDefaults()
+		cfg.ElasticsearchURL = server.URL
+
+		err := writeElasticsearch(context.Background(), cfg, runtimeAttributes, suites, traceID)
+		require.EqualError(t, err, "failed to index 1 documents: mapper_parsing_exception: failed to parse")
+	})
+
+	t.Run("Unexpected status code", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		cfg := config.NewConfigFromDefaults()
+		cfg.ElasticsearchURL = server.URL
+
+		err := writeElasticsearch(context.Background(), cfg, runtimeAttributes, suites, traceID)
+		require.ErrorContains(t, err, "unexpected status code sending the bulk request: 401")
+	})
+}
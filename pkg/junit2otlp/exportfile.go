@@ -0,0 +1,165 @@
+package junit2otlp
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mdelapenya/junit2otlp/internal/parquet"
+)
+
+// exportFileColumns the columns of the export file before the attributes, in order
+var exportFileColumns = []parquet.Column{
+	{Name: "timestamp", Type: parquet.Timestamp},
+	{Name: "trace_id", Type: parquet.String},
+	{Name: "suite", Type: parquet.String},
+	{Name: "classname", Type: parquet.String},
+	{Name: "name", Type: parquet.String},
+	{Name: "status", Type: parquet.String},
+	{Name: "message", Type: parquet.String},
+	{Name: "duration_ms", Type: parquet.Int64},
+}
+
+// validateExportFile returns an error if the format of the export file cannot be inferred from its extension
+func validateExportFile(path string) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv", ".parquet":
+		return nil
+	default:
+		return fmt.Errorf("invalid export file: %s. Supported extensions: .csv, .parquet", path)
+	}
+}
+
+// writeExportFile writes the rows as a flat file, with a row per test case and a column per attribute, in the
+// format of the extension of the path: CSV or Parquet. The attributes which are not set for a test case are
+// empty, or null in Parquet, and the values which are not strings are written as in JSON. The attributes named
+// after the columns before them, i.e. the classname and name of the test case, are already written there
+func writeExportFile(path string, rows []testRow) error {
+	if err := validateExportFile(path); err != nil {
+		return err
+	}
+
+	set := map[string]bool{}
+	for _, row := range rows {
+		for key := range row.Attributes {
+			set[key] = true
+		}
+	}
+	for _, column := range exportFileColumns {
+		delete(set, column.Name)
+	}
+	keys := slices.Sorted(maps.Keys(set))
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create the export file: %w", err)
+	}
+	defer f.Close()
+
+	if strings.ToLower(filepath.Ext(path)) == ".parquet" {
+		err = writeParquet(f, keys, rows)
+	} else {
+		err = writeCSV(f, keys, rows)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write the export file: %w", err)
+	}
+
+	return f.Close()
+}
+
+func writeCSV(f *os.File, keys []string, rows []testRow) error {
+	w := csv.NewWriter(f)
+
+	header := []string{}
+	for _, column := range exportFileColumns {
+		header = append(header, column.Name)
+	}
+	if err := w.Write(append(header, keys...)); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		record := []string{
+			row.Timestamp.Format(time.RFC3339Nano),
+			row.TraceID,
+			row.Suite,
+			row.Classname,
+			row.Name,
+			row.Status,
+			row.Message,
+			strconv.FormatInt(row.DurationMs, 10),
+		}
+
+		for _, key := range keys {
+			value, ok := row.Attributes[key]
+			if !ok {
+				record = append(record, "")
+				continue
+			}
+			record = append(record, attributeString(value))
+		}
+
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func writeParquet(f *os.File, keys []string, rows []testRow) error {
+	columns := slices.Clone(exportFileColumns)
+	for _, key := range keys {
+		columns = append(columns, parquet.Column{Name: key, Type: parquet.String, Optional: true})
+	}
+
+	values := make([][]interface{}, 0, len(rows))
+	for _, row := range rows {
+		record := []interface{}{
+			row.Timestamp,
+			row.TraceID,
+			row.Suite,
+			row.Classname,
+			row.Name,
+			row.Status,
+			row.Message,
+			row.DurationMs,
+		}
+
+		for _, key := range keys {
+			value, ok := row.Attributes[key]
+			if !ok {
+				record = append(record, nil)
+				continue
+			}
+			record = append(record, attributeString(value))
+		}
+
+		values = append(values, record)
+	}
+
+	return parquet.Write(f, columns, values)
+}
+
+// attributeString returns the strings as they are, and the rest of the values of the attributes as JSON
+func attributeString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(b)
+}
@@ -0,0 +1,83 @@
+package junit2otlp
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+
+	parquetgo "github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteExportFile(t *testing.T) {
+	t.Run("CSV", func(t *testing.T) {
+		rows := testRows()
+		rows[0].Attributes["retries"] = int64(2)
+		rows[0].Attributes["tags"] = []string{"slow", "db"}
+
+		path := filepath.Join(t.TempDir(), "results.csv")
+		require.NoError(t, writeExportFile(path, rows))
+
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		defer f.Close()
+
+		records, err := csv.NewReader(f).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+
+		header := records[0]
+		require.Equal(t, []string{"timestamp", "trace_id", "suite", "classname", "name", "status", "message", "duration_ms"}, header[:8])
+
+		column := func(name string) int {
+			for i, h := range header {
+				if h == name {
+					return i
+				}
+			}
+			t.Fatalf("missing column %s", name)
+			return -1
+		}
+
+		require.Equal(t, "2024-05-06T10:00:00Z", records[1][column("timestamp")])
+		require.Equal(t, "1500", records[1][column("duration_ms")])
+		require.Equal(t, "amd64", records[1][column("host.arch")])
+		require.Equal(t, "2", records[1][column("retries")])
+		require.Equal(t, `["slow","db"]`, records[1][column("tags")])
+		require.Equal(t, "", records[2][column("retries")])
+		require.Equal(t, "boom", records[2][column("message")])
+	})
+
+	t.Run("Parquet", func(t *testing.T) {
+		rows := testRows()
+		// the attributes of the test cases named after the columns, as the ones of the legacy schema
+		rows[0].Attributes["classname"] = rows[0].Classname
+		rows[0].Attributes["name"] = rows[0].Name
+
+		path := filepath.Join(t.TempDir(), "results.parquet")
+		require.NoError(t, writeExportFile(path, rows))
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		file, err := parquetgo.OpenFile(bytes.NewReader(data), int64(len(data)))
+		require.NoError(t, err)
+		require.Equal(t, int64(len(rows)), file.NumRows())
+
+		names := []string{}
+		for _, path := range file.Schema().Columns() {
+			names = append(names, path[0])
+		}
+		require.Equal(t, []string{"timestamp", "trace_id", "suite", "classname", "name", "status", "message", "duration_ms"}, names[:8])
+		require.Contains(t, names, "host.arch")
+		require.NotContains(t, names[8:], "classname")
+		require.NotContains(t, names[8:], "name")
+	})
+
+	t.Run("Unsupported extension", func(t *testing.T) {
+		err := writeExportFile(filepath.Join(t.TempDir(), "results.xlsx"), testRows())
+		require.ErrorContains(t, err, "invalid export file")
+	})
+}
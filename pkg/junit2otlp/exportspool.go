@@ -0,0 +1,255 @@
+package junit2otlp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// spooledTracesPattern the pattern of the files of the spooled spans, each one an OTLP export request
+const spooledTracesPattern = "traces-*.otlp"
+
+// spoolingTraceClient uploads the spans with the OTLP client, writing them to the spool directory when the upload
+// fails after the retries of the client, so an unreachable collector does not lose them. They are resent later
+// with the flush command
+type spoolingTraceClient struct {
+	otlptrace.Client
+	dir string
+}
+
+// UploadTraces uploads the spans, spooling them if the upload fails. It only fails if the spans cannot be spooled
+func (c *spoolingTraceClient) UploadTraces(ctx context.Context, protoSpans []*tracepb.ResourceSpans) error {
+	err := c.Client.UploadTraces(ctx, protoSpans)
+	if err == nil {
+		return nil
+	}
+
+	path, spoolErr := spoolTraces(c.dir, protoSpans)
+	if spoolErr != nil {
+		return errors.Join(err, fmt.Errorf("failed to spool the spans: %w", spoolErr))
+	}
+
+	slog.Warn("failed to export the spans, spooled to disk to be sent with the flush command", "path", path, "error", err)
+	return nil
+}
+
+// spoolTraces writes the spans to a new file in the directory, as an OTLP export request, returning its path
+func spoolTraces(dir string, protoSpans []*tracepb.ResourceSpans) (string, error) {
+	data, err := proto.Marshal(&coltracepb.ExportTraceServiceRequest{ResourceSpans: protoSpans})
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	f, err := os.CreateTemp(dir, spooledTracesPattern)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+
+	return f.Name(), f.Close()
+}
+
+// lostSignals records the signals failing to be exported when the spool directory is set. Only the spans are
+// spooled, so the metrics and the log records failing to be exported are lost, and the run fails once the providers
+// are shut down, instead of succeeding with the spans spooled
+type lostSignals struct {
+	mu   sync.Mutex
+	errs map[string]error
+}
+
+// record records the first error exporting the signal, warning that its telemetry is lost
+func (l *lostSignals) record(signal string, err error) {
+	slog.Error("failed to export the "+signal+", which are lost as only the spans are spooled", "error", err)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.errs == nil {
+		l.errs = map[string]error{}
+	}
+	if _, ok := l.errs[signal]; !ok {
+		l.errs[signal] = err
+	}
+}
+
+// err returns the error of the lost signals, or nil if all of them were exported
+func (l *lostSignals) err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	errs := []error{}
+	for _, signal := range []string{"metrics", "log records"} {
+		if err, ok := l.errs[signal]; ok {
+			errs = append(errs, fmt.Errorf("the %s of the run were lost, as only the spans are spooled: %w", signal, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// lossRecordingMetricExporter exports the metrics with the exporter, recording them as lost if the export fails
+type lossRecordingMetricExporter struct {
+	sdkmetric.Exporter
+	lost *lostSignals
+}
+
+// Export exports the metrics, recording them as lost if the export fails
+func (e *lossRecordingMetricExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	err := e.Exporter.Export(ctx, rm)
+	if err != nil {
+		e.lost.record("metrics", err)
+	}
+
+	return err
+}
+
+// lossRecordingLogExporter exports the log records with the exporter, recording them as lost if the export fails
+type lossRecordingLogExporter struct {
+	sdklog.Exporter
+	lost *lostSignals
+}
+
+// Export exports the log records, recording them as lost if the export fails
+func (e *lossRecordingLogExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	err := e.Exporter.Export(ctx, records)
+	if err != nil {
+		e.lost.record("log records", err)
+	}
+
+	return err
+}
+
+// Flush resends the spans spooled to the export-spool-dir of the configuration, with the OTLP settings of the
+// configuration, removing each file once it's sent. It stops at the first failure, keeping the files not sent
+func Flush(ctx context.Context, cfg *config.Config) error {
+	if cfg.ExportSpoolDir == "" {
+		return fmt.Errorf("the export spool is not configured: set the export-spool-dir")
+	}
+
+	paths, err := filepath.Glob(filepath.Join(cfg.ExportSpoolDir, spooledTracesPattern))
+	if err != nil {
+		return err
+	}
+
+	if len(paths) == 0 {
+		slog.Info("there are no spooled spans to send", "dir", cfg.ExportSpoolDir)
+		return nil
+	}
+
+	conn, err := newGRPCConn(cfg)
+	if err != nil {
+		return err
+	}
+	if conn != nil {
+		defer conn.Close()
+	}
+
+	client, err := newTraceClient(cfg, conn)
+	if err != nil {
+		return err
+	}
+	if err := client.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start the traces client: %w", err)
+	}
+	defer client.Stop(ctx)
+
+	for i, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		var request coltracepb.ExportTraceServiceRequest
+		if err := proto.Unmarshal(data, &request); err != nil {
+			return fmt.Errorf("failed to read the spooled spans of %s: %w", path, err)
+		}
+
+		if err := client.UploadTraces(ctx, request.ResourceSpans); err != nil {
+			return fmt.Errorf("failed to send the spooled spans, %d files left: %w", len(paths)-i, err)
+		}
+
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("spooled spans sent", "files", len(paths))
+	return nil
+}
+
+// fileTraceClient writes the spans to a file as OTLP JSON, an export request per line, as the file exporter of the
+// OpenTelemetry Collector does, so they can be inspected, or replayed with its otlpjsonfile receiver
+type fileTraceClient struct {
+	path string
+	mu   sync.Mutex
+	f    *os.File
+}
+
+// Start creates the file, truncating it if it exists
+func (c *fileTraceClient) Start(context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f, err := os.Create(c.path)
+	if err != nil {
+		return fmt.Errorf("failed to create the exporter file: %w", err)
+	}
+	c.f = f
+
+	return nil
+}
+
+// Stop closes the file
+func (c *fileTraceClient) Stop(context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.f == nil {
+		return nil
+	}
+
+	err := c.f.Close()
+	c.f = nil
+	return err
+}
+
+// UploadTraces appends the spans to the file, as a line with an OTLP export request
+func (c *fileTraceClient) UploadTraces(_ context.Context, protoSpans []*tracepb.ResourceSpans) error {
+	data, err := protojson.Marshal(&coltracepb.ExportTraceServiceRequest{ResourceSpans: protoSpans})
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.f == nil {
+		return fmt.Errorf("the exporter file %s is closed", c.path)
+	}
+
+	_, err = c.f.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,139 @@
+package junit2otlp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutlog"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc"
+)
+
+// failingTraceClient an OTLP client whose uploads always fail, as if the collector was unreachable
+type failingTraceClient struct{}
+
+func (failingTraceClient) Start(context.Context) error { return nil }
+func (failingTraceClient) Stop(context.Context) error  { return nil }
+func (failingTraceClient) UploadTraces(context.Context, []*tracepb.ResourceSpans) error {
+	return errors.New("connection refused")
+}
+
+// testTraceCollector an OTLP gRPC receiver counting the received spans
+type testTraceCollector struct {
+	coltracepb.UnimplementedTraceServiceServer
+	mu    sync.Mutex
+	spans []string
+}
+
+func (c *testTraceCollector) Export(_ context.Context, request *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, rs := range request.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			for _, span := range ss.Spans {
+				c.spans = append(c.spans, span.Name)
+			}
+		}
+	}
+
+	return &coltracepb.ExportTraceServiceResponse{}, nil
+}
+
+func TestExportSpool(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "spool")
+
+	client := &spoolingTraceClient{Client: failingTraceClient{}, dir: dir}
+	resourceSpans := []*tracepb.ResourceSpans{{
+		ScopeSpans: []*tracepb.ScopeSpans{{
+			Spans: []*tracepb.Span{
+				{Name: "suite", TraceId: make([]byte, 16), SpanId: make([]byte, 8)},
+				{Name: "test", TraceId: make([]byte, 16), SpanId: make([]byte, 8)},
+			},
+		}},
+	}}
+
+	t.Run("Spools the failed uploads", func(t *testing.T) {
+		require.NoError(t, client.UploadTraces(context.Background(), resourceSpans))
+
+		paths, err := filepath.Glob(filepath.Join(dir, spooledTracesPattern))
+		require.NoError(t, err)
+		require.Len(t, paths, 1)
+	})
+
+	t.Run("Flush", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		collector := &testTraceCollector{}
+		server := grpc.NewServer()
+		coltracepb.RegisterTraceServiceServer(server, collector)
+		go server.Serve(listener)
+		defer server.Stop()
+
+		cfg := config.NewConfigFromDefaults()
+		cfg.ExportSpoolDir = dir
+		cfg.Exporter.Endpoint = "http://" + listener.Addr().String()
+		cfg.Exporter.Insecure = true
+
+		require.NoError(t, Flush(context.Background(), cfg))
+		require.Equal(t, []string{"suite", "test"}, collector.spans)
+
+		paths, err := filepath.Glob(filepath.Join(dir, spooledTracesPattern))
+		require.NoError(t, err)
+		require.Empty(t, paths)
+
+		// nothing left to send
+		require.NoError(t, Flush(context.Background(), cfg))
+	})
+
+	t.Run("Not configured", func(t *testing.T) {
+		require.ErrorContains(t, Flush(context.Background(), config.NewConfigFromDefaults()), "set the export-spool-dir")
+	})
+}
+
+// failingWriter a writer always failing, as the stdout exporters writing to a closed pipe
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("broken pipe") }
+
+func TestLostSignals(t *testing.T) {
+	t.Run("Nothing lost", func(t *testing.T) {
+		lost := &lostSignals{}
+		require.NoError(t, lost.err())
+	})
+
+	t.Run("Metrics lost", func(t *testing.T) {
+		metricExporter, err := stdoutmetric.New(stdoutmetric.WithWriter(failingWriter{}))
+		require.NoError(t, err)
+
+		lost := &lostSignals{}
+		exporter := &lossRecordingMetricExporter{Exporter: metricExporter, lost: lost}
+		require.Error(t, exporter.Export(context.Background(), &metricdata.ResourceMetrics{}))
+
+		require.ErrorContains(t, lost.err(), "the metrics of the run were lost")
+		require.NotContains(t, lost.err().Error(), "log records")
+	})
+
+	t.Run("Log records lost", func(t *testing.T) {
+		logExporter, err := stdoutlog.New(stdoutlog.WithWriter(failingWriter{}))
+		require.NoError(t, err)
+
+		lost := &lostSignals{}
+		exporter := &lossRecordingLogExporter{Exporter: logExporter, lost: lost}
+		require.Error(t, exporter.Export(context.Background(), []sdklog.Record{{}}))
+		require.Error(t, exporter.Export(context.Background(), []sdklog.Record{{}}))
+
+		require.EqualError(t, lost.err(), "the log records of the run were lost, as only the spans are spooled: broken pipe")
+	})
+}
@@ -0,0 +1,68 @@
+package junit2otlp
+
+import (
+	"strconv"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/internal/formats"
+)
+
+// flakyTests returns the test cases of the suite rerun after failing, i.e. by the rerunFailingTestsCount of
+// Surefire or by pytest-rerunfailures, which report each run as a test case with the same classname and name, and
+// the number of reruns of the suite. The reruns are the runs of a test case after the first one, if any of them
+// failed or errored, as the test cases repeated without failures are parameterized ones. A rerun test case is
+// flaky if it also passed, and all of its runs are flagged. The reruns reported by Surefire in a single test case,
+// as flakyFailure, flakyError, rerunFailure or rerunError elements, are counted too, the test case being flaky if it
+// passed in the end
+func flakyTests(suite junit.Suite) (map[int]bool, int) {
+	type outcomes struct {
+		runs   []int
+		reruns int
+		failed bool
+		passed bool
+	}
+
+	byTest := map[[2]string]*outcomes{}
+	order := [][2]string{}
+	for i, test := range suite.Tests {
+		key := [2]string{test.Classname, test.Name}
+		o, ok := byTest[key]
+		if !ok {
+			o = &outcomes{}
+			byTest[key] = o
+			order = append(order, key)
+		}
+
+		o.runs = append(o.runs, i)
+
+		if reruns, _ := strconv.Atoi(test.Properties[formats.SurefireReruns]); reruns > 0 {
+			o.failed = true
+			o.reruns += reruns
+		}
+
+		switch test.Status {
+		case junit.StatusFailed, junit.StatusError:
+			o.failed = true
+		case junit.StatusPassed:
+			o.passed = true
+		}
+	}
+
+	flaky := map[int]bool{}
+	retries := 0
+	for _, key := range order {
+		o := byTest[key]
+		if len(o.runs)+o.reruns < 2 || !o.failed {
+			continue
+		}
+
+		retries += len(o.runs) - 1 + o.reruns
+		if o.passed {
+			for _, i := range o.runs {
+				flaky[i] = true
+			}
+		}
+	}
+
+	return flaky, retries
+}
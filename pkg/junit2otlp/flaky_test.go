@@ -0,0 +1,80 @@
+package junit2otlp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/internal/formats"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlakyTests(t *testing.T) {
+	t.Run("Rerun until passing", func(t *testing.T) {
+		suite := junit.Suite{
+			Tests: []junit.Test{
+				{Classname: "FooTest", Name: "testA", Status: junit.StatusFailed},
+				{Classname: "FooTest", Name: "testB", Status: junit.StatusPassed},
+				{Classname: "FooTest", Name: "testA", Status: junit.StatusError},
+				{Classname: "FooTest", Name: "testA", Status: junit.StatusPassed},
+			},
+		}
+
+		flaky, retries := flakyTests(suite)
+		require.Equal(t, map[int]bool{0: true, 2: true, 3: true}, flaky)
+		require.Equal(t, 2, retries)
+	})
+
+	t.Run("Rerun without passing", func(t *testing.T) {
+		suite := junit.Suite{
+			Tests: []junit.Test{
+				{Classname: "FooTest", Name: "testA", Status: junit.StatusFailed},
+				{Classname: "FooTest", Name: "testA", Status: junit.StatusFailed},
+			},
+		}
+
+		flaky, retries := flakyTests(suite)
+		require.Empty(t, flaky)
+		require.Equal(t, 1, retries)
+	})
+
+	t.Run("Repeated without failures", func(t *testing.T) {
+		suite := junit.Suite{
+			Tests: []junit.Test{
+				{Classname: "FooTest", Name: "testA", Status: junit.StatusPassed},
+				{Classname: "FooTest", Name: "testA", Status: junit.StatusPassed},
+			},
+		}
+
+		flaky, retries := flakyTests(suite)
+		require.Empty(t, flaky)
+		require.Zero(t, retries)
+	})
+
+	t.Run("Same name in other classes", func(t *testing.T) {
+		suite := junit.Suite{
+			Tests: []junit.Test{
+				{Classname: "FooTest", Name: "testA", Status: junit.StatusFailed},
+				{Classname: "BarTest", Name: "testA", Status: junit.StatusPassed},
+			},
+		}
+
+		flaky, retries := flakyTests(suite)
+		require.Empty(t, flaky)
+		require.Zero(t, retries)
+	})
+	t.Run("Surefire reruns", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join("..", "..", "internal", "formats", "testdata", "TEST-surefire-rerun.xml"))
+		require.NoError(t, err)
+
+		suites, err := formats.Parse(formats.JUnit, data)
+		require.NoError(t, err)
+		require.Len(t, suites, 1)
+
+		// retriesTheGateway passed after two failed runs, and refundsTheOrder failed its two reruns
+		flaky, retries := flakyTests(suites[0])
+		require.Equal(t, map[int]bool{1: true}, flaky)
+		require.Equal(t, 4, retries)
+	})
+}
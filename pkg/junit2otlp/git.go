@@ -1,7 +1,9 @@
-package main
+package junit2otlp
 
 import (
-	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
 	"time"
 
 	git "github.com/go-git/go-git/v5"
@@ -13,19 +15,22 @@ import (
 
 // GitScm represents the metadata used to build a Git SCM repository
 type GitScm struct {
-	baseRef        string
-	branchName     string
-	headSha        string
-	changeRequest  bool // if the tool is evaluating a change request or a branch
-	provider       string
-	repository     *git.Repository
-	repositoryPath string
+	attributesSchema string // legacy, vcs or both
+	baseRef          string
+	branchName       string
+	headSha          string
+	changeID         string
+	changeRequest    bool // if the tool is evaluating a change request or a branch
+	provider         string
+	repository       *git.Repository
+	repositoryPath   string
 }
 
 // NewGitScm retrieves a Git SCM repository, using the repository filesystem path to read it
 func NewGitScm(repositoryPath string) *GitScm {
 	scm := &GitScm{
-		repositoryPath: repositoryPath,
+		attributesSchema: ScmAttributesSchemaLegacy,
+		repositoryPath:   repositoryPath,
 	}
 
 	repository, err := scm.openLocalRepository()
@@ -43,6 +48,7 @@ func NewGitScm(repositoryPath string) *GitScm {
 	scm.headSha = gitCtx.Commit
 	scm.branchName = gitCtx.Branch
 	scm.baseRef = gitCtx.GetTargetBranch()
+	scm.changeID = gitCtx.ChangeID
 	scm.changeRequest = gitCtx.ChangeRequest
 	scm.provider = gitCtx.Provider
 
@@ -89,8 +95,27 @@ func (scm *GitScm) calculateCommits() (*object.Commit, *object.Commit, error) {
 }
 
 // contributeAttributes this method never fails, returning the current state of the contributed attributes
-// at the moment of the failure
+// at the moment of the failure, using the SCM attributes schema selected by the user
 func (scm *GitScm) contributeAttributes() []attribute.KeyValue {
+	gitAttributes := scm.contributeScmAttributes()
+
+	if scm.attributesSchema == ScmAttributesSchemaLegacy {
+		return gitAttributes
+	}
+
+	vcsAttributes := toVcsAttributes(gitAttributes)
+	vcsAttributes = append(vcsAttributes, scm.contributeVcsAttributes()...)
+
+	if scm.attributesSchema == ScmAttributesSchemaBoth {
+		return append(gitAttributes, vcsAttributes...)
+	}
+
+	return append(withoutLegacyScmAttributes(gitAttributes), vcsAttributes...)
+}
+
+// contributeScmAttributes this method never fails, returning the current state of the contributed attributes
+// at the moment of the failure, using the legacy scm.* keys
+func (scm *GitScm) contributeScmAttributes() []attribute.KeyValue {
 	// from now on, this is a Git repository
 	gitAttributes := []attribute.KeyValue{
 		attribute.Key(ScmType).String("git"),
@@ -143,7 +168,7 @@ func (scm *GitScm) contributeAttributes() []attribute.KeyValue {
 	for _, contribution := range contributions {
 		contributtedAttributes, err := contribution(headCommit, targetCommit)
 		if err != nil {
-			fmt.Printf(">> not contributing attributes: %v", err)
+			slog.Warn("not contributing Git attributes", "error", err)
 			continue
 		}
 
@@ -153,6 +178,30 @@ func (scm *GitScm) contributeAttributes() []attribute.KeyValue {
 	return gitAttributes
 }
 
+// contributeVcsAttributes contributes the attributes from the OpenTelemetry VCS conventions that have no
+// legacy scm.* equivalent: the revision of the HEAD commit and the identifier of the change request
+func (scm *GitScm) contributeVcsAttributes() []attribute.KeyValue {
+	attributes := []attribute.KeyValue{}
+
+	revision := scm.headSha
+	if revision == "" {
+		headRef, err := scm.repository.Head()
+		if err == nil {
+			revision = headRef.Hash().String()
+		}
+	}
+
+	if revision != "" {
+		attributes = append(attributes, attribute.Key(VcsRefHeadRevision).String(revision))
+	}
+
+	if scm.changeRequest && scm.changeID != "" {
+		attributes = append(attributes, attribute.Key(VcsChangeID).String(scm.changeID))
+	}
+
+	return attributes
+}
+
 // contributeCommitters this algorithm will look for the first ancestor between HEAD and the TARGET_BRANCH, and will iterate through
 // the list of commits, storing the author and the committer for each commit, contributing an array of Strings
 // attribute including the email of the author/commiter.
@@ -242,13 +291,9 @@ func (scm *GitScm) contributeFilesAndLines(headCommit *object.Commit, targetComm
 	return
 }
 
+// mapToArray returns the keys of the map, sorted so the values of the attributes are the same on every run
 func mapToArray(m map[string]bool) []string {
-	array := []string{}
-	for k := range m {
-		array = append(array, k)
-	}
-
-	return array
+	return slices.Sorted(maps.Keys(m))
 }
 
 func (scm *GitScm) openLocalRepository() (*git.Repository, error) {
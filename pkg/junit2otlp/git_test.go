@@ -1,4 +1,4 @@
-package main
+package junit2otlp
 
 import (
 	"fmt"
@@ -25,7 +25,8 @@ func init() {
 		log.Fatalln("Cannot get current working dir, which is needed by tests")
 	}
 
-	workingDir = wd
+	// the sample reports are in the root of the repository
+	workingDir = path.Join(wd, "..", "..")
 }
 
 // FakeGitRepo downloads Octocat's hello-world repository from Github, providing a simple DSL to add/remove files and commit them into
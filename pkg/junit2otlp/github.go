@@ -0,0 +1,205 @@
+package junit2otlp
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const defaultGithubAPIURL = "https://api.github.com"
+
+var githubPullRequestRef = regexp.MustCompile(`^refs/pull/(\d+)/`)
+
+// GithubAPI represents the metadata used to enrich the SCM attributes calling the Github REST API
+type GithubAPI struct {
+	apiURL     string
+	client     *http.Client
+	job        string
+	pullNumber string // only present for pull requests on Github Actions
+	repository string // owner/name of the repository
+	sha        string
+	token      string
+}
+
+type githubUser struct {
+	Login string `json:"login"`
+}
+
+type githubPullRequest struct {
+	Draft  bool `json:"draft"`
+	Labels []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	RequestedReviewers []githubUser `json:"requested_reviewers"`
+}
+
+type githubReview struct {
+	User githubUser `json:"user"`
+}
+
+type githubCheckRuns struct {
+	CheckRuns []struct {
+		ID int64 `json:"id"`
+	} `json:"check_runs"`
+}
+
+// NewGithubAPI returns a Github API client, reading the right environment variables, as described
+// in their docs. It will return nil if the GITHUB_TOKEN environment variable is not set, or the tool
+// is not running in the context of a Github Action
+func NewGithubAPI() *GithubAPI {
+	token := os.Getenv("GITHUB_TOKEN")
+	repository := os.Getenv("GITHUB_REPOSITORY")
+	if token == "" || repository == "" {
+		return nil
+	}
+
+	apiURL := os.Getenv("GITHUB_API_URL")
+	if apiURL == "" {
+		apiURL = defaultGithubAPIURL
+	}
+
+	pullNumber := ""
+	matches := githubPullRequestRef.FindStringSubmatch(os.Getenv("GITHUB_REF"))
+	if len(matches) == 2 {
+		pullNumber = matches[1]
+	}
+
+	return &GithubAPI{
+		apiURL:     apiURL,
+		client:     newScmAPIClient(),
+		job:        os.Getenv("GITHUB_JOB"),
+		pullNumber: pullNumber,
+		repository: repository,
+		sha:        os.Getenv("GITHUB_SHA"),
+		token:      token,
+	}
+}
+
+// contributeAttributes this method never fails, returning the current state of the contributed attributes
+// at the moment of the failure
+func (gh *GithubAPI) contributeAttributes() []attribute.KeyValue {
+	githubAttributes := []attribute.KeyValue{}
+
+	contributions := []func() ([]attribute.KeyValue, error){
+		gh.contributeCheckRun,
+	}
+
+	if gh.pullNumber != "" {
+		contributions = append(contributions, gh.contributePullRequest)
+	}
+
+	for _, contribution := range contributions {
+		contributtedAttributes, err := contribution()
+		if err != nil {
+			slog.Warn("not contributing Github attributes", "error", err)
+			continue
+		}
+
+		githubAttributes = append(githubAttributes, contributtedAttributes...)
+	}
+
+	return githubAttributes
+}
+
+// contributeCheckRun looks up the check run created for the current job and commit, contributing its ID
+func (gh *GithubAPI) contributeCheckRun() ([]attribute.KeyValue, error) {
+	if gh.sha == "" || gh.job == "" {
+		return []attribute.KeyValue{}, nil
+	}
+
+	checkRunsURL := fmt.Sprintf("%s/repos/%s/commits/%s/check-runs?check_name=%s", gh.apiURL, gh.repository, gh.sha, url.QueryEscape(gh.job))
+
+	var checkRuns githubCheckRuns
+	if err := gh.get(checkRunsURL, &checkRuns); err != nil {
+		return nil, errors.Wrapf(err, "not able to retrieve the check runs for %s", gh.sha)
+	}
+
+	if len(checkRuns.CheckRuns) == 0 {
+		return []attribute.KeyValue{}, nil
+	}
+
+	return []attribute.KeyValue{
+		attribute.Key(GithubCheckRunID).Int64(checkRuns.CheckRuns[0].ID),
+	}, nil
+}
+
+// contributePullRequest retrieves the pull request and its reviews, contributing the draft status,
+// the labels and the unique logins of the requested reviewers and the users who already reviewed it
+func (gh *GithubAPI) contributePullRequest() ([]attribute.KeyValue, error) {
+	pullURL := fmt.Sprintf("%s/repos/%s/pulls/%s", gh.apiURL, gh.repository, gh.pullNumber)
+
+	var pull githubPullRequest
+	if err := gh.get(pullURL, &pull); err != nil {
+		return nil, errors.Wrapf(err, "not able to retrieve the pull request %s", gh.pullNumber)
+	}
+
+	var reviews []githubReview
+	if err := gh.get(pullURL+"/reviews", &reviews); err != nil {
+		return nil, errors.Wrapf(err, "not able to retrieve the reviews for the pull request %s", gh.pullNumber)
+	}
+
+	labels := []string{}
+	for _, label := range pull.Labels {
+		labels = append(labels, label.Name)
+	}
+
+	reviewers := map[string]bool{}
+	for _, reviewer := range pull.RequestedReviewers {
+		reviewers[reviewer.Login] = true
+	}
+	for _, review := range reviews {
+		reviewers[review.User.Login] = true
+	}
+
+	attributes := []attribute.KeyValue{
+		attribute.Key(GithubPullRequestDraft).Bool(pull.Draft),
+		attribute.Key(GithubPullRequestLabels).StringSlice(labels),
+	}
+
+	if len(reviewers) > 0 {
+		attributes = append(attributes, attribute.Key(GithubPullRequestReviewers).StringSlice(mapToArray(reviewers)))
+	}
+
+	return attributes, nil
+}
+
+func (gh *GithubAPI) get(url string, v interface{}) error {
+	headers := map[string]string{
+		"Accept":               "application/vnd.github+json",
+		"Authorization":        "Bearer " + gh.token,
+		"X-GitHub-Api-Version": "2022-11-28",
+	}
+
+	return getJSON(gh.client, url, headers, v)
+}
+
+// writeGithubOutputs sets the outputs of the current step of Github Actions, appending them to the file of the
+// GITHUB_OUTPUT environment variable, in the order of their names
+func writeGithubOutputs(outputs map[string]string) error {
+	path := os.Getenv("GITHUB_OUTPUT")
+	if path == "" {
+		return fmt.Errorf("the GITHUB_OUTPUT environment variable is required to set the outputs of the Github Actions step")
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open the Github Actions outputs: %w", err)
+	}
+	defer f.Close()
+
+	for _, name := range slices.Sorted(maps.Keys(outputs)) {
+		if _, err := fmt.Fprintf(f, "%s=%s\n", name, outputs[name]); err != nil {
+			return fmt.Errorf("failed to write the Github Actions outputs: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,223 @@
+package junit2otlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// githubAnnotationsPerRequest maximum number of annotations of a check run in a request, as the API allows
+	githubAnnotationsPerRequest = 50
+
+	// githubCheckRunTextLimit maximum number of characters of the summary and the details of a check run
+	githubCheckRunTextLimit = 65535
+)
+
+type githubCheckRunRequest struct {
+	Name       string               `json:"name,omitempty"`
+	HeadSHA    string               `json:"head_sha,omitempty"`
+	Status     string               `json:"status,omitempty"`
+	Conclusion string               `json:"conclusion,omitempty"`
+	DetailsURL string               `json:"details_url,omitempty"`
+	Output     githubCheckRunOutput `json:"output"`
+}
+
+type githubCheckRunOutput struct {
+	Title       string             `json:"title"`
+	Summary     string             `json:"summary"`
+	Annotations []githubAnnotation `json:"annotations,omitempty"`
+}
+
+type githubAnnotation struct {
+	Path            string `json:"path"`
+	StartLine       int    `json:"start_line"`
+	EndLine         int    `json:"end_line"`
+	AnnotationLevel string `json:"annotation_level"`
+	Title           string `json:"title"`
+	Message         string `json:"message"`
+	RawDetails      string `json:"raw_details,omitempty"`
+}
+
+type githubCheckRunResponse struct {
+	ID int64 `json:"id"`
+}
+
+// githubCheckRunAnnotations returns a failure annotation for each failed or errored test whose file can be
+// resolved, as the annotations of a check run must be located in a file
+func githubCheckRunAnnotations(suites []junit.Suite) []githubAnnotation {
+	annotations := []githubAnnotation{}
+
+	for _, suite := range suites {
+		for _, test := range suite.Tests {
+			if test.Status != junit.StatusFailed && test.Status != junit.StatusError {
+				continue
+			}
+
+			file, line := resolveFileLine(test)
+			if file == "" {
+				continue
+			}
+			line = max(line, 1)
+
+			title := test.Name
+			if test.Classname != "" {
+				title = test.Classname + "." + test.Name
+			}
+
+			message := test.Message
+			if message == "" && test.Error != nil {
+				message = test.Error.Error()
+			}
+			if message == "" {
+				message = fmt.Sprintf("%s %s", title, test.Status)
+			}
+
+			details := ""
+			var junitErr junit.Error
+			if errors.As(test.Error, &junitErr) {
+				details = junitErr.Body
+			}
+
+			annotations = append(annotations, githubAnnotation{
+				Path:            file,
+				StartLine:       line,
+				EndLine:         line,
+				AnnotationLevel: "failure",
+				Title:           title,
+				Message:         truncateText(message, githubCheckRunTextLimit),
+				RawDetails:      truncateText(details, githubCheckRunTextLimit),
+			})
+		}
+	}
+
+	return annotations
+}
+
+// createCheckRun creates a completed check run for the commit, with the summary of the run, linking to the trace
+// if the template is not empty, and a failure annotation for each failed test. The annotations beyond the maximum
+// of a request are added updating the check run, as the API appends them
+func (gh *GithubAPI) createCheckRun(ctx context.Context, name string, suites []junit.Suite, traceID trace.TraceID, traceURLTemplate string) error {
+	if gh.sha == "" {
+		return fmt.Errorf("the GITHUB_SHA environment variable is required to create a Github check run")
+	}
+
+	summary := newRunSummary(suites, traceID)
+
+	var sb strings.Builder
+	if err := printSummaryMarkdown(&sb, name, summary, traceURLTemplate); err != nil {
+		return err
+	}
+
+	totals := summary.Totals
+	conclusion := "success"
+	if totals.Failed+totals.Errored > 0 {
+		conclusion = "failure"
+	} else if totals.Tests == 0 {
+		conclusion = "neutral"
+	}
+
+	output := githubCheckRunOutput{
+		Title:   fmt.Sprintf("%d tests, %d failed, %d errored, %d skipped", totals.Tests, totals.Failed, totals.Errored, totals.Skipped),
+		Summary: truncateText(sb.String(), githubCheckRunTextLimit),
+	}
+
+	detailsURL := ""
+	if traceURLTemplate != "" && summary.TraceID != "" {
+		detailsURL = strings.ReplaceAll(traceURLTemplate, traceIDPlaceholder, summary.TraceID)
+	}
+
+	annotations := githubCheckRunAnnotations(suites)
+
+	first := output
+	first.Annotations = annotations[:min(githubAnnotationsPerRequest, len(annotations))]
+
+	var checkRun githubCheckRunResponse
+	checkRunsURL := fmt.Sprintf("%s/repos/%s/check-runs", gh.apiURL, gh.repository)
+	err := gh.send(ctx, http.MethodPost, checkRunsURL, githubCheckRunRequest{
+		Name:       name,
+		HeadSHA:    gh.sha,
+		Status:     "completed",
+		Conclusion: conclusion,
+		DetailsURL: detailsURL,
+		Output:     first,
+	}, &checkRun)
+	if err != nil {
+		return fmt.Errorf("failed to create the Github check run: %w", err)
+	}
+
+	for start := githubAnnotationsPerRequest; start < len(annotations); start += githubAnnotationsPerRequest {
+		batch := output
+		batch.Annotations = annotations[start:min(start+githubAnnotationsPerRequest, len(annotations))]
+
+		checkRunURL := fmt.Sprintf("%s/%d", checkRunsURL, checkRun.ID)
+		if err := gh.send(ctx, http.MethodPatch, checkRunURL, githubCheckRunRequest{Output: batch}, nil); err != nil {
+			return fmt.Errorf("failed to add the annotations to the Github check run: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// send performs a request to the URL with the body as JSON, decoding the JSON response into v, if not nil
+func (gh *GithubAPI) send(ctx context.Context, method string, url string, body interface{}, v interface{}) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+gh.token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	resp, err := gh.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status code calling %s: %d: %s", url, resp.StatusCode, b)
+	}
+
+	if v == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// reportGithubCheckRun creates the check run of the run for the commit of the Github Action, named after the trace
+func reportGithubCheckRun(ctx context.Context, cfg *config.Config, suites []junit.Suite, traceID trace.TraceID) error {
+	gh := NewGithubAPI()
+	if gh == nil {
+		return fmt.Errorf("the GITHUB_TOKEN and GITHUB_REPOSITORY environment variables are required to create a Github check run")
+	}
+
+	return gh.createCheckRun(ctx, cfg.TraceName, suites, traceID, cfg.TraceURLTemplate)
+}
+
+// truncateText returns the text cut to the limit of characters, if it's longer
+func truncateText(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+
+	return string(runes[:limit])
+}
@@ -0,0 +1,101 @@
+package junit2otlp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestGithubAPI_CreateCheckRun(t *testing.T) {
+	failures := make([]junit.Test, 0, 60)
+	for i := range 60 {
+		failures = append(failures, junit.Test{
+			Name:     fmt.Sprintf("TestFail%d", i),
+			Status:   junit.StatusFailed,
+			Message:  fmt.Sprintf("foo_test.go:%d: boom", i),
+			Duration: time.Millisecond,
+		})
+	}
+	suites := []junit.Suite{
+		{
+			Name: "github.com/octocat/hello-world",
+			Tests: append(failures,
+				junit.Test{Name: "TestPass", Status: junit.StatusPassed},
+				junit.Test{Name: "TestNoFile", Status: junit.StatusError, Message: "panic"},
+			),
+		},
+	}
+	suites[0].Aggregate()
+
+	traceID, err := trace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	require.NoError(t, err)
+
+	var created githubCheckRunRequest
+	var updated []githubCheckRunRequest
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /repos/octocat/hello-world/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer gh-token", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":42}`)
+	})
+	mux.HandleFunc("PATCH /repos/octocat/hello-world/check-runs/42", func(w http.ResponseWriter, r *http.Request) {
+		var req githubCheckRunRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		updated = append(updated, req)
+
+		fmt.Fprint(w, `{"id":42}`)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	t.Setenv("GITHUB_TOKEN", "gh-token")
+	t.Setenv("GITHUB_REPOSITORY", "octocat/hello-world")
+	t.Setenv("GITHUB_API_URL", server.URL)
+	t.Setenv("GITHUB_SHA", "0123456")
+
+	err = NewGithubAPI().createCheckRun(context.Background(), "unit-tests", suites, traceID, "https://tracing.example.com/trace/{traceId}")
+	require.NoError(t, err)
+
+	require.Equal(t, "unit-tests", created.Name)
+	require.Equal(t, "0123456", created.HeadSHA)
+	require.Equal(t, "completed", created.Status)
+	require.Equal(t, "failure", created.Conclusion)
+	require.Equal(t, "https://tracing.example.com/trace/0102030405060708090a0b0c0d0e0f10", created.DetailsURL)
+	require.Equal(t, "62 tests, 60 failed, 1 errored, 0 skipped", created.Output.Title)
+	require.Contains(t, created.Output.Summary, "TestNoFile")
+	require.Len(t, created.Output.Annotations, githubAnnotationsPerRequest)
+	require.Equal(t, githubAnnotation{
+		Path:            "foo_test.go",
+		StartLine:       1,
+		EndLine:         1,
+		AnnotationLevel: "failure",
+		Title:           "TestFail0",
+		Message:         "foo_test.go:0: boom",
+	}, created.Output.Annotations[0])
+
+	// the rest of the annotations are appended, and the test without file is not annotated
+	require.Len(t, updated, 1)
+	require.Equal(t, created.Output.Title, updated[0].Output.Title)
+	require.Len(t, updated[0].Output.Annotations, 10)
+	require.Equal(t, "TestFail59", updated[0].Output.Annotations[9].Title)
+}
+
+func TestGithubAPI_CreateCheckRun_WithoutSHA(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "gh-token")
+	t.Setenv("GITHUB_REPOSITORY", "octocat/hello-world")
+	t.Setenv("GITHUB_SHA", "")
+
+	err := NewGithubAPI().createCheckRun(context.Background(), "unit-tests", nil, trace.TraceID{}, "")
+	require.ErrorContains(t, err, "GITHUB_SHA")
+}
@@ -0,0 +1,111 @@
+package junit2otlp
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newFakeGithubAPI(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/octocat/hello-world/commits/0123456/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer gh-token", r.Header.Get("Authorization"))
+		require.Equal(t, "unit-tests", r.URL.Query().Get("check_name"))
+
+		fmt.Fprint(w, `{"total_count":1,"check_runs":[{"id":987654321}]}`)
+	})
+	mux.HandleFunc("/repos/octocat/hello-world/pulls/23", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"draft":true,"labels":[{"name":"bug"},{"name":"tests"}],"requested_reviewers":[{"login":"octocat"}]}`)
+	})
+	mux.HandleFunc("/repos/octocat/hello-world/pulls/23/reviews", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"user":{"login":"mdelapenya"}},{"user":{"login":"octocat"}}]`)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestNewGithubAPI(t *testing.T) {
+	t.Run("Without GITHUB_TOKEN", func(t *testing.T) {
+		t.Setenv("GITHUB_TOKEN", "")
+		t.Setenv("GITHUB_REPOSITORY", "octocat/hello-world")
+
+		require.Nil(t, NewGithubAPI())
+	})
+
+	t.Run("Running for Branches", func(t *testing.T) {
+		t.Setenv("GITHUB_TOKEN", "gh-token")
+		t.Setenv("GITHUB_REPOSITORY", "octocat/hello-world")
+		t.Setenv("GITHUB_API_URL", "")
+		t.Setenv("GITHUB_REF", "refs/heads/main")
+
+		gh := NewGithubAPI()
+		require.NotNil(t, gh)
+		require.Equal(t, defaultGithubAPIURL, gh.apiURL)
+		require.Equal(t, "", gh.pullNumber)
+	})
+
+	t.Run("Running for Pull Requests", func(t *testing.T) {
+		t.Setenv("GITHUB_TOKEN", "gh-token")
+		t.Setenv("GITHUB_REPOSITORY", "octocat/hello-world")
+		t.Setenv("GITHUB_REF", "refs/pull/23/merge")
+
+		gh := NewGithubAPI()
+		require.NotNil(t, gh)
+		require.Equal(t, "23", gh.pullNumber)
+	})
+}
+
+func TestGithubAPI_ContributeAttributes(t *testing.T) {
+	server := newFakeGithubAPI(t)
+
+	t.Setenv("GITHUB_TOKEN", "gh-token")
+	t.Setenv("GITHUB_REPOSITORY", "octocat/hello-world")
+	t.Setenv("GITHUB_API_URL", server.URL)
+	t.Setenv("GITHUB_SHA", "0123456")
+	t.Setenv("GITHUB_JOB", "unit-tests")
+
+	t.Run("Running for Branches", func(t *testing.T) {
+		t.Setenv("GITHUB_REF", "refs/heads/main")
+
+		atts := NewGithubAPI().contributeAttributes()
+
+		require.Len(t, atts, 1)
+		require.True(t, keyExistsWithIntValue(t, atts, GithubCheckRunID, 987654321))
+	})
+
+	t.Run("Running for Pull Requests", func(t *testing.T) {
+		t.Setenv("GITHUB_REF", "refs/pull/23/merge")
+
+		atts := NewGithubAPI().contributeAttributes()
+
+		require.True(t, keyExistsWithIntValue(t, atts, GithubCheckRunID, 987654321))
+		require.True(t, keyExistsWithBoolValue(t, atts, GithubPullRequestDraft, true))
+		require.True(t, keyExistsWithValue(t, atts, GithubPullRequestLabels, "bug", "tests"))
+		require.True(t, keyExistsWithValue(t, atts, GithubPullRequestReviewers, "octocat"))
+		require.True(t, keyExistsWithValue(t, atts, GithubPullRequestReviewers, "mdelapenya"))
+
+		// the reviewers are sorted, so the value of the attribute is the same on every run
+		for _, att := range atts {
+			if string(att.Key) == GithubPullRequestReviewers {
+				require.Equal(t, []string{"mdelapenya", "octocat"}, att.Value.AsStringSlice())
+			}
+		}
+	})
+
+	t.Run("API errors are not contributed", func(t *testing.T) {
+		t.Setenv("GITHUB_REF", "refs/pull/42/merge")
+
+		atts := NewGithubAPI().contributeAttributes()
+
+		require.Len(t, atts, 1)
+		require.True(t, keyExistsWithIntValue(t, atts, GithubCheckRunID, 987654321))
+	})
+}
@@ -0,0 +1,181 @@
+package junit2otlp
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const defaultGitlabAPIURL = "https://gitlab.com/api/v4"
+
+// GitlabAPI represents the metadata used to enrich the SCM attributes calling the Gitlab REST API
+type GitlabAPI struct {
+	apiURL          string
+	client          *http.Client
+	mergeRequestIID string // only present on merge requests on Gitlab CI
+	pipelineID      string
+	pipelineURL     string
+	projectID       string
+	tokenHeader     string // PRIVATE-TOKEN for personal/project tokens, JOB-TOKEN for the CI job token
+	token           string
+}
+
+type gitlabUser struct {
+	Username string `json:"username"`
+}
+
+type gitlabMergeRequest struct {
+	Labels    []string `json:"labels"`
+	Milestone *struct {
+		Title string `json:"title"`
+	} `json:"milestone"`
+}
+
+type gitlabApprovals struct {
+	ApprovedBy []struct {
+		User gitlabUser `json:"user"`
+	} `json:"approved_by"`
+}
+
+type gitlabPipeline struct {
+	WebURL string `json:"web_url"`
+}
+
+// NewGitlabAPI returns a Gitlab API client, reading the right environment variables, as described
+// in their docs. The GITLAB_TOKEN environment variable takes precedence over CI_JOB_TOKEN. It will
+// return nil if none of them is set, or the tool is not running in the context of a Gitlab runner
+func NewGitlabAPI() *GitlabAPI {
+	projectID := os.Getenv("CI_PROJECT_ID")
+	if projectID == "" {
+		return nil
+	}
+
+	tokenHeader := "PRIVATE-TOKEN"
+	token := os.Getenv("GITLAB_TOKEN")
+	if token == "" {
+		tokenHeader = "JOB-TOKEN"
+		token = os.Getenv("CI_JOB_TOKEN")
+	}
+
+	if token == "" {
+		return nil
+	}
+
+	apiURL := os.Getenv("CI_API_V4_URL")
+	if apiURL == "" {
+		apiURL = defaultGitlabAPIURL
+	}
+
+	return &GitlabAPI{
+		apiURL:          apiURL,
+		client:          newScmAPIClient(),
+		mergeRequestIID: os.Getenv("CI_MERGE_REQUEST_IID"),
+		pipelineID:      os.Getenv("CI_PIPELINE_ID"),
+		pipelineURL:     os.Getenv("CI_PIPELINE_URL"),
+		projectID:       projectID,
+		tokenHeader:     tokenHeader,
+		token:           token,
+	}
+}
+
+// contributeAttributes this method never fails, returning the current state of the contributed attributes
+// at the moment of the failure
+func (gl *GitlabAPI) contributeAttributes() []attribute.KeyValue {
+	gitlabAttributes := []attribute.KeyValue{}
+
+	contributions := []func() ([]attribute.KeyValue, error){
+		gl.contributePipeline,
+	}
+
+	if gl.mergeRequestIID != "" {
+		contributions = append(contributions, gl.contributeMergeRequest)
+	}
+
+	for _, contribution := range contributions {
+		contributtedAttributes, err := contribution()
+		if err != nil {
+			slog.Warn("not contributing Gitlab attributes", "error", err)
+			continue
+		}
+
+		gitlabAttributes = append(gitlabAttributes, contributtedAttributes...)
+	}
+
+	return gitlabAttributes
+}
+
+// contributePipeline contributes the URL of the pipeline, only calling the API if the runner does not
+// expose it in the CI_PIPELINE_URL environment variable
+func (gl *GitlabAPI) contributePipeline() ([]attribute.KeyValue, error) {
+	if gl.pipelineURL != "" {
+		return []attribute.KeyValue{attribute.Key(GitlabPipelineURL).String(gl.pipelineURL)}, nil
+	}
+
+	if gl.pipelineID == "" {
+		return []attribute.KeyValue{}, nil
+	}
+
+	var pipeline gitlabPipeline
+	if err := gl.get(fmt.Sprintf("%s/pipelines/%s", gl.projectURL(), gl.pipelineID), &pipeline); err != nil {
+		return nil, errors.Wrapf(err, "not able to retrieve the pipeline %s", gl.pipelineID)
+	}
+
+	return []attribute.KeyValue{attribute.Key(GitlabPipelineURL).String(pipeline.WebURL)}, nil
+}
+
+// contributeMergeRequest retrieves the merge request and its approvals, contributing the labels,
+// the milestone and the unique usernames of the approvers
+func (gl *GitlabAPI) contributeMergeRequest() ([]attribute.KeyValue, error) {
+	mergeRequestURL := fmt.Sprintf("%s/merge_requests/%s", gl.projectURL(), gl.mergeRequestIID)
+
+	var mergeRequest gitlabMergeRequest
+	if err := gl.get(mergeRequestURL, &mergeRequest); err != nil {
+		return nil, errors.Wrapf(err, "not able to retrieve the merge request %s", gl.mergeRequestIID)
+	}
+
+	var approvals gitlabApprovals
+	if err := gl.get(mergeRequestURL+"/approvals", &approvals); err != nil {
+		return nil, errors.Wrapf(err, "not able to retrieve the approvals for the merge request %s", gl.mergeRequestIID)
+	}
+
+	labels := mergeRequest.Labels
+	if labels == nil {
+		labels = []string{}
+	}
+
+	attributes := []attribute.KeyValue{
+		attribute.Key(GitlabMergeRequestLabels).StringSlice(labels),
+	}
+
+	if mergeRequest.Milestone != nil {
+		attributes = append(attributes, attribute.Key(GitlabMergeRequestMilestone).String(mergeRequest.Milestone.Title))
+	}
+
+	approvers := map[string]bool{}
+	for _, approval := range approvals.ApprovedBy {
+		approvers[approval.User.Username] = true
+	}
+
+	if len(approvers) > 0 {
+		attributes = append(attributes, attribute.Key(GitlabMergeRequestApprovers).StringSlice(mapToArray(approvers)))
+	}
+
+	return attributes, nil
+}
+
+func (gl *GitlabAPI) projectURL() string {
+	return fmt.Sprintf("%s/projects/%s", gl.apiURL, url.PathEscape(gl.projectID))
+}
+
+func (gl *GitlabAPI) get(url string, v interface{}) error {
+	headers := map[string]string{
+		gl.tokenHeader: gl.token,
+	}
+
+	return getJSON(gl.client, url, headers, v)
+}
@@ -0,0 +1,98 @@
+package junit2otlp
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newFakeGitlabAPI(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/projects/123/pipelines/456", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "job-token", r.Header.Get("JOB-TOKEN"))
+
+		fmt.Fprint(w, `{"id":456,"web_url":"https://gitlab.com/octocat/hello-world/-/pipelines/456"}`)
+	})
+	mux.HandleFunc("/projects/123/merge_requests/7", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"iid":7,"labels":["bug","tests"],"milestone":{"title":"v1.0.0"}}`)
+	})
+	mux.HandleFunc("/projects/123/merge_requests/7/approvals", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"approved_by":[{"user":{"username":"octocat"}},{"user":{"username":"mdelapenya"}}]}`)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestNewGitlabAPI(t *testing.T) {
+	t.Run("Without tokens", func(t *testing.T) {
+		t.Setenv("CI_PROJECT_ID", "123")
+		t.Setenv("GITLAB_TOKEN", "")
+		t.Setenv("CI_JOB_TOKEN", "")
+
+		require.Nil(t, NewGitlabAPI())
+	})
+
+	t.Run("GITLAB_TOKEN takes precedence", func(t *testing.T) {
+		t.Setenv("CI_PROJECT_ID", "123")
+		t.Setenv("GITLAB_TOKEN", "private-token")
+		t.Setenv("CI_JOB_TOKEN", "job-token")
+
+		gl := NewGitlabAPI()
+		require.NotNil(t, gl)
+		require.Equal(t, "PRIVATE-TOKEN", gl.tokenHeader)
+		require.Equal(t, "private-token", gl.token)
+	})
+
+	t.Run("CI_JOB_TOKEN", func(t *testing.T) {
+		t.Setenv("CI_PROJECT_ID", "123")
+		t.Setenv("GITLAB_TOKEN", "")
+		t.Setenv("CI_JOB_TOKEN", "job-token")
+		t.Setenv("CI_API_V4_URL", "")
+
+		gl := NewGitlabAPI()
+		require.NotNil(t, gl)
+		require.Equal(t, "JOB-TOKEN", gl.tokenHeader)
+		require.Equal(t, defaultGitlabAPIURL, gl.apiURL)
+	})
+}
+
+func TestGitlabAPI_ContributeAttributes(t *testing.T) {
+	server := newFakeGitlabAPI(t)
+
+	t.Setenv("CI_PROJECT_ID", "123")
+	t.Setenv("CI_API_V4_URL", server.URL)
+	t.Setenv("GITLAB_TOKEN", "")
+	t.Setenv("CI_JOB_TOKEN", "job-token")
+	t.Setenv("CI_PIPELINE_ID", "456")
+
+	t.Run("Running for Branches", func(t *testing.T) {
+		t.Setenv("CI_MERGE_REQUEST_IID", "")
+		t.Setenv("CI_PIPELINE_URL", "")
+
+		atts := NewGitlabAPI().contributeAttributes()
+
+		require.Len(t, atts, 1)
+		require.True(t, keyExistsWithValue(t, atts, GitlabPipelineURL, "https://gitlab.com/octocat/hello-world/-/pipelines/456"))
+	})
+
+	t.Run("Running for Merge Requests", func(t *testing.T) {
+		t.Setenv("CI_MERGE_REQUEST_IID", "7")
+		t.Setenv("CI_PIPELINE_URL", "https://gitlab.example.com/pipelines/456")
+
+		atts := NewGitlabAPI().contributeAttributes()
+
+		require.True(t, keyExistsWithValue(t, atts, GitlabPipelineURL, "https://gitlab.example.com/pipelines/456"))
+		require.True(t, keyExistsWithValue(t, atts, GitlabMergeRequestLabels, "bug", "tests"))
+		require.True(t, keyExistsWithValue(t, atts, GitlabMergeRequestMilestone, "v1.0.0"))
+		require.True(t, keyExistsWithValue(t, atts, GitlabMergeRequestApprovers, "octocat"))
+		require.True(t, keyExistsWithValue(t, atts, GitlabMergeRequestApprovers, "mdelapenya"))
+	})
+}
@@ -0,0 +1,103 @@
+package junit2otlp
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// globDoubleStar segment of a glob pattern matching any number of directories, including none
+const globDoubleStar = "**"
+
+// hasGlobMeta reports whether the path has any of the special characters of the glob patterns
+func hasGlobMeta(p string) bool {
+	return strings.ContainsAny(p, "*?[")
+}
+
+// expandGlob returns the regular files matching the pattern, sorted by path. Besides the syntax of filepath.Match,
+// a ** segment matches any number of directories, i.e. **/target/surefire-reports/*.xml. The directories are walked
+// from the longest leading part of the pattern without special characters
+func expandGlob(pattern string) ([]string, error) {
+	segments := strings.Split(filepath.ToSlash(pattern), "/")
+
+	for _, segment := range segments {
+		if _, err := path.Match(segment, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+		}
+	}
+
+	n := 0
+	for n < len(segments)-1 && !hasGlobMeta(segments[n]) {
+		n++
+	}
+
+	root := strings.Join(segments[:n], "/")
+	switch {
+	case n > 0 && root == "":
+		root = "/"
+	case root == "":
+		root = "."
+	}
+	rest := segments[n:]
+	unbounded := slices.Contains(rest, globDoubleStar)
+
+	matches := []string{}
+	err := filepath.WalkDir(filepath.FromSlash(root), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == filepath.FromSlash(root) && errors.Is(err, fs.ErrNotExist) {
+				return filepath.SkipAll
+			}
+			return err
+		}
+
+		rel, err := filepath.Rel(filepath.FromSlash(root), p)
+		if err != nil || rel == "." {
+			return err
+		}
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+
+		if d.IsDir() {
+			// without **, the directories deeper than the pattern cannot match
+			if !unbounded && len(parts) >= len(rest) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.Type().IsRegular() && matchGlobSegments(rest, parts) {
+			matches = append(matches, p)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Sort(matches)
+
+	return matches, nil
+}
+
+// matchGlobSegments reports whether the segments of the path match the segments of the pattern, where a **
+// segment matches any number of segments of the path
+func matchGlobSegments(pattern []string, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+
+	if pattern[0] == globDoubleStar {
+		return matchGlobSegments(pattern[1:], parts) || (len(parts) > 0 && matchGlobSegments(pattern, parts[1:]))
+	}
+
+	if len(parts) == 0 {
+		return false
+	}
+
+	ok, _ := path.Match(pattern[0], parts[0])
+	return ok && matchGlobSegments(pattern[1:], parts[1:])
+}
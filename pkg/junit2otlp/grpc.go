@@ -0,0 +1,110 @@
+package junit2otlp
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const defaultCollectorGRPCEndpoint = "localhost:4317"
+
+// signalExporterEnvVars the environment variables configuring the connection of a single signal. If any of
+// them is set, each exporter dials its own connection, so the per-signal settings are honoured
+var signalExporterEnvVars = []string{
+	"OTEL_EXPORTER_OTLP_CERTIFICATE",
+	"OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE",
+	"OTEL_EXPORTER_OTLP_LOGS_CERTIFICATE",
+	"OTEL_EXPORTER_OTLP_LOGS_ENDPOINT",
+	"OTEL_EXPORTER_OTLP_LOGS_INSECURE",
+	"OTEL_EXPORTER_OTLP_LOGS_PROTOCOL",
+	"OTEL_EXPORTER_OTLP_METRICS_CERTIFICATE",
+	"OTEL_EXPORTER_OTLP_METRICS_ENDPOINT",
+	"OTEL_EXPORTER_OTLP_METRICS_INSECURE",
+	"OTEL_EXPORTER_OTLP_METRICS_PROTOCOL",
+	"OTEL_EXPORTER_OTLP_TRACES_CERTIFICATE",
+	"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT",
+	"OTEL_EXPORTER_OTLP_TRACES_INSECURE",
+	"OTEL_EXPORTER_OTLP_TRACES_PROTOCOL",
+}
+
+// newGRPCConn creates the gRPC client connection shared by the traces, metrics and logs exporters, so the
+// connection and the TLS handshake happen once per run. It returns nil if the connection cannot be shared, or if
+// the exporters use OTLP/HTTP
+func newGRPCConn(cfg *config.Config) (*grpc.ClientConn, error) {
+	for _, envVar := range signalExporterEnvVars {
+		if os.Getenv(envVar) != "" {
+			return nil, nil
+		}
+	}
+
+	if protocol, err := otlpProtocol(cfg, ""); err != nil || protocol != protocolGRPC {
+		// the invalid protocols are reported by the exporters
+		return nil, nil
+	}
+
+	target, plaintext, ok := grpcTarget(cfg)
+	if !ok {
+		return nil, nil
+	}
+
+	creds := credentials.NewTLS(nil)
+	if plaintext {
+		creds = insecure.NewCredentials()
+	}
+
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the gRPC connection to %s: %w", target, err)
+	}
+
+	return conn, nil
+}
+
+// grpcTarget resolves the host and port of the collector, and whether the connection is not secure, the
+// same way the exporters do: the endpoint from the configuration wins over the OTEL_EXPORTER_OTLP_ENDPOINT
+// environment variable, and the http scheme makes the connection insecure. It returns false if the endpoint
+// is not a valid URL, leaving the exporters to report it
+func grpcTarget(cfg *config.Config) (string, bool, bool) {
+	return otlpTarget(cfg, defaultCollectorGRPCEndpoint)
+}
+
+// otlpTarget resolves the host and port of the collector, and whether the connection is not secure, as grpcTarget
+// does, with the default endpoint of the protocol. The endpoints without port use the one of the default endpoint
+func otlpTarget(cfg *config.Config, defaultEndpoint string) (string, bool, bool) {
+	plaintext := cfg.Exporter.Insecure
+	if !plaintext {
+		if v, err := strconv.ParseBool(os.Getenv("OTEL_EXPORTER_OTLP_INSECURE")); err == nil {
+			plaintext = v
+		}
+	}
+
+	endpoint := getOtlpEnvVar(cfg.Exporter.Endpoint, "OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	if endpoint == "" {
+		return defaultEndpoint, plaintext, true
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return "", false, false
+	}
+
+	if strings.EqualFold(u.Scheme, "http") {
+		plaintext = true
+	}
+
+	target := u.Host
+	if u.Port() == "" {
+		_, port, _ := net.SplitHostPort(defaultEndpoint)
+		target = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	return target, plaintext, true
+}
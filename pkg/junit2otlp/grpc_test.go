@@ -0,0 +1,80 @@
+package junit2otlp
+
+import (
+	"testing"
+
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrpcTarget(t *testing.T) {
+	t.Run("Default endpoint", func(t *testing.T) {
+		t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+		t.Setenv("OTEL_EXPORTER_OTLP_INSECURE", "")
+
+		target, plaintext, ok := grpcTarget(config.NewConfigFromDefaults())
+		require.True(t, ok)
+		require.Equal(t, defaultCollectorGRPCEndpoint, target)
+		require.False(t, plaintext)
+	})
+
+	t.Run("Endpoint from the configuration wins", func(t *testing.T) {
+		t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "https://env:4317")
+
+		cfg := config.NewConfigFromDefaults()
+		cfg.Exporter.Endpoint = "http://collector:14317"
+
+		target, plaintext, ok := grpcTarget(cfg)
+		require.True(t, ok)
+		require.Equal(t, "collector:14317", target)
+		require.True(t, plaintext)
+	})
+
+	t.Run("Endpoint from the environment without port", func(t *testing.T) {
+		t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "https://collector")
+		t.Setenv("OTEL_EXPORTER_OTLP_INSECURE", "")
+
+		target, plaintext, ok := grpcTarget(config.NewConfigFromDefaults())
+		require.True(t, ok)
+		require.Equal(t, "collector:4317", target)
+		require.False(t, plaintext)
+	})
+
+	t.Run("Insecure from the environment", func(t *testing.T) {
+		t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "https://collector:4317")
+		t.Setenv("OTEL_EXPORTER_OTLP_INSECURE", "true")
+
+		_, plaintext, ok := grpcTarget(config.NewConfigFromDefaults())
+		require.True(t, ok)
+		require.True(t, plaintext)
+	})
+
+	t.Run("Invalid endpoint", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.Exporter.Endpoint = "collector:4317"
+
+		_, _, ok := grpcTarget(cfg)
+		require.False(t, ok)
+	})
+}
+
+func TestNewGRPCConn(t *testing.T) {
+	t.Run("Shared connection", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.Exporter.Endpoint = "http://localhost:4317"
+
+		conn, err := newGRPCConn(cfg)
+		require.NoError(t, err)
+		require.NotNil(t, conn)
+		require.Equal(t, "localhost:4317", conn.Target())
+		require.NoError(t, conn.Close())
+	})
+
+	t.Run("Per-signal endpoints are not shared", func(t *testing.T) {
+		t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "http://traces:4317")
+
+		conn, err := newGRPCConn(config.NewConfigFromDefaults())
+		require.NoError(t, err)
+		require.Nil(t, conn)
+	})
+}
@@ -0,0 +1,284 @@
+package junit2otlp
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"go.opentelemetry.io/otel/trace"
+	_ "modernc.org/sqlite"
+)
+
+// sqliteDriver name of the database/sql driver of the history store, written in pure Go, so the history store is
+// available in the static builds, i.e. the released binaries and the Docker image
+const sqliteDriver = "sqlite"
+
+// historySchema the tables of the history store, created when the store is opened
+const historySchema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	timestamp TEXT NOT NULL,
+	trace_id TEXT,
+	service_name TEXT
+);
+CREATE TABLE IF NOT EXISTS test_results (
+	run_id INTEGER NOT NULL REFERENCES runs(id),
+	suite TEXT NOT NULL,
+	classname TEXT NOT NULL,
+	name TEXT NOT NULL,
+	status TEXT NOT NULL,
+	duration_ms INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS test_results_test ON test_results (suite, classname, name);
+`
+
+// historyStore the SQLite database recording the outcomes and the durations of the test cases of each run
+type historyStore struct {
+	db *sql.DB
+}
+
+// openHistory opens the SQLite database at path, creating it and its tables if they do not exist
+func openHistory(path string) (*historyStore, error) {
+	db, err := sql.Open(sqliteDriver, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the history store: %w", err)
+	}
+
+	if _, err := db.Exec(historySchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create the tables of the history store %s: %w", path, err)
+	}
+
+	return &historyStore{db: db}, nil
+}
+
+func (h *historyStore) close() error {
+	return h.db.Close()
+}
+
+// record stores the outcomes of the test cases of the suites as a new run, in a single transaction
+func (h *historyStore) record(ctx context.Context, serviceName string, traceID trace.TraceID, timestamp time.Time, suites []junit.Suite) error {
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var id sql.NullString
+	if traceID.IsValid() {
+		id = sql.NullString{String: traceID.String(), Valid: true}
+	}
+
+	res, err := tx.ExecContext(ctx, "INSERT INTO runs (timestamp, trace_id, service_name) VALUES (?, ?, ?)", timestamp.UTC().Format(time.RFC3339Nano), id, serviceName)
+	if err != nil {
+		return err
+	}
+
+	runID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, "INSERT INTO test_results (run_id, suite, classname, name, status, duration_ms) VALUES (?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, suite := range suites {
+		for _, test := range suite.Tests {
+			if _, err := stmt.ExecContext(ctx, runID, suite.Name, test.Classname, test.Name, string(test.Status), test.Duration.Milliseconds()); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// testHistory the outcomes of a test case in the runs recorded in the history store
+type testHistory struct {
+	Suite     string
+	Classname string
+	Name      string
+	Runs      int
+	Passed    int
+	Failed    int
+	Skipped   int
+	// AvgDuration mean duration of the runs of the test case
+	AvgDuration time.Duration
+	// LastStatus status of the test case in the last run where it was present
+	LastStatus junit.Status
+	// Streak number of consecutive runs, up to the last one, with the last status
+	Streak int
+}
+
+// FailureRate ratio of the runs of the test case which failed or errored
+func (t testHistory) FailureRate() float64 {
+	if t.Runs == 0 {
+		return 0
+	}
+
+	return float64(t.Failed) / float64(t.Runs)
+}
+
+// tests returns the history of each test case, sorted by the number of failures in descending order, then by name
+func (h *historyStore) tests(ctx context.Context) ([]testHistory, error) {
+	rows, err := h.db.QueryContext(ctx, "SELECT suite, classname, name, status, duration_ms FROM test_results ORDER BY run_id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the history store: %w", err)
+	}
+	defer rows.Close()
+
+	histories := map[historyKey]*testHistory{}
+	durations := map[historyKey]int64{}
+
+	for rows.Next() {
+		var key historyKey
+		var status string
+		var durationMs int64
+		if err := rows.Scan(&key.suite, &key.classname, &key.name, &status, &durationMs); err != nil {
+			return nil, fmt.Errorf("failed to read the history store: %w", err)
+		}
+
+		history, ok := histories[key]
+		if !ok {
+			history = &testHistory{Suite: key.suite, Classname: key.classname, Name: key.name}
+			histories[key] = history
+		}
+
+		history.Runs++
+		durations[key] += durationMs
+
+		switch junit.Status(status) {
+		case junit.StatusPassed:
+			history.Passed++
+		case junit.StatusFailed, junit.StatusError:
+			history.Failed++
+		case junit.StatusSkipped:
+			history.Skipped++
+		}
+
+		if history.LastStatus == junit.Status(status) {
+			history.Streak++
+		} else {
+			history.LastStatus = junit.Status(status)
+			history.Streak = 1
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the history store: %w", err)
+	}
+
+	tests := make([]testHistory, 0, len(histories))
+	for key, history := range histories {
+		history.AvgDuration = time.Duration(durations[key]/int64(history.Runs)) * time.Millisecond
+		tests = append(tests, *history)
+	}
+
+	slices.SortFunc(tests, func(a, b testHistory) int {
+		if c := cmp.Compare(b.Failed, a.Failed); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(strings.Join([]string{a.Suite, a.Classname, a.Name}, "\x00"), strings.Join([]string{b.Suite, b.Classname, b.Name}, "\x00"))
+	})
+
+	return tests, nil
+}
+
+// historyKey identifies a test case in the history store
+type historyKey struct {
+	suite, classname, name string
+}
+
+// durationBaselines returns the statistics of the durations of each test case in the runs recorded in the history
+// store, in milliseconds, without the runs where the test case was skipped
+func (h *historyStore) durationBaselines(ctx context.Context) (map[historyKey]durationStats, error) {
+	rows, err := h.db.QueryContext(ctx, `SELECT suite, classname, name, COUNT(*), AVG(duration_ms), AVG(duration_ms * duration_ms)
+		FROM test_results WHERE status != ? GROUP BY suite, classname, name`, string(junit.StatusSkipped))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the history store: %w", err)
+	}
+	defer rows.Close()
+
+	baselines := map[historyKey]durationStats{}
+	for rows.Next() {
+		var key historyKey
+		var count int
+		var mean, meanSquares float64
+		if err := rows.Scan(&key.suite, &key.classname, &key.name, &count, &mean, &meanSquares); err != nil {
+			return nil, fmt.Errorf("failed to read the history store: %w", err)
+		}
+
+		baselines[key] = durationStats{count: count, mean: mean, stddev: math.Sqrt(max(0, meanSquares-mean*mean))}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the history store: %w", err)
+	}
+
+	return baselines, nil
+}
+
+// printHistory writes a table with the history of each test case to w
+func printHistory(w io.Writer, tests []testHistory) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "SUITE\tTEST\tRUNS\tPASSED\tFAILED\tSKIPPED\tFAILURE RATE\tAVG DURATION\tLAST STATUS\tSTREAK")
+	for _, t := range tests {
+		name := t.Name
+		if t.Classname != "" {
+			name = t.Classname + "." + t.Name
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%.1f%%\t%s\t%s\t%d\n",
+			t.Suite, name, t.Runs, t.Passed, t.Failed, t.Skipped, t.FailureRate()*100, formatDuration(t.AvgDuration), t.LastStatus, t.Streak)
+	}
+
+	return tw.Flush()
+}
+
+// History prints a table with the outcomes of each test case recorded in the history store of the configuration:
+// the number of runs, passes, failures and skips, the mean duration, and the streak of its last status
+func History(ctx context.Context, cfg *config.Config, w io.Writer) error {
+	if cfg.HistoryDB == "" {
+		return fmt.Errorf("the history store is not configured: set the history-db")
+	}
+
+	store, err := openHistory(cfg.HistoryDB)
+	if err != nil {
+		return err
+	}
+	defer store.close()
+
+	tests, err := store.tests(ctx)
+	if err != nil {
+		return err
+	}
+
+	return printHistory(w, tests)
+}
+
+// recordHistory records the run in the history store of the configuration
+func recordHistory(ctx context.Context, cfg *config.Config, traceID trace.TraceID, suites []junit.Suite) error {
+	store, err := openHistory(cfg.HistoryDB)
+	if err != nil {
+		return err
+	}
+	defer store.close()
+
+	if err := store.record(ctx, getOtlpServiceName(cfg), traceID, time.Now(), suites); err != nil {
+		return fmt.Errorf("failed to record the run in the history store: %w", err)
+	}
+
+	return nil
+}
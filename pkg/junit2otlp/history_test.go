@@ -0,0 +1,115 @@
+package junit2otlp
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// historyRun returns the suites of a run with the outcomes of the tests a and b
+func historyRun(a junit.Status, b junit.Status) []junit.Suite {
+	return []junit.Suite{
+		{
+			Name: "suite",
+			Tests: []junit.Test{
+				{Name: "a", Classname: "pkg.Class", Status: a, Duration: 100 * time.Millisecond},
+				{Name: "b", Classname: "pkg.Class", Status: b, Duration: 300 * time.Millisecond},
+			},
+		},
+	}
+}
+
+func TestHistoryStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "history.db")
+
+	store, err := openHistory(path)
+	require.NoError(t, err)
+
+	require.NoError(t, store.record(ctx, "svc", trace.TraceID{0x01}, time.Now(), historyRun(junit.StatusPassed, junit.StatusFailed)))
+	require.NoError(t, store.record(ctx, "svc", trace.TraceID{0x02}, time.Now(), historyRun(junit.StatusFailed, junit.StatusPassed)))
+	require.NoError(t, store.close())
+
+	// the runs are kept when the store is opened again
+	store, err = openHistory(path)
+	require.NoError(t, err)
+	defer store.close()
+
+	require.NoError(t, store.record(ctx, "svc", trace.TraceID{}, time.Now(), historyRun(junit.StatusFailed, junit.StatusPassed)))
+
+	tests, err := store.tests(ctx)
+	require.NoError(t, err)
+	require.Len(t, tests, 2)
+
+	require.Equal(t, testHistory{
+		Suite:       "suite",
+		Classname:   "pkg.Class",
+		Name:        "a",
+		Runs:        3,
+		Passed:      1,
+		Failed:      2,
+		AvgDuration: 100 * time.Millisecond,
+		LastStatus:  junit.StatusFailed,
+		Streak:      2,
+	}, tests[0])
+	require.InDelta(t, 0.67, tests[0].FailureRate(), 0.01)
+
+	require.Equal(t, "b", tests[1].Name)
+	require.Equal(t, 1, tests[1].Failed)
+	require.Equal(t, junit.StatusPassed, tests[1].LastStatus)
+	require.Equal(t, 2, tests[1].Streak)
+}
+
+func TestHistoryStore_DurationBaselines(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := openHistory(filepath.Join(t.TempDir(), "history.db"))
+	require.NoError(t, err)
+	defer store.close()
+
+	require.NoError(t, store.record(ctx, "svc", trace.TraceID{0x01}, time.Now(), historyRun(junit.StatusPassed, junit.StatusPassed)))
+	require.NoError(t, store.record(ctx, "svc", trace.TraceID{0x02}, time.Now(), historyRun(junit.StatusFailed, junit.StatusSkipped)))
+
+	run := historyRun(junit.StatusPassed, junit.StatusPassed)
+	run[0].Tests[0].Duration = 400 * time.Millisecond
+	require.NoError(t, store.record(ctx, "svc", trace.TraceID{0x03}, time.Now(), run))
+
+	baselines, err := store.durationBaselines(ctx)
+	require.NoError(t, err)
+
+	a := baselines[historyKey{suite: "suite", classname: "pkg.Class", name: "a"}]
+	require.Equal(t, 3, a.count)
+	require.Equal(t, 200.0, a.mean)
+	require.InDelta(t, 141.42, a.stddev, 0.01)
+
+	// the skipped runs are not part of the baseline
+	b := baselines[historyKey{suite: "suite", classname: "pkg.Class", name: "b"}]
+	require.Equal(t, durationStats{count: 2, mean: 300}, b)
+}
+
+func TestHistory(t *testing.T) {
+	t.Run("Prints the history of the tests", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.HistoryDB = filepath.Join(t.TempDir(), "history.db")
+
+		require.NoError(t, recordHistory(context.Background(), cfg, trace.TraceID{0x01}, historyRun(junit.StatusPassed, junit.StatusSkipped)))
+
+		var buf bytes.Buffer
+		require.NoError(t, History(context.Background(), cfg, &buf))
+		require.Contains(t, buf.String(), "SUITE")
+		require.Contains(t, buf.String(), "pkg.Class.a")
+		require.Contains(t, buf.String(), "skipped")
+	})
+
+	t.Run("Without history store", func(t *testing.T) {
+		err := History(context.Background(), config.NewConfigFromDefaults(), &bytes.Buffer{})
+		require.ErrorContains(t, err, "the history store is not configured")
+	})
+}
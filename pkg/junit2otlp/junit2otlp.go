@@ -0,0 +1,1053 @@
+package junit2otlp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/internal/coverage"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutlog"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/metric"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+const (
+	// SpanProcessorBatch exports the spans in batches, in the background
+	SpanProcessorBatch = "batch"
+	// SpanProcessorSimple exports each span synchronously as soon as it ends
+	SpanProcessorSimple = "simple"
+)
+
+// shutdownTimeout maximum time to flush the pending telemetry when the providers are shut down
+const shutdownTimeout = 30 * time.Second
+
+const (
+	// exporterOTLP exports the telemetry with OTLP, which is the default
+	exporterOTLP = "otlp"
+	// exporterConsole writes the telemetry to stdout, as JSON
+	exporterConsole = "console"
+	// exporterNone drops the telemetry
+	exporterNone = "none"
+	// exporterStdout alias of the console exporter, for the exporter of the configuration
+	exporterStdout = "stdout"
+	// exporterFile writes the spans to the exporter file of the configuration as OTLP JSON, dropping the metrics
+	// and the log records
+	exporterFile = "file"
+)
+
+// selectedExporter returns the exporter selected in the environment variable, i.e. OTEL_TRACES_EXPORTER, as the
+// OpenTelemetry specification defines them. It defaults to OTLP
+func selectedExporter(envVarKey string) (string, error) {
+	exporter := strings.ToLower(strings.TrimSpace(os.Getenv(envVarKey)))
+
+	switch exporter {
+	case "":
+		return exporterOTLP, nil
+	case exporterOTLP, exporterConsole, exporterNone:
+		return exporter, nil
+	default:
+		return "", fmt.Errorf("invalid exporter in %s: %s. Supported exporters: %s, %s, %s", envVarKey, exporter, exporterOTLP, exporterConsole, exporterNone)
+	}
+}
+
+// signalExporter returns the exporter of the signal: the exporter of the configuration, if set, selects the one
+// of all the signals, overriding the environment variable of the signal, i.e. OTEL_TRACES_EXPORTER
+func signalExporter(cfg *config.Config, envVarKey string) (string, error) {
+	exporter := strings.ToLower(strings.TrimSpace(cfg.Exporter.Type))
+
+	switch exporter {
+	case "":
+		return selectedExporter(envVarKey)
+	case exporterStdout:
+		return exporterConsole, nil
+	case exporterFile:
+		if cfg.Exporter.File == "" {
+			return "", fmt.Errorf("the file of the %s exporter is not set: set the exporter-file", exporterFile)
+		}
+		return exporter, nil
+	case exporterOTLP, exporterConsole, exporterNone:
+		return exporter, nil
+	default:
+		return "", fmt.Errorf("invalid exporter: %s. Supported exporters: %s, %s, %s, %s, %s", exporter, exporterOTLP, exporterStdout, exporterConsole, exporterFile, exporterNone)
+	}
+}
+
+func createIntCounter(meter metric.Meter, name string, description string) metric.Int64Counter {
+	counter, _ := meter.Int64Counter(name, metric.WithDescription(description))
+	// Accumulators always return nil errors
+	// see https://github.com/open-telemetry/opentelemetry-go/blob/e8fbfd3ec52d8153eea3f13465b7de15cd8f6320/sdk/metric/sdk.go#L256-L264
+	return counter
+}
+
+func createIntHistogram(meter metric.Meter, name string, description string, buckets []float64) metric.Int64Histogram {
+	options := []metric.Int64HistogramOption{metric.WithDescription(description), metric.WithUnit("ms")}
+	if len(buckets) > 0 {
+		options = append(options, metric.WithExplicitBucketBoundaries(buckets...))
+	}
+
+	// as the counters, the histograms always return nil errors
+	histogram, _ := meter.Int64Histogram(name, options...)
+	return histogram
+}
+
+// suiteInstruments the tracer, the logger and the metric instruments the suites of a service are recorded with
+type suiteInstruments struct {
+	tracer          trace.Tracer
+	logger          log.Logger
+	durationCounter metric.Int64Counter
+	errorCounter    metric.Int64Counter
+	failedCounter   metric.Int64Counter
+	passedCounter   metric.Int64Counter
+	retriesCounter  metric.Int64Counter
+	skippedCounter  metric.Int64Counter
+	testsCounter    metric.Int64Counter
+	coverageGauge   metric.Float64Gauge
+	suiteDurations  metric.Int64Histogram
+	testDurations   metric.Int64Histogram
+}
+
+// newSuiteInstruments returns the instruments of the service. The histograms of the durations use the bucket
+// boundaries, in milliseconds, or the default ones of the SDK if empty
+func newSuiteInstruments(providers Providers, name string, buckets []float64) *suiteInstruments {
+	meter := providers.meterProvider().Meter(name)
+	coverageGauge, _ := meter.Float64Gauge(TestsCoveragePercentage, metric.WithDescription("Percentage of the lines covered by the tests of the package"), metric.WithUnit("%"))
+	suiteDurations := createIntHistogram(meter, TestsDurationHistogram, "Distribution of the durations of the suites", buckets)
+	testDurations := createIntHistogram(meter, TestDurationHistogram, "Distribution of the durations of the test cases", buckets)
+
+	return &suiteInstruments{
+		tracer:          providers.TracerProvider.Tracer(name),
+		logger:          providers.loggerProvider().Logger(name),
+		durationCounter: createIntCounter(meter, TestsDuration, "Duration of the tests"),
+		errorCounter:    createIntCounter(meter, ErrorTestsCount, "Total number of failed tests"),
+		failedCounter:   createIntCounter(meter, FailedTestsCount, "Total number of failed tests"),
+		passedCounter:   createIntCounter(meter, PassedTestsCount, "Total number of passed tests"),
+		retriesCounter:  createIntCounter(meter, TestRetries, "Total number of reruns of the failed tests"),
+		skippedCounter:  createIntCounter(meter, SkippedTestsCount, "Total number of skipped tests"),
+		testsCounter:    createIntCounter(meter, TotalTestsCount, "Total number of executed tests"),
+		coverageGauge:   coverageGauge,
+		suiteDurations:  suiteDurations,
+		testDurations:   testDurations,
+	}
+}
+
+// createTracesAndSpans creates the spans and metrics for the suites as they are received, returning the context
+// of the span identifying the run once the channel is closed: the root span, the parent span of the TRACEPARENT
+// environment variable without it, or the first suite without both. The start times of the suites, if known, are added as attributes, as
+// the line coverage of their packages if the coverage report is not nil. The suites owned by other services are
+// sent to their providers, in the same trace.
+//
+// The spans are laid out as the tests ran: a suite starts at its start time, or ends at the time of the conversion
+// if it's unknown, and lasts as long as its test cases, which run one after the other from its start, as the
+// reports have no start time per test case. The root span starts with the first suite, if it started earlier.
+// If configured, the test cases are grouped by their classname, under a span per class
+func createTracesAndSpans(ctx context.Context, cfg *config.Config, srvName string, providers Providers, runtimeAttributes []attribute.KeyValue, coverageReport *coverage.Report, suites <-chan reportSuite) (trace.SpanContext, error) {
+	instruments := newSuiteInstruments(providers, srvName, cfg.HistogramBuckets)
+	tracer := instruments.tracer
+
+	// the instruments of the services the suites are sent to, other than the service of the run
+	serviceInstruments := map[string]*suiteInstruments{}
+
+	// without the root span, the suites are top-level spans, or children of the incoming TRACEPARENT
+	var outerSpan trace.Span
+
+	// the suites are read ahead by one, so the root span covers the execution of the first one
+	now := time.Now()
+	first, more := <-suites
+	runStart, runEnd := now, now
+	if more {
+		if start := suiteStart(first, now); start.Before(runStart) {
+			runStart = start
+		}
+	}
+
+	if !cfg.SkipRootSpan {
+		ctx, outerSpan = tracer.Start(ctx, cfg.TraceName, trace.WithAttributes(runtimeAttributes...), trace.WithSpanKind(trace.SpanKindServer), trace.WithTimestamp(runStart))
+		defer func() {
+			outerSpan.End(trace.WithTimestamp(latest(runEnd, time.Now())))
+		}()
+
+		if coverageReport != nil {
+			outerSpan.SetAttributes(prefixAttributes(cfg.AttributePrefix, coverageAttributes(coverageReport.Total()))...)
+		}
+	}
+
+	runSpan := trace.SpanContextFromContext(ctx)
+
+	// the test cases with a file in the repository link to it, if the host of the repository is known
+	links := newSourceLinks(cfg.RepositoryPath)
+
+	// the slow test cases are flagged if the threshold is set
+	anomalies := newAnomalyDetector(ctx, cfg)
+
+	// test spans created and dropped when the -max-spans limit is reached. The metrics are always accurate
+	testSpans := 0
+	droppedSpans := 0
+
+	// the combined totals of the suites of a matrix build
+	runTotals := junit.Totals{}
+	reruns := 0
+
+	for rs := first; more; rs, more = <-suites {
+		suite := rs.suite
+		totals := suite.Totals
+
+		runTotals.Tests += totals.Tests
+		runTotals.Passed += totals.Passed
+		runTotals.Failed += totals.Failed
+		runTotals.Error += totals.Error
+		runTotals.Skipped += totals.Skipped
+		reruns += rs.reruns
+
+		suiteAttributes := getSuiteAttributes(cfg, suite, runtimeAttributes)
+		suiteAttributes = append(suiteAttributes, prefixAttributes(cfg.AttributePrefix, rs.dimensions)...)
+		suiteAttributes = append(suiteAttributes, pluginAttributes(ctx, cfg, suite)...)
+		if !rs.startTime.IsZero() {
+			timestamp := attribute.Key(TestsSuiteTimestamp).String(rs.startTime.UTC().Format(time.RFC3339Nano))
+			suiteAttributes = append(suiteAttributes, prefixAttributes(cfg.AttributePrefix, []attribute.KeyValue{timestamp})...)
+		}
+
+		pkgCoverage, hasCoverage := suiteCoverage(coverageReport, suite)
+		if hasCoverage {
+			suiteAttributes = append(suiteAttributes, prefixAttributes(cfg.AttributePrefix, coverageAttributes(pkgCoverage))...)
+		}
+
+		suiteInst := instruments
+		if service := suiteServiceName(cfg, suite); service != "" && service != srvName && providers.ServiceProviders != nil {
+			if suiteInst = serviceInstruments[service]; suiteInst == nil {
+				suiteInst = newSuiteInstruments(providers.ServiceProviders(service), srvName, cfg.HistogramBuckets)
+				serviceInstruments[service] = suiteInst
+			}
+		}
+
+		attributeSet := attribute.NewSet(suiteAttributes...)
+		metricAttributes := metric.WithAttributeSet(attributeSet)
+
+		suiteInst.durationCounter.Add(ctx, totals.Duration.Milliseconds(), metricAttributes)
+		suiteInst.errorCounter.Add(ctx, int64(totals.Error), metricAttributes)
+		suiteInst.failedCounter.Add(ctx, int64(totals.Failed), metricAttributes)
+		suiteInst.passedCounter.Add(ctx, int64(totals.Passed), metricAttributes)
+		suiteInst.skippedCounter.Add(ctx, int64(totals.Skipped), metricAttributes)
+		suiteInst.testsCounter.Add(ctx, int64(totals.Tests), metricAttributes)
+		suiteInst.suiteDurations.Record(ctx, totals.Duration.Milliseconds(), metricAttributes)
+		recordTestDurations(ctx, cfg, suiteInst.testDurations, suite, suiteAttributes)
+		if hasCoverage {
+			suiteInst.coverageGauge.Record(ctx, pkgCoverage.Percentage(), metricAttributes)
+		}
+
+		start := suiteStart(rs, time.Now())
+		ctx, suiteSpan := suiteInst.tracer.Start(ctx, suite.Name, trace.WithAttributes(limitSpanAttributes(cfg, suiteAttributes)...), trace.WithTimestamp(start))
+		emitOversizedOutput(ctx, cfg, suiteInst.logger, outputStdout, suite.SystemOut)
+		emitOversizedOutput(ctx, cfg, suiteInst.logger, outputStderr, suite.SystemErr)
+		sharedAttributes := getSharedSuiteAttributes(cfg, suiteAttributes)
+		if !runSpan.IsValid() {
+			// the first top-level suite identifies the run when there is neither a root span nor a parent
+			runSpan = suiteSpan.SpanContext()
+		}
+
+		suiteAnomalies := anomalies.detect(suite)
+		suiteFlaky, suiteRetries := flakyTests(suite)
+		if suiteRetries > 0 {
+			suiteInst.retriesCounter.Add(ctx, int64(suiteRetries), metricAttributes)
+		}
+		suiteDroppedSpans := 0
+		for _, shard := range suiteShards(rs, start) {
+			// the test cases of the merged suites are children of the span of their shard
+			shardCtx := ctx
+			var shardSpan trace.Span
+			if shard.rs != nil && (cfg.MaxSpans <= 0 || testSpans < cfg.MaxSpans) {
+				shardAttributes := getSuiteAttributes(cfg, shard.rs.suite, runtimeAttributes)
+				shardAttributes = append(shardAttributes, prefixAttributes(cfg.AttributePrefix, append(slices.Clip(shard.rs.dimensions), attribute.Key(TestsSuiteShard).String(shard.name)))...)
+				shardCtx, shardSpan = suiteInst.tracer.Start(ctx, shard.name, trace.WithAttributes(limitSpanAttributes(cfg, shardAttributes)...), trace.WithTimestamp(shard.start))
+				emitOversizedOutput(shardCtx, cfg, suiteInst.logger, outputStdout, shard.rs.suite.SystemOut)
+				emitOversizedOutput(shardCtx, cfg, suiteInst.logger, outputStderr, shard.rs.suite.SystemErr)
+			}
+
+			testStart := shard.start
+			for _, class := range testClasses(cfg, shard.suite) {
+				// the test cases are children of the span of their class, if they are grouped by class
+				classCtx := shardCtx
+				var classSpan trace.Span
+				if class.name != "" && (cfg.MaxSpans <= 0 || testSpans < cfg.MaxSpans) {
+					classAttributes := append(slices.Clip(sharedAttributes), prefixAttributes(cfg.AttributePrefix, []attribute.KeyValue{attribute.Key(TestClassName).String(class.name)})...)
+					classCtx, classSpan = suiteInst.tracer.Start(shardCtx, class.name, trace.WithAttributes(classAttributes...), trace.WithTimestamp(testStart))
+				}
+
+				for _, i := range class.tests {
+					i += shard.offset
+					test := suite.Tests[i]
+					testEnd := testStart.Add(test.Duration)
+					if cfg.MaxSpans > 0 && testSpans >= cfg.MaxSpans {
+						suiteDroppedSpans++
+						testStart = testEnd
+						continue
+					}
+
+					testAttributes := getTestAttributes(cfg, test, sharedAttributes)
+					if links != nil {
+						if permalink := links.url(resolveFileLine(test)); permalink != "" {
+							testAttributes = append(testAttributes, attribute.Key(CodeURL).String(permalink))
+						}
+					}
+					if suiteAnomalies[i] {
+						testAttributes = append(testAttributes, prefixAttributes(cfg.AttributePrefix, []attribute.KeyValue{attribute.Key(TestAnomaly).Bool(true)})...)
+					}
+					if suiteFlaky[i] {
+						testAttributes = append(testAttributes, prefixAttributes(cfg.AttributePrefix, []attribute.KeyValue{attribute.Key(TestFlaky).Bool(true)})...)
+					}
+
+					testCtx, testSpan := suiteInst.tracer.Start(classCtx, test.Name, trace.WithAttributes(limitSpanAttributes(cfg, testAttributes)...), trace.WithTimestamp(testStart))
+					addFailureEvents(cfg, testSpan, test, trace.WithTimestamp(testEnd))
+					emitFailureLogs(testCtx, cfg, suiteInst.logger, test, testEnd)
+					emitOversizedOutput(testCtx, cfg, suiteInst.logger, outputStdout, test.SystemOut)
+					emitOversizedOutput(testCtx, cfg, suiteInst.logger, outputStderr, test.SystemErr)
+					testSpan.End(trace.WithTimestamp(testEnd))
+					testSpans++
+					testStart = testEnd
+				}
+
+				if classSpan != nil {
+					classSpan.End(trace.WithTimestamp(testStart))
+				}
+			}
+
+			if shardSpan != nil {
+				shardSpan.End(trace.WithTimestamp(shard.end))
+			}
+		}
+
+		if suiteDroppedSpans > 0 && outerSpan == nil {
+			suiteSpan.SetAttributes(
+				attribute.Key(TestsRunTruncated).Bool(true),
+				attribute.Key(TestsRunDroppedSpans).Int(suiteDroppedSpans),
+			)
+		}
+
+		droppedSpans += suiteDroppedSpans
+		end := suiteEnd(rs, start)
+		runEnd = latest(runEnd, end)
+		suiteSpan.End(trace.WithTimestamp(end))
+	}
+
+	if cfg.MatrixPattern != "" && outerSpan != nil {
+		outerSpan.SetAttributes(prefixAttributes(cfg.AttributePrefix, []attribute.KeyValue{
+			attribute.Key(TestsRunError).Int(runTotals.Error),
+			attribute.Key(TestsRunFailed).Int(runTotals.Failed),
+			attribute.Key(TestsRunPassed).Int(runTotals.Passed),
+			attribute.Key(TestsRunReruns).Int(reruns),
+			attribute.Key(TestsRunSkipped).Int(runTotals.Skipped),
+			attribute.Key(TestsRunTotal).Int(runTotals.Tests),
+		})...)
+	}
+
+	if droppedSpans > 0 {
+		slog.Warn("maximum number of spans reached, not creating the rest of the test spans", "maxSpans", cfg.MaxSpans, "dropped", droppedSpans)
+
+		if outerSpan != nil {
+			outerSpan.SetAttributes(
+				attribute.Key(TestsRunTruncated).Bool(true),
+				attribute.Key(TestsRunDroppedSpans).Int(droppedSpans),
+			)
+		}
+	}
+
+	return runSpan, nil
+}
+
+// getOtlpEnvVar the precedence order is: flag > env var > fallback
+func getOtlpEnvVar(flag string, envVarKey string, fallback string) string {
+	if flag != "" {
+		return flag
+	}
+
+	envVar := os.Getenv(envVarKey)
+	if envVar != "" {
+		return envVar
+	}
+
+	return fallback
+}
+
+// getOtlpServiceName checks the service name, detecting it from the project manifests
+// at the repository path if neither the flag nor the environment variable are set
+func getOtlpServiceName(cfg *config.Config) string {
+	serviceName := getOtlpEnvVar(cfg.ServiceName, "OTEL_SERVICE_NAME", "")
+	if serviceName != "" {
+		return serviceName
+	}
+
+	serviceName = detectServiceName(cfg.RepositoryPath)
+	if serviceName != "" {
+		return serviceName
+	}
+
+	return Junit2otlp
+}
+
+// getOtlpServiceVersion checks the service version, detecting it from the Git repository
+// at the repository path if neither the flag nor the environment variable are set
+func getOtlpServiceVersion(cfg *config.Config) string {
+	serviceVersion := getOtlpEnvVar(cfg.ServiceVersion, "OTEL_SERVICE_VERSION", "")
+	if serviceVersion != "" {
+		return serviceVersion
+	}
+
+	return detectServiceVersion(cfg.RepositoryPath)
+}
+
+// newSpanProcessor returns the span processor selected in the configuration for the exporter
+func newSpanProcessor(cfg *config.Config, exporter sdktrace.SpanExporter) (sdktrace.SpanProcessor, error) {
+	switch cfg.SpanProcessor {
+	case SpanProcessorBatch:
+		return sdktrace.NewBatchSpanProcessor(exporter, batchSpanProcessorOptions(cfg)...), nil
+	case SpanProcessorSimple:
+		// exports each span synchronously when it ends, so it's only suitable for small reports
+		return sdktrace.NewSimpleSpanProcessor(exporter), nil
+	default:
+		return nil, fmt.Errorf("invalid span processor: %s. Supported processors: %s, %s", cfg.SpanProcessor, SpanProcessorBatch, SpanProcessorSimple)
+	}
+}
+
+// batchSpanProcessorOptions returns the options for the BatchSpanProcessor from the configuration. The settings
+// not present in the configuration are read by the SDK from the OTEL_BSP_* environment variables
+func batchSpanProcessorOptions(cfg *config.Config) []sdktrace.BatchSpanProcessorOption {
+	opts := []sdktrace.BatchSpanProcessorOption{
+		sdktrace.WithMaxExportBatchSize(cfg.BatchSize),
+	}
+
+	if cfg.BatchTimeout > 0 {
+		opts = append(opts, sdktrace.WithBatchTimeout(cfg.BatchTimeout))
+	}
+
+	if cfg.MaxQueueSize > 0 {
+		opts = append(opts, sdktrace.WithMaxQueueSize(cfg.MaxQueueSize))
+	}
+
+	return opts
+}
+
+// metricExporterOptions returns the options for the metrics exporter from the configuration. Settings
+// not present in the configuration are read by the exporter from the OTEL_EXPORTER_OTLP_* environment variables
+func metricExporterOptions(cfg *config.Config, conn *grpc.ClientConn) []otlpmetricgrpc.Option {
+	opts := []otlpmetricgrpc.Option{}
+
+	if cfg.Exporter.Endpoint != "" {
+		opts = append(opts, otlpmetricgrpc.WithEndpointURL(cfg.Exporter.Endpoint))
+	}
+
+	if cfg.Exporter.Insecure {
+		opts = append(opts, otlpmetricgrpc.WithInsecure())
+	}
+
+	if len(cfg.Exporter.Headers) > 0 {
+		opts = append(opts, otlpmetricgrpc.WithHeaders(cfg.Exporter.Headers))
+	}
+
+	if conn != nil {
+		opts = append(opts, otlpmetricgrpc.WithGRPCConn(conn))
+	}
+
+	return opts
+}
+
+// traceExporterOptions returns the options for the traces exporter from the configuration. Settings
+// not present in the configuration are read by the exporter from the OTEL_EXPORTER_OTLP_* environment variables.
+// If the connection is not nil, the exporter uses it instead of dialing its own one
+func traceExporterOptions(cfg *config.Config, conn *grpc.ClientConn) []otlptracegrpc.Option {
+	opts := []otlptracegrpc.Option{}
+
+	if cfg.Exporter.Endpoint != "" {
+		opts = append(opts, otlptracegrpc.WithEndpointURL(cfg.Exporter.Endpoint))
+	}
+
+	if cfg.Exporter.Insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+
+	if len(cfg.Exporter.Headers) > 0 {
+		opts = append(opts, otlptracegrpc.WithHeaders(cfg.Exporter.Headers))
+	}
+
+	if conn != nil {
+		opts = append(opts, otlptracegrpc.WithGRPCConn(conn))
+	}
+
+	return opts
+}
+
+// logExporterOptions returns the options for the logs exporter from the configuration. Settings
+// not present in the configuration are read by the exporter from the OTEL_EXPORTER_OTLP_* environment variables
+func logExporterOptions(cfg *config.Config, conn *grpc.ClientConn) []otlploggrpc.Option {
+	opts := []otlploggrpc.Option{}
+
+	if cfg.Exporter.Endpoint != "" {
+		opts = append(opts, otlploggrpc.WithEndpointURL(cfg.Exporter.Endpoint))
+	}
+
+	if cfg.Exporter.Insecure {
+		opts = append(opts, otlploggrpc.WithInsecure())
+	}
+
+	if len(cfg.Exporter.Headers) > 0 {
+		opts = append(opts, otlploggrpc.WithHeaders(cfg.Exporter.Headers))
+	}
+
+	if conn != nil {
+		opts = append(opts, otlploggrpc.WithGRPCConn(conn))
+	}
+
+	return opts
+}
+
+// initLoggerProvider creates the provider for the log records of the oversized console outputs and of the failures,
+// with the exporter of the configuration or the one selected in the OTEL_LOGS_EXPORTER environment variable
+func initLoggerProvider(ctx context.Context, cfg *config.Config, res *resource.Resource, conn *grpc.ClientConn, lost *lostSignals) (*sdklog.LoggerProvider, error) {
+	selected, err := signalExporter(cfg, "OTEL_LOGS_EXPORTER")
+	if err != nil {
+		return nil, err
+	}
+
+	var exporter sdklog.Exporter
+	switch selected {
+	case exporterNone, exporterFile:
+		return sdklog.NewLoggerProvider(sdklog.WithResource(res)), nil
+	case exporterConsole:
+		exporter, err = stdoutlog.New()
+	default:
+		var protocol string
+		if protocol, err = otlpProtocol(cfg, signalLogs); err != nil {
+			return nil, err
+		}
+
+		if protocol == protocolHTTPProtobuf {
+			exporter, err = otlploghttp.New(ctx, logHTTPExporterOptions(cfg)...)
+			break
+		}
+		exporter, err = otlploggrpc.New(ctx, logExporterOptions(cfg, conn)...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the logs exporter: %v", err)
+	}
+
+	// the log records are not spooled, so losing them fails the run
+	if cfg.ExportSpoolDir != "" {
+		exporter = &lossRecordingLogExporter{Exporter: exporter, lost: lost}
+	}
+
+	loggerProvider := sdklog.NewLoggerProvider(
+		sdklog.WithResource(res),
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)),
+	)
+
+	return loggerProvider, nil
+}
+
+// initMetricsProvider creates the provider for the metrics of the test outcomes, with the exporter of the
+// configuration or the one selected in the OTEL_METRICS_EXPORTER environment variable
+func initMetricsProvider(ctx context.Context, cfg *config.Config, res *resource.Resource, conn *grpc.ClientConn, lost *lostSignals) (*sdkmetric.MeterProvider, error) {
+	selected, err := signalExporter(cfg, "OTEL_METRICS_EXPORTER")
+	if err != nil {
+		return nil, err
+	}
+
+	var exporter sdkmetric.Exporter
+	switch selected {
+	case exporterNone, exporterFile:
+		return sdkmetric.NewMeterProvider(sdkmetric.WithResource(res)), nil
+	case exporterConsole:
+		exporter, err = stdoutmetric.New()
+	default:
+		var protocol string
+		if protocol, err = otlpProtocol(cfg, signalMetrics); err != nil {
+			return nil, err
+		}
+
+		if protocol == protocolHTTPProtobuf {
+			exporter, err = otlpmetrichttp.New(ctx, metricHTTPExporterOptions(cfg)...)
+			break
+		}
+		exporter, err = otlpmetricgrpc.New(ctx, metricExporterOptions(cfg, conn)...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the collector exporter: %v", err)
+	}
+
+	// the metrics are not spooled, so losing them fails the run
+	if cfg.ExportSpoolDir != "" {
+		exporter = &lossRecordingMetricExporter{Exporter: exporter, lost: lost}
+	}
+
+	reader := sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(2*time.Second))
+	meterProvider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(reader),
+		sdkmetric.WithResource(res),
+	)
+
+	return meterProvider, nil
+}
+
+// newTraceClient creates the OTLP client of the traces exporter, for the protocol of the configuration
+func newTraceClient(cfg *config.Config, conn *grpc.ClientConn) (otlptrace.Client, error) {
+	protocol, err := otlpProtocol(cfg, signalTraces)
+	if err != nil {
+		return nil, err
+	}
+
+	if protocol == protocolHTTPProtobuf {
+		return otlptracehttp.NewClient(traceHTTPExporterOptions(cfg)...), nil
+	}
+
+	return otlptracegrpc.NewClient(traceExporterOptions(cfg, conn)...), nil
+}
+
+// initTracerProvider creates the provider for the spans of the suites and test cases, with the exporter of the
+// configuration or the one selected in the OTEL_TRACES_EXPORTER environment variable. Without exporter, the spans are still created, so the trace ID
+// is still reported. The OTLP spans failing to be exported are spooled to disk if the spool directory is set
+func initTracerProvider(ctx context.Context, cfg *config.Config, res *resource.Resource, conn *grpc.ClientConn) (*sdktrace.TracerProvider, error) {
+	selected, err := signalExporter(cfg, "OTEL_TRACES_EXPORTER")
+	if err != nil {
+		return nil, err
+	}
+
+	var traceExporter sdktrace.SpanExporter
+	switch selected {
+	case exporterNone:
+		return sdktrace.NewTracerProvider(sdktrace.WithResource(res)), nil
+	case exporterConsole:
+		traceExporter, err = stdouttrace.New()
+	case exporterFile:
+		traceExporter, err = otlptrace.New(ctx, &fileTraceClient{path: cfg.Exporter.File})
+	default:
+		var client otlptrace.Client
+		if client, err = newTraceClient(cfg, conn); err != nil {
+			return nil, err
+		}
+
+		if cfg.ExportSpoolDir != "" {
+			client = &spoolingTraceClient{Client: client, dir: cfg.ExportSpoolDir}
+		}
+		traceExporter, err = otlptrace.New(ctx, client)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	spanProcessor, err := newSpanProcessor(cfg, traceExporter)
+	if err != nil {
+		return nil, err
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithResource(res),
+		sdktrace.WithSpanProcessor(spanProcessor),
+	)
+
+	return tracerProvider, nil
+}
+
+// Export converts the reports read from the readers, exporting the telemetry with OTLP, as the command line
+// does: it creates the OpenTelemetry providers for the configuration, and shuts them down once the telemetry
+// is exported. The readers are read as a single input, as Run does
+func Export(ctx context.Context, cfg *config.Config, readers ...InputReader) error {
+	ctx = initOtelContext(ctx)
+
+	if err := validateScmAttributesSchema(cfg.ScmAttributesSchema); err != nil {
+		return err
+	}
+
+	if err := validateAttributeSchema(cfg.AttributeSchema); err != nil {
+		return err
+	}
+
+	if err := validateSummaryFormat(cfg.OutputFormat); err != nil {
+		return err
+	}
+
+	reader, err := combineReaders(readers, int64(cfg.MaxInputSize)<<20)
+	if err != nil {
+		return err
+	}
+
+	if cfg.PrintAttributes {
+		suites, _, err := readReport(cfg, reader)
+		if err != nil {
+			return err
+		}
+
+		return printAttributes(ctx, os.Stdout, cfg, resolveRuntimeAttributes(ctx, cfg), suites)
+	}
+
+	// the garbage collector works harder as the limit gets close, and the retained suites are spilled to disk
+	if cfg.MemoryLimit > 0 {
+		debug.SetMemoryLimit(int64(cfg.MemoryLimit) << 20)
+	}
+
+	// the SCM analysis runs while the providers are created, including the service version derived from git,
+	// and while the report is parsed
+	runtimeAttributesCh := startRuntimeAttributes(ctx, cfg)
+
+	providers, shutdown, err := newProviders(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer shutdown()
+
+	if err := run(ctx, cfg, providers, runtimeAttributesCh, reader); err != nil {
+		return err
+	}
+
+	// the pending telemetry is pushed before returning, failing if the metrics or the logs were lost
+	return shutdown()
+}
+
+// newResource creates the OpenTelemetry resource of the providers, with the service name and version resolved by the
+// tool, the attributes of the process, and the ones in OTEL_RESOURCE_ATTRIBUTES. The arguments of the process are not
+// added, as they may include secrets, i.e. the tokens of --otlp-headers
+func newResource(ctx context.Context, cfg *config.Config) (*resource.Resource, error) {
+	// set the service name that will show up in tracing UIs
+	resAttrs := resource.WithAttributes(
+		semconv.ServiceNameKey.String(getOtlpServiceName(cfg)),
+		semconv.ServiceVersionKey.String(getOtlpServiceVersion(cfg)),
+	)
+	// the attributes in OTEL_RESOURCE_ATTRIBUTES are added, overridden by the ones of the configuration, although the
+	// service name and version resolved by the tool win
+	options := []resource.Option{
+		resource.WithProcessPID(),
+		resource.WithProcessExecutableName(),
+		resource.WithProcessExecutablePath(),
+		resource.WithProcessOwner(),
+		resource.WithProcessRuntimeName(),
+		resource.WithProcessRuntimeVersion(),
+		resource.WithProcessRuntimeDescription(),
+		resource.WithFromEnv(),
+	}
+	if cfg.BaggageResourceAttributes {
+		options = append(options, resource.WithAttributes(baggageAttributes(ctx)...))
+	}
+	if len(cfg.ResourceAttributes) > 0 {
+		attributes := make([]attribute.KeyValue, 0, len(cfg.ResourceAttributes))
+		for _, k := range slices.Sorted(maps.Keys(cfg.ResourceAttributes)) {
+			attributes = append(attributes, attribute.String(k, cfg.ResourceAttributes[k]))
+		}
+		options = append(options, resource.WithAttributes(attributes...))
+	}
+	res, err := resource.New(ctx, append(options, resAttrs)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OpenTelemetry service name resource: %s", err)
+	}
+
+	return res, nil
+}
+
+// newProviders creates the OTLP providers of the configuration, sharing the gRPC connection, and the function
+// shutting them down, which pushes the pending telemetry to the receiver and closes the connection. If the
+// services are derived from the suites, the providers of each service are created the first time it's used.
+// The shutdown runs once, failing if the metrics or the logs were lost while the spans were spooled
+func newProviders(ctx context.Context, cfg *config.Config) (Providers, func() error, error) {
+	res, err := newResource(ctx, cfg)
+	if err != nil {
+		return Providers{}, nil, err
+	}
+
+	// the exporters share the connection, closed once all of them are shut down
+	conn, err := newGRPCConn(cfg)
+	if err != nil {
+		return Providers{}, nil, err
+	}
+
+	// the shutdowns run in the reverse order of the creation, guarded as the providers of the services are created
+	// while the suites are sent. They are bounded, but not cancelled with the context, so the telemetry of the runs
+	// interrupted by a signal is still flushed
+	var mu sync.Mutex
+	shutdowns := []func(context.Context){}
+	lost := &lostSignals{}
+	shutdown := func() error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+		defer cancel()
+
+		for i := len(shutdowns) - 1; i >= 0; i-- {
+			shutdowns[i](ctx)
+		}
+		shutdowns = nil
+
+		return lost.err()
+	}
+
+	if conn != nil {
+		shutdowns = append(shutdowns, func(context.Context) { conn.Close() })
+	}
+
+	providers, providerShutdowns, err := initProviders(ctx, cfg, res, conn, lost)
+	shutdowns = append(shutdowns, providerShutdowns...)
+	if err != nil {
+		shutdown()
+		return Providers{}, nil, err
+	}
+
+	if cfg.ServicePerSuite || len(cfg.ServiceMapping) > 0 {
+		services := map[string]Providers{}
+		defaultProviders := providers
+
+		providers.ServiceProviders = func(serviceName string) Providers {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if p, ok := services[serviceName]; ok {
+				return p
+			}
+
+			serviceRes, err := resource.Merge(res, resource.NewSchemaless(semconv.ServiceNameKey.String(serviceName)))
+			if err != nil {
+				slog.Warn("sending the suites of the service under the service of the run", "serviceName", serviceName, "error", err)
+				return defaultProviders
+			}
+
+			p, providerShutdowns, err := initProviders(ctx, cfg, serviceRes, conn, lost)
+			shutdowns = append(shutdowns, providerShutdowns...)
+			if err != nil {
+				slog.Warn("sending the suites of the service under the service of the run", "serviceName", serviceName, "error", err)
+				p = defaultProviders
+			}
+
+			services[serviceName] = p
+			return p
+		}
+	}
+
+	return providers, shutdown, nil
+}
+
+// initProviders creates the OTLP providers for the resource, returning the functions shutting down the ones created,
+// even if there is an error
+func initProviders(ctx context.Context, cfg *config.Config, res *resource.Resource, conn *grpc.ClientConn, lost *lostSignals) (Providers, []func(context.Context), error) {
+	shutdowns := []func(context.Context){}
+
+	tracesProvides, err := initTracerProvider(ctx, cfg, res, conn)
+	if err != nil {
+		return Providers{}, shutdowns, err
+	}
+	shutdowns = append(shutdowns, func(ctx context.Context) {
+		if err := tracesProvides.Shutdown(ctx); err != nil {
+			slog.Error("failed to shutdown the tracer provider", "error", err)
+		}
+	})
+
+	provider, err := initMetricsProvider(ctx, cfg, res, conn, lost)
+	if err != nil {
+		return Providers{}, shutdowns, fmt.Errorf("failed to initialise pusher: %v", err)
+	}
+	shutdowns = append(shutdowns, func(ctx context.Context) {
+		// pushes any last exports to the receiver
+		if err := provider.Shutdown(ctx); err != nil {
+			otel.Handle(err)
+		}
+	})
+
+	providers := Providers{
+		TracerProvider: tracesProvides,
+		MeterProvider:  provider,
+	}
+
+	if !cfg.LogsSkipSending {
+		loggerProvider, err := initLoggerProvider(ctx, cfg, res, conn, lost)
+		if err != nil {
+			return Providers{}, shutdowns, err
+		}
+		shutdowns = append(shutdowns, func(ctx context.Context) {
+			if err := loggerProvider.Shutdown(ctx); err != nil {
+				slog.Error("failed to shutdown the logger provider", "error", err)
+			}
+		})
+
+		providers.LoggerProvider = loggerProvider
+	}
+
+	return providers, shutdowns, nil
+}
+
+// suiteDuration returns the duration of the suite, which is at least the one of its test cases, as the time of
+// the suite may not include their setup
+func suiteDuration(suite junit.Suite) time.Duration {
+	tests := time.Duration(0)
+	for _, test := range suite.Tests {
+		tests += test.Duration
+	}
+
+	return max(suite.Totals.Duration, tests)
+}
+
+// suiteStart returns the start time of the span of the suite: its start time, if known, or the time that makes it
+// end at the time of the conversion. The merged suites last as long as their longest shard
+func suiteStart(rs reportSuite, now time.Time) time.Time {
+	if !rs.startTime.IsZero() {
+		return rs.startTime
+	}
+
+	if len(rs.shards) == 0 {
+		return now.Add(-suiteDuration(rs.suite))
+	}
+
+	duration := time.Duration(0)
+	for _, shard := range rs.shards {
+		duration = max(duration, suiteDuration(shard.suite))
+	}
+
+	return now.Add(-duration)
+}
+
+// suiteEnd returns the end time of the span of the suite starting at the start time: the end of its last shard, if
+// it's merged, as the shards run in parallel, or the end of its test cases otherwise
+func suiteEnd(rs reportSuite, start time.Time) time.Time {
+	if len(rs.shards) == 0 {
+		return start.Add(suiteDuration(rs.suite))
+	}
+
+	end := start
+	for _, shard := range suiteShards(rs, start) {
+		end = latest(end, shard.end)
+	}
+
+	return end
+}
+
+// suiteShard the test cases of a suite run by one of the shards merged into it, starting at the offset in the
+// test cases of the suite, and the times of its span. The suites not merged are their only shard, without span
+type suiteShard struct {
+	rs     *reportSuite
+	suite  junit.Suite
+	name   string
+	offset int
+	start  time.Time
+	end    time.Time
+}
+
+// suiteShards returns the shards of the suite starting at the start time. The span of each shard is named after its
+// report, or after its suite and its position if the report has no name, and starts with its suite, or at the start
+// time if its start time is unknown
+func suiteShards(rs reportSuite, start time.Time) []suiteShard {
+	if len(rs.shards) == 0 {
+		return []suiteShard{{suite: rs.suite, start: start, end: start.Add(suiteDuration(rs.suite))}}
+	}
+
+	shards := make([]suiteShard, 0, len(rs.shards))
+	offset := 0
+	for i := range rs.shards {
+		shard := &rs.shards[i]
+		name := shard.report
+		if name == "" {
+			name = fmt.Sprintf("%s #%d", shard.suite.Name, i+1)
+		}
+
+		shardStart := start
+		if !shard.startTime.IsZero() {
+			shardStart = shard.startTime
+		}
+
+		shards = append(shards, suiteShard{
+			rs:     shard,
+			suite:  shard.suite,
+			name:   name,
+			offset: offset,
+			start:  shardStart,
+			end:    shardStart.Add(suiteDuration(shard.suite)),
+		})
+		offset += len(shard.suite.Tests)
+	}
+
+	return shards
+}
+
+// latest returns the latest of the times
+func latest(a time.Time, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+
+	return b
+}
+
+// testClass the test cases of a suite sharing a classname, by their index in the suite
+type testClass struct {
+	name  string
+	tests []int
+}
+
+// testClasses returns the test cases of the suite grouped by their classname, in the order of their first test
+// case, if configured. The test cases without classname are grouped under the name of the suite. Otherwise, all the
+// test cases belong to a single group without name, so they are children of the suite
+func testClasses(cfg *config.Config, suite junit.Suite) []testClass {
+	if !cfg.GroupByClass {
+		tests := make([]int, len(suite.Tests))
+		for i := range suite.Tests {
+			tests[i] = i
+		}
+
+		return []testClass{{tests: tests}}
+	}
+
+	classes := []testClass{}
+	index := map[string]int{}
+	for i, test := range suite.Tests {
+		name := test.Classname
+		if name == "" {
+			name = suite.Name
+		}
+
+		c, ok := index[name]
+		if !ok {
+			c = len(classes)
+			index[name] = c
+			classes = append(classes, testClass{name: name})
+		}
+
+		classes[c].tests = append(classes[c].tests, i)
+	}
+
+	return classes
+}
+
+// recordTestDurations records the durations of the test cases of the suite in the histogram, with the attributes
+// of the suite and the status of the test case, so the percentiles can be split by outcome. The name of the test
+// cases is not an attribute, to keep the cardinality of the metric bounded
+func recordTestDurations(ctx context.Context, cfg *config.Config, histogram metric.Int64Histogram, suite junit.Suite, suiteAttributes []attribute.KeyValue) {
+	byStatus := map[junit.Status]metric.MeasurementOption{}
+	for _, test := range suite.Tests {
+		options, ok := byStatus[test.Status]
+		if !ok {
+			status := prefixAttributes(cfg.AttributePrefix, []attribute.KeyValue{attribute.Key(TestStatus).String(string(test.Status))})
+			options = metric.WithAttributeSet(attribute.NewSet(append(slices.Clip(suiteAttributes), status...)...))
+			byStatus[test.Status] = options
+		}
+
+		histogram.Record(ctx, test.Duration.Milliseconds(), options)
+	}
+}
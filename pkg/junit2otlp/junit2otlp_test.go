@@ -0,0 +1,976 @@
+package junit2otlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/network"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const exporterEndpointKey = "OTEL_EXPORTER_OTLP_ENDPOINT"
+
+type TestReader struct {
+	testFile string
+}
+
+func (tr *TestReader) Read() ([]byte, error) {
+	b, err := os.ReadFile(tr.testFile)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return b, nil
+}
+
+type TestAttributeValue struct {
+	IntValue    string `json:"intValue"`
+	StringValue string `json:"stringValue"`
+}
+
+type TestAttribute struct {
+	Key   string             `json:"key"`
+	Value TestAttributeValue `json:"value,omitempty"`
+}
+
+type ResourceSpans struct {
+	Spans []ResourceSpan `json:"resourceSpans"`
+}
+type ResourceSpan struct {
+	Resource struct {
+		Attributes []TestAttribute `json:"attributes"`
+	} `json:"resource"`
+	InstrumentationLibrarySpans []struct {
+		InstrumentationLibrary struct {
+			Name string `json:"name"`
+		} `json:"instrumentationLibrary"`
+		Spans []struct {
+			TraceID           string          `json:"traceId"`
+			SpanID            string          `json:"spanId"`
+			ParentSpanID      string          `json:"parentSpanId"`
+			Name              string          `json:"name"`
+			Kind              string          `json:"kind"`
+			StartTimeUnixNano string          `json:"startTimeUnixNano"`
+			EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+			Attributes        []TestAttribute `json:"attributes"`
+			Status            struct {
+			} `json:"status"`
+		} `json:"spans"`
+	} `json:"instrumentationLibrarySpans"`
+}
+
+type ResourceMetrics struct {
+	Metrics []ResourceMetric `json:"resourceMetrics"`
+}
+type ResourceMetric struct {
+	Resource struct {
+		Attributes []TestAttribute `json:"attributes"`
+	} `json:"resource"`
+	InstrumentationLibraryMetrics []struct {
+		InstrumentationLibrary struct {
+			Name string `json:"name"`
+		} `json:"instrumentationLibrary"`
+		Metrics []struct {
+			Name        string `json:"name"`
+			Description string `json:"description"`
+			Sum         struct {
+				DataPoints []struct {
+					Attributes        []TestAttribute `json:"attributes"`
+					StartTimeUnixNano string          `json:"startTimeUnixNano"`
+					TimeUnixNano      string          `json:"timeUnixNano"`
+					AsInt             string          `json:"asInt"`
+				} `json:"dataPoints"`
+				AggregationTemporality string `json:"aggregationTemporality"`
+				IsMonotonic            bool   `json:"isMonotonic"`
+			} `json:"sum"`
+		} `json:"metrics"`
+	} `json:"instrumentationLibraryMetrics"`
+	SchemaURL string `json:"schemaUrl"`
+}
+
+// TestReport holds references to the File exporter defined by the otel-collector
+type TestReport struct {
+	resourceSpans   ResourceSpans
+	resourceMetrics ResourceMetrics
+}
+
+func assertStringValueInAttribute(t *testing.T, att TestAttributeValue, expected string) {
+	t.Helper()
+
+	require.Equal(t, expected, att.StringValue)
+}
+
+func requireAttributeInArray(t *testing.T, attributes []TestAttribute, key string) TestAttribute {
+	t.Helper()
+
+	for _, att := range attributes {
+		if att.Key == key {
+			return att
+		}
+	}
+
+	t.Fatalf("attribute with key '%s' not found", key)
+
+	return TestAttribute{}
+}
+
+func setupRuntimeDependencies(t *testing.T) (context.Context, string, testcontainers.Container) {
+	ctx := context.Background()
+
+	// create file for otel to store the traces
+	tmpDir := t.TempDir()
+
+	reportFilePath := filepath.Join(tmpDir, "otel-collector.json")
+	reportFile, err := os.Create(reportFilePath)
+	require.NoError(t, err)
+	defer reportFile.Close()
+
+	// create docker network for the containers
+	nw, err := network.New(ctx)
+	testcontainers.CleanupNetwork(t, nw)
+	require.NoError(t, err)
+
+	networkName := nw.Name
+
+	jaeger, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image: "jaegertracing/all-in-one:latest",
+			ExposedPorts: []string{
+				"14250/tcp",
+				"14268/tcp",
+				"16686/tcp",
+			},
+			Networks: []string{networkName},
+		},
+		Started: true,
+	})
+	testcontainers.CleanupContainer(t, jaeger)
+	require.NoError(t, err)
+
+	otelCollector, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image: "otel/opentelemetry-collector-contrib-dev:93a9885459c9406db8ac446f77f290b02542e8d5",
+			ExposedPorts: []string{
+				"1888/tcp",  // pprof extension
+				"13133/tcp", // health_check extension
+				"4317/tcp",  // OTLP gRPC receiver
+				"55679/tcp", // zpages extension
+			},
+			Files: []testcontainers.ContainerFile{
+				{
+					ContainerFilePath: "/etc/otel/config.yaml",
+					HostFilePath:      filepath.Join("testdata", "otel-collector-config.yml"),
+				},
+				{
+					Reader:            reportFile,
+					ContainerFilePath: "/tmp/tests.json",
+				},
+			},
+			WaitingFor: wait.ForListeningPort("4317/tcp"),
+		},
+		Started: true,
+	})
+	testcontainers.CleanupContainer(t, otelCollector)
+	require.NoError(t, err)
+
+	collectorPort, err := otelCollector.MappedPort(ctx, "4317/tcp")
+	require.NoError(t, err)
+
+	t.Setenv(exporterEndpointKey, "http://localhost:"+collectorPort.Port())
+	t.Setenv("OTEL_EXPORTER_OTLP_SPAN_INSECURE", "true")
+	t.Setenv("OTEL_EXPORTER_OTLP_INSECURE", "true")
+	t.Setenv("OTEL_EXPORTER_OTLP_METRIC_INSECURE", "true")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "")
+	t.Setenv("OTEL_SERVICE_NAME", "jaeger-srv-test")
+	t.Setenv("OTEL_SERVICE_VERSION", "jaeger-srv-version")
+
+	return ctx, reportFilePath, otelCollector
+}
+
+func Test_Export_SampleXML(t *testing.T) {
+	t.Setenv("BRANCH", "main")
+
+	cfg := config.NewConfigFromDefaults()
+	cfg.BatchSize = 25
+
+	ctx, reportFilePath, otelCollector := setupRuntimeDependencies(t)
+
+	defer func() {
+		// clean up test report
+		os.Remove(reportFilePath)
+	}()
+
+	err := Export(context.Background(), cfg, &TestReader{testFile: "../../TEST-sample.xml"})
+	require.NoError(t, err)
+
+	// wait for the file to be written by the otel-exporter
+	var out bytes.Buffer
+	err = wait.ForFile("/tmp/tests.json").
+		WithStartupTimeout(time.Second*10).
+		WithPollInterval(time.Second).
+		WithMatcher(func(r io.Reader) error {
+			if _, err := io.Copy(&out, r); err != nil {
+				return fmt.Errorf("copy: %w", err)
+			}
+			return nil
+		}).WaitUntilReady(ctx, otelCollector)
+	require.NoError(t, err)
+
+	// assert using the generated file
+	// merge both JSON files
+	// 1. get the spans and metrics JSONs, they are separated by \n
+	// 2. remote white spaces
+	// 3. unmarshal each resource separately
+	// 4. assign each resource to the test report struct
+	jsons := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(jsons) != 2 {
+		t.Errorf("expected 2 JSONs, got %d - %s", len(jsons), jsons)
+	}
+
+	jsonSpans := ""
+	jsonMetrics := ""
+	// the order of the lines is not guaranteed
+	if strings.Contains(jsons[0], "resourceSpans") {
+		jsonSpans = strings.TrimSpace(jsons[0])
+		jsonMetrics = strings.TrimSpace(jsons[1])
+	} else {
+		jsonSpans = strings.TrimSpace(jsons[1])
+		jsonMetrics = strings.TrimSpace(jsons[0])
+	}
+
+	var resSpans ResourceSpans
+	err = json.Unmarshal([]byte(jsonSpans), &resSpans)
+	require.NoError(t, err)
+
+	var resMetrics ResourceMetrics
+	err = json.Unmarshal([]byte(jsonMetrics), &resMetrics)
+	require.NoError(t, err)
+
+	testReport := TestReport{
+		resourceSpans:   resSpans,
+		resourceMetrics: resMetrics,
+	}
+
+	resourceSpans := testReport.resourceSpans.Spans[0]
+
+	srvNameAttribute := requireAttributeInArray(t, resourceSpans.Resource.Attributes, "service.name")
+	require.NoError(t, err)
+	require.Equal(t, "service.name", srvNameAttribute.Key)
+	assertStringValueInAttribute(t, srvNameAttribute.Value, "jaeger-srv-test")
+
+	srvVersionAttribute := requireAttributeInArray(t, resourceSpans.Resource.Attributes, "service.version")
+	require.NoError(t, err)
+	require.Equal(t, "service.version", srvVersionAttribute.Key)
+	assertStringValueInAttribute(t, srvVersionAttribute.Value, "jaeger-srv-version")
+
+	instrumentationLibrarySpans := resourceSpans.InstrumentationLibrarySpans[0]
+
+	require.Equal(t, "jaeger-srv-test", instrumentationLibrarySpans.InstrumentationLibrary.Name)
+
+	spans := instrumentationLibrarySpans.Spans
+
+	// there are 15 elements:
+	//   1 testsuites element (root element)
+	// 	 3 testsuite element
+	// 	 11 testcase elements
+	expectedSpansCount := 15
+
+	require.Equal(t, expectedSpansCount, len(spans))
+
+	aTestCase := spans[2]
+	require.Equal(t, "TestCheckConfigDirsCreatesWorkspaceAtHome", aTestCase.Name)
+	require.Equal(t, "SPAN_KIND_INTERNAL", aTestCase.Kind)
+
+	codeFunction := requireAttributeInArray(t, aTestCase.Attributes, "code.function")
+	require.NoError(t, err)
+	assertStringValueInAttribute(t, codeFunction.Value, "TestCheckConfigDirsCreatesWorkspaceAtHome")
+
+	testClassName := requireAttributeInArray(t, aTestCase.Attributes, "tests.case.classname")
+	require.NoError(t, err)
+	assertStringValueInAttribute(t, testClassName.Value, "github.com/elastic/e2e-testing/cli/config")
+
+	goVersion := requireAttributeInArray(t, aTestCase.Attributes, "go.version")
+	require.NoError(t, err)
+	assertStringValueInAttribute(t, goVersion.Value, "go1.16.3 linux/amd64")
+
+	// last span is server type
+	aTestCase = spans[expectedSpansCount-1]
+	require.Equal(t, "SPAN_KIND_SERVER", aTestCase.Kind)
+}
+
+func Test_GetServiceVariable(t *testing.T) {
+	var otlpTests = []struct {
+		fallback     string
+		getFn        func(*config.Config) string
+		setFlag      func(*config.Config, string)
+		otelVariable string
+	}{
+		{
+			fallback: Junit2otlp,
+			getFn:    getOtlpServiceName,
+			setFlag: func(cfg *config.Config, value string) {
+				cfg.ServiceName = value
+			},
+			otelVariable: "OTEL_SERVICE_NAME",
+		},
+		{
+			fallback: "",
+			getFn:    getOtlpServiceVersion,
+			setFlag: func(cfg *config.Config, value string) {
+				cfg.ServiceVersion = value
+			},
+			otelVariable: "OTEL_SERVICE_VERSION",
+		},
+	}
+
+	for _, otlpotlpTest := range otlpTests {
+		t.Run(otlpotlpTest.otelVariable, func(t *testing.T) {
+			t.Run("no-env/no-flag/fallback", func(t *testing.T) {
+				t.Setenv(otlpotlpTest.otelVariable, "")
+				cfg := config.NewConfigFromDefaults()
+				cfg.RepositoryPath = t.TempDir() // no project manifests to detect the values from
+				otlpotlpTest.setFlag(cfg, "")
+
+				actualValue := otlpotlpTest.getFn(cfg)
+
+				require.Equal(t, otlpotlpTest.fallback, actualValue)
+			})
+
+			t.Run("env/no-flag/env", func(t *testing.T) {
+				t.Setenv(otlpotlpTest.otelVariable, "foobar")
+				cfg := config.NewConfigFromDefaults()
+				otlpotlpTest.setFlag(cfg, "")
+
+				actualValue := otlpotlpTest.getFn(cfg)
+
+				require.Equal(t, "foobar", actualValue)
+			})
+
+			t.Run("no-env/flag/flag", func(t *testing.T) {
+				t.Setenv(otlpotlpTest.otelVariable, "")
+				cfg := config.NewConfigFromDefaults()
+				otlpotlpTest.setFlag(cfg, "this-is-a-flag")
+
+				actualValue := otlpotlpTest.getFn(cfg)
+
+				require.Equal(t, "this-is-a-flag", actualValue)
+			})
+
+			t.Run("env/flag/flag", func(t *testing.T) {
+				t.Setenv(otlpotlpTest.otelVariable, "foobar")
+				cfg := config.NewConfigFromDefaults()
+				otlpotlpTest.setFlag(cfg, "this-is-a-flag")
+
+				actualValue := otlpotlpTest.getFn(cfg)
+
+				require.Equal(t, "this-is-a-flag", actualValue)
+			})
+		})
+	}
+}
+
+func Test_CreateTracesAndSpans_MaxSpans(t *testing.T) {
+	suites, err := junit.IngestFile("../../TEST-sample.xml")
+	require.NoError(t, err)
+
+	totalTests := 0
+	for _, suite := range suites {
+		totalTests += len(suite.Tests)
+	}
+
+	createSpans := func(t *testing.T, maxSpans int) []sdktrace.ReadOnlySpan {
+		t.Helper()
+
+		recorder := tracetest.NewSpanRecorder()
+		tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+		cfg := config.NewConfigFromDefaults()
+		cfg.RepositoryPath = t.TempDir()
+		cfg.MaxSpans = maxSpans
+
+		_, err := createTracesAndSpans(context.Background(), cfg, "test-service", Providers{TracerProvider: tracerProvider}, resolveRuntimeAttributes(context.Background(), cfg), nil, sendSuites(suites))
+		require.NoError(t, err)
+
+		return recorder.Ended()
+	}
+
+	rootSpan := func(t *testing.T, spans []sdktrace.ReadOnlySpan) sdktrace.ReadOnlySpan {
+		t.Helper()
+
+		for _, span := range spans {
+			if !span.Parent().IsValid() {
+				return span
+			}
+		}
+
+		t.Fatal("root span not found")
+		return nil
+	}
+
+	t.Run("Without limit", func(t *testing.T) {
+		spans := createSpans(t, 0)
+		require.Len(t, spans, 1+len(suites)+totalTests)
+
+		for _, att := range rootSpan(t, spans).Attributes() {
+			require.NotEqual(t, TestsRunTruncated, string(att.Key))
+		}
+	})
+
+	t.Run("With limit", func(t *testing.T) {
+		spans := createSpans(t, 5)
+		require.Len(t, spans, 1+len(suites)+5)
+
+		atts := rootSpan(t, spans).Attributes()
+		require.Contains(t, atts, attribute.Key(TestsRunTruncated).Bool(true))
+		require.Contains(t, atts, attribute.Key(TestsRunDroppedSpans).Int(totalTests-5))
+	})
+}
+
+func Test_CreateTracesAndSpans_SkipRootSpan(t *testing.T) {
+	suites, err := junit.IngestFile("../../TEST-sample.xml")
+	require.NoError(t, err)
+
+	createSpans := func(t *testing.T, ctx context.Context) (trace.TraceID, []sdktrace.ReadOnlySpan) {
+		t.Helper()
+
+		recorder := tracetest.NewSpanRecorder()
+		tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+		cfg := config.NewConfigFromDefaults()
+		cfg.RepositoryPath = t.TempDir()
+		cfg.SkipRootSpan = true
+
+		runSpan, err := createTracesAndSpans(ctx, cfg, "test-service", Providers{TracerProvider: tracerProvider}, resolveRuntimeAttributes(context.Background(), cfg), nil, sendSuites(suites))
+		require.NoError(t, err)
+
+		return runSpan.TraceID(), recorder.Ended()
+	}
+
+	t.Run("Without parent", func(t *testing.T) {
+		traceID, spans := createSpans(t, context.Background())
+		require.True(t, traceID.IsValid())
+
+		topLevel := 0
+		for _, span := range spans {
+			require.NotEqual(t, config.NewConfigFromDefaults().TraceName, span.Name())
+			if !span.Parent().IsValid() {
+				topLevel++
+			}
+		}
+		require.Equal(t, len(suites), topLevel)
+	})
+
+	t.Run("With TRACEPARENT", func(t *testing.T) {
+		t.Setenv("TRACEPARENT", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+		traceID, spans := createSpans(t, initOtelContext(context.Background()))
+		require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID.String())
+
+		for _, span := range spans {
+			require.Equal(t, traceID, span.SpanContext().TraceID())
+			if span.Parent().IsRemote() {
+				require.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+			}
+		}
+	})
+}
+
+func Test_CreateTracesAndSpans_Timestamps(t *testing.T) {
+	suite := junit.Suite{
+		Name: "suite",
+		Tests: []junit.Test{
+			{Name: "first", Duration: 2 * time.Second, Status: junit.StatusPassed},
+			{Name: "second", Duration: 3 * time.Second, Status: junit.StatusFailed, Error: junit.Error{Message: "failed"}},
+		},
+	}
+	suite.Aggregate()
+
+	createSpans := func(t *testing.T, startTime time.Time) map[string]sdktrace.ReadOnlySpan {
+		t.Helper()
+
+		recorder := tracetest.NewSpanRecorder()
+		tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+		cfg := config.NewConfigFromDefaults()
+		cfg.RepositoryPath = t.TempDir()
+
+		ch := make(chan reportSuite, 1)
+		ch <- reportSuite{suite: suite, startTime: startTime}
+		close(ch)
+
+		_, err := createTracesAndSpans(context.Background(), cfg, "test-service", Providers{TracerProvider: tracerProvider}, nil, nil, ch)
+		require.NoError(t, err)
+
+		spans := map[string]sdktrace.ReadOnlySpan{}
+		for _, span := range recorder.Ended() {
+			spans[span.Name()] = span
+		}
+
+		return spans
+	}
+
+	t.Run("With the start time of the suite", func(t *testing.T) {
+		startTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		spans := createSpans(t, startTime)
+
+		root := spans[config.NewConfigFromDefaults().TraceName]
+		require.Equal(t, startTime, root.StartTime())
+		require.False(t, root.EndTime().Before(startTime.Add(5*time.Second)))
+
+		require.Equal(t, startTime, spans["suite"].StartTime())
+		require.Equal(t, startTime.Add(5*time.Second), spans["suite"].EndTime())
+
+		require.Equal(t, startTime, spans["first"].StartTime())
+		require.Equal(t, startTime.Add(2*time.Second), spans["first"].EndTime())
+
+		require.Equal(t, startTime.Add(2*time.Second), spans["second"].StartTime())
+		require.Equal(t, startTime.Add(5*time.Second), spans["second"].EndTime())
+		for _, event := range spans["second"].Events() {
+			require.Equal(t, startTime.Add(5*time.Second), event.Time)
+		}
+	})
+
+	t.Run("Without the start time of the suite", func(t *testing.T) {
+		before := time.Now()
+		spans := createSpans(t, time.Time{})
+
+		suiteSpan := spans["suite"]
+		require.Equal(t, 5*time.Second, suiteSpan.EndTime().Sub(suiteSpan.StartTime()))
+		require.False(t, suiteSpan.EndTime().Before(before))
+		require.False(t, suiteSpan.EndTime().After(time.Now()))
+
+		require.Equal(t, suiteSpan.StartTime(), spans["first"].StartTime())
+		require.Equal(t, suiteSpan.EndTime(), spans["second"].EndTime())
+		require.False(t, spans[config.NewConfigFromDefaults().TraceName].StartTime().After(suiteSpan.StartTime()))
+	})
+}
+
+func Test_CreateTracesAndSpans_GroupByClass(t *testing.T) {
+	suite := junit.Suite{
+		Name: "suite",
+		Tests: []junit.Test{
+			{Name: "a1", Classname: "A", Duration: time.Second, Status: junit.StatusPassed},
+			{Name: "b1", Classname: "B", Duration: time.Second, Status: junit.StatusPassed},
+			{Name: "a2", Classname: "A", Duration: time.Second, Status: junit.StatusPassed},
+			{Name: "none", Duration: time.Second, Status: junit.StatusPassed},
+		},
+	}
+	suite.Aggregate()
+
+	createSpans := func(t *testing.T, groupByClass bool) map[string]sdktrace.ReadOnlySpan {
+		t.Helper()
+
+		recorder := tracetest.NewSpanRecorder()
+		tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+		cfg := config.NewConfigFromDefaults()
+		cfg.RepositoryPath = t.TempDir()
+		cfg.SkipRootSpan = true
+		cfg.GroupByClass = groupByClass
+
+		_, err := createTracesAndSpans(context.Background(), cfg, "test-service", Providers{TracerProvider: tracerProvider}, nil, nil, sendSuites([]junit.Suite{suite}))
+		require.NoError(t, err)
+
+		// the test cases without classname are grouped under a span named after the suite
+		spans := map[string]sdktrace.ReadOnlySpan{}
+		for _, span := range recorder.Ended() {
+			name := span.Name()
+			if name == "suite" && span.Parent().IsValid() {
+				name = "class suite"
+			}
+			spans[name] = span
+		}
+
+		return spans
+	}
+
+	t.Run("Without grouping", func(t *testing.T) {
+		spans := createSpans(t, false)
+		require.Len(t, spans, 5)
+
+		for _, name := range []string{"a1", "b1", "a2", "none"} {
+			require.Equal(t, spans["suite"].SpanContext().SpanID(), spans[name].Parent().SpanID())
+		}
+	})
+
+	t.Run("With grouping", func(t *testing.T) {
+		spans := createSpans(t, true)
+		require.Len(t, spans, 8)
+
+		for _, class := range []string{"A", "B", "class suite"} {
+			require.Equal(t, spans["suite"].SpanContext().SpanID(), spans[class].Parent().SpanID())
+		}
+		require.Contains(t, spans["A"].Attributes(), attribute.Key(TestClassName).String("A"))
+		require.Equal(t, spans["A"].SpanContext().SpanID(), spans["a1"].Parent().SpanID())
+		require.Equal(t, spans["A"].SpanContext().SpanID(), spans["a2"].Parent().SpanID())
+		require.Equal(t, spans["B"].SpanContext().SpanID(), spans["b1"].Parent().SpanID())
+		require.Equal(t, spans["class suite"].SpanContext().SpanID(), spans["none"].Parent().SpanID())
+
+		// the test cases of a class run one after the other
+		require.Equal(t, spans["a1"].EndTime(), spans["a2"].StartTime())
+		require.Equal(t, spans["a2"].EndTime(), spans["b1"].StartTime())
+		require.Equal(t, 2*time.Second, spans["A"].EndTime().Sub(spans["A"].StartTime()))
+	})
+}
+
+func Test_CreateTracesAndSpans_DurationHistograms(t *testing.T) {
+	suite := junit.Suite{
+		Name: "suite",
+		Tests: []junit.Test{
+			{Name: "fast", Duration: 5 * time.Millisecond, Status: junit.StatusPassed},
+			{Name: "slow", Duration: 500 * time.Millisecond, Status: junit.StatusPassed},
+			{Name: "failed", Duration: 50 * time.Millisecond, Status: junit.StatusFailed},
+		},
+	}
+	suite.Aggregate()
+
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	tracerProvider := sdktrace.NewTracerProvider()
+
+	cfg := config.NewConfigFromDefaults()
+	cfg.RepositoryPath = t.TempDir()
+	cfg.HistogramBuckets = []float64{10, 100, 1000}
+
+	_, err := createTracesAndSpans(context.Background(), cfg, "test-service", Providers{TracerProvider: tracerProvider, MeterProvider: meterProvider}, nil, nil, sendSuites([]junit.Suite{suite}))
+	require.NoError(t, err)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	histograms := map[string]metricdata.Histogram[int64]{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if h, ok := m.Data.(metricdata.Histogram[int64]); ok {
+				histograms[m.Name] = h
+			}
+		}
+	}
+
+	suiteHistogram := histograms[TestsDurationHistogram]
+	require.Len(t, suiteHistogram.DataPoints, 1)
+	require.Equal(t, []float64{10, 100, 1000}, suiteHistogram.DataPoints[0].Bounds)
+	require.Equal(t, int64(555), suiteHistogram.DataPoints[0].Sum)
+
+	// a data point per status of the test cases
+	testHistogram := histograms[TestDurationHistogram]
+	require.Len(t, testHistogram.DataPoints, 2)
+	for _, dp := range testHistogram.DataPoints {
+		status, ok := dp.Attributes.Value(attribute.Key(TestStatus))
+		require.True(t, ok)
+
+		switch status.AsString() {
+		case string(junit.StatusPassed):
+			require.Equal(t, []uint64{1, 0, 1, 0}, dp.BucketCounts)
+		case string(junit.StatusFailed):
+			require.Equal(t, []uint64{0, 1, 0, 0}, dp.BucketCounts)
+		default:
+			t.Fatalf("unexpected status %s", status.AsString())
+		}
+	}
+}
+
+func Test_CreateTracesAndSpans_FlakyTests(t *testing.T) {
+	suite := junit.Suite{
+		Name: "suite",
+		Tests: []junit.Test{
+			{Classname: "FooTest", Name: "flaky", Status: junit.StatusFailed},
+			{Classname: "FooTest", Name: "stable", Status: junit.StatusPassed},
+			{Classname: "FooTest", Name: "flaky", Status: junit.StatusPassed},
+		},
+	}
+	suite.Aggregate()
+
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	cfg := config.NewConfigFromDefaults()
+	cfg.RepositoryPath = t.TempDir()
+
+	_, err := createTracesAndSpans(context.Background(), cfg, "test-service", Providers{TracerProvider: tracerProvider, MeterProvider: meterProvider}, nil, nil, sendSuites([]junit.Suite{suite}))
+	require.NoError(t, err)
+
+	flaky := map[string]int{}
+	for _, span := range recorder.Ended() {
+		for _, kv := range span.Attributes() {
+			if kv.Key == TestFlaky && kv.Value.AsBool() {
+				flaky[span.Name()]++
+			}
+		}
+	}
+	require.Equal(t, map[string]int{"flaky": 2}, flaky)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	var retries *metricdata.Sum[int64]
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if sum, ok := m.Data.(metricdata.Sum[int64]); ok && m.Name == TestRetries {
+				retries = &sum
+			}
+		}
+	}
+	require.NotNil(t, retries)
+	require.Len(t, retries.DataPoints, 1)
+	require.Equal(t, int64(1), retries.DataPoints[0].Value)
+}
+
+func Test_CreateTracesAndSpans_ServicePerSuite(t *testing.T) {
+	suites, err := junit.IngestFile("../../TEST-sample.xml")
+	require.NoError(t, err)
+
+	newRecorder := func() (*tracetest.SpanRecorder, Providers) {
+		recorder := tracetest.NewSpanRecorder()
+		return recorder, Providers{TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))}
+	}
+
+	recorder, providers := newRecorder()
+	serviceRecorders := map[string]*tracetest.SpanRecorder{}
+	providers.ServiceProviders = func(serviceName string) Providers {
+		serviceRecorder, serviceProviders := newRecorder()
+		serviceRecorders[serviceName] = serviceRecorder
+		return serviceProviders
+	}
+
+	cfg := config.NewConfigFromDefaults()
+	cfg.RepositoryPath = t.TempDir()
+	cfg.ServiceMapping = map[string]string{"github.com/elastic/e2e-testing/cli/config": "config-service"}
+
+	runSpan, err := createTracesAndSpans(context.Background(), cfg, "test-service", providers, nil, nil, sendSuites(suites))
+	require.NoError(t, err)
+	traceID := runSpan.TraceID()
+
+	// the root span and the suites not mapped are sent to the service of the run
+	require.Len(t, serviceRecorders, 1)
+	serviceSpans := serviceRecorders["config-service"].Ended()
+	require.NotEmpty(t, serviceSpans)
+	for _, span := range serviceSpans {
+		require.Equal(t, traceID, span.SpanContext().TraceID())
+	}
+
+	require.Len(t, append(recorder.Ended(), serviceSpans...), 1+len(suites)+suites[2].Totals.Tests)
+}
+
+func Test_NewSpanProcessor(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+
+	t.Run("Simple exports each span when it ends", func(t *testing.T) {
+		exporter.Reset()
+
+		cfg := config.NewConfigFromDefaults()
+		cfg.SpanProcessor = SpanProcessorSimple
+
+		processor, err := newSpanProcessor(cfg, exporter)
+		require.NoError(t, err)
+
+		_, span := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(processor)).Tracer("test").Start(context.Background(), "span")
+		span.End()
+
+		require.Len(t, exporter.GetSpans(), 1)
+	})
+
+	t.Run("Batch exports the spans in the background", func(t *testing.T) {
+		exporter.Reset()
+
+		processor, err := newSpanProcessor(config.NewConfigFromDefaults(), exporter)
+		require.NoError(t, err)
+
+		tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(processor))
+		_, span := tracerProvider.Tracer("test").Start(context.Background(), "span")
+		span.End()
+
+		require.NoError(t, tracerProvider.ForceFlush(context.Background()))
+		require.Len(t, exporter.GetSpans(), 1)
+	})
+
+	t.Run("Batch timeout exports the partial batches", func(t *testing.T) {
+		exporter.Reset()
+
+		cfg := config.NewConfigFromDefaults()
+		cfg.BatchSize = 512
+		cfg.BatchTimeout = 10 * time.Millisecond
+
+		processor, err := newSpanProcessor(cfg, exporter)
+		require.NoError(t, err)
+
+		tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(processor))
+		defer tracerProvider.Shutdown(context.Background())
+
+		_, span := tracerProvider.Tracer("test").Start(context.Background(), "span")
+		span.End()
+
+		require.Eventually(t, func() bool { return len(exporter.GetSpans()) == 1 }, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.SpanProcessor = "eager"
+
+		_, err := newSpanProcessor(cfg, exporter)
+		require.ErrorContains(t, err, "invalid span processor: eager")
+	})
+}
+
+func Test_NewResource(t *testing.T) {
+	cfg := config.NewConfigFromDefaults()
+	cfg.ServiceName = "my-service"
+
+	res, err := newResource(context.Background(), cfg)
+	require.NoError(t, err)
+
+	value, ok := res.Set().Value(attribute.Key("service.name"))
+	require.True(t, ok)
+	require.Equal(t, "my-service", value.AsString())
+
+	_, ok = res.Set().Value(attribute.Key("process.pid"))
+	require.True(t, ok)
+
+	// the arguments may include the secrets of --otlp-headers
+	_, ok = res.Set().Value(attribute.Key("process.command_args"))
+	require.False(t, ok)
+
+	t.Run("Resource attributes", func(t *testing.T) {
+		t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "team=platform,region=eu")
+
+		cfg := config.NewConfigFromDefaults()
+		cfg.ServiceName = "my-service"
+		cfg.ResourceAttributes = map[string]string{"team": "observability", "service.name": "other-service"}
+
+		res, err := newResource(context.Background(), cfg)
+		require.NoError(t, err)
+
+		for key, expected := range map[string]string{"team": "observability", "region": "eu", "service.name": "my-service"} {
+			value, ok := res.Set().Value(attribute.Key(key))
+			require.True(t, ok, key)
+			require.Equal(t, expected, value.AsString(), key)
+		}
+	})
+}
+
+func Test_NewProviders_SelectedExporters(t *testing.T) {
+	t.Run("None", func(t *testing.T) {
+		t.Setenv("OTEL_TRACES_EXPORTER", "none")
+		t.Setenv("OTEL_METRICS_EXPORTER", "none")
+
+		providers, shutdown, err := newProviders(context.Background(), config.NewConfigFromDefaults())
+		require.NoError(t, err)
+		defer shutdown()
+
+		// the spans are still created, so the trace ID is reported
+		_, span := providers.TracerProvider.Tracer("test").Start(context.Background(), "span")
+		span.End()
+		require.True(t, span.SpanContext().TraceID().IsValid())
+	})
+
+	t.Run("Console", func(t *testing.T) {
+		t.Setenv("OTEL_TRACES_EXPORTER", "console")
+		t.Setenv("OTEL_METRICS_EXPORTER", "Console")
+
+		_, shutdown, err := newProviders(context.Background(), config.NewConfigFromDefaults())
+		require.NoError(t, err)
+		shutdown()
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+		t.Setenv("OTEL_METRICS_EXPORTER", "prometheus")
+
+		_, _, err := newProviders(context.Background(), config.NewConfigFromDefaults())
+		require.ErrorContains(t, err, "invalid exporter in OTEL_METRICS_EXPORTER: prometheus")
+	})
+
+	t.Run("Exporter of the configuration wins", func(t *testing.T) {
+		t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+
+		cfg := config.NewConfigFromDefaults()
+		cfg.Exporter.Type = "stdout"
+
+		selected, err := signalExporter(cfg, "OTEL_TRACES_EXPORTER")
+		require.NoError(t, err)
+		require.Equal(t, exporterConsole, selected)
+
+		cfg.Exporter.Type = "kafka"
+		_, err = signalExporter(cfg, "OTEL_TRACES_EXPORTER")
+		require.ErrorContains(t, err, "invalid exporter: kafka")
+	})
+
+	t.Run("File", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.Exporter.Type = "file"
+
+		_, _, err := newProviders(context.Background(), cfg)
+		require.ErrorContains(t, err, "set the exporter-file")
+
+		cfg.Exporter.File = filepath.Join(t.TempDir(), "spans.jsonl")
+		providers, shutdown, err := newProviders(context.Background(), cfg)
+		require.NoError(t, err)
+
+		_, span := providers.TracerProvider.Tracer("test").Start(context.Background(), "span")
+		span.End()
+		shutdown()
+
+		data, err := os.ReadFile(cfg.Exporter.File)
+		require.NoError(t, err)
+
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		require.Len(t, lines, 1)
+
+		var request coltracepb.ExportTraceServiceRequest
+		require.NoError(t, protojson.Unmarshal([]byte(lines[0]), &request))
+		require.Equal(t, "span", request.ResourceSpans[0].ScopeSpans[0].Spans[0].Name)
+	})
+}
+
+func Test_NewProviders_ShutdownAfterCancel(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	collector := &testTraceCollector{}
+	server := grpc.NewServer()
+	coltracepb.RegisterTraceServiceServer(server, collector)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	cfg := config.NewConfigFromDefaults()
+	cfg.Exporter.Endpoint = "http://" + listener.Addr().String()
+	cfg.Exporter.Insecure = true
+
+	// as if the run was interrupted by a signal
+	ctx, cancel := context.WithCancel(context.Background())
+
+	providers, shutdown, err := newProviders(ctx, cfg)
+	require.NoError(t, err)
+
+	_, span := providers.TracerProvider.Tracer("test").Start(ctx, "interrupted")
+	span.End()
+
+	cancel()
+	shutdown()
+
+	require.Equal(t, []string{"interrupted"}, collector.spans)
+}
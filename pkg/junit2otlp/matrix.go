@@ -0,0 +1,203 @@
+package junit2otlp
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/joshdk/go-junit"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// matrixShardDimension dimension of the matrix pattern telling apart the shards of the same matrix cell, which run
+// different test cases, so a test case run by more than one shard is a rerun
+const matrixShardDimension = "shard"
+
+var matrixDimensionName = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// matrixPattern matches the names of the reports of a CI matrix build, capturing the dimensions of their matrix cell
+type matrixPattern struct {
+	re    *regexp.Regexp
+	names []string
+}
+
+// newMatrixPattern compiles the pattern of the names of the reports, where each {name} captures the value of a
+// dimension, within a segment of the path, and * matches anything but a slash. The pattern matches the end of the
+// names, so it can be relative. It returns nil if the pattern is empty
+func newMatrixPattern(pattern string) (*matrixPattern, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+
+	mp := &matrixPattern{}
+	var expr strings.Builder
+	expr.WriteString(`(?:^|/)`)
+
+	rest := filepath.ToSlash(pattern)
+	for rest != "" {
+		switch {
+		case rest[0] == '{':
+			end := strings.IndexByte(rest, '}')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid matrix pattern %q: unclosed {", pattern)
+			}
+
+			name := rest[1:end]
+			if !matrixDimensionName.MatchString(name) {
+				return nil, fmt.Errorf("invalid matrix pattern %q: invalid dimension name %q", pattern, name)
+			}
+			for _, n := range mp.names {
+				if n == name {
+					return nil, fmt.Errorf("invalid matrix pattern %q: duplicated dimension %q", pattern, name)
+				}
+			}
+
+			mp.names = append(mp.names, name)
+			expr.WriteString(`([^/]+)`)
+			rest = rest[end+1:]
+		case rest[0] == '*':
+			expr.WriteString(`[^/]*`)
+			rest = rest[1:]
+		default:
+			next := strings.IndexAny(rest, "{*")
+			if next < 0 {
+				next = len(rest)
+			}
+
+			expr.WriteString(regexp.QuoteMeta(rest[:next]))
+			rest = rest[next:]
+		}
+	}
+
+	if len(mp.names) == 0 {
+		return nil, fmt.Errorf("invalid matrix pattern %q: there are no {dimension} placeholders", pattern)
+	}
+
+	expr.WriteString(`$`)
+	mp.re = regexp.MustCompile(expr.String())
+
+	return mp, nil
+}
+
+// dimensions returns the tests.matrix.<dimension> attributes of the report with the name, in the order of the
+// pattern. It returns nil if the pattern is nil or does not match the name
+func (mp *matrixPattern) dimensions(name string) []attribute.KeyValue {
+	if mp == nil {
+		return nil
+	}
+
+	match := mp.re.FindStringSubmatch(filepath.ToSlash(name))
+	if match == nil {
+		slog.Warn("the report does not match the matrix pattern, so its suites have no matrix dimensions", "report", name)
+		return nil
+	}
+
+	dimensions := make([]attribute.KeyValue, 0, len(mp.names))
+	for i, name := range mp.names {
+		dimensions = append(dimensions, attribute.Key(TestsMatrixPrefix+name).String(match[i+1]))
+	}
+
+	return dimensions
+}
+
+// matrixCell identifies the matrix cell of the dimensions, which are all of them but the shard
+func matrixCell(dimensions []attribute.KeyValue) string {
+	var cell strings.Builder
+	for _, kv := range dimensions {
+		if kv.Key == attribute.Key(TestsMatrixPrefix+matrixShardDimension) {
+			continue
+		}
+
+		cell.WriteString(string(kv.Key))
+		cell.WriteByte('=')
+		cell.WriteString(kv.Value.Emit())
+		cell.WriteByte(',')
+	}
+
+	return cell.String()
+}
+
+// aggregateSuites merges the suites of the reports of a matrix build into one run, reading all of them before
+// sending them in the same order. A test case run more than once in the same matrix cell, i.e. rerun by another
+// shard, is kept once: its first passing run, or its last run if none passed. The totals of the suites are
+// recomputed without the reruns, and the suites left without test cases are dropped
+func aggregateSuites(in <-chan reportSuite) <-chan reportSuite {
+	out := make(chan reportSuite)
+
+	go func() {
+		defer close(out)
+
+		all := []reportSuite{}
+		for rs := range in {
+			all = append(all, rs)
+		}
+
+		// the test cases repeated in the same report, i.e. parameterized ones, are not reruns
+		type run struct{ suite, test int }
+		kept := map[string]run{}
+		dropped := make([]map[int]bool, len(all))
+		for i, rs := range all {
+			cell := matrixCell(rs.dimensions)
+			for j, test := range rs.suite.Tests {
+				key := cell + "\x00" + rs.suite.Name + "\x00" + test.Classname + "\x00" + test.Name
+				previous, ok := kept[key]
+				if !ok {
+					kept[key] = run{suite: i, test: j}
+					continue
+				}
+				if all[previous.suite].report == rs.report {
+					continue
+				}
+
+				drop := previous
+				if all[previous.suite].suite.Tests[previous.test].Status == junit.StatusPassed {
+					drop = run{suite: i, test: j}
+				} else {
+					kept[key] = run{suite: i, test: j}
+				}
+
+				if dropped[drop.suite] == nil {
+					dropped[drop.suite] = map[int]bool{}
+				}
+				dropped[drop.suite][drop.test] = true
+			}
+		}
+
+		aggregated := make([]reportSuite, 0, len(all))
+		reruns := 0
+		for i, rs := range all {
+			if len(dropped[i]) == 0 {
+				aggregated = append(aggregated, rs)
+				continue
+			}
+
+			tests := make([]junit.Test, 0, len(rs.suite.Tests)-len(dropped[i]))
+			for j, test := range rs.suite.Tests {
+				if !dropped[i][j] {
+					tests = append(tests, test)
+				}
+			}
+
+			reruns += len(rs.suite.Tests) - len(tests)
+			if len(tests) > 0 {
+				rs.suite.Tests = tests
+				rs.suite.Aggregate()
+				aggregated = append(aggregated, rs)
+			}
+		}
+
+		if reruns > 0 {
+			slog.Info("deduplicated the test cases rerun in the same matrix cell", "reruns", reruns)
+			// the total is reported with the last suite, as the suites made only of reruns are dropped
+			aggregated[len(aggregated)-1].reruns = reruns
+		}
+
+		for _, rs := range aggregated {
+			out <- rs
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,148 @@
+package junit2otlp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestMatrixPattern(t *testing.T) {
+	t.Run("Dimensions", func(t *testing.T) {
+		mp, err := newMatrixPattern("results-{os}-{go}/shard-{shard}/*.xml")
+		require.NoError(t, err)
+
+		require.Equal(t, []attribute.KeyValue{
+			attribute.Key("tests.matrix.os").String("ubuntu-latest"),
+			attribute.Key("tests.matrix.go").String("1.22"),
+			attribute.Key("tests.matrix.shard").String("2"),
+		}, mp.dimensions("artifacts/results-ubuntu-latest-1.22/shard-2/TEST-calc.xml"))
+
+		require.Nil(t, mp.dimensions("results-ubuntu-latest-1.22/TEST-calc.xml"))
+		require.Nil(t, mp.dimensions("results-ubuntu-latest-1.22/shard-2/nested/TEST-calc.xml"))
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		mp, err := newMatrixPattern("")
+		require.NoError(t, err)
+		require.Nil(t, mp.dimensions("TEST-calc.xml"))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, pattern := range []string{"{os/*.xml", "{}/*.xml", "{os}/{os}/*.xml", "reports/*.xml"} {
+			_, err := newMatrixPattern(pattern)
+			require.Error(t, err, pattern)
+		}
+	})
+}
+
+func TestRun_MatrixPattern(t *testing.T) {
+	report := func(tests string) string {
+		return `<testsuites><testsuite name="calc">` + tests + `</testsuite></testsuites>`
+	}
+
+	path := filepath.Join(t.TempDir(), "results.tar")
+	require.NoError(t, os.WriteFile(path, writeTar(t, map[string]string{
+		"results/linux/shard-1/TEST-calc.xml":   report(`<testcase name="add"><failure message="boom"/></testcase><testcase name="sub"/>`),
+		"results/linux/shard-2/TEST-calc.xml":   report(`<testcase name="add"/>`),
+		"results/windows/shard-1/TEST-calc.xml": report(`<testcase name="add"/><testcase name="sub"/>`),
+	}), 0o600))
+
+	reader, err := NewTarReader(path)
+	require.NoError(t, err)
+
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	cfg := config.NewConfigFromDefaults()
+	cfg.RepositoryPath = t.TempDir()
+	cfg.Summary = false
+	cfg.MatrixPattern = "{os}/shard-{shard}/*.xml"
+
+	require.NoError(t, Run(context.Background(), cfg, Providers{TracerProvider: tracerProvider}, reader))
+
+	var root sdktrace.ReadOnlySpan
+	suites := map[string][]string{}
+	for _, span := range recorder.Ended() {
+		switch {
+		case !span.Parent().IsValid():
+			root = span
+		case span.Name() == "calc":
+			platform, shard := "", ""
+			for _, att := range span.Attributes() {
+				switch att.Key {
+				case "tests.matrix.os":
+					platform = att.Value.AsString()
+				case "tests.matrix.shard":
+					shard = att.Value.AsString()
+				}
+			}
+			cell := platform + "/" + shard
+			suites[cell] = append(suites[cell], span.Name())
+		}
+	}
+
+	require.Equal(t, map[string][]string{"linux/1": {"calc"}, "linux/2": {"calc"}, "windows/1": {"calc"}}, suites)
+
+	require.NotNil(t, root)
+	require.Contains(t, root.Attributes(), attribute.Key(TestsRunTotal).Int(4))
+	require.Contains(t, root.Attributes(), attribute.Key(TestsRunPassed).Int(4))
+	require.Contains(t, root.Attributes(), attribute.Key(TestsRunFailed).Int(0))
+	require.Contains(t, root.Attributes(), attribute.Key(TestsRunReruns).Int(1))
+
+	// the failed run of add in the first linux shard is dropped, as it passed in the second one
+	adds := 0
+	for _, span := range recorder.Ended() {
+		if span.Name() == "add" {
+			adds++
+		}
+	}
+	require.Equal(t, 2, adds)
+}
+
+func TestRun_MatrixPatternFileReader(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "linux", "shard-1")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+
+	path := filepath.Join(dir, "TEST-calc.xml")
+	require.NoError(t, os.WriteFile(path, []byte(`<testsuites>
+		<testsuite name="add"><testcase name="ints"/></testsuite>
+		<testsuite name="sub"><testcase name="ints"/></testsuite>
+		<testsuite name="mul"><testcase name="ints"/></testsuite>
+	</testsuites>`), 0o600))
+
+	for _, format := range []string{"auto", "junit"} {
+		t.Run(format, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+			// the report is named after the path of the file reader, as the input is not set by the library callers, and
+			// the prefix does not rewrite the dimensions shared by the suites of the report
+			cfg := config.NewConfigFromDefaults()
+			cfg.RepositoryPath = t.TempDir()
+			cfg.Format = format
+			cfg.AttributePrefix = "ci."
+			cfg.MatrixPattern = "{os}/shard-{shard}/*.xml"
+
+			require.NoError(t, Run(context.Background(), cfg, Providers{TracerProvider: tracerProvider}, NewFileReader(path)))
+
+			suites := 0
+			for _, span := range recorder.Ended() {
+				if span.Name() != "add" && span.Name() != "sub" && span.Name() != "mul" {
+					continue
+				}
+
+				suites++
+				require.Contains(t, span.Attributes(), attribute.Key("ci.tests.matrix.os").String("linux"), span.Name())
+				require.Contains(t, span.Attributes(), attribute.Key("ci.tests.matrix.shard").String("1"), span.Name())
+			}
+			require.Equal(t, 3, suites)
+		})
+	}
+}
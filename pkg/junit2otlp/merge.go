@@ -0,0 +1,91 @@
+package junit2otlp
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// mergeSuites merges the suites with the same name, and in the same matrix cell, of all the reports into one suite,
+// i.e. the suites of the shards of a CI job, reading all of them before sending them in the order of their first
+// suite. The merged suite has the test cases of all of them, one after the other, with the totals recomputed, and
+// keeps them as its shards, so each one gets its own span. It starts with the earliest shard, and its console
+// outputs are the ones of its shards. The suites without duplicates are sent as they are
+func mergeSuites(in <-chan reportSuite) <-chan reportSuite {
+	out := make(chan reportSuite)
+
+	go func() {
+		defer close(out)
+
+		groups := [][]reportSuite{}
+		index := map[string]int{}
+		for rs := range in {
+			key := matrixCell(rs.dimensions) + "\x00" + rs.suite.Name
+			i, ok := index[key]
+			if !ok {
+				i = len(groups)
+				index[key] = i
+				groups = append(groups, nil)
+			}
+
+			groups[i] = append(groups[i], rs)
+		}
+
+		for _, group := range groups {
+			if len(group) == 1 {
+				out <- group[0]
+				continue
+			}
+
+			slog.Debug("merged the suites with the same name", "suite", group[0].suite.Name, "shards", len(group))
+			out <- mergeShards(group)
+		}
+	}()
+
+	return out
+}
+
+// mergeShards merges the suites into one, with the name, the package and the matrix cell of the first one
+func mergeShards(shards []reportSuite) reportSuite {
+	first := shards[0]
+	merged := reportSuite{
+		suite: junit.Suite{
+			Name:       first.suite.Name,
+			Package:    first.suite.Package,
+			Properties: map[string]string{},
+		},
+		report: first.report,
+		shards: shards,
+	}
+
+	// the shard dimension tells apart the merged suites
+	for _, kv := range first.dimensions {
+		if kv.Key != attribute.Key(TestsMatrixPrefix+matrixShardDimension) {
+			merged.dimensions = append(merged.dimensions, kv)
+		}
+	}
+
+	duration := time.Duration(0)
+	for _, shard := range shards {
+		merged.suite.Tests = append(merged.suite.Tests, shard.suite.Tests...)
+		for k, v := range shard.suite.Properties {
+			if _, ok := merged.suite.Properties[k]; !ok {
+				merged.suite.Properties[k] = v
+			}
+		}
+
+		if !shard.startTime.IsZero() && (merged.startTime.IsZero() || shard.startTime.Before(merged.startTime)) {
+			merged.startTime = shard.startTime
+		}
+
+		duration += suiteDuration(shard.suite)
+		merged.reruns += shard.reruns
+	}
+
+	merged.suite.Aggregate()
+	merged.suite.Totals.Duration = duration
+
+	return merged
+}
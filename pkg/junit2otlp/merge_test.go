@@ -0,0 +1,101 @@
+package junit2otlp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestMergeSuites(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	in := make(chan reportSuite, 4)
+	in <- reportSuite{report: "shard-1/TEST-calc.xml", startTime: start.Add(time.Second), suite: junit.Suite{
+		Name:  "calc",
+		Tests: []junit.Test{{Name: "add", Status: junit.StatusPassed, Duration: time.Second}},
+	}}
+	in <- reportSuite{report: "shard-1/TEST-io.xml", suite: junit.Suite{
+		Name:  "io",
+		Tests: []junit.Test{{Name: "read", Status: junit.StatusPassed}},
+	}}
+	in <- reportSuite{report: "shard-2/TEST-calc.xml", startTime: start, suite: junit.Suite{
+		Name:  "calc",
+		Tests: []junit.Test{{Name: "sub", Status: junit.StatusFailed, Duration: 2 * time.Second}},
+	}}
+	close(in)
+
+	merged := []reportSuite{}
+	for rs := range mergeSuites(in) {
+		merged = append(merged, rs)
+	}
+
+	require.Len(t, merged, 2)
+
+	calc := merged[0]
+	require.Equal(t, "calc", calc.suite.Name)
+	require.Len(t, calc.shards, 2)
+	require.Equal(t, start, calc.startTime)
+	require.Equal(t, 2, calc.suite.Totals.Tests)
+	require.Equal(t, 1, calc.suite.Totals.Failed)
+	require.Equal(t, 3*time.Second, calc.suite.Totals.Duration)
+	require.Equal(t, start.Add(2*time.Second), suiteEnd(calc, calc.startTime))
+
+	require.Equal(t, "io", merged[1].suite.Name)
+	require.Empty(t, merged[1].shards)
+}
+
+func TestRun_MergeSuites(t *testing.T) {
+	report := func(tests string) string {
+		return `<testsuites><testsuite name="calc">` + tests + `</testsuite></testsuites>`
+	}
+
+	path := filepath.Join(t.TempDir(), "results.tar")
+	require.NoError(t, os.WriteFile(path, writeTar(t, map[string]string{
+		"shard-1/TEST-calc.xml": report(`<testcase name="add"><failure message="boom"/></testcase><testcase name="sub"/>`),
+		"shard-2/TEST-calc.xml": report(`<testcase name="mul"/>`),
+	}), 0o600))
+
+	reader, err := NewTarReader(path)
+	require.NoError(t, err)
+
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	cfg := config.NewConfigFromDefaults()
+	cfg.RepositoryPath = t.TempDir()
+	cfg.Summary = false
+	cfg.MergeSuites = true
+
+	require.NoError(t, Run(context.Background(), cfg, Providers{TracerProvider: tracerProvider}, reader))
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	suites := 0
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+		if span.Name() == "calc" {
+			suites++
+		}
+	}
+
+	require.Equal(t, 1, suites)
+	calc := spans["calc"]
+
+	for _, name := range []string{"shard-1/TEST-calc.xml", "shard-2/TEST-calc.xml"} {
+		shard := spans[name]
+		require.NotNil(t, shard, name)
+		require.Equal(t, calc.SpanContext().SpanID(), shard.Parent().SpanID())
+		require.Contains(t, shard.Attributes(), attribute.Key(TestsSuiteShard).String(name))
+	}
+
+	require.Equal(t, spans["shard-1/TEST-calc.xml"].SpanContext().SpanID(), spans["add"].Parent().SpanID())
+	require.Equal(t, spans["shard-2/TEST-calc.xml"].SpanContext().SpanID(), spans["mul"].Parent().SpanID())
+}
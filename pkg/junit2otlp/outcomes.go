@@ -0,0 +1,35 @@
+package junit2otlp
+
+import (
+	"fmt"
+
+	"github.com/joshdk/go-junit"
+)
+
+// TestsFailedError is returned when the report contains more failed or errored tests than the allowed threshold
+type TestsFailedError struct {
+	Errored   int
+	Failed    int
+	Threshold int
+}
+
+func (e *TestsFailedError) Error() string {
+	return fmt.Sprintf("the report contains %d failed and %d errored tests, exceeding the threshold of %d", e.Failed, e.Errored, e.Threshold)
+}
+
+// checkTestOutcomes returns a TestsFailedError if the number of failed and errored tests in the suites
+// is greater than the threshold
+func checkTestOutcomes(suites []junit.Suite, threshold int) error {
+	failed := 0
+	errored := 0
+	for _, suite := range suites {
+		failed += suite.Totals.Failed
+		errored += suite.Totals.Error
+	}
+
+	if failed+errored > threshold {
+		return &TestsFailedError{Errored: errored, Failed: failed, Threshold: threshold}
+	}
+
+	return nil
+}
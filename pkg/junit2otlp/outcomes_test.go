@@ -0,0 +1,35 @@
+package junit2otlp
+
+import (
+	"testing"
+
+	"github.com/joshdk/go-junit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckTestOutcomes(t *testing.T) {
+	suites := []junit.Suite{
+		{Totals: junit.Totals{Tests: 10, Passed: 8, Failed: 1, Error: 1}},
+		{Totals: junit.Totals{Tests: 5, Passed: 4, Failed: 1}},
+	}
+
+	t.Run("No failures", func(t *testing.T) {
+		err := checkTestOutcomes([]junit.Suite{{Totals: junit.Totals{Tests: 10, Passed: 10}}}, 0)
+		require.NoError(t, err)
+	})
+
+	t.Run("Failures over the threshold", func(t *testing.T) {
+		err := checkTestOutcomes(suites, 2)
+
+		var failedErr *TestsFailedError
+		require.ErrorAs(t, err, &failedErr)
+		require.Equal(t, 2, failedErr.Failed)
+		require.Equal(t, 1, failedErr.Errored)
+		require.Equal(t, 2, failedErr.Threshold)
+	})
+
+	t.Run("Failures within the threshold", func(t *testing.T) {
+		err := checkTestOutcomes(suites, 3)
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,175 @@
+package junit2otlp
+
+import (
+	"context"
+	"time"
+	"unicode/utf8"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/internal/formats"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+)
+
+// truncationMarker suffix of the attribute values truncated to the maximum attribute length
+const truncationMarker = "...[truncated]"
+
+const (
+	// outputStdout value of the log.iostream attribute for the system-out of the reports
+	outputStdout = "stdout"
+	// outputStderr value of the log.iostream attribute for the system-err of the reports
+	outputStderr = "stderr"
+)
+
+// isOversizedOutput returns true if the console output exceeds the maximum size of the configuration,
+// so it's sent as log records instead of as a span attribute. Without log records, it's always an attribute, and
+// the skipped outputs are never sent
+func isOversizedOutput(cfg *config.Config, output string) bool {
+	return !cfg.LogsSkipSending && !cfg.SkipSystemOutput && cfg.MaxOutputSize > 0 && len(output) > cfg.MaxOutputSize
+}
+
+// outputAttribute returns the span attribute for a console output. The oversized outputs are replaced by
+// the number of log records they are split into, with the .chunks suffix, i.e. tests.case.systemout.chunks
+func outputAttribute(cfg *config.Config, key string, output string) attribute.KeyValue {
+	if isOversizedOutput(cfg, output) {
+		return attribute.Key(key + ".chunks").Int(len(chunkOutput(output, cfg.MaxOutputSize)))
+	}
+
+	return attribute.Key(key).String(output)
+}
+
+// limitSpanAttributes applies the size guards of the configuration to the attributes of a span: the console
+// outputs are dropped if they are skipped, and the string values longer than the maximum attribute length are
+// truncated, ending with the truncation marker, so the collectors do not drop the spans exceeding their limits
+func limitSpanAttributes(cfg *config.Config, attributes []attribute.KeyValue) []attribute.KeyValue {
+	if !cfg.SkipSystemOutput && cfg.MaxAttributeLength <= 0 {
+		return attributes
+	}
+
+	var outputKeys map[attribute.Key]bool
+	if cfg.SkipSystemOutput {
+		outputKeys = map[attribute.Key]bool{}
+		for _, key := range []string{TestsSystemErr, TestsSystemOut, TestSystemErr, TestSystemOut} {
+			prefixed := attribute.Key(cfg.AttributePrefix + key)
+			outputKeys[prefixed] = true
+			outputKeys[prefixed+".chunks"] = true
+		}
+	}
+
+	limited := make([]attribute.KeyValue, 0, len(attributes))
+	for _, kv := range attributes {
+		if outputKeys[kv.Key] {
+			continue
+		}
+
+		if cfg.MaxAttributeLength > 0 && kv.Value.Type() == attribute.STRING && len(kv.Value.AsString()) > cfg.MaxAttributeLength {
+			kv = kv.Key.String(truncateValue(kv.Value.AsString(), cfg.MaxAttributeLength))
+		}
+
+		limited = append(limited, kv)
+	}
+
+	return limited
+}
+
+// truncateValue truncates the value to at most size bytes, including the truncation marker, without splitting
+// multi-byte characters. The marker is omitted if it does not fit
+func truncateValue(value string, size int) string {
+	marker := truncationMarker
+	if size <= len(marker) {
+		marker = ""
+	}
+
+	end := size - len(marker)
+	for end > 0 && !utf8.RuneStart(value[end]) {
+		end--
+	}
+
+	return value[:end] + marker
+}
+
+// emitOversizedOutput sends the console output as log records, one per chunk, if it's oversized. The context
+// must contain the span the output belongs to, so the log records are correlated with it
+func emitOversizedOutput(ctx context.Context, cfg *config.Config, logger log.Logger, stream string, output string) {
+	if !isOversizedOutput(cfg, output) {
+		return
+	}
+
+	severity := log.SeverityInfo
+	if stream == outputStderr {
+		severity = log.SeverityWarn
+	}
+
+	chunks := chunkOutput(output, cfg.MaxOutputSize)
+	for i, chunk := range chunks {
+		var record log.Record
+		record.SetSeverity(severity)
+		record.SetBody(log.StringValue(chunk))
+		record.AddAttributes(
+			log.String(LogIOStream, stream),
+			log.Int(TestsOutputChunk, i),
+			log.Int(TestsOutputChunks, len(chunks)),
+		)
+
+		logger.Emit(ctx, record)
+	}
+}
+
+// emitFailureLogs sends the body of each failure of the test case as an error log record, with the attributes of
+// its failure event, at the end of the test case, so the stack traces are searchable in the logs backend. The
+// context must contain the span of the test case, so the log records are correlated with it
+func emitFailureLogs(ctx context.Context, cfg *config.Config, logger log.Logger, test junit.Test, timestamp time.Time) {
+	if cfg.LogsSkipSending {
+		return
+	}
+
+	for i, failure := range formats.TestFailures(test) {
+		body := failure.Body
+		if body == "" {
+			body = failure.Message
+		}
+		if body == "" {
+			continue
+		}
+
+		var record log.Record
+		record.SetTimestamp(timestamp)
+		record.SetSeverity(log.SeverityError)
+		record.SetBody(log.StringValue(body))
+		record.AddAttributes(
+			log.String(TestFailureMessage, failure.Message),
+			log.Int(TestFailureOrdinal, i+1),
+		)
+		if failure.Type != "" {
+			record.AddAttributes(log.String(TestFailureType, failure.Type))
+		}
+
+		logger.Emit(ctx, record)
+	}
+}
+
+// chunkOutput splits the output into chunks of at most size bytes, without splitting multi-byte characters
+func chunkOutput(output string, size int) []string {
+	chunks := make([]string, 0, len(output)/size+1)
+
+	for len(output) > size {
+		end := size
+		for end > 0 && !utf8.RuneStart(output[end]) {
+			end--
+		}
+		if end == 0 {
+			// the size is smaller than the first character, which is never split
+			_, end = utf8.DecodeRuneInString(output)
+		}
+
+		chunks = append(chunks, output[:end])
+		output = output[end:]
+	}
+
+	if output != "" {
+		chunks = append(chunks, output)
+	}
+
+	return chunks
+}
@@ -0,0 +1,230 @@
+package junit2otlp
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// recordingProcessor keeps the emitted log records in memory
+type recordingProcessor struct {
+	records []sdklog.Record
+}
+
+func (p *recordingProcessor) OnEmit(_ context.Context, record *sdklog.Record) error {
+	p.records = append(p.records, record.Clone())
+	return nil
+}
+
+func (p *recordingProcessor) Shutdown(context.Context) error   { return nil }
+func (p *recordingProcessor) ForceFlush(context.Context) error { return nil }
+
+func TestChunkOutput(t *testing.T) {
+	t.Run("Exact chunks", func(t *testing.T) {
+		require.Equal(t, []string{"abc", "def"}, chunkOutput("abcdef", 3))
+	})
+
+	t.Run("Last chunk is shorter", func(t *testing.T) {
+		require.Equal(t, []string{"abc", "de"}, chunkOutput("abcde", 3))
+	})
+
+	t.Run("Multi-byte characters are not split", func(t *testing.T) {
+		chunks := chunkOutput("aññb", 2)
+		require.Equal(t, []string{"a", "ñ", "ñ", "b"}, chunks)
+	})
+
+	t.Run("Size smaller than a character", func(t *testing.T) {
+		require.Equal(t, []string{"€", "€"}, chunkOutput("€€", 1))
+	})
+}
+
+func TestOutputAttribute(t *testing.T) {
+	cfg := config.NewConfigFromDefaults()
+	cfg.MaxOutputSize = 4
+
+	t.Run("Small outputs are span attributes", func(t *testing.T) {
+		require.Equal(t, attribute.Key(TestSystemOut).String("abc"), outputAttribute(cfg, TestSystemOut, "abc"))
+	})
+
+	t.Run("Oversized outputs are replaced by the number of chunks", func(t *testing.T) {
+		require.Equal(t, attribute.Key(TestSystemOut+".chunks").Int(3), outputAttribute(cfg, TestSystemOut, "abcdefghi"))
+	})
+
+	t.Run("Skip sending the logs disables the routing", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.MaxOutputSize = 4
+		cfg.LogsSkipSending = true
+
+		require.Equal(t, attribute.Key(TestSystemOut).String("abcdefghi"), outputAttribute(cfg, TestSystemOut, "abcdefghi"))
+	})
+
+	t.Run("Zero disables the routing", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.MaxOutputSize = 0
+
+		output := strings.Repeat("a", 1024)
+		require.Equal(t, attribute.Key(TestSystemOut).String(output), outputAttribute(cfg, TestSystemOut, output))
+	})
+}
+
+func TestLimitSpanAttributes(t *testing.T) {
+	attributes := []attribute.KeyValue{
+		attribute.Key(TestSystemOut).String(strings.Repeat("a", 64)),
+		attribute.Key(TestsSystemErr + ".chunks").Int(3),
+		attribute.Key(TestClassName).String("short"),
+		attribute.Key(TestFailureOrdinal).Int(1),
+	}
+
+	t.Run("No limits", func(t *testing.T) {
+		require.Equal(t, attributes, limitSpanAttributes(config.NewConfigFromDefaults(), attributes))
+	})
+
+	t.Run("Long values are truncated", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.MaxAttributeLength = 20
+
+		limited := limitSpanAttributes(cfg, attributes)
+		require.Len(t, limited, 4)
+		require.Equal(t, "aaaaaa"+truncationMarker, limited[0].Value.AsString())
+		require.Len(t, limited[0].Value.AsString(), 20)
+		require.Equal(t, attributes[1:], limited[1:])
+	})
+
+	t.Run("Multi-byte characters are not split", func(t *testing.T) {
+		require.Equal(t, "a"+truncationMarker, truncateValue("aññññ", len(truncationMarker)+2))
+	})
+
+	t.Run("Limit shorter than the marker", func(t *testing.T) {
+		require.Equal(t, "ab", truncateValue("abcdef", 2))
+	})
+
+	t.Run("Skip the console outputs", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.SkipSystemOutput = true
+
+		require.Equal(t, attributes[2:], limitSpanAttributes(cfg, attributes))
+	})
+
+	t.Run("Skip the prefixed console outputs", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.AttributePrefix = "acme."
+		cfg.SkipSystemOutput = true
+
+		prefixed := prefixAttributes(cfg.AttributePrefix, slices.Clone(attributes))
+		require.Equal(t, prefixed[2:], limitSpanAttributes(cfg, prefixed))
+	})
+}
+
+func TestEmitFailureLogs(t *testing.T) {
+	processor := &recordingProcessor{}
+	lp := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := lp.Logger("test")
+
+	tp := sdktrace.NewTracerProvider()
+	ctx, span := tp.Tracer("test").Start(context.Background(), "test")
+	defer span.End()
+
+	test := junit.Test{
+		Name:   "test",
+		Status: junit.StatusFailed,
+		Error:  junit.Error{Message: "expected 1", Type: "AssertionError", Body: "at FooTest.java:21"},
+	}
+	timestamp := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("With logs", func(t *testing.T) {
+		processor.records = nil
+
+		emitFailureLogs(ctx, config.NewConfigFromDefaults(), logger, test, timestamp)
+		require.Len(t, processor.records, 1)
+
+		record := processor.records[0]
+		require.Equal(t, log.SeverityError, record.Severity())
+		require.Equal(t, "at FooTest.java:21", record.Body().AsString())
+		require.Equal(t, timestamp, record.Timestamp())
+		require.Equal(t, span.SpanContext().SpanID(), record.SpanID())
+
+		attributes := map[string]log.Value{}
+		record.WalkAttributes(func(kv log.KeyValue) bool {
+			attributes[kv.Key] = kv.Value
+			return true
+		})
+		require.Equal(t, "expected 1", attributes[TestFailureMessage].AsString())
+		require.Equal(t, "AssertionError", attributes[TestFailureType].AsString())
+		require.Equal(t, int64(1), attributes[TestFailureOrdinal].AsInt64())
+	})
+
+	t.Run("Skip sending", func(t *testing.T) {
+		processor.records = nil
+
+		cfg := config.NewConfigFromDefaults()
+		cfg.LogsSkipSending = true
+
+		emitFailureLogs(ctx, cfg, logger, test, timestamp)
+		require.Empty(t, processor.records)
+	})
+}
+
+func TestEmitOversizedOutput(t *testing.T) {
+	cfg := config.NewConfigFromDefaults()
+	cfg.MaxOutputSize = 4
+
+	processor := &recordingProcessor{}
+	lp := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := lp.Logger("test")
+
+	tp := sdktrace.NewTracerProvider()
+	ctx, span := tp.Tracer("test").Start(context.Background(), "test")
+	defer span.End()
+
+	t.Run("Small outputs are not emitted", func(t *testing.T) {
+		processor.records = nil
+
+		emitOversizedOutput(ctx, cfg, logger, outputStdout, "abc")
+		require.Empty(t, processor.records)
+	})
+
+	t.Run("Skipped outputs are not emitted", func(t *testing.T) {
+		processor.records = nil
+
+		skipCfg := *cfg
+		skipCfg.SkipSystemOutput = true
+
+		emitOversizedOutput(ctx, &skipCfg, logger, outputStdout, "abcdefghi")
+		require.Empty(t, processor.records)
+	})
+
+	t.Run("Oversized outputs are emitted in chunks", func(t *testing.T) {
+		processor.records = nil
+
+		emitOversizedOutput(ctx, cfg, logger, outputStderr, "abcdefghi")
+		require.Len(t, processor.records, 3)
+
+		for i, record := range processor.records {
+			require.Equal(t, log.SeverityWarn, record.Severity())
+			require.Equal(t, span.SpanContext().TraceID(), record.TraceID())
+			require.Equal(t, span.SpanContext().SpanID(), record.SpanID())
+
+			attributes := map[string]log.Value{}
+			record.WalkAttributes(func(kv log.KeyValue) bool {
+				attributes[kv.Key] = kv.Value
+				return true
+			})
+			require.Equal(t, outputStderr, attributes[LogIOStream].AsString())
+			require.Equal(t, int64(i), attributes[TestsOutputChunk].AsInt64())
+			require.Equal(t, int64(3), attributes[TestsOutputChunks].AsInt64())
+		}
+
+		require.Equal(t, "abcd", processor.records[0].Body().AsString())
+		require.Equal(t, "i", processor.records[2].Body().AsString())
+	})
+}
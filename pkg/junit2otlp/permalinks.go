@@ -0,0 +1,133 @@
+package junit2otlp
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// permalinkHosts the hosts of the repositories whose permalinks can be built, and the path segment of the files
+var permalinkHosts = map[string]string{
+	"github.com": "/blob/",
+	"gitlab.com": "/-/blob/",
+}
+
+// sourceLinks builds the permalinks to the files of a repository at the tested commit, in the web UI of its host
+type sourceLinks struct {
+	// root absolute path of the worktree of the repository
+	root string
+	// blobURL URL of the files at the commit, i.e. https://github.com/owner/name/blob/<sha>/
+	blobURL string
+	// exists whether each file is in the worktree, as only the existing files are linked
+	exists map[string]bool
+}
+
+// newSourceLinks returns the permalinks of the Git repository at the given directory, at the commit of the CI
+// context or at HEAD. It returns nil if it's not a Git repository, or if the host of its origin remote is unknown
+func newSourceLinks(dir string) *sourceLinks {
+	repository, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil
+	}
+
+	remote, err := repository.Remote("origin")
+	if err != nil || len(remote.Config().URLs) == 0 {
+		return nil
+	}
+
+	repoURL, blobPath, ok := repositoryWebURL(remote.Config().URLs[0])
+	if !ok {
+		return nil
+	}
+
+	sha := ""
+	if gitCtx := checkGitContext(); gitCtx != nil {
+		sha = gitCtx.Commit
+	}
+	if sha == "" {
+		head, err := repository.Head()
+		if err != nil {
+			return nil
+		}
+		sha = head.Hash().String()
+	}
+
+	worktree, err := repository.Worktree()
+	if err != nil {
+		return nil
+	}
+
+	return &sourceLinks{
+		root:    worktree.Filesystem.Root(),
+		blobURL: repoURL + blobPath + sha + "/",
+		exists:  map[string]bool{},
+	}
+}
+
+// repositoryWebURL returns the URL of the web UI of the repository of a remote, for both the https://host/owner/name
+// and the git@host:owner/name remotes, and the path segment of its files. It returns false if the host is unknown
+func repositoryWebURL(remote string) (string, string, bool) {
+	var host, path string
+	if u, err := url.Parse(remote); err == nil && u.Host != "" {
+		host, path = u.Hostname(), u.Path
+	} else if at, colon := strings.Index(remote, "@"), strings.Index(remote, ":"); colon > at {
+		host, path = remote[at+1:colon], remote[colon+1:]
+	}
+
+	blobPath, ok := permalinkHosts[strings.ToLower(host)]
+	if !ok {
+		return "", "", false
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if path == "" {
+		return "", "", false
+	}
+
+	return "https://" + strings.ToLower(host) + "/" + path, blobPath, true
+}
+
+// url returns the permalink to the line of the file, if the file is in the worktree: either an absolute path inside
+// it, or a path relative to its root. The line is omitted if it's not positive. It returns an empty string otherwise
+func (l *sourceLinks) url(file string, line int) string {
+	if l == nil || file == "" {
+		return ""
+	}
+
+	rel := filepath.Clean(filepath.FromSlash(file))
+	if filepath.IsAbs(rel) {
+		var err error
+		if rel, err = filepath.Rel(l.root, rel); err != nil {
+			return ""
+		}
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ""
+	}
+
+	exists, ok := l.exists[rel]
+	if !ok {
+		info, err := os.Stat(filepath.Join(l.root, rel))
+		exists = err == nil && !info.IsDir()
+		l.exists[rel] = exists
+	}
+	if !exists {
+		return ""
+	}
+
+	segments := strings.Split(filepath.ToSlash(rel), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	permalink := l.blobURL + strings.Join(segments, "/")
+	if line > 0 {
+		permalink += "#L" + strconv.Itoa(line)
+	}
+
+	return permalink
+}
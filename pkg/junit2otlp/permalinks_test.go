@@ -0,0 +1,112 @@
+package junit2otlp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRepositoryWebURL(t *testing.T) {
+	tests := []struct {
+		remote   string
+		expected string
+		blobPath string
+	}{
+		{remote: "https://github.com/foo/my-repo.git", expected: "https://github.com/foo/my-repo", blobPath: "/blob/"},
+		{remote: "git@github.com:foo/my-repo.git", expected: "https://github.com/foo/my-repo", blobPath: "/blob/"},
+		{remote: "ssh://git@gitlab.com/group/subgroup/my-repo.git", expected: "https://gitlab.com/group/subgroup/my-repo", blobPath: "/-/blob/"},
+		{remote: "https://gitlab.com/group/my-repo/", expected: "https://gitlab.com/group/my-repo", blobPath: "/-/blob/"},
+		{remote: "https://git.example.com/foo/my-repo.git"},
+		{remote: "/srv/git/my-repo.git"},
+	}
+
+	for _, tt := range tests {
+		repoURL, blobPath, ok := repositoryWebURL(tt.remote)
+		require.Equal(t, tt.expected != "", ok, tt.remote)
+		require.Equal(t, tt.expected, repoURL, tt.remote)
+		require.Equal(t, tt.blobPath, blobPath, tt.remote)
+	}
+}
+
+func TestSourceLinks(t *testing.T) {
+	t.Setenv("BRANCH", "")
+	t.Setenv("GITHUB_SHA", "0123456789abcdef")
+
+	dir := t.TempDir()
+	repository, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+	_, err = repository.CreateRemote(&gitconfig.RemoteConfig{Name: "origin", URLs: []string{"git@github.com:foo/my-repo.git"}})
+	require.NoError(t, err)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "src", "test"), 0o755))
+	writeProjectFile(t, dir, filepath.Join("src", "test", "Calculator Test.java"), "class CalculatorTest {}")
+
+	t.Run("Links the files of the worktree", func(t *testing.T) {
+		links := newSourceLinks(dir)
+		require.NotNil(t, links)
+
+		expected := "https://github.com/foo/my-repo/blob/0123456789abcdef/src/test/Calculator%20Test.java"
+		require.Equal(t, expected+"#L21", links.url("src/test/Calculator Test.java", 21))
+		require.Equal(t, expected+"#L21", links.url(filepath.Join(dir, "src", "test", "Calculator Test.java"), 21))
+		require.Equal(t, expected, links.url("src/test/Calculator Test.java", 0))
+
+		require.Empty(t, links.url("src/test/Missing.java", 1))
+		require.Empty(t, links.url("src/test", 1))
+		require.Empty(t, links.url("../outside.java", 1))
+		require.Empty(t, links.url(filepath.Join(t.TempDir(), "outside.java"), 1))
+		require.Empty(t, links.url("", 1))
+	})
+
+	t.Run("Unknown host", func(t *testing.T) {
+		other := t.TempDir()
+		repository, err := git.PlainInit(other, false)
+		require.NoError(t, err)
+		_, err = repository.CreateRemote(&gitconfig.RemoteConfig{Name: "origin", URLs: []string{"https://git.example.com/foo/my-repo.git"}})
+		require.NoError(t, err)
+
+		require.Nil(t, newSourceLinks(other))
+	})
+
+	t.Run("Not a Git repository", func(t *testing.T) {
+		require.Nil(t, newSourceLinks(t.TempDir()))
+	})
+
+	t.Run("Test spans", func(t *testing.T) {
+		suites := []junit.Suite{{
+			Name: "calculator",
+			Tests: []junit.Test{
+				{Name: "testAdd", Status: junit.StatusFailed, Properties: map[string]string{"file": "src/test/Calculator Test.java", "line": "21"}},
+				{Name: "testSub", Status: junit.StatusPassed},
+			},
+		}}
+
+		recorder := tracetest.NewSpanRecorder()
+		tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+		cfg := config.NewConfigFromDefaults()
+		cfg.RepositoryPath = dir
+
+		_, err := createTracesAndSpans(context.Background(), cfg, "test-service", Providers{TracerProvider: tracerProvider}, nil, nil, sendSuites(suites))
+		require.NoError(t, err)
+
+		spans := map[string]sdktrace.ReadOnlySpan{}
+		for _, span := range recorder.Ended() {
+			spans[span.Name()] = span
+		}
+
+		require.Contains(t, spans["testAdd"].Attributes(), attribute.Key(CodeURL).String("https://github.com/foo/my-repo/blob/0123456789abcdef/src/test/Calculator%20Test.java#L21"))
+		for _, att := range spans["testSub"].Attributes() {
+			require.NotEqual(t, CodeURL, string(att.Key))
+		}
+	})
+}
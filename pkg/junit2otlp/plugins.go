@@ -0,0 +1,202 @@
+package junit2otlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// defaultPluginTimeout the maximum time to wait for a plugin, when its timeout is not set
+const defaultPluginTimeout = 10 * time.Second
+
+// pluginSuite the suite as written to the standard input of the plugins. The console outputs are not included,
+// as they can be huge
+type pluginSuite struct {
+	Name       string            `json:"name"`
+	Package    string            `json:"package"`
+	Properties map[string]string `json:"properties"`
+	Totals     pluginTotals      `json:"totals"`
+	Tests      []pluginTest      `json:"tests"`
+}
+
+type pluginTotals struct {
+	Tests      int   `json:"tests"`
+	Passed     int   `json:"passed"`
+	Skipped    int   `json:"skipped"`
+	Failed     int   `json:"failed"`
+	Error      int   `json:"error"`
+	DurationMs int64 `json:"duration_ms"`
+}
+
+type pluginTest struct {
+	Name       string            `json:"name"`
+	Classname  string            `json:"classname"`
+	Status     string            `json:"status"`
+	Message    string            `json:"message"`
+	DurationMs int64             `json:"duration_ms"`
+	Properties map[string]string `json:"properties"`
+}
+
+func newPluginSuite(suite junit.Suite) pluginSuite {
+	ps := pluginSuite{
+		Name:       suite.Name,
+		Package:    suite.Package,
+		Properties: suite.Properties,
+		Totals: pluginTotals{
+			Tests:      suite.Totals.Tests,
+			Passed:     suite.Totals.Passed,
+			Skipped:    suite.Totals.Skipped,
+			Failed:     suite.Totals.Failed,
+			Error:      suite.Totals.Error,
+			DurationMs: suite.Totals.Duration.Milliseconds(),
+		},
+		Tests: make([]pluginTest, 0, len(suite.Tests)),
+	}
+
+	for _, test := range suite.Tests {
+		ps.Tests = append(ps.Tests, pluginTest{
+			Name:       test.Name,
+			Classname:  test.Classname,
+			Status:     string(test.Status),
+			Message:    test.Message,
+			DurationMs: test.Duration.Milliseconds(),
+			Properties: test.Properties,
+		})
+	}
+
+	return ps
+}
+
+// pluginName returns the name of the plugin, or the name of its executable if it's not set
+func pluginName(plugin config.PluginConfig) string {
+	if plugin.Name != "" || len(plugin.Command) == 0 {
+		return plugin.Name
+	}
+
+	return filepath.Base(plugin.Command[0])
+}
+
+// runPlugin runs the command of the plugin with the input in its standard input, returning the attributes of the
+// JSON object written to its standard output. The values of the object must be strings, numbers, booleans or
+// arrays of strings
+func runPlugin(ctx context.Context, plugin config.PluginConfig, input []byte) ([]attribute.KeyValue, error) {
+	if len(plugin.Command) == 0 {
+		return nil, fmt.Errorf("the command of the plugin is empty")
+	}
+
+	timeout := plugin.Timeout
+	if timeout <= 0 {
+		timeout = defaultPluginTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, plugin.Command[0], plugin.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// the processes started by the plugin could keep the output open after it's killed
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("the plugin did not finish in %s", timeout)
+		}
+
+		return nil, fmt.Errorf("failed to run the plugin: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	decoder := json.NewDecoder(&stdout)
+	decoder.UseNumber()
+
+	var values map[string]interface{}
+	if err := decoder.Decode(&values); err != nil {
+		return nil, fmt.Errorf("failed to decode the output of the plugin as a JSON object: %w", err)
+	}
+
+	attributes := make([]attribute.KeyValue, 0, len(values))
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		kv, err := pluginAttribute(key, values[key])
+		if err != nil {
+			return nil, err
+		}
+
+		attributes = append(attributes, kv)
+	}
+
+	return attributes, nil
+}
+
+// pluginAttribute returns the attribute for a value of the output of a plugin, keeping the integers as such
+func pluginAttribute(key string, value interface{}) (attribute.KeyValue, error) {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v), nil
+	case bool:
+		return attribute.Bool(key, v), nil
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return attribute.Int64(key, i), nil
+		}
+
+		f, err := v.Float64()
+		if err != nil {
+			return attribute.KeyValue{}, fmt.Errorf("invalid number for the attribute %s: %w", key, err)
+		}
+		return attribute.Float64(key, f), nil
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return attribute.KeyValue{}, fmt.Errorf("unsupported value for the attribute %s: only arrays of strings are allowed", key)
+			}
+			values = append(values, s)
+		}
+		return attribute.StringSlice(key, values), nil
+	default:
+		return attribute.KeyValue{}, fmt.Errorf("unsupported value for the attribute %s: %v", key, value)
+	}
+}
+
+// pluginAttributes returns the attributes added to the suite by the plugins of the configuration, in the order
+// they are declared, prefixed as the rest of the attributes. The errors of a plugin are logged, without failing
+// the conversion, as with the attribute contributors
+func pluginAttributes(ctx context.Context, cfg *config.Config, suite junit.Suite) []attribute.KeyValue {
+	if len(cfg.Plugins) == 0 {
+		return nil
+	}
+
+	input, err := json.Marshal(newPluginSuite(suite))
+	if err != nil {
+		slog.Warn("failed to marshal the suite for the plugins", "suite", suite.Name, "error", err)
+		return nil
+	}
+
+	attributes := []attribute.KeyValue{}
+	for _, plugin := range cfg.Plugins {
+		pluginAttrs, err := runPlugin(ctx, plugin, input)
+		if err != nil {
+			slog.Warn("failed to enrich the suite with the plugin", "plugin", pluginName(plugin), "suite", suite.Name, "error", err)
+			continue
+		}
+
+		attributes = append(attributes, pluginAttrs...)
+	}
+
+	return prefixAttributes(cfg.AttributePrefix, attributes)
+}
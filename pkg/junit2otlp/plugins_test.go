@@ -0,0 +1,84 @@
+package junit2otlp
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginAttributes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the plugins of the tests are shell scripts")
+	}
+
+	xmlBuffer, err := os.ReadFile("../../TEST-sample.xml")
+	require.NoError(t, err)
+
+	suites, err := junit.Ingest(xmlBuffer)
+	require.NoError(t, err)
+	suite := suites[2]
+
+	t.Run("The plugins receive the suite and return attributes", func(t *testing.T) {
+		input := filepath.Join(t.TempDir(), "suite.json")
+
+		cfg := config.NewConfigFromDefaults()
+		cfg.AttributePrefix = "ci."
+		cfg.Plugins = []config.PluginConfig{
+			{Name: "owners", Command: []string{"sh", "-c", `cat > "$0"; echo '{"team": "platform", "tier": 1, "ratio": 0.5, "critical": true, "owners": ["alice", "bob"]}'`, input}},
+			{Name: "code", Command: []string{"sh", "-c", `cat > /dev/null; echo '{"code.owner": "platform"}'`}},
+		}
+
+		attributes := attributesToMap(pluginAttributes(context.Background(), cfg, suite))
+		require.Equal(t, map[string]interface{}{
+			"ci.team":     "platform",
+			"ci.tier":     int64(1),
+			"ci.ratio":    0.5,
+			"ci.critical": true,
+			"ci.owners":   []string{"alice", "bob"},
+			// the attributes of the semantic conventions are not prefixed
+			"code.owner": "platform",
+		}, attributes)
+
+		b, err := os.ReadFile(input)
+		require.NoError(t, err)
+
+		var ps pluginSuite
+		require.NoError(t, json.Unmarshal(b, &ps))
+		require.Equal(t, suite.Name, ps.Name)
+		require.Equal(t, suite.Totals.Tests, ps.Totals.Tests)
+		require.Len(t, ps.Tests, len(suite.Tests))
+		require.Equal(t, suite.Tests[0].Name, ps.Tests[0].Name)
+	})
+
+	t.Run("The failed plugins are skipped", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.Plugins = []config.PluginConfig{
+			{Name: "exit", Command: []string{"sh", "-c", `echo '{"exit": "1"}'; exit 1`}},
+			{Name: "invalid", Command: []string{"sh", "-c", `echo 'not json'`}},
+			{Name: "nested", Command: []string{"sh", "-c", `echo '{"nested": {"a": 1}}'`}},
+			{Name: "slow", Command: []string{"sh", "-c", `sleep 5`}, Timeout: 100 * time.Millisecond},
+			{Name: "missing", Command: []string{filepath.Join(t.TempDir(), "missing")}},
+			{Name: "valid", Command: []string{"sh", "-c", `echo '{"valid": "yes"}'`}},
+		}
+
+		attributes := attributesToMap(pluginAttributes(context.Background(), cfg, suite))
+		require.Equal(t, map[string]interface{}{"valid": "yes"}, attributes)
+	})
+
+	t.Run("Without plugins", func(t *testing.T) {
+		require.Empty(t, pluginAttributes(context.Background(), config.NewConfigFromDefaults(), suite))
+	})
+}
+
+func TestPluginName(t *testing.T) {
+	require.Equal(t, "owners", pluginName(config.PluginConfig{Name: "owners", Command: []string{"/usr/bin/enrich"}}))
+	require.Equal(t, "enrich", pluginName(config.PluginConfig{Command: []string{"/usr/bin/enrich"}}))
+}
@@ -0,0 +1,73 @@
+package junit2otlp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// attributesPreview represents the resolved attributes for a jUnit report, as they would be exported
+type attributesPreview struct {
+	Runtime map[string]interface{} `json:"runtime"`
+	Suites  []suitePreview         `json:"suites"`
+}
+
+type suitePreview struct {
+	Name       string                 `json:"name"`
+	Attributes map[string]interface{} `json:"attributes"`
+	Tests      []testPreview          `json:"tests"`
+}
+
+type testPreview struct {
+	Name       string                 `json:"name"`
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+// printAttributes writes the attributes resolved for the runtime, the suites and the test cases as indented JSON to w,
+// without exporting anything
+func printAttributes(ctx context.Context, w io.Writer, cfg *config.Config, runtimeAttributes []attribute.KeyValue, suites []junit.Suite) error {
+	preview := attributesPreview{
+		Runtime: attributesToMap(runtimeAttributes),
+		Suites:  []suitePreview{},
+	}
+
+	for _, suite := range suites {
+		suiteAttributes := getSuiteAttributes(cfg, suite, runtimeAttributes)
+		suiteAttributes = append(suiteAttributes, pluginAttributes(ctx, cfg, suite)...)
+		sharedAttributes := getSharedSuiteAttributes(cfg, suiteAttributes)
+
+		sp := suitePreview{
+			Name:       suite.Name,
+			Attributes: attributesToMap(limitSpanAttributes(cfg, suiteAttributes)),
+			Tests:      []testPreview{},
+		}
+
+		for _, test := range suite.Tests {
+			sp.Tests = append(sp.Tests, testPreview{
+				Name:       test.Name,
+				Attributes: attributesToMap(limitSpanAttributes(cfg, getTestAttributes(cfg, test, sharedAttributes))),
+			})
+		}
+
+		preview.Suites = append(preview.Suites, sp)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(preview)
+}
+
+// attributesToMap converts the attributes into a map, where the last value wins for duplicated keys, as in the exported spans
+func attributesToMap(attributes []attribute.KeyValue) map[string]interface{} {
+	m := map[string]interface{}{}
+	for _, att := range attributes {
+		m[string(att.Key)] = att.Value.AsInterface()
+	}
+
+	return m
+}
@@ -0,0 +1,51 @@
+package junit2otlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestPrintAttributes(t *testing.T) {
+	xmlBuffer, err := os.ReadFile("../../TEST-sample.xml")
+	require.NoError(t, err)
+
+	suites, err := junit.Ingest(xmlBuffer)
+	require.NoError(t, err)
+
+	cfg := config.NewConfigFromDefaults()
+	cfg.PropertiesAllowed = []string{"go.version"}
+
+	runtimeAttributes := []attribute.KeyValue{
+		attribute.Key("team").String("platform"),
+	}
+
+	var buf bytes.Buffer
+	err = printAttributes(context.Background(), &buf, cfg, runtimeAttributes, suites)
+	require.NoError(t, err)
+
+	var preview attributesPreview
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &preview))
+
+	require.Equal(t, map[string]interface{}{"team": "platform"}, preview.Runtime)
+	require.Len(t, preview.Suites, 3)
+
+	suite := preview.Suites[2]
+	require.Equal(t, "github.com/elastic/e2e-testing/cli/config", suite.Name)
+	require.Equal(t, "platform", suite.Attributes["team"])
+	require.Equal(t, "go1.16.3 linux/amd64", suite.Attributes["go.version"])
+	require.Len(t, suite.Tests, 11)
+
+	test := suite.Tests[0]
+	require.Equal(t, "TestCheckConfigDirsCreatesWorkspaceAtHome", test.Name)
+	require.Equal(t, "TestCheckConfigDirsCreatesWorkspaceAtHome", test.Attributes["code.function"])
+	require.Equal(t, "passed", test.Attributes[TestStatus])
+	require.Equal(t, "platform", test.Attributes["team"])
+}
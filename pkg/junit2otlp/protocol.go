@@ -0,0 +1,123 @@
+package junit2otlp
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+)
+
+const (
+	// protocolGRPC OTLP over gRPC, the default protocol of the exporters
+	protocolGRPC = "grpc"
+	// protocolHTTPProtobuf OTLP over HTTP, with the payloads encoded as protobuf
+	protocolHTTPProtobuf = "http/protobuf"
+
+	// defaultCollectorHTTPEndpoint the endpoint of the OTLP/HTTP receiver of a local collector
+	defaultCollectorHTTPEndpoint = "localhost:4318"
+)
+
+const (
+	signalLogs    = "LOGS"
+	signalMetrics = "METRICS"
+	signalTraces  = "TRACES"
+)
+
+// signalURLPaths paths of the OTLP/HTTP receivers of each signal, appended to the endpoint of the configuration
+var signalURLPaths = map[string]string{
+	signalLogs:    "/v1/logs",
+	signalMetrics: "/v1/metrics",
+	signalTraces:  "/v1/traces",
+}
+
+// otlpProtocol returns the protocol of the OTLP exporter of the signal: the one of the configuration wins over the
+// OTEL_EXPORTER_OTLP_<SIGNAL>_PROTOCOL and OTEL_EXPORTER_OTLP_PROTOCOL environment variables, in that order. An
+// empty signal resolves the protocol shared by all the signals. It defaults to gRPC
+func otlpProtocol(cfg *config.Config, signal string) (string, error) {
+	protocol := cfg.Exporter.Protocol
+	envVarKey := "OTEL_EXPORTER_OTLP_PROTOCOL"
+	if protocol == "" && signal != "" {
+		signalKey := "OTEL_EXPORTER_OTLP_" + signal + "_PROTOCOL"
+		if protocol = os.Getenv(signalKey); protocol != "" {
+			envVarKey = signalKey
+		}
+	}
+	protocol = getOtlpEnvVar(protocol, envVarKey, protocolGRPC)
+
+	switch protocol {
+	case protocolGRPC, protocolHTTPProtobuf:
+		return protocol, nil
+	default:
+		return "", fmt.Errorf("invalid OTLP protocol in %s: %s. Supported protocols: %s, %s", envVarKey, protocol, protocolGRPC, protocolHTTPProtobuf)
+	}
+}
+
+// signalURL returns the URL of the OTLP/HTTP receiver of the signal at the endpoint, as the exporters do with the
+// OTEL_EXPORTER_OTLP_ENDPOINT environment variable, i.e. http://collector:4318/v1/traces
+func signalURL(endpoint string, signal string) string {
+	return strings.TrimSuffix(endpoint, "/") + signalURLPaths[signal]
+}
+
+// traceHTTPExporterOptions returns the options for the OTLP/HTTP traces exporter from the configuration, as
+// traceExporterOptions does for the gRPC one
+func traceHTTPExporterOptions(cfg *config.Config) []otlptracehttp.Option {
+	opts := []otlptracehttp.Option{}
+
+	if cfg.Exporter.Endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpointURL(signalURL(cfg.Exporter.Endpoint, signalTraces)))
+	}
+
+	if cfg.Exporter.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+
+	if len(cfg.Exporter.Headers) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(cfg.Exporter.Headers))
+	}
+
+	return opts
+}
+
+// metricHTTPExporterOptions returns the options for the OTLP/HTTP metrics exporter from the configuration, as
+// metricExporterOptions does for the gRPC one
+func metricHTTPExporterOptions(cfg *config.Config) []otlpmetrichttp.Option {
+	opts := []otlpmetrichttp.Option{}
+
+	if cfg.Exporter.Endpoint != "" {
+		opts = append(opts, otlpmetrichttp.WithEndpointURL(signalURL(cfg.Exporter.Endpoint, signalMetrics)))
+	}
+
+	if cfg.Exporter.Insecure {
+		opts = append(opts, otlpmetrichttp.WithInsecure())
+	}
+
+	if len(cfg.Exporter.Headers) > 0 {
+		opts = append(opts, otlpmetrichttp.WithHeaders(cfg.Exporter.Headers))
+	}
+
+	return opts
+}
+
+// logHTTPExporterOptions returns the options for the OTLP/HTTP logs exporter from the configuration, as
+// logExporterOptions does for the gRPC one
+func logHTTPExporterOptions(cfg *config.Config) []otlploghttp.Option {
+	opts := []otlploghttp.Option{}
+
+	if cfg.Exporter.Endpoint != "" {
+		opts = append(opts, otlploghttp.WithEndpointURL(signalURL(cfg.Exporter.Endpoint, signalLogs)))
+	}
+
+	if cfg.Exporter.Insecure {
+		opts = append(opts, otlploghttp.WithInsecure())
+	}
+
+	if len(cfg.Exporter.Headers) > 0 {
+		opts = append(opts, otlploghttp.WithHeaders(cfg.Exporter.Headers))
+	}
+
+	return opts
+}
@@ -0,0 +1,104 @@
+package junit2otlp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOtlpProtocol(t *testing.T) {
+	t.Run("Default protocol", func(t *testing.T) {
+		t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "")
+		t.Setenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "")
+
+		protocol, err := otlpProtocol(config.NewConfigFromDefaults(), signalTraces)
+		require.NoError(t, err)
+		require.Equal(t, protocolGRPC, protocol)
+	})
+
+	t.Run("Protocol from the environment", func(t *testing.T) {
+		t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/protobuf")
+		t.Setenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "")
+
+		protocol, err := otlpProtocol(config.NewConfigFromDefaults(), signalTraces)
+		require.NoError(t, err)
+		require.Equal(t, protocolHTTPProtobuf, protocol)
+	})
+
+	t.Run("Protocol of the signal wins", func(t *testing.T) {
+		t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/protobuf")
+		t.Setenv("OTEL_EXPORTER_OTLP_METRICS_PROTOCOL", "grpc")
+
+		protocol, err := otlpProtocol(config.NewConfigFromDefaults(), signalMetrics)
+		require.NoError(t, err)
+		require.Equal(t, protocolGRPC, protocol)
+
+		protocol, err = otlpProtocol(config.NewConfigFromDefaults(), "")
+		require.NoError(t, err)
+		require.Equal(t, protocolHTTPProtobuf, protocol)
+	})
+
+	t.Run("Protocol from the configuration wins", func(t *testing.T) {
+		t.Setenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "grpc")
+
+		cfg := config.NewConfigFromDefaults()
+		cfg.Exporter.Protocol = protocolHTTPProtobuf
+
+		protocol, err := otlpProtocol(cfg, signalTraces)
+		require.NoError(t, err)
+		require.Equal(t, protocolHTTPProtobuf, protocol)
+	})
+
+	t.Run("Invalid protocol", func(t *testing.T) {
+		t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "")
+		t.Setenv("OTEL_EXPORTER_OTLP_LOGS_PROTOCOL", "http/json")
+
+		_, err := otlpProtocol(config.NewConfigFromDefaults(), signalLogs)
+		require.ErrorContains(t, err, "invalid OTLP protocol in OTEL_EXPORTER_OTLP_LOGS_PROTOCOL: http/json")
+	})
+}
+
+func TestSignalURL(t *testing.T) {
+	require.Equal(t, "http://collector:4318/v1/traces", signalURL("http://collector:4318", signalTraces))
+	require.Equal(t, "https://example.com/otlp/v1/metrics", signalURL("https://example.com/otlp/", signalMetrics))
+}
+
+func Test_NewProviders_HTTPProtobuf(t *testing.T) {
+	var mu sync.Mutex
+	paths := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths[r.URL.Path] = r.Header.Get("Content-Type")
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	t.Setenv("OTEL_TRACES_EXPORTER", "")
+	t.Setenv("OTEL_METRICS_EXPORTER", "")
+	t.Setenv("OTEL_LOGS_EXPORTER", "")
+
+	cfg := config.NewConfigFromDefaults()
+	cfg.Exporter.Endpoint = server.URL
+	cfg.Exporter.Protocol = protocolHTTPProtobuf
+
+	providers, shutdown, err := newProviders(context.Background(), cfg)
+	require.NoError(t, err)
+
+	_, span := providers.TracerProvider.Tracer("test").Start(context.Background(), "span")
+	span.End()
+	counter, _ := providers.MeterProvider.Meter("test").Int64Counter("counter")
+	counter.Add(context.Background(), 1)
+
+	// the telemetry is pushed to the receiver on shutdown
+	shutdown()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, "application/x-protobuf", paths["/v1/traces"])
+	require.Equal(t, "application/x-protobuf", paths["/v1/metrics"])
+}
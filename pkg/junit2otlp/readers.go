@@ -0,0 +1,457 @@
+package junit2otlp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf16"
+)
+
+// inputStdin reads a single jUnit report from the standard input
+const inputStdin = "-"
+
+var (
+	utf8BOM    = []byte{0xEF, 0xBB, 0xBF}
+	utf16LEBOM = []byte{0xFF, 0xFE}
+	utf16BEBOM = []byte{0xFE, 0xFF}
+)
+
+// xmlDeclaredEncoding the encoding of the XML declaration of a report
+var xmlDeclaredEncoding = regexp.MustCompile(`encoding\s*=\s*["'][^"']*["']`)
+
+// InputReader reads the reports of a conversion, i.e. a file, the standard input or a report already in memory.
+// Read returns a single report, or all of them concatenated for the readers of several reports, which implement
+// MultiInputReader too
+type InputReader interface {
+	Read() ([]byte, error)
+}
+
+// MultiInputReader reads several reports at once, i.e. the files inside a tar archive,
+// so that they can be parsed in parallel
+type MultiInputReader interface {
+	InputReader
+	ReadAll() ([]InputReport, error)
+}
+
+// StreamInputReader reads a single report while it's being written, i.e. the output of go test -json piped
+// to the tool, so the formats parsed incrementally export each suite as soon as it's complete
+type StreamInputReader interface {
+	InputReader
+	Open() (io.ReadCloser, error)
+}
+
+// InputReport a report read by a MultiInputReader, and the name of its source
+type InputReport struct {
+	Name string
+	Data []byte
+}
+
+// inputSizeLimiter is implemented by the readers failing on the reports larger than a maximum size
+type inputSizeLimiter interface {
+	limitInputSize(maxSize int64)
+}
+
+// limitedReadCloser fails the reads past the maximum size with an explicit error, instead of truncating the
+// report, which would be parsed as a malformed one
+type limitedReadCloser struct {
+	io.ReadCloser
+	name      string
+	maxSize   int64
+	remaining int64
+}
+
+// limitReadCloser returns the reader of the report with the name, failing once it reads more than the maximum
+// size in bytes. It returns the reader as it is if the maximum size is not positive
+func limitReadCloser(rc io.ReadCloser, name string, maxSize int64) io.ReadCloser {
+	if maxSize <= 0 {
+		return rc
+	}
+
+	return &limitedReadCloser{ReadCloser: rc, name: name, maxSize: maxSize, remaining: maxSize}
+}
+
+func (l *limitedReadCloser) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		// the report is only too large if there is anything left after the maximum size
+		var b [1]byte
+		n, err := l.ReadCloser.Read(b[:])
+		if n > 0 {
+			return 0, inputSizeError(l.name, l.maxSize)
+		}
+		return 0, err
+	}
+
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+
+	n, err := l.ReadCloser.Read(p)
+	l.remaining -= int64(n)
+
+	return n, err
+}
+
+// inputSizeError the error of the reports larger than the maximum size in bytes
+func inputSizeError(name string, maxSize int64) error {
+	return fmt.Errorf("the report %s is larger than the maximum input size of %d MiB: increase it with --max-input-size", name, maxSize>>20)
+}
+
+// combineReaders returns a single reader for the readers, reading the reports of all of them. The readers fail on
+// the reports larger than the maximum size in bytes, if it's positive, whichever way they were created
+func combineReaders(readers []InputReader, maxSize int64) (InputReader, error) {
+	var reader InputReader
+	switch len(readers) {
+	case 0:
+		return nil, fmt.Errorf("at least one input reader is required")
+	case 1:
+		reader = readers[0]
+	default:
+		reader = &multiReader{readers: readers}
+	}
+
+	if limiter, ok := reader.(inputSizeLimiter); ok && maxSize > 0 {
+		limiter.limitInputSize(maxSize)
+	}
+
+	return reader, nil
+}
+
+// multiReader reads the reports of several readers, as a single input
+type multiReader struct {
+	readers []InputReader
+}
+
+func (mr *multiReader) limitInputSize(maxSize int64) {
+	for _, reader := range mr.readers {
+		if limiter, ok := reader.(inputSizeLimiter); ok {
+			limiter.limitInputSize(maxSize)
+		}
+	}
+}
+
+// Read reads the reports of all the readers, one after the other
+func (mr *multiReader) Read() ([]byte, error) {
+	reports, err := mr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	for _, report := range reports {
+		buf.Write(report.Data)
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ReadAll reads the reports of all the readers, expanding the ones reading several reports. The reports
+// of the files are named after their paths, and the rest after their position in the readers
+func (mr *multiReader) ReadAll() ([]InputReport, error) {
+	reports := []InputReport{}
+
+	for i, reader := range mr.readers {
+		if multiReader, ok := reader.(MultiInputReader); ok {
+			all, err := multiReader.ReadAll()
+			if err != nil {
+				return nil, err
+			}
+
+			reports = append(reports, all...)
+			continue
+		}
+
+		data, err := reader.Read()
+		if err != nil {
+			return nil, err
+		}
+
+		name := fmt.Sprintf("input %d", i+1)
+		if fileReader, ok := reader.(*FileReader); ok {
+			name = fileReader.path
+		}
+
+		reports = append(reports, InputReport{Name: name, Data: data})
+	}
+
+	return reports, nil
+}
+
+type PipeReader struct {
+	maxSize int64
+}
+
+func (pr *PipeReader) limitInputSize(maxSize int64) {
+	pr.maxSize = maxSize
+}
+
+// Read reads the whole standard input, which must be a pipe or a redirected file, such as
+// a PowerShell pipeline on Windows
+func (pr *PipeReader) Read() ([]byte, error) {
+	stdin, err := pr.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	buf, err := io.ReadAll(stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	return normalizeInput(buf), nil
+}
+
+// Open returns the standard input, which must be a pipe or a redirected file, without reading it.
+// Closing it does not close the standard input
+func (pr *PipeReader) Open() (io.ReadCloser, error) {
+	stat, err := os.Stdin.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	if (stat.Mode() & os.ModeCharDevice) != 0 {
+		return nil, fmt.Errorf("there is no data in the pipe")
+	}
+
+	return limitReadCloser(io.NopCloser(os.Stdin), "from the standard input", pr.maxSize), nil
+}
+
+// FileReader reads the report from a file, which can also be a named pipe, including the
+// Windows ones, i.e. \\.\pipe\reports
+type FileReader struct {
+	path    string
+	maxSize int64
+}
+
+// NewFileReader returns a reader for the file at path
+func NewFileReader(path string) *FileReader {
+	return &FileReader{path: path}
+}
+
+func (fr *FileReader) limitInputSize(maxSize int64) {
+	fr.maxSize = maxSize
+}
+
+func (fr *FileReader) Read() ([]byte, error) {
+	f, err := fr.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	buf, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the report: %w", err)
+	}
+
+	return normalizeInput(buf), nil
+}
+
+// Open opens the file without reading it, so a named pipe can be read while it's written
+func (fr *FileReader) Open() (io.ReadCloser, error) {
+	path := fr.path
+	if !isNamedPipe(path) {
+		path = normalizePath(path)
+
+		stat, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the report: %w", err)
+		}
+
+		if stat.IsDir() {
+			return nil, fmt.Errorf("failed to read the report: %s is a directory", path)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the report: %w", err)
+	}
+
+	return limitReadCloser(f, fr.path, fr.maxSize), nil
+}
+
+// BytesReader reads a report already in memory, i.e. produced by the test framework embedding the conversion,
+// with the name of its source, used in the errors and by the matrix pattern
+type BytesReader struct {
+	name    string
+	data    []byte
+	maxSize int64
+}
+
+// NewBytesReader returns a reader for the report in data, named after its source
+func NewBytesReader(name string, data []byte) *BytesReader {
+	return &BytesReader{name: name, data: data}
+}
+
+func (br *BytesReader) limitInputSize(maxSize int64) {
+	br.maxSize = maxSize
+}
+
+func (br *BytesReader) Read() ([]byte, error) {
+	if br.maxSize > 0 && int64(len(br.data)) > br.maxSize {
+		return nil, inputSizeError(br.name, br.maxSize)
+	}
+
+	return normalizeInput(br.data), nil
+}
+
+// ReadAll returns the report with its name, so it's named after its source when it's combined with other readers
+func (br *BytesReader) ReadAll() ([]InputReport, error) {
+	data, err := br.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	return []InputReport{{Name: br.name, Data: data}}, nil
+}
+
+// normalizeInput removes the byte order marks and the Windows line endings, which are common when the
+// reports are produced or piped on Windows. UTF-16 encoded input, the default of some PowerShell
+// versions, is converted to UTF-8, and so is the encoding of its XML declaration
+func normalizeInput(buf []byte) []byte {
+	switch {
+	case bytes.HasPrefix(buf, utf8BOM):
+		buf = buf[len(utf8BOM):]
+	case bytes.HasPrefix(buf, utf16LEBOM):
+		buf = declareUTF8(decodeUTF16(buf[len(utf16LEBOM):], false))
+	case bytes.HasPrefix(buf, utf16BEBOM):
+		buf = declareUTF8(decodeUTF16(buf[len(utf16BEBOM):], true))
+	}
+
+	return bytes.ReplaceAll(buf, []byte("\r\n"), []byte("\n"))
+}
+
+// declareUTF8 replaces the encoding of the XML declaration of a report converted to UTF-8, i.e.
+// <?xml version="1.0" encoding="UTF-16"?>, as the XML decoders reject the encodings other than UTF-8
+func declareUTF8(buf []byte) []byte {
+	if !bytes.HasPrefix(buf, []byte("<?xml")) {
+		return buf
+	}
+
+	end := bytes.Index(buf, []byte("?>"))
+	if end < 0 {
+		return buf
+	}
+
+	declaration := xmlDeclaredEncoding.ReplaceAll(buf[:end], []byte(`encoding="UTF-8"`))
+
+	return append(declaration, buf[end:]...)
+}
+
+// decodeUTF16 converts UTF-16 encoded bytes into UTF-8, ignoring the trailing odd byte, if any
+func decodeUTF16(buf []byte, bigEndian bool) []byte {
+	units := make([]uint16, 0, len(buf)/2)
+	for i := 0; i+1 < len(buf); i += 2 {
+		if bigEndian {
+			units = append(units, uint16(buf[i])<<8|uint16(buf[i+1]))
+		} else {
+			units = append(units, uint16(buf[i+1])<<8|uint16(buf[i]))
+		}
+	}
+
+	return []byte(string(utf16.Decode(units)))
+}
+
+// NewInputReader returns the reader for the input set in the configuration: the standard input
+// by default, a tar or a zip archive if the input starts with 'tar:' or 'zip:', the files matching
+// the input if it's a glob pattern, or the file at the given path. The files with the extension of
+// an archive, i.e. .zip or .tgz, are read as archives
+func NewInputReader(input string) (InputReader, error) {
+	if input == "" || input == inputStdin {
+		return &PipeReader{}, nil
+	}
+
+	if path, ok := strings.CutPrefix(input, inputTarPrefix); ok {
+		return NewTarReader(path)
+	}
+
+	if path, ok := strings.CutPrefix(input, inputZipPrefix); ok {
+		return NewZipReader(path), nil
+	}
+
+	if hasGlobMeta(input) {
+		paths, err := expandGlob(input)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("there are no reports matching %s", input)
+		}
+
+		readers := make([]InputReader, 0, len(paths))
+		for _, path := range paths {
+			if prefix := archivePrefix(path); prefix != "" {
+				reader, err := NewInputReader(prefix + path)
+				if err != nil {
+					return nil, err
+				}
+
+				readers = append(readers, reader)
+				continue
+			}
+
+			readers = append(readers, NewFileReader(path))
+		}
+
+		// even a single report is named after its path, instead of the pattern
+		return &multiReader{readers: readers}, nil
+	}
+
+	if prefix := archivePrefix(input); prefix != "" {
+		return NewInputReader(prefix + input)
+	}
+
+	return NewFileReader(input), nil
+}
+
+// NewInputReaders returns the reader of all the inputs of the configuration, reading their reports in the same
+// run: the input, the inputs, i.e. the positional arguments of the command line, and the reports found in the scan
+// directory. The standard input is only read when there are no other inputs. The readers fail on the reports larger
+// than the maximum input size of the configuration, if any
+func NewInputReaders(cfg *Config) (InputReader, error) {
+	inputs := slices.Clone(cfg.Inputs)
+	if cfg.ScanDir != "" {
+		if hasGlobMeta(cfg.ScanDir) {
+			return nil, fmt.Errorf("the scan directory %s cannot be a glob pattern, use the input instead", cfg.ScanDir)
+		}
+		if strings.Contains(filepath.ToSlash(cfg.ScanPattern), "/") {
+			return nil, fmt.Errorf("the scan pattern %s must match the names of the reports, not their paths", cfg.ScanPattern)
+		}
+		if _, err := os.Stat(cfg.ScanDir); err != nil {
+			return nil, fmt.Errorf("failed to scan the directory: %w", err)
+		}
+
+		inputs = append(inputs, filepath.Join(cfg.ScanDir, globDoubleStar, cfg.ScanPattern))
+	}
+	if len(inputs) == 0 || (cfg.Input != "" && cfg.Input != inputStdin) {
+		inputs = append([]string{cfg.Input}, inputs...)
+	}
+
+	readers := make([]InputReader, 0, len(inputs))
+	for _, input := range inputs {
+		reader, err := NewInputReader(input)
+		if err != nil {
+			return nil, err
+		}
+
+		if limiter, ok := reader.(inputSizeLimiter); ok && cfg.MaxInputSize > 0 {
+			limiter.limitInputSize(int64(cfg.MaxInputSize) << 20)
+		}
+
+		readers = append(readers, reader)
+	}
+
+	if len(readers) == 1 && inputs[0] == cfg.Input {
+		return readers[0], nil
+	}
+
+	// the reports are named after their inputs, instead of the input of the configuration
+	return &multiReader{readers: readers}, nil
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+
+package junit2otlp
+
+import "path/filepath"
+
+// isNamedPipe returns false, as the named pipes (FIFOs) can be read as regular files outside Windows
+func isNamedPipe(_ string) bool {
+	return false
+}
+
+// normalizePath cleans the path, as the backslashes are valid characters in the file names outside Windows
+func normalizePath(path string) string {
+	return filepath.Clean(path)
+}
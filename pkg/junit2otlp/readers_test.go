@@ -0,0 +1,250 @@
+package junit2otlp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mdelapenya/junit2otlp/internal/formats"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileReader(t *testing.T) {
+	t.Run("Regular file", func(t *testing.T) {
+		expected, err := os.ReadFile("../../TEST-sample.xml")
+		require.NoError(t, err)
+
+		b, err := NewFileReader("../../TEST-sample.xml").Read()
+		require.NoError(t, err)
+		require.Equal(t, normalizeInput(expected), b)
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		_, err := NewFileReader(filepath.Join(t.TempDir(), "TEST-missing.xml")).Read()
+		require.ErrorContains(t, err, "failed to read the report")
+	})
+
+	t.Run("Directory", func(t *testing.T) {
+		_, err := NewFileReader(t.TempDir()).Read()
+		require.ErrorContains(t, err, "is a directory")
+	})
+}
+
+func TestNormalizeInput(t *testing.T) {
+	expected := []byte("<testsuites>\n</testsuites>\n")
+
+	t.Run("UTF-8", func(t *testing.T) {
+		require.Equal(t, expected, normalizeInput([]byte("<testsuites>\n</testsuites>\n")))
+	})
+
+	t.Run("UTF-8 with BOM and CRLF", func(t *testing.T) {
+		input := append([]byte{0xEF, 0xBB, 0xBF}, []byte("<testsuites>\r\n</testsuites>\r\n")...)
+		require.Equal(t, expected, normalizeInput(input))
+	})
+
+	t.Run("UTF-16", func(t *testing.T) {
+		le := []byte{0xFF, 0xFE}
+		be := []byte{0xFE, 0xFF}
+		for _, r := range "<testsuites>\r\n</testsuites>\r\n" {
+			le = append(le, byte(r), 0)
+			be = append(be, 0, byte(r))
+		}
+
+		require.Equal(t, expected, normalizeInput(le))
+		require.Equal(t, expected, normalizeInput(be))
+	})
+
+	t.Run("UTF-16 with XML declaration", func(t *testing.T) {
+		// written by PowerShell, i.e. Pester, with a byte order mark and Windows line endings
+		b, err := NewFileReader(filepath.Join("testdata", "TEST-utf16.xml")).Read()
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(string(b), `<?xml version="1.0" encoding="UTF-8"?>`+"\n<testsuites>"))
+
+		suites, err := formats.Parse(formats.JUnit, b)
+		require.NoError(t, err)
+		require.Len(t, suites, 1)
+		require.Equal(t, "Pester", suites[0].Name)
+		require.Equal(t, 1, suites[0].Totals.Failed)
+	})
+}
+
+func TestCombineReaders(t *testing.T) {
+	t.Run("Without readers", func(t *testing.T) {
+		_, err := combineReaders(nil, 0)
+		require.ErrorContains(t, err, "at least one input reader is required")
+	})
+
+	t.Run("Single reader", func(t *testing.T) {
+		reader := NewFileReader("../../TEST-sample.xml")
+
+		combined, err := combineReaders([]InputReader{reader}, 0)
+		require.NoError(t, err)
+		require.Same(t, reader, combined)
+	})
+
+	t.Run("Several readers", func(t *testing.T) {
+		sample, err := os.ReadFile("../../TEST-sample.xml")
+		require.NoError(t, err)
+
+		combined, err := combineReaders([]InputReader{
+			NewFileReader("../../TEST-sample.xml"),
+			&TestReader{testFile: "../../TEST-sample2.xml"},
+		}, 0)
+		require.NoError(t, err)
+
+		reports, err := combined.(MultiInputReader).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, reports, 2)
+		require.Equal(t, "../../TEST-sample.xml", reports[0].Name)
+		require.Equal(t, normalizeInput(sample), reports[0].Data)
+		require.Equal(t, "input 2", reports[1].Name)
+	})
+
+	t.Run("Bytes reader", func(t *testing.T) {
+		combined, err := combineReaders([]InputReader{
+			NewBytesReader("linux/TEST-a.xml", []byte("\xEF\xBB\xBF<testsuite/>")),
+			NewBytesReader("windows/TEST-a.xml", []byte("<testsuite/>")),
+		}, 0)
+		require.NoError(t, err)
+
+		reports, err := combined.(MultiInputReader).ReadAll()
+		require.NoError(t, err)
+		require.Equal(t, []InputReport{
+			{Name: "linux/TEST-a.xml", Data: []byte("<testsuite/>")},
+			{Name: "windows/TEST-a.xml", Data: []byte("<testsuite/>")},
+		}, reports)
+	})
+
+	t.Run("Max input size", func(t *testing.T) {
+		largeFile := filepath.Join(t.TempDir(), "TEST-large.xml")
+		require.NoError(t, os.WriteFile(largeFile, []byte(strings.Repeat("x", 1<<20+1)), 0o644))
+
+		// the readers created without NewInputReaders are limited too
+		combined, err := combineReaders([]InputReader{NewFileReader(largeFile)}, 1<<20)
+		require.NoError(t, err)
+		_, err = combined.Read()
+		require.ErrorContains(t, err, "TEST-large.xml is larger than the maximum input size of 1 MiB")
+
+		combined, err = combineReaders([]InputReader{
+			NewBytesReader("TEST-small.xml", []byte("<testsuite/>")),
+			NewBytesReader("TEST-large.xml", []byte(strings.Repeat("x", 1<<20+1))),
+		}, 1<<20)
+		require.NoError(t, err)
+		_, err = combined.(MultiInputReader).ReadAll()
+		require.ErrorContains(t, err, "TEST-large.xml is larger than the maximum input size of 1 MiB")
+	})
+}
+
+func TestNewInputReaders(t *testing.T) {
+	dir := t.TempDir()
+	for _, path := range []string{"api/target/surefire-reports", "web/target/surefire-reports", "web/target/other"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, path), 0o755))
+	}
+	writeProjectFile(t, dir, filepath.Join("api", "target", "surefire-reports", "TEST-api.xml"), "<testsuite/>")
+	writeProjectFile(t, dir, filepath.Join("web", "target", "surefire-reports", "TEST-web.xml"), "<testsuite/>")
+	writeProjectFile(t, dir, filepath.Join("web", "target", "other", "TEST-other.xml"), "<testsuite/>")
+	writeProjectFile(t, dir, "TEST-root.xml", "<testsuite/>")
+
+	names := func(t *testing.T, reader InputReader) []string {
+		t.Helper()
+
+		reports, err := reader.(MultiInputReader).ReadAll()
+		require.NoError(t, err)
+
+		names := []string{}
+		for _, report := range reports {
+			rel, err := filepath.Rel(dir, report.Name)
+			require.NoError(t, err)
+			names = append(names, filepath.ToSlash(rel))
+		}
+
+		return names
+	}
+
+	t.Run("Glob with double star", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.Input = filepath.Join(dir, "**", "surefire-reports", "*.xml")
+
+		reader, err := NewInputReaders(cfg)
+		require.NoError(t, err)
+		require.Equal(t, []string{"api/target/surefire-reports/TEST-api.xml", "web/target/surefire-reports/TEST-web.xml"}, names(t, reader))
+	})
+
+	t.Run("Positional inputs without stdin", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.Inputs = []string{filepath.Join(dir, "TEST-root.xml"), filepath.Join(dir, "web", "*", "*", "*.xml")}
+
+		reader, err := NewInputReaders(cfg)
+		require.NoError(t, err)
+		require.Equal(t, []string{"TEST-root.xml", "web/target/other/TEST-other.xml", "web/target/surefire-reports/TEST-web.xml"}, names(t, reader))
+	})
+
+	t.Run("Scan dir", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.ScanDir = dir
+
+		reader, err := NewInputReaders(cfg)
+		require.NoError(t, err)
+		require.Equal(t, []string{"TEST-root.xml", "api/target/surefire-reports/TEST-api.xml", "web/target/other/TEST-other.xml", "web/target/surefire-reports/TEST-web.xml"}, names(t, reader))
+
+		cfg.ScanPattern = "TEST-api.xml"
+		reader, err = NewInputReaders(cfg)
+		require.NoError(t, err)
+		require.Equal(t, []string{"api/target/surefire-reports/TEST-api.xml"}, names(t, reader))
+
+		cfg.ScanPattern = "surefire-reports/*.xml"
+		_, err = NewInputReaders(cfg)
+		require.ErrorContains(t, err, "must match the names of the reports")
+
+		cfg.ScanDir = filepath.Join(dir, "missing")
+		cfg.ScanPattern = "TEST-*.xml"
+		_, err = NewInputReaders(cfg)
+		require.ErrorContains(t, err, "failed to scan the directory")
+	})
+
+	t.Run("Max input size", func(t *testing.T) {
+		limitDir := t.TempDir()
+		writeProjectFile(t, limitDir, "TEST-limit.xml", strings.Repeat("x", 1<<20))
+		writeProjectFile(t, limitDir, "TEST-large.xml", strings.Repeat("x", 1<<20+1))
+
+		cfg := config.NewConfigFromDefaults()
+		cfg.MaxInputSize = 1
+		cfg.Input = filepath.Join(limitDir, "TEST-limit.xml")
+
+		reader, err := NewInputReaders(cfg)
+		require.NoError(t, err)
+		data, err := reader.Read()
+		require.NoError(t, err)
+		require.Len(t, data, 1<<20)
+
+		cfg.Input = filepath.Join(limitDir, "TEST-*.xml")
+		reader, err = NewInputReaders(cfg)
+		require.NoError(t, err)
+		_, err = reader.(MultiInputReader).ReadAll()
+		require.ErrorContains(t, err, "TEST-large.xml is larger than the maximum input size of 1 MiB")
+	})
+
+	t.Run("Stdin by default", func(t *testing.T) {
+		reader, err := NewInputReaders(config.NewConfigFromDefaults())
+		require.NoError(t, err)
+		require.IsType(t, &PipeReader{}, reader)
+	})
+
+	t.Run("No matches", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.Input = filepath.Join(dir, "**", "*.json")
+
+		_, err := NewInputReaders(cfg)
+		require.ErrorContains(t, err, "there are no reports matching")
+	})
+
+	t.Run("Invalid pattern", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.Input = filepath.Join(dir, "[", "*.xml")
+
+		_, err := NewInputReaders(cfg)
+		require.ErrorContains(t, err, "invalid glob pattern")
+	})
+}
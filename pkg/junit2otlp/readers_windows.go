@@ -0,0 +1,22 @@
+//go:build windows
+
+package junit2otlp
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// windowsNamedPipePrefix prefix of the paths of the local Windows named pipes
+const windowsNamedPipePrefix = `\\.\pipe\`
+
+// isNamedPipe returns true if the path is a Windows named pipe, which cannot be stat'ed as a regular file
+func isNamedPipe(path string) bool {
+	return strings.HasPrefix(strings.ToLower(path), windowsNamedPipePrefix)
+}
+
+// normalizePath converts the forward slashes into backslashes, so that the paths written
+// in the Unix style, common in CI templates, work on Windows too
+func normalizePath(path string) string {
+	return filepath.Clean(filepath.FromSlash(path))
+}
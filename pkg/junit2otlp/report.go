@@ -0,0 +1,281 @@
+package junit2otlp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/internal/formats"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/sync/errgroup"
+)
+
+// suitesBufferSize number of parsed suites waiting to be transformed into spans, which bounds
+// the memory used when the parsing is faster than the creation of the spans
+const suitesBufferSize = 16
+
+// reportSuite a suite of the report, with its start time, which is the zero time if the report
+// does not include it
+type reportSuite struct {
+	suite     junit.Suite
+	startTime time.Time
+	// report name of the report of the suite
+	report string
+	// dimensions the tests.matrix.* attributes of the report, when the matrix pattern is set
+	dimensions []attribute.KeyValue
+	// reruns number of test cases dropped from the run as reruns of the same matrix cell, set on the last suite
+	reruns int
+	// shards the suites merged into this one, when the suites are merged, whose test cases are the ones of the
+	// suite, in the same order
+	shards []reportSuite
+}
+
+// reportStream parses the suites of a report in the background, sending each one of them to the
+// suites channel as soon as it's parsed. The channel is closed when the parsing finishes
+type reportStream struct {
+	suites  <-chan reportSuite
+	reports int
+	done    chan struct{}
+	end     time.Time
+	err     error
+}
+
+// wait blocks until the parsing finishes, returning when it finished and the parsing error, if any
+func (rs *reportStream) wait() (time.Time, error) {
+	<-rs.done
+	return rs.end, rs.err
+}
+
+// streamReport reads the reports from the reader, validating them in strict mode if configured, and starts
+// parsing them in the background with the format of the configuration. The reports of a MultiInputReader are
+// parsed in parallel, although their suites are sent in the order of the reports. Cancelling the context stops
+// the parsing
+func streamReport(ctx context.Context, cfg *config.Config, reader InputReader) (*reportStream, error) {
+	if err := formats.Validate(cfg.Format); err != nil {
+		return nil, err
+	}
+
+	loc, err := loadTimezone(cfg.AssumeTimezone)
+	if err != nil {
+		return nil, err
+	}
+
+	matrix, err := newMatrixPattern(cfg.MatrixPattern)
+	if err != nil {
+		return nil, err
+	}
+
+	// the strict mode checks the whole report before parsing it, so it's not parsed while it's read
+	if streamReader, ok := reader.(StreamInputReader); ok && !cfg.StrictParse && formats.IsIncremental(formats.ForReport(cfg.Format, reportName(cfg, reader), nil)) {
+		return streamInput(ctx, cfg, streamReader, loc, matrix)
+	}
+
+	reports, err := readReports(cfg, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from pipe: %v", err)
+	}
+
+	if cfg.StrictParse {
+		errs := []error{}
+		for _, report := range reports {
+			errs = append(errs, formats.Check(formats.ForReport(cfg.Format, report.Name, report.Data), report.Name, report.Data))
+		}
+
+		if err := errors.Join(errs...); err != nil {
+			return nil, fmt.Errorf("failed to parse the %s report in strict mode:\n%w", cfg.Format, err)
+		}
+	}
+
+	suites := make(chan reportSuite, suitesBufferSize)
+	stream := &reportStream{suites: suites, reports: len(reports), done: make(chan struct{})}
+
+	workers := cfg.Parallelism
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	go func() {
+		defer close(stream.done)
+		defer close(suites)
+
+		// the suites are forwarded until the parsing is cancelled, as the context of the group is cancelled once
+		// the workers finish, maybe before their suites are forwarded
+		parent := ctx
+
+		// the first error stops the rest of the workers
+		group, ctx := errgroup.WithContext(ctx)
+		group.SetLimit(workers)
+
+		// each report sends its suites to its own channel, forwarded in the order of the reports, so the suites
+		// are created in the same order whatever the parallelism
+		reportSuites := make([]chan reportSuite, len(reports))
+		for i := range reportSuites {
+			reportSuites[i] = make(chan reportSuite, suitesBufferSize)
+		}
+
+		forwarded := make(chan struct{})
+		go func() {
+			defer close(forwarded)
+
+			for _, ch := range reportSuites {
+				for rs := range ch {
+					select {
+					case suites <- rs:
+					case <-parent.Done():
+					}
+				}
+			}
+		}()
+
+		var count atomic.Int64
+		for i, report := range reports {
+			group.Go(func() error {
+				defer close(reportSuites[i])
+
+				dimensions := matrix.dimensions(report.Name)
+				format := formats.ForReport(cfg.Format, report.Name, report.Data)
+
+				err := formats.Stream(format, report.Data, func(suite junit.Suite, timestamp string) error {
+					startTime := resolveTimestamps([]string{timestamp}, cfg.TimestampLayouts, loc)[0]
+
+					select {
+					case reportSuites[i] <- reportSuite{suite: suite, startTime: startTime, report: report.Name, dimensions: dimensions}:
+						count.Add(1)
+						return nil
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+				})
+				if err != nil {
+					return fmt.Errorf("failed to ingest the %s report %s: %w", format, report.Name, err)
+				}
+
+				return nil
+			})
+		}
+
+		stream.err = group.Wait()
+		<-forwarded
+		stream.end = time.Now()
+
+		if stream.err == nil {
+			slog.Debug("reports ingested", "format", cfg.Format, "reports", len(reports), "suites", count.Load(), "workers", workers)
+		}
+	}()
+
+	return stream, nil
+}
+
+// streamInput parses the report while it's read, for the formats parsed incrementally, so each suite is sent
+// as soon as it's complete, i.e. when a package of go test -json finishes, instead of at the end of the input
+func streamInput(ctx context.Context, cfg *config.Config, reader StreamInputReader, loc *time.Location, matrix *matrixPattern) (*reportStream, error) {
+	r, err := reader.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from pipe: %v", err)
+	}
+
+	suites := make(chan reportSuite, suitesBufferSize)
+	stream := &reportStream{suites: suites, reports: 1, done: make(chan struct{})}
+
+	go func() {
+		defer close(stream.done)
+		defer close(suites)
+		defer r.Close()
+
+		name := reportName(cfg, reader)
+		dimensions := matrix.dimensions(name)
+		count := 0
+		err := formats.StreamReader(cfg.Format, r, func(suite junit.Suite, timestamp string) error {
+			startTime := resolveTimestamps([]string{timestamp}, cfg.TimestampLayouts, loc)[0]
+
+			select {
+			case suites <- reportSuite{suite: suite, startTime: startTime, report: name, dimensions: dimensions}:
+				count++
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err != nil {
+			stream.err = fmt.Errorf("failed to ingest the %s report %s: %w", cfg.Format, name, err)
+		}
+
+		stream.end = time.Now()
+
+		if stream.err == nil {
+			slog.Debug("report ingested incrementally", "format", cfg.Format, "suites", count)
+		}
+	}()
+
+	return stream, nil
+}
+
+// readReports reads the reports from the reader, naming the report of the readers not reading several of them
+// as reportName does
+func readReports(cfg *config.Config, reader InputReader) ([]InputReport, error) {
+	if multiReader, ok := reader.(MultiInputReader); ok {
+		return multiReader.ReadAll()
+	}
+
+	data, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	return []InputReport{{Name: reportName(cfg, reader), Data: data}}, nil
+}
+
+// reportName returns the name of the report of a reader not reading several of them: the path of the file, as the
+// library callers may pass a file reader without setting the input of the configuration, or the input otherwise
+func reportName(cfg *config.Config, reader InputReader) string {
+	if fileReader, ok := reader.(*FileReader); ok {
+		return fileReader.path
+	}
+
+	return cfg.Input
+}
+
+// readReport reads the whole report from the reader, parsing it with the format of the configuration. It also
+// returns the start time of each suite, which is the zero time if the report does not include it
+func readReport(cfg *config.Config, reader InputReader) ([]junit.Suite, []time.Time, error) {
+	stream, err := streamReport(context.Background(), cfg, reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	suites := []junit.Suite{}
+	startTimes := []time.Time{}
+	for rs := range stream.suites {
+		suites = append(suites, rs.suite)
+		startTimes = append(startTimes, rs.startTime)
+	}
+
+	if _, err := stream.wait(); err != nil {
+		return nil, nil, err
+	}
+
+	return suites, startTimes, nil
+}
+
+// retainSuites forwards the suites to the returned channel, adding them to the spool too, for the outputs
+// needing the whole report once the spans are created. The spool must not be loaded until the returned channel is closed
+func retainSuites(in <-chan reportSuite, spool *suiteSpool) <-chan reportSuite {
+	out := make(chan reportSuite)
+
+	go func() {
+		defer close(out)
+
+		for rs := range in {
+			spool.add(rs.suite)
+			out <- rs
+		}
+	}()
+
+	return out
+}
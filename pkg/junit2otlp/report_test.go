@@ -0,0 +1,192 @@
+package junit2otlp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"github.com/stretchr/testify/require"
+)
+
+// sendSuites returns a closed channel with the suites, without start times
+func sendSuites(suites []junit.Suite) <-chan reportSuite {
+	ch := make(chan reportSuite, len(suites))
+	for _, suite := range suites {
+		ch <- reportSuite{suite: suite}
+	}
+	close(ch)
+
+	return ch
+}
+
+func TestStreamReport(t *testing.T) {
+	t.Run("Streams the suites", func(t *testing.T) {
+		expected, err := junit.IngestFile("../../TEST-sample.xml")
+		require.NoError(t, err)
+
+		stream, err := streamReport(context.Background(), config.NewConfigFromDefaults(), &TestReader{testFile: "../../TEST-sample.xml"})
+		require.NoError(t, err)
+
+		suites := []junit.Suite{}
+		for rs := range stream.suites {
+			suites = append(suites, rs.suite)
+		}
+
+		end, err := stream.wait()
+		require.NoError(t, err)
+		require.False(t, end.IsZero())
+		require.Equal(t, expected, suites)
+	})
+
+	t.Run("Resolves the start times", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.AssumeTimezone = "UTC"
+
+		_, startTimes, err := readReport(cfg, &bytesReader{data: []byte(`<testsuites>
+<testsuite name="a" timestamp="2024-01-02T10:00:00"/>
+<testsuite name="b"/>
+</testsuites>`)})
+		require.NoError(t, err)
+		require.Equal(t, []time.Time{time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC), {}}, startTimes)
+	})
+
+	t.Run("Parse errors are returned when waiting", func(t *testing.T) {
+		stream, err := streamReport(context.Background(), config.NewConfigFromDefaults(), &bytesReader{data: []byte(`<testsuite name="a"><testcase name="b">`)})
+		require.NoError(t, err)
+
+		for range stream.suites {
+		}
+
+		_, err = stream.wait()
+		require.ErrorContains(t, err, "failed to ingest the junit report -")
+	})
+
+	t.Run("Cancelling the context stops the parsing", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		data := []byte("<testsuites>")
+		for range suitesBufferSize * 2 {
+			data = append(data, `<testsuite name="a"/>`...)
+		}
+		data = append(data, "</testsuites>"...)
+
+		stream, err := streamReport(ctx, config.NewConfigFromDefaults(), &bytesReader{data: data})
+		require.NoError(t, err)
+
+		cancel()
+
+		_, err = stream.wait()
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestStreamReport_Parallel(t *testing.T) {
+	sample, err := os.ReadFile("../../TEST-sample.xml")
+	require.NoError(t, err)
+
+	sample2, err := os.ReadFile("../../TEST-sample2.xml")
+	require.NoError(t, err)
+
+	expected, err := junit.IngestFiles([]string{"../../TEST-sample.xml", "../../TEST-sample2.xml"})
+	require.NoError(t, err)
+
+	for _, parallelism := range []int{0, 1, 2} {
+		t.Run(fmt.Sprintf("Parallelism %d", parallelism), func(t *testing.T) {
+			cfg := config.NewConfigFromDefaults()
+			cfg.Parallelism = parallelism
+
+			reader := &TarReader{reader: bytes.NewReader(writeTar(t, map[string]string{
+				"TEST-sample.xml":  string(sample),
+				"TEST-sample2.xml": string(sample2),
+			}))}
+
+			// the suites are in the order of the reports, whatever the parallelism
+			suites, _, err := readReport(cfg, reader)
+			require.NoError(t, err)
+			require.Equal(t, expected, suites)
+		})
+	}
+
+	t.Run("Parse errors name the report", func(t *testing.T) {
+		reader := &TarReader{reader: bytes.NewReader(writeTar(t, map[string]string{
+			"TEST-sample.xml": string(sample),
+			"TEST-broken.xml": `<testsuite name="a"><testcase name="b">`,
+		}))}
+
+		_, _, err := readReport(config.NewConfigFromDefaults(), reader)
+		require.ErrorContains(t, err, "failed to ingest the junit report TEST-broken.xml")
+	})
+}
+
+// pipeReader a stream input reader returning the read end of a pipe
+type pipeReader struct {
+	r io.ReadCloser
+}
+
+func (pr *pipeReader) Read() ([]byte, error) {
+	return io.ReadAll(pr.r)
+}
+
+func (pr *pipeReader) Open() (io.ReadCloser, error) {
+	return pr.r, nil
+}
+
+func TestStreamReport_Incremental(t *testing.T) {
+	r, w := io.Pipe()
+
+	cfg := config.NewConfigFromDefaults()
+	cfg.Format = "gotest"
+
+	stream, err := streamReport(context.Background(), cfg, &pipeReader{r: r})
+	require.NoError(t, err)
+
+	_, err = io.WriteString(w, `{"Time":"2024-05-06T10:00:00Z","Action":"run","Package":"a","Test":"TestA"}
+{"Time":"2024-05-06T10:00:01Z","Action":"pass","Package":"a","Test":"TestA","Elapsed":1}
+{"Time":"2024-05-06T10:00:01Z","Action":"pass","Package":"a","Elapsed":1}
+`)
+	require.NoError(t, err)
+
+	// the package is received before the output ends
+	rs := <-stream.suites
+	require.Equal(t, "a", rs.suite.Name)
+	require.Equal(t, time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC), rs.startTime.UTC())
+
+	require.NoError(t, w.Close())
+
+	_, ok := <-stream.suites
+	require.False(t, ok)
+
+	_, err = stream.wait()
+	require.NoError(t, err)
+}
+
+func TestRetainSuites(t *testing.T) {
+	suites, err := junit.IngestFile("../../TEST-sample.xml")
+	require.NoError(t, err)
+
+	spool := newSuiteSpool(0)
+	forwarded := []junit.Suite{}
+	for rs := range retainSuites(sendSuites(suites), spool) {
+		forwarded = append(forwarded, rs.suite)
+	}
+
+	retained, err := spool.load()
+	require.NoError(t, err)
+
+	require.Equal(t, suites, forwarded)
+	require.Equal(t, suites, retained)
+}
+
+type bytesReader struct {
+	data []byte
+}
+
+func (br *bytesReader) Read() ([]byte, error) {
+	return br.data, nil
+}
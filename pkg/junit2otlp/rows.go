@@ -0,0 +1,57 @@
+package junit2otlp
+
+import (
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// testRow a flat record of a test case, for the tabular outputs: the fields queried the most as columns of
+// their own, and every attribute of the span of the test case
+type testRow struct {
+	Timestamp  time.Time
+	TraceID    string
+	Suite      string
+	Classname  string
+	Name       string
+	Status     string
+	Message    string
+	DurationMs int64
+	Attributes map[string]interface{}
+}
+
+// newTestRows returns a row per test case of the suites, with the timestamp of the run. The outputs are never
+// split in log records, as the rows have no size limit
+func newTestRows(cfg *config.Config, runtimeAttributes []attribute.KeyValue, suites []junit.Suite, traceID trace.TraceID, timestamp time.Time) []testRow {
+	rowCfg := *cfg
+	rowCfg.MaxOutputSize = 0
+
+	var id string
+	if traceID.IsValid() {
+		id = traceID.String()
+	}
+
+	rows := []testRow{}
+	for _, suite := range suites {
+		sharedAttributes := getSharedSuiteAttributes(&rowCfg, getSuiteAttributes(&rowCfg, suite, runtimeAttributes))
+
+		for _, test := range suite.Tests {
+			rows = append(rows, testRow{
+				Timestamp:  timestamp,
+				TraceID:    id,
+				Suite:      suite.Name,
+				Classname:  test.Classname,
+				Name:       test.Name,
+				Status:     string(test.Status),
+				Message:    test.Message,
+				DurationMs: test.Duration.Milliseconds(),
+				Attributes: attributesToMap(getTestAttributes(&rowCfg, test, sharedAttributes)),
+			})
+		}
+	}
+
+	return rows
+}
@@ -0,0 +1,272 @@
+package junit2otlp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	lognoop "go.opentelemetry.io/otel/log/noop"
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Providers the OpenTelemetry providers a conversion sends its telemetry to. They are owned by the caller,
+// who shuts them down, so the same providers can be reused by many conversions, even concurrent ones
+type Providers struct {
+	// TracerProvider receives the spans of the suites and test cases. It's required
+	TracerProvider trace.TracerProvider
+	// MeterProvider receives the metrics of the test outcomes. If nil, the metrics are not recorded
+	MeterProvider metric.MeterProvider
+	// LoggerProvider receives the oversized console outputs. If nil, they are dropped, so set the
+	// max-output-size to zero to keep them as span attributes
+	LoggerProvider log.LoggerProvider
+	// ServiceProviders returns the providers of the suites owned by another service, with its name in their
+	// resource, when the service-mapping or service-per-suite settings are set. If nil, all the suites are
+	// sent to the providers above, as the root span always is
+	ServiceProviders func(serviceName string) Providers
+}
+
+func (p Providers) meterProvider() metric.MeterProvider {
+	if p.MeterProvider == nil {
+		return metricnoop.NewMeterProvider()
+	}
+
+	return p.MeterProvider
+}
+
+func (p Providers) loggerProvider() log.LoggerProvider {
+	if p.LoggerProvider == nil {
+		return lognoop.NewLoggerProvider()
+	}
+
+	return p.LoggerProvider
+}
+
+// Run converts the reports read from the readers into telemetry, sent to the providers, and processes the outputs
+// of the configuration. The reports of all the readers belong to the same run, so they are part of the same trace.
+// It does not modify the configuration nor any package-level state, so it's safe to call it concurrently, with
+// the same or different providers. The telemetry is flushed when the providers are shut down
+func Run(ctx context.Context, cfg *config.Config, providers Providers, readers ...InputReader) error {
+	return run(ctx, cfg, providers, nil, readers...)
+}
+
+// startRuntimeAttributes resolves the runtime attributes in the background, as the SCM analysis is slow on big
+// repositories, returning the channel receiving them
+func startRuntimeAttributes(ctx context.Context, cfg *config.Config) <-chan []attribute.KeyValue {
+	runtimeAttributesCh := make(chan []attribute.KeyValue, 1)
+	go func() {
+		runtimeAttributesCh <- resolveRuntimeAttributes(ctx, cfg)
+	}()
+
+	return runtimeAttributesCh
+}
+
+// run is Run receiving the runtime attributes from the channel, if any, so their resolution started by the caller
+// overlaps with the creation of the providers. Otherwise, it's started once the configuration is validated
+func run(ctx context.Context, cfg *config.Config, providers Providers, runtimeAttributesCh <-chan []attribute.KeyValue, readers ...InputReader) error {
+	if providers.TracerProvider == nil {
+		return fmt.Errorf("the tracer provider is required")
+	}
+
+	reader, err := combineReaders(readers, int64(cfg.MaxInputSize)<<20)
+	if err != nil {
+		return err
+	}
+
+	if err := validateScmAttributesSchema(cfg.ScmAttributesSchema); err != nil {
+		return err
+	}
+
+	if err := validateAttributeSchema(cfg.AttributeSchema); err != nil {
+		return err
+	}
+
+	if cfg.BigQueryTable != "" {
+		if _, err := parseBigQueryTable(cfg.BigQueryTable); err != nil {
+			return err
+		}
+	}
+
+	if cfg.ExportFile != "" {
+		if err := validateExportFile(cfg.ExportFile); err != nil {
+			return err
+		}
+	}
+
+	if cfg.BuildkiteAnalytics && os.Getenv(buildkiteAnalyticsTokenEnv) == "" {
+		return fmt.Errorf("the %s environment variable is required to upload to Buildkite Test Analytics", buildkiteAnalyticsTokenEnv)
+	}
+
+	coverageReport, err := loadCoverage(cfg)
+	if err != nil {
+		return err
+	}
+
+	if cfg.GithubChecks && NewGithubAPI() == nil {
+		return fmt.Errorf("the GITHUB_TOKEN and GITHUB_REPOSITORY environment variables are required to create a Github check run")
+	}
+
+	otlpSrvName := getOtlpServiceName(cfg)
+
+	// the SCM analysis, slow on big repositories, runs while the report is parsed
+	if runtimeAttributesCh == nil {
+		runtimeAttributesCh = startRuntimeAttributes(ctx, cfg)
+	}
+
+	// stops the parsing if the spans are not created
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// the suites are parsed while the spans are created
+	parseStart := time.Now()
+	stream, err := streamReport(ctx, cfg, reader)
+	if err != nil {
+		return err
+	}
+
+	var self *selfTelemetry
+	if cfg.SelfTelemetry {
+		self = newSelfTelemetry(providers.TracerProvider, providers.meterProvider())
+	}
+	tracerProvider := providers.TracerProvider
+	providers.TracerProvider = self.wrap(tracerProvider)
+	if serviceProviders := providers.ServiceProviders; serviceProviders != nil {
+		providers.ServiceProviders = func(serviceName string) Providers {
+			p := serviceProviders(serviceName)
+			p.TracerProvider = self.wrap(p.TracerProvider)
+			return p
+		}
+	}
+
+	selfCtx, selfSpan := self.start(ctx, parseStart)
+
+	// only the outputs processed after the spans are created need the whole report, spilled to disk under memory pressure
+	spool := newSuiteSpool(uint64(cfg.MemoryLimit) << 20)
+	defer func() {
+		if err := spool.close(); err != nil {
+			slog.Warn("failed to remove the spilled suites", "error", err)
+		}
+	}()
+
+	reportSuites := stream.suites
+	if cfg.MatrixPattern != "" {
+		reportSuites = aggregateSuites(reportSuites)
+	}
+	if cfg.MergeSuites {
+		reportSuites = mergeSuites(reportSuites)
+	}
+	if summaryEnabled(cfg) || cfg.SummaryJSON != "" || cfg.SummaryMarkdown != "" || cfg.GithubAnnotations || cfg.GithubChecks || cfg.FailOnError || cfg.ElasticsearchURL != "" || cfg.BigQueryTable != "" || cfg.ExportFile != "" || cfg.HistoryDB != "" || cfg.BuildkiteAnalytics || cfg.AllureResults != "" {
+		reportSuites = retainSuites(reportSuites, spool)
+	}
+
+	runtimeAttributes := <-runtimeAttributesCh
+
+	runSpan, err := createTracesAndSpans(ctx, cfg, otlpSrvName, providers, runtimeAttributes, coverageReport, reportSuites)
+	if err != nil {
+		return err
+	}
+	traceID := runSpan.TraceID()
+
+	if err := writeTraceContext(ctx, cfg, runSpan); err != nil {
+		return err
+	}
+
+	parseEnd, err := stream.wait()
+	if err != nil {
+		// the suites parsed before the error are already exported
+		return err
+	}
+	self.recordParse(selfCtx, cfg.Format, stream.reports, parseStart, parseEnd)
+
+	self.recordExport(selfCtx, tracerProvider)
+	selfSpan.End()
+
+	suites, err := spool.load()
+	if err != nil {
+		return err
+	}
+
+	if cfg.SummaryJSON != "" {
+		summary := newRunSummary(suites, traceID)
+		summary.Destination = exportDestination(cfg)
+		if err := writeSummaryJSON(cfg.SummaryJSON, summary); err != nil {
+			return err
+		}
+	}
+
+	if cfg.SummaryMarkdown != "" {
+		if err := writeSummaryMarkdown(cfg.SummaryMarkdown, cfg.TraceName, newRunSummary(suites, traceID), cfg.TraceURLTemplate); err != nil {
+			return err
+		}
+	}
+
+	if cfg.ElasticsearchURL != "" {
+		if err := writeElasticsearch(ctx, cfg, runtimeAttributes, suites, traceID); err != nil {
+			return fmt.Errorf("failed to index the test cases in Elasticsearch: %w", err)
+		}
+	}
+
+	if cfg.BigQueryTable != "" || cfg.ExportFile != "" || cfg.HistoryDB != "" || cfg.BuildkiteAnalytics || cfg.AllureResults != "" {
+		rows := newTestRows(cfg, runtimeAttributes, suites, traceID, time.Now().UTC())
+
+		if cfg.ExportFile != "" {
+			if err := writeExportFile(cfg.ExportFile, rows); err != nil {
+				return err
+			}
+		}
+
+		if cfg.BigQueryTable != "" {
+			if err := writeBigQuery(ctx, cfg.BigQueryTable, rows); err != nil {
+				return fmt.Errorf("failed to stream the test cases into BigQuery: %w", err)
+			}
+		}
+	}
+
+	if cfg.HistoryDB != "" {
+		if err := recordHistory(ctx, cfg, traceID, suites); err != nil {
+			return err
+		}
+	}
+
+	if cfg.AllureResults != "" {
+		if err := writeAllureResults(cfg.AllureResults, suites, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	if cfg.BuildkiteAnalytics {
+		if err := uploadBuildkiteAnalytics(ctx, suites, traceID); err != nil {
+			return err
+		}
+	}
+
+	if cfg.GithubChecks {
+		if err := reportGithubCheckRun(ctx, cfg, suites, traceID); err != nil {
+			return err
+		}
+	}
+
+	if cfg.GithubAnnotations {
+		if err := printGithubAnnotations(os.Stdout, suites); err != nil {
+			slog.Warn("failed to print the Github annotations", "error", err)
+		}
+	}
+
+	if summaryEnabled(cfg) {
+		if err := printRunSummary(os.Stdout, os.Stderr, cfg, suites, traceID); err != nil {
+			slog.Warn("failed to print the summary", "error", err)
+		}
+	}
+
+	if cfg.FailOnError {
+		return checkTestOutcomes(suites, cfg.FailThreshold)
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package junit2otlp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRun(t *testing.T) {
+	t.Run("Tracer provider is required", func(t *testing.T) {
+		err := Run(context.Background(), config.NewConfigFromDefaults(), Providers{}, &TestReader{testFile: "../../TEST-sample.xml"})
+		require.ErrorContains(t, err, "the tracer provider is required")
+	})
+
+	t.Run("Trace context of the run", func(t *testing.T) {
+		recorder := tracetest.NewSpanRecorder()
+		tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+		outputs := filepath.Join(t.TempDir(), "github-output")
+		t.Setenv("GITHUB_OUTPUT", outputs)
+
+		cfg := config.NewConfigFromDefaults()
+		cfg.RepositoryPath = t.TempDir()
+		cfg.Summary = false
+		cfg.TraceparentOut = filepath.Join(t.TempDir(), "traceparent")
+		cfg.GithubOutput = true
+
+		require.NoError(t, Run(context.Background(), cfg, Providers{TracerProvider: tracerProvider}, &TestReader{testFile: "../../TEST-sample.xml"}))
+
+		var root sdktrace.ReadOnlySpan
+		for _, s := range recorder.Ended() {
+			if !s.Parent().IsValid() {
+				root = s
+			}
+		}
+		require.NotNil(t, root)
+
+		traceparent := fmt.Sprintf("00-%s-%s-01", root.SpanContext().TraceID(), root.SpanContext().SpanID())
+
+		b, err := os.ReadFile(cfg.TraceparentOut)
+		require.NoError(t, err)
+		require.Equal(t, traceparent+"\n", string(b))
+
+		b, err = os.ReadFile(outputs)
+		require.NoError(t, err)
+		require.Equal(t, fmt.Sprintf("trace-id=%s\ntraceparent=%s\n", root.SpanContext().TraceID(), traceparent), string(b))
+	})
+
+	t.Run("Github outputs outside of Github Actions", func(t *testing.T) {
+		t.Setenv("GITHUB_OUTPUT", "")
+
+		cfg := config.NewConfigFromDefaults()
+		cfg.RepositoryPath = t.TempDir()
+		cfg.Summary = false
+		cfg.GithubOutput = true
+
+		err := Run(context.Background(), cfg, Providers{TracerProvider: sdktrace.NewTracerProvider()}, &TestReader{testFile: "../../TEST-sample.xml"})
+		require.ErrorContains(t, err, "the GITHUB_OUTPUT environment variable is required")
+	})
+
+	t.Run("Failed tests gate the run once it is exported", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "TEST-calc.xml")
+		require.NoError(t, os.WriteFile(path, []byte(`<testsuite name="calc" tests="2" failures="1">
+  <testcase name="add" classname="calc"/>
+  <testcase name="div" classname="calc"><failure message="division by zero"/></testcase>
+</testsuite>`), 0o644))
+
+		for _, tc := range []struct {
+			name      string
+			threshold int
+			failed    bool
+		}{
+			{name: "Over the threshold", threshold: 0, failed: true},
+			{name: "Within the threshold", threshold: 1},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				recorder := tracetest.NewSpanRecorder()
+				tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+				cfg := config.NewConfigFromDefaults()
+				cfg.RepositoryPath = t.TempDir()
+				cfg.Summary = false
+				cfg.FailOnError = true
+				cfg.FailThreshold = tc.threshold
+
+				err := Run(context.Background(), cfg, Providers{TracerProvider: tracerProvider}, NewFileReader(path))
+				require.NotEmpty(t, recorder.Ended())
+
+				if !tc.failed {
+					require.NoError(t, err)
+					return
+				}
+
+				var failedErr *TestsFailedError
+				require.ErrorAs(t, err, &failedErr)
+				require.Equal(t, 1, failedErr.Failed)
+			})
+		}
+	})
+
+	t.Run("Concurrent conversions share the providers", func(t *testing.T) {
+		recorder := tracetest.NewSpanRecorder()
+		tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+		cfg := config.NewConfigFromDefaults()
+		cfg.RepositoryPath = t.TempDir()
+		cfg.Summary = false
+
+		const runs = 8
+
+		var wg sync.WaitGroup
+		errs := make([]error, runs)
+		for i := 0; i < runs; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				errs[i] = Run(context.Background(), cfg, Providers{TracerProvider: tracerProvider}, &TestReader{testFile: "../../TEST-sample.xml"})
+			}(i)
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			require.NoError(t, err)
+		}
+
+		roots := 0
+		traces := map[string]int{}
+		for _, s := range recorder.Ended() {
+			traces[s.SpanContext().TraceID().String()]++
+			if !s.Parent().IsValid() {
+				roots++
+			}
+		}
+
+		require.Equal(t, runs, roots)
+		require.Len(t, traces, runs)
+		for _, spans := range traces {
+			require.Equal(t, len(recorder.Ended())/runs, spans)
+		}
+	})
+}
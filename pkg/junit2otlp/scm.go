@@ -0,0 +1,464 @@
+package junit2otlp
+
+import (
+	"os"
+	"path"
+)
+
+type Scm interface {
+	OTELAttributesContributor
+}
+
+// ScmContext represent the execution context in which the SCM is used
+type ScmContext struct {
+	// Branch the name of the branch, which will be calculated
+	// reading the environment variables for each supported context
+	Branch string
+	// ChangeRequest if the SCM context is for a change request
+	ChangeRequest bool
+	// ChangeID the identifier of the change request, such as the pull request number.
+	// In the case ChangeRequest is false, it will be empty
+	ChangeID string
+	// Commit the commit hash for the SCM context
+	Commit string
+	// Provider the provider of the SCM context: Github, Gitlab, Jenkins, Other, etc.
+	Provider string
+	// TargetBranch the name of the branch in the case the SCM context represents a
+	// change request. In the case ChangeRequest is false, it won't be considered
+	TargetBranch string
+}
+
+// checkGitContext identifies the head sha and target branch from the environment variables that are
+// populated from a Git provider, such as Github or Gitlab. If no proprietary env vars are set, then it will
+// look up this tool-specific variable for the target branch.
+func checkGitContext() *ScmContext {
+	// in local branches, we are not in pull/merge requests
+	localContext := FromLocal()
+	if localContext != nil {
+		return localContext
+	}
+
+	// is Github?
+	githubContext := FromGithub()
+	if githubContext != nil {
+		return githubContext
+	}
+
+	// is Jenkins?
+	jenkinsContext := FromJenkins()
+	if jenkinsContext != nil {
+		return jenkinsContext
+	}
+
+	// is Gitlab?
+	gitlabContext := FromGitlab()
+	if gitlabContext != nil {
+		return gitlabContext
+	}
+
+	// is Travis?
+	travisContext := FromTravis()
+	if travisContext != nil {
+		return travisContext
+	}
+
+	// is Buildkite?
+	buildkiteContext := FromBuildkite()
+	if buildkiteContext != nil {
+		return buildkiteContext
+	}
+
+	// is Drone?
+	droneContext := FromDrone()
+	if droneContext != nil {
+		return droneContext
+	}
+
+	// is Bitbucket?
+	bitbucketContext := FromBitbucket()
+	if bitbucketContext != nil {
+		return bitbucketContext
+	}
+
+	// is TeamCity?
+	teamcityContext := FromTeamCity()
+	if teamcityContext != nil {
+		return teamcityContext
+	}
+
+	// is Bamboo?
+	bambooContext := FromBamboo()
+	if bambooContext != nil {
+		return bambooContext
+	}
+
+	// SCM context not supported
+	return nil
+}
+
+// GetTargetBranch returns the target branch for change requests, or branches in any other case
+func (ctx *ScmContext) GetTargetBranch() string {
+	if ctx.ChangeRequest {
+		return ctx.TargetBranch
+	}
+
+	return ctx.Branch
+}
+
+// FromGithub returns an SCM context for Github, reading the right environment variables, as described
+// in their docs
+func FromGithub() *ScmContext {
+	if os.Getenv("GITHUB_SHA") == "" {
+		return nil
+	}
+
+	sha := os.Getenv("GITHUB_SHA")
+	branchName := os.Getenv("GITHUB_REF_NAME")
+	baseRef := os.Getenv("GITHUB_BASE_REF") // only present for pull requests on Github Actions
+	headRef := os.Getenv("GITHUB_HEAD_REF") // only present for pull requests on Github Actions
+
+	isChangeRequest := (baseRef != "" && headRef != "")
+
+	changeID := ""
+	matches := githubPullRequestRef.FindStringSubmatch(os.Getenv("GITHUB_REF")) // refs/pull/:prNumber/merge for pull requests
+	if isChangeRequest && len(matches) == 2 {
+		changeID = matches[1]
+	}
+
+	return &ScmContext{
+		ChangeRequest: isChangeRequest,
+		ChangeID:      changeID,
+		Commit:        sha,
+		Branch:        branchName,
+		Provider:      "Github",
+		TargetBranch:  baseRef,
+	}
+}
+
+// FromGitlab returns an SCM context for Gitlab, reading the right environment variables, as described
+// in their docs
+func FromGitlab() *ScmContext {
+	if os.Getenv("CI_COMMIT_REF_NAME") == "" {
+		return nil
+	}
+
+	sha := os.Getenv("CI_MERGE_REQUEST_SOURCE_BRANCH_SHA")      // only present on merge requests on Gitlab CI
+	commitBranch := os.Getenv("CI_COMMIT_BRANCH")               // only present on branches on Gitlab CI
+	headRef := os.Getenv("CI_COMMIT_REF_NAME")                  // only present on branches on Gitlab CI
+	baseRef := os.Getenv("CI_MERGE_REQUEST_TARGET_BRANCH_NAME") // only present on merge requests on Gitlab CI
+	changeID := os.Getenv("CI_MERGE_REQUEST_IID")               // only present on merge requests on Gitlab CI
+
+	isChangeRequest := (commitBranch == "")
+
+	return &ScmContext{
+		ChangeRequest: isChangeRequest,
+		ChangeID:      changeID,
+		Commit:        sha,
+		Branch:        headRef,
+		Provider:      "Gitlab",
+		TargetBranch:  baseRef,
+	}
+}
+
+// FromJenkins returns an SCM context for Jenkins, reading the right environment variables, as described
+// in their docs
+func FromJenkins() *ScmContext {
+	if os.Getenv("JENKINS_URL") == "" {
+		return nil
+	}
+
+	changeID := os.Getenv("CHANGE_ID")    // only present on multibranch pipelines on Jenkins
+	headRef := os.Getenv("BRANCH_NAME")   // only present on multibranch pipelines on Jenkins
+	sha := os.Getenv("GIT_COMMIT")        // only present on multibranch pipelines on Jenkins
+	baseRef := os.Getenv("CHANGE_TARGET") // only present on multibranch pipelines on Jenkins
+
+	isPR := (changeID != "")
+
+	if isPR {
+		return &ScmContext{
+			ChangeRequest: isPR,
+			ChangeID:      changeID,
+			Commit:        sha,
+			Branch:        headRef,
+			Provider:      "Jenkins",
+			TargetBranch:  baseRef,
+		}
+	} else {
+		return &ScmContext{
+			ChangeRequest: isPR,
+			Commit:        sha,
+			Branch:        headRef,
+			Provider:      "Jenkins",
+			TargetBranch:  headRef,
+		}
+	}
+}
+
+// FromTravis returns an SCM context for Travis CI, reading the right environment variables, as described
+// in their docs
+func FromTravis() *ScmContext {
+	if os.Getenv("TRAVIS_COMMIT") == "" {
+		return nil
+	}
+
+	sha := os.Getenv("TRAVIS_COMMIT")                  // the merge commit on pull requests on Travis CI
+	branch := os.Getenv("TRAVIS_BRANCH")               // the target branch on pull requests on Travis CI
+	headRef := os.Getenv("TRAVIS_PULL_REQUEST_BRANCH") // only present on pull requests on Travis CI
+	changeID := os.Getenv("TRAVIS_PULL_REQUEST")       // the number of the pull request, or false
+	headSha := os.Getenv("TRAVIS_PULL_REQUEST_SHA")    // only present on pull requests on Travis CI
+
+	isPR := (changeID != "" && changeID != "false")
+
+	if isPR {
+		if headSha != "" {
+			sha = headSha
+		}
+
+		return &ScmContext{
+			ChangeRequest: isPR,
+			ChangeID:      changeID,
+			Commit:        sha,
+			Branch:        headRef,
+			Provider:      "Travis",
+			TargetBranch:  branch,
+		}
+	}
+
+	return &ScmContext{
+		ChangeRequest: isPR,
+		Commit:        sha,
+		Branch:        branch,
+		Provider:      "Travis",
+		TargetBranch:  branch,
+	}
+}
+
+// FromBuildkite returns an SCM context for Buildkite, reading the right environment variables, as described
+// in their docs
+func FromBuildkite() *ScmContext {
+	if os.Getenv("BUILDKITE_COMMIT") == "" {
+		return nil
+	}
+
+	sha := os.Getenv("BUILDKITE_COMMIT")
+	headRef := os.Getenv("BUILDKITE_BRANCH")
+	changeID := os.Getenv("BUILDKITE_PULL_REQUEST")            // the number of the pull request, or false
+	baseRef := os.Getenv("BUILDKITE_PULL_REQUEST_BASE_BRANCH") // only present on pull requests on Buildkite
+
+	isPR := (changeID != "" && changeID != "false")
+
+	if isPR {
+		return &ScmContext{
+			ChangeRequest: isPR,
+			ChangeID:      changeID,
+			Commit:        sha,
+			Branch:        headRef,
+			Provider:      "Buildkite",
+			TargetBranch:  baseRef,
+		}
+	}
+
+	return &ScmContext{
+		ChangeRequest: isPR,
+		Commit:        sha,
+		Branch:        headRef,
+		Provider:      "Buildkite",
+		TargetBranch:  headRef,
+	}
+}
+
+// FromDrone returns an SCM context for Drone, reading the right environment variables, as described
+// in their docs
+func FromDrone() *ScmContext {
+	if os.Getenv("DRONE_COMMIT_SHA") == "" {
+		return nil
+	}
+
+	sha := os.Getenv("DRONE_COMMIT_SHA")
+	headRef := os.Getenv("DRONE_SOURCE_BRANCH") // the branch of the commit, or the source branch on pull requests
+	baseRef := os.Getenv("DRONE_TARGET_BRANCH") // the branch of the commit, or the target branch on pull requests
+	changeID := os.Getenv("DRONE_PULL_REQUEST") // only present on pull requests on Drone
+
+	if headRef == "" {
+		headRef = os.Getenv("DRONE_BRANCH")
+	}
+
+	isPR := (changeID != "")
+
+	if isPR {
+		return &ScmContext{
+			ChangeRequest: isPR,
+			ChangeID:      changeID,
+			Commit:        sha,
+			Branch:        headRef,
+			Provider:      "Drone",
+			TargetBranch:  baseRef,
+		}
+	}
+
+	return &ScmContext{
+		ChangeRequest: isPR,
+		Commit:        sha,
+		Branch:        headRef,
+		Provider:      "Drone",
+		TargetBranch:  headRef,
+	}
+}
+
+// FromBitbucket returns an SCM context for Bitbucket Pipelines, reading the right environment variables, as
+// described in their docs
+func FromBitbucket() *ScmContext {
+	if os.Getenv("BITBUCKET_COMMIT") == "" {
+		return nil
+	}
+
+	sha := os.Getenv("BITBUCKET_COMMIT")
+	headRef := os.Getenv("BITBUCKET_BRANCH")                // only present on branches and pull requests on Bitbucket Pipelines
+	changeID := os.Getenv("BITBUCKET_PR_ID")                // only present on pull requests on Bitbucket Pipelines
+	baseRef := os.Getenv("BITBUCKET_PR_DESTINATION_BRANCH") // only present on pull requests on Bitbucket Pipelines
+
+	isPR := (changeID != "")
+
+	if isPR {
+		return &ScmContext{
+			ChangeRequest: isPR,
+			ChangeID:      changeID,
+			Commit:        sha,
+			Branch:        headRef,
+			Provider:      "Bitbucket",
+			TargetBranch:  baseRef,
+		}
+	}
+
+	return &ScmContext{
+		ChangeRequest: isPR,
+		Commit:        sha,
+		Branch:        headRef,
+		Provider:      "Bitbucket",
+		TargetBranch:  headRef,
+	}
+}
+
+// FromTeamCity returns an SCM context for TeamCity, reading the commit from the right environment variables, and
+// the branch and the pull request from the configuration parameters of the build, as described in their docs
+func FromTeamCity() *ScmContext {
+	if os.Getenv("TEAMCITY_VERSION") == "" {
+		return nil
+	}
+
+	parameters := teamcityParameters()
+
+	sha := os.Getenv("BUILD_VCS_NUMBER")
+	headRef := parameters["teamcity.build.branch"]
+	changeID := parameters["teamcity.pullRequest.number"]       // only present on pull requests with the Pull Requests build feature
+	baseRef := parameters["teamcity.pullRequest.target.branch"] // only present on pull requests with the Pull Requests build feature
+
+	isPR := (changeID != "")
+
+	if isPR {
+		if sourceBranch := parameters["teamcity.pullRequest.source.branch"]; sourceBranch != "" {
+			// the branch of the build is the one of the pull request, i.e. pull/42
+			headRef = sourceBranch
+		}
+
+		return &ScmContext{
+			ChangeRequest: isPR,
+			ChangeID:      changeID,
+			Commit:        sha,
+			Branch:        headRef,
+			Provider:      "TeamCity",
+			TargetBranch:  baseRef,
+		}
+	}
+
+	return &ScmContext{
+		ChangeRequest: isPR,
+		Commit:        sha,
+		Branch:        headRef,
+		Provider:      "TeamCity",
+		TargetBranch:  headRef,
+	}
+}
+
+// FromBamboo returns an SCM context for Bamboo, reading the right environment variables, as described in their docs
+func FromBamboo() *ScmContext {
+	if os.Getenv("bamboo_planRepository_revision") == "" {
+		return nil
+	}
+
+	sha := os.Getenv("bamboo_planRepository_revision")
+	headRef := os.Getenv("bamboo_planRepository_branchName")
+	changeID := os.Getenv("bamboo_repository_pr_key")         // only present on pull requests on Bamboo
+	baseRef := os.Getenv("bamboo_repository_pr_targetBranch") // only present on pull requests on Bamboo
+
+	if headRef == "" {
+		headRef = os.Getenv("bamboo_planRepository_branch")
+	}
+
+	isPR := (changeID != "")
+
+	if isPR {
+		if sourceBranch := os.Getenv("bamboo_repository_pr_sourceBranch"); sourceBranch != "" {
+			headRef = sourceBranch
+		}
+
+		return &ScmContext{
+			ChangeRequest: isPR,
+			ChangeID:      changeID,
+			Commit:        sha,
+			Branch:        headRef,
+			Provider:      "Bamboo",
+			TargetBranch:  baseRef,
+		}
+	}
+
+	return &ScmContext{
+		ChangeRequest: isPR,
+		Commit:        sha,
+		Branch:        headRef,
+		Provider:      "Bamboo",
+		TargetBranch:  headRef,
+	}
+}
+
+// FromLocal returns an SCM context for local, using TARGET_BRANCH and BRANCH as the variables controlling
+// if the SCM context represents a change request. BRANCH is mandatory, otherwise an empty context will be retrieved.
+// If TARGET_BRANCH is not empty, it will represent a change request
+func FromLocal() *ScmContext {
+	baseRef := os.Getenv("TARGET_BRANCH")
+	headRef := os.Getenv("BRANCH")
+	if headRef == "" {
+		return nil
+	}
+
+	isPR := (baseRef != "")
+
+	return &ScmContext{
+		ChangeRequest: isPR,
+		Commit:        "",
+		Branch:        headRef,
+		Provider:      "",
+		TargetBranch:  baseRef,
+	}
+}
+
+// GetScm checks if the underlying filesystem repository is a Git repository
+// checking the existence of the .git directory in the current workspace. The attributes
+// schema defines the keys used for the SCM attributes: legacy, vcs or both
+func GetScm(repoDir string, attributesSchema string) Scm {
+	// if .git file exists
+	_, err := os.Stat(path.Join(repoDir, ".git"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	// .git exists
+	scm := NewGitScm(repoDir)
+	if scm == nil {
+		return nil
+	}
+
+	scm.attributesSchema = attributesSchema
+
+	return scm
+}
@@ -0,0 +1,56 @@
+package junit2otlp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// defaultScmAPITimeout the maximum time to wait for a response from an SCM provider API
+const defaultScmAPITimeout = 10 * time.Second
+
+// newScmAPIClient returns the HTTP client used to call the SCM provider APIs
+func newScmAPIClient() *http.Client {
+	return &http.Client{Timeout: defaultScmAPITimeout}
+}
+
+// getScmAPIContributor returns the attributes contributor calling the API of the SCM provider
+// detected from the environment, or nil if there is no provider API to call
+func getScmAPIContributor() OTELAttributesContributor {
+	githubAPI := NewGithubAPI()
+	if githubAPI != nil {
+		return githubAPI
+	}
+
+	gitlabAPI := NewGitlabAPI()
+	if gitlabAPI != nil {
+		return gitlabAPI
+	}
+
+	return nil
+}
+
+// getJSON performs a GET request to the URL, including the headers, decoding the JSON response into v
+func getJSON(client *http.Client, url string, headers map[string]string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	for k, h := range headers {
+		req.Header.Set(k, h)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code calling %s: %d", url, resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,487 @@
+package junit2otlp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckGitContext(t *testing.T) {
+	t.Run("Github", func(t *testing.T) {
+		// Disable Local
+		t.Setenv("BRANCH", "")
+
+		testSha := "0123456"
+		testBaseRef := "main"
+		testHeadRef := "feature/pr-23"
+
+		t.Run("Running for Branches", func(t *testing.T) {
+			t.Setenv("GITHUB_SHA", testSha)
+			t.Setenv("GITHUB_REF_NAME", testHeadRef)
+			t.Setenv("GITHUB_BASE_REF", "") // only for pull requests
+			t.Setenv("GITHUB_HEAD_REF", "") // only for pull requests
+
+			gitCtx := checkGitContext()
+			require.Equal(t, testSha, gitCtx.Commit)
+			require.Equal(t, testHeadRef, gitCtx.Branch)
+			require.Equal(t, testHeadRef, gitCtx.GetTargetBranch())
+			require.Equal(t, "Github", gitCtx.Provider)
+			require.False(t, gitCtx.ChangeRequest)
+		})
+
+		t.Run("Running for Pull Requests", func(t *testing.T) {
+			t.Setenv("GITHUB_SHA", testSha)
+			t.Setenv("GITHUB_REF", "refs/pull/23/merge")
+			t.Setenv("GITHUB_REF_NAME", testHeadRef)
+			t.Setenv("GITHUB_BASE_REF", testBaseRef)
+			t.Setenv("GITHUB_HEAD_REF", testHeadRef)
+
+			gitCtx := checkGitContext()
+			require.Equal(t, "23", gitCtx.ChangeID)
+			require.Equal(t, testSha, gitCtx.Commit)
+			require.Equal(t, testHeadRef, gitCtx.Branch)
+			require.Equal(t, testBaseRef, gitCtx.GetTargetBranch())
+			require.Equal(t, "Github", gitCtx.Provider)
+			require.True(t, gitCtx.ChangeRequest)
+		})
+	})
+
+	t.Run("Jenkins", func(t *testing.T) {
+		testSha := "0123456"
+		testBranch := "mybranch"
+
+		// Disable Local and Github
+		t.Setenv("BRANCH", "")
+		t.Setenv("GITHUB_SHA", "")
+
+		t.Run("Running for Branches", func(t *testing.T) {
+			t.Setenv("JENKINS_URL", "http://jenkins.local")
+			t.Setenv("GIT_COMMIT", testSha)
+			t.Setenv("CHANGE_ID", "")
+			t.Setenv("CHANGE_TARGET", "")
+			t.Setenv("BRANCH_NAME", testBranch)
+
+			gitCtx := checkGitContext()
+			require.Equal(t, testSha, gitCtx.Commit)
+			require.Equal(t, testBranch, gitCtx.Branch)
+			require.Equal(t, testBranch, gitCtx.GetTargetBranch())
+			require.Equal(t, "Jenkins", gitCtx.Provider)
+			require.False(t, gitCtx.ChangeRequest)
+		})
+
+		t.Run("Running for Pull Requests", func(t *testing.T) {
+			t.Setenv("JENKINS_URL", "http://jenkins.local")
+			t.Setenv("GIT_COMMIT", testSha)
+			t.Setenv("CHANGE_ID", "PR-123")
+			t.Setenv("CHANGE_TARGET", "main")
+			t.Setenv("BRANCH_NAME", testBranch)
+
+			gitCtx := checkGitContext()
+			require.Equal(t, "PR-123", gitCtx.ChangeID)
+			require.Equal(t, testSha, gitCtx.Commit)
+			require.Equal(t, testBranch, gitCtx.Branch)
+			require.Equal(t, "main", gitCtx.GetTargetBranch())
+			require.Equal(t, "Jenkins", gitCtx.Provider)
+			require.True(t, gitCtx.ChangeRequest)
+		})
+	})
+
+	t.Run("Gitlab", func(t *testing.T) {
+		// Disable Local, Github and Jenkins
+		t.Setenv("BRANCH", "")
+		t.Setenv("GITHUB_SHA", "")
+		t.Setenv("JENKINS_URL", "")
+
+		t.Run("Running for Branches", func(t *testing.T) {
+			t.Setenv("CI_COMMIT_BRANCH", "branch")
+			t.Setenv("CI_COMMIT_REF_NAME", "branch")
+			t.Setenv("CI_MERGE_REQUEST_SOURCE_BRANCH_SHA", "0123456")
+			t.Setenv("CI_MERGE_REQUEST_TARGET_BRANCH_NAME", "main")
+
+			gitCtx := checkGitContext()
+			require.Equal(t, "0123456", gitCtx.Commit)
+			require.Equal(t, "branch", gitCtx.Branch)
+			require.Equal(t, "branch", gitCtx.GetTargetBranch())
+			require.Equal(t, "Gitlab", gitCtx.Provider)
+			require.False(t, gitCtx.ChangeRequest)
+		})
+
+		t.Run("Running for Merge Requests", func(t *testing.T) {
+			t.Setenv("CI_COMMIT_REF_NAME", "branch")
+			t.Setenv("CI_MERGE_REQUEST_IID", "7")
+			t.Setenv("CI_MERGE_REQUEST_SOURCE_BRANCH_SHA", "0123456")
+			t.Setenv("CI_MERGE_REQUEST_TARGET_BRANCH_NAME", "main")
+
+			gitCtx := checkGitContext()
+			require.Equal(t, "7", gitCtx.ChangeID)
+			require.Equal(t, "0123456", gitCtx.Commit)
+			require.Equal(t, "branch", gitCtx.Branch)
+			require.Equal(t, "main", gitCtx.GetTargetBranch())
+			require.Equal(t, "Gitlab", gitCtx.Provider)
+			require.True(t, gitCtx.ChangeRequest)
+		})
+	})
+
+	t.Run("Travis", func(t *testing.T) {
+		// Disable Local, Github, Jenkins and Gitlab
+		t.Setenv("BRANCH", "")
+		t.Setenv("GITHUB_SHA", "")
+		t.Setenv("JENKINS_URL", "")
+		t.Setenv("CI_COMMIT_REF_NAME", "")
+
+		t.Run("Running for Branches", func(t *testing.T) {
+			t.Setenv("TRAVIS_COMMIT", "0123456")
+			t.Setenv("TRAVIS_BRANCH", "main")
+			t.Setenv("TRAVIS_PULL_REQUEST", "false")
+			t.Setenv("TRAVIS_PULL_REQUEST_BRANCH", "")
+			t.Setenv("TRAVIS_PULL_REQUEST_SHA", "")
+
+			gitCtx := checkGitContext()
+			require.Equal(t, "0123456", gitCtx.Commit)
+			require.Equal(t, "main", gitCtx.Branch)
+			require.Equal(t, "main", gitCtx.GetTargetBranch())
+			require.Equal(t, "Travis", gitCtx.Provider)
+			require.False(t, gitCtx.ChangeRequest)
+		})
+
+		t.Run("Running for Pull Requests", func(t *testing.T) {
+			t.Setenv("TRAVIS_COMMIT", "merge01")
+			t.Setenv("TRAVIS_BRANCH", "main")
+			t.Setenv("TRAVIS_PULL_REQUEST", "42")
+			t.Setenv("TRAVIS_PULL_REQUEST_BRANCH", "feature")
+			t.Setenv("TRAVIS_PULL_REQUEST_SHA", "0123456")
+
+			gitCtx := checkGitContext()
+			require.Equal(t, "42", gitCtx.ChangeID)
+			require.Equal(t, "0123456", gitCtx.Commit)
+			require.Equal(t, "feature", gitCtx.Branch)
+			require.Equal(t, "main", gitCtx.GetTargetBranch())
+			require.Equal(t, "Travis", gitCtx.Provider)
+			require.True(t, gitCtx.ChangeRequest)
+		})
+	})
+
+	t.Run("Buildkite", func(t *testing.T) {
+		// Disable Local, Github, Jenkins, Gitlab and Travis
+		t.Setenv("BRANCH", "")
+		t.Setenv("GITHUB_SHA", "")
+		t.Setenv("JENKINS_URL", "")
+		t.Setenv("CI_COMMIT_REF_NAME", "")
+		t.Setenv("TRAVIS_COMMIT", "")
+
+		t.Run("Running for Branches", func(t *testing.T) {
+			t.Setenv("BUILDKITE_COMMIT", "0123456")
+			t.Setenv("BUILDKITE_BRANCH", "main")
+			t.Setenv("BUILDKITE_PULL_REQUEST", "false")
+			t.Setenv("BUILDKITE_PULL_REQUEST_BASE_BRANCH", "")
+
+			gitCtx := checkGitContext()
+			require.Equal(t, "0123456", gitCtx.Commit)
+			require.Equal(t, "main", gitCtx.Branch)
+			require.Equal(t, "main", gitCtx.GetTargetBranch())
+			require.Equal(t, "Buildkite", gitCtx.Provider)
+			require.False(t, gitCtx.ChangeRequest)
+		})
+
+		t.Run("Running for Pull Requests", func(t *testing.T) {
+			t.Setenv("BUILDKITE_COMMIT", "0123456")
+			t.Setenv("BUILDKITE_BRANCH", "feature")
+			t.Setenv("BUILDKITE_PULL_REQUEST", "42")
+			t.Setenv("BUILDKITE_PULL_REQUEST_BASE_BRANCH", "main")
+
+			gitCtx := checkGitContext()
+			require.Equal(t, "42", gitCtx.ChangeID)
+			require.Equal(t, "feature", gitCtx.Branch)
+			require.Equal(t, "main", gitCtx.GetTargetBranch())
+			require.Equal(t, "Buildkite", gitCtx.Provider)
+			require.True(t, gitCtx.ChangeRequest)
+		})
+	})
+
+	t.Run("Drone", func(t *testing.T) {
+		// Disable Local, Github, Jenkins, Gitlab, Travis and Buildkite
+		t.Setenv("BRANCH", "")
+		t.Setenv("GITHUB_SHA", "")
+		t.Setenv("JENKINS_URL", "")
+		t.Setenv("CI_COMMIT_REF_NAME", "")
+		t.Setenv("TRAVIS_COMMIT", "")
+		t.Setenv("BUILDKITE_COMMIT", "")
+
+		t.Run("Running for Branches", func(t *testing.T) {
+			t.Setenv("DRONE_COMMIT_SHA", "0123456")
+			t.Setenv("DRONE_SOURCE_BRANCH", "main")
+			t.Setenv("DRONE_TARGET_BRANCH", "main")
+			t.Setenv("DRONE_PULL_REQUEST", "")
+
+			gitCtx := checkGitContext()
+			require.Equal(t, "0123456", gitCtx.Commit)
+			require.Equal(t, "main", gitCtx.Branch)
+			require.Equal(t, "main", gitCtx.GetTargetBranch())
+			require.Equal(t, "Drone", gitCtx.Provider)
+			require.False(t, gitCtx.ChangeRequest)
+		})
+
+		t.Run("Running for Pull Requests", func(t *testing.T) {
+			t.Setenv("DRONE_COMMIT_SHA", "0123456")
+			t.Setenv("DRONE_SOURCE_BRANCH", "feature")
+			t.Setenv("DRONE_TARGET_BRANCH", "main")
+			t.Setenv("DRONE_PULL_REQUEST", "42")
+
+			gitCtx := checkGitContext()
+			require.Equal(t, "42", gitCtx.ChangeID)
+			require.Equal(t, "feature", gitCtx.Branch)
+			require.Equal(t, "main", gitCtx.GetTargetBranch())
+			require.Equal(t, "Drone", gitCtx.Provider)
+			require.True(t, gitCtx.ChangeRequest)
+		})
+	})
+
+	t.Run("Bitbucket", func(t *testing.T) {
+		// Disable Local, Github, Jenkins, Gitlab, Travis, Buildkite and Drone
+		t.Setenv("BRANCH", "")
+		t.Setenv("GITHUB_SHA", "")
+		t.Setenv("JENKINS_URL", "")
+		t.Setenv("CI_COMMIT_REF_NAME", "")
+		t.Setenv("TRAVIS_COMMIT", "")
+		t.Setenv("BUILDKITE_COMMIT", "")
+		t.Setenv("DRONE_COMMIT_SHA", "")
+
+		t.Run("Running for Branches", func(t *testing.T) {
+			t.Setenv("BITBUCKET_COMMIT", "0123456")
+			t.Setenv("BITBUCKET_BRANCH", "main")
+			t.Setenv("BITBUCKET_PR_ID", "")
+			t.Setenv("BITBUCKET_PR_DESTINATION_BRANCH", "")
+
+			gitCtx := checkGitContext()
+			require.Equal(t, "0123456", gitCtx.Commit)
+			require.Equal(t, "main", gitCtx.Branch)
+			require.Equal(t, "main", gitCtx.GetTargetBranch())
+			require.Equal(t, "Bitbucket", gitCtx.Provider)
+			require.False(t, gitCtx.ChangeRequest)
+		})
+
+		t.Run("Running for Pull Requests", func(t *testing.T) {
+			t.Setenv("BITBUCKET_COMMIT", "0123456")
+			t.Setenv("BITBUCKET_BRANCH", "feature")
+			t.Setenv("BITBUCKET_PR_ID", "42")
+			t.Setenv("BITBUCKET_PR_DESTINATION_BRANCH", "main")
+
+			gitCtx := checkGitContext()
+			require.Equal(t, "42", gitCtx.ChangeID)
+			require.Equal(t, "0123456", gitCtx.Commit)
+			require.Equal(t, "feature", gitCtx.Branch)
+			require.Equal(t, "main", gitCtx.GetTargetBranch())
+			require.Equal(t, "Bitbucket", gitCtx.Provider)
+			require.True(t, gitCtx.ChangeRequest)
+		})
+	})
+
+	t.Run("TeamCity", func(t *testing.T) {
+		// Disable Local, Github, Jenkins, Gitlab, Travis, Buildkite, Drone and Bitbucket
+		t.Setenv("BRANCH", "")
+		t.Setenv("GITHUB_SHA", "")
+		t.Setenv("JENKINS_URL", "")
+		t.Setenv("CI_COMMIT_REF_NAME", "")
+		t.Setenv("TRAVIS_COMMIT", "")
+		t.Setenv("BUILDKITE_COMMIT", "")
+		t.Setenv("DRONE_COMMIT_SHA", "")
+		t.Setenv("BITBUCKET_COMMIT", "")
+
+		// writeTeamCityParameters writes the configuration parameters of the build, and the build properties
+		// file pointing to them, escaped as TeamCity does
+		writeTeamCityParameters := func(t *testing.T, parameters string) {
+			dir := t.TempDir()
+			configurationFile := filepath.Join(dir, "build.configuration.properties")
+			buildFile := filepath.Join(dir, "build.properties")
+
+			require.NoError(t, os.WriteFile(configurationFile, []byte(parameters), 0o644))
+			escaped := strings.NewReplacer(`\`, `\\`, ":", `\:`).Replace(configurationFile)
+			require.NoError(t, os.WriteFile(buildFile, []byte("#TeamCity build properties\nteamcity.configuration.properties.file="+escaped+"\n"), 0o644))
+
+			t.Setenv("TEAMCITY_BUILD_PROPERTIES_FILE", buildFile)
+		}
+
+		t.Run("Running for Branches", func(t *testing.T) {
+			t.Setenv("TEAMCITY_VERSION", "2024.12")
+			t.Setenv("BUILD_VCS_NUMBER", "0123456")
+			writeTeamCityParameters(t, "teamcity.build.branch=main\n")
+
+			gitCtx := checkGitContext()
+			require.Equal(t, "0123456", gitCtx.Commit)
+			require.Equal(t, "main", gitCtx.Branch)
+			require.Equal(t, "main", gitCtx.GetTargetBranch())
+			require.Equal(t, "TeamCity", gitCtx.Provider)
+			require.False(t, gitCtx.ChangeRequest)
+		})
+
+		t.Run("Running for Pull Requests", func(t *testing.T) {
+			t.Setenv("TEAMCITY_VERSION", "2024.12")
+			t.Setenv("BUILD_VCS_NUMBER", "0123456")
+			writeTeamCityParameters(t, "teamcity.build.branch=pull/42\n"+
+				"teamcity.pullRequest.number=42\n"+
+				"teamcity.pullRequest.source.branch=feature\n"+
+				"teamcity.pullRequest.target.branch=main\n")
+
+			gitCtx := checkGitContext()
+			require.Equal(t, "42", gitCtx.ChangeID)
+			require.Equal(t, "0123456", gitCtx.Commit)
+			require.Equal(t, "feature", gitCtx.Branch)
+			require.Equal(t, "main", gitCtx.GetTargetBranch())
+			require.Equal(t, "TeamCity", gitCtx.Provider)
+			require.True(t, gitCtx.ChangeRequest)
+		})
+
+		t.Run("Running without the build properties", func(t *testing.T) {
+			t.Setenv("TEAMCITY_VERSION", "2024.12")
+			t.Setenv("BUILD_VCS_NUMBER", "0123456")
+			t.Setenv("TEAMCITY_BUILD_PROPERTIES_FILE", "")
+
+			gitCtx := checkGitContext()
+			require.Equal(t, "0123456", gitCtx.Commit)
+			require.Empty(t, gitCtx.Branch)
+			require.Equal(t, "TeamCity", gitCtx.Provider)
+			require.False(t, gitCtx.ChangeRequest)
+		})
+	})
+
+	t.Run("Bamboo", func(t *testing.T) {
+		// Disable Local, Github, Jenkins, Gitlab, Travis, Buildkite, Drone, Bitbucket and TeamCity
+		t.Setenv("BRANCH", "")
+		t.Setenv("GITHUB_SHA", "")
+		t.Setenv("JENKINS_URL", "")
+		t.Setenv("CI_COMMIT_REF_NAME", "")
+		t.Setenv("TRAVIS_COMMIT", "")
+		t.Setenv("BUILDKITE_COMMIT", "")
+		t.Setenv("DRONE_COMMIT_SHA", "")
+		t.Setenv("BITBUCKET_COMMIT", "")
+		t.Setenv("TEAMCITY_VERSION", "")
+
+		t.Run("Running for Branches", func(t *testing.T) {
+			t.Setenv("bamboo_planRepository_revision", "0123456")
+			t.Setenv("bamboo_planRepository_branchName", "main")
+			t.Setenv("bamboo_repository_pr_key", "")
+
+			gitCtx := checkGitContext()
+			require.Equal(t, "0123456", gitCtx.Commit)
+			require.Equal(t, "main", gitCtx.Branch)
+			require.Equal(t, "main", gitCtx.GetTargetBranch())
+			require.Equal(t, "Bamboo", gitCtx.Provider)
+			require.False(t, gitCtx.ChangeRequest)
+		})
+
+		t.Run("Running for Pull Requests", func(t *testing.T) {
+			t.Setenv("bamboo_planRepository_revision", "0123456")
+			t.Setenv("bamboo_planRepository_branchName", "feature")
+			t.Setenv("bamboo_repository_pr_key", "42")
+			t.Setenv("bamboo_repository_pr_sourceBranch", "feature")
+			t.Setenv("bamboo_repository_pr_targetBranch", "main")
+
+			gitCtx := checkGitContext()
+			require.Equal(t, "42", gitCtx.ChangeID)
+			require.Equal(t, "0123456", gitCtx.Commit)
+			require.Equal(t, "feature", gitCtx.Branch)
+			require.Equal(t, "main", gitCtx.GetTargetBranch())
+			require.Equal(t, "Bamboo", gitCtx.Provider)
+			require.True(t, gitCtx.ChangeRequest)
+		})
+	})
+
+	t.Run("Local machine", func(t *testing.T) {
+		t.Run("Running with TARGET_BRANCH", func(t *testing.T) {
+			t.Setenv("BRANCH", "foo")
+			t.Setenv("TARGET_BRANCH", "main")
+
+			gitCtx := checkGitContext()
+			require.Equal(t, "", gitCtx.Commit)
+			require.Equal(t, "foo", gitCtx.Branch)
+			require.Equal(t, "main", gitCtx.GetTargetBranch())
+			require.Equal(t, "", gitCtx.Provider)
+			require.True(t, gitCtx.ChangeRequest)
+		})
+
+		t.Run("Running without TARGET_BRANCH", func(t *testing.T) {
+			t.Setenv("BRANCH", "foo")
+
+			gitCtx := checkGitContext()
+			require.Equal(t, "", gitCtx.Commit)
+			require.Equal(t, "foo", gitCtx.Branch)
+			require.Equal(t, "foo", gitCtx.GetTargetBranch())
+			require.Equal(t, "", gitCtx.Provider)
+			require.False(t, gitCtx.ChangeRequest)
+		})
+	})
+
+	t.Run("Empty SCM context", func(t *testing.T) {
+		// Disable Local, Github, Jenkins, Gitlab, Travis, Buildkite, Drone, Bitbucket, TeamCity and Bamboo
+		t.Setenv("BRANCH", "")
+		t.Setenv("GITHUB_SHA", "")
+		t.Setenv("JENKINS_URL", "")
+		t.Setenv("CI_COMMIT_BRANCH", "")
+		t.Setenv("TRAVIS_COMMIT", "")
+		t.Setenv("BUILDKITE_COMMIT", "")
+		t.Setenv("DRONE_COMMIT_SHA", "")
+		t.Setenv("BITBUCKET_COMMIT", "")
+		t.Setenv("TEAMCITY_VERSION", "")
+		t.Setenv("bamboo_planRepository_revision", "")
+
+		gitCtx := checkGitContext()
+		require.Nil(t, gitCtx)
+	})
+}
+
+func TestGetScm(t *testing.T) {
+	t.Run("This project uses Git", func(t *testing.T) {
+		t.Setenv("BRANCH", "main")
+
+		scm := GetScm(workingDir, ScmAttributesSchemaLegacy)
+		switch scm.(type) {
+		case *GitScm:
+			// NOOP
+		default:
+			t.Error()
+		}
+	})
+
+	t.Run("This project uses Git without SCM context", func(t *testing.T) {
+		// Disable Local, Github, Jenkins and Gitlab
+		t.Setenv("BRANCH", "")
+		t.Setenv("GITHUB_SHA", "")
+		t.Setenv("JENKINS_URL", "")
+		t.Setenv("CI_COMMIT_REF_NAME", "")
+
+		scm := GetScm(workingDir, ScmAttributesSchemaLegacy)
+
+		require.Nil(t, scm, "The SCM context should not be detected")
+	})
+
+	t.Run("This project does not use Git", func(t *testing.T) {
+		scm := GetScm(t.TempDir(), ScmAttributesSchemaLegacy)
+
+		require.Nil(t, scm, "The directory should not contain a .git directory")
+	})
+}
+
+func TestGetTargetBranch(t *testing.T) {
+	t.Run("For change-requests it must return target branch", func(t *testing.T) {
+		ctx := &ScmContext{
+			ChangeRequest: true,
+			TargetBranch:  "target",
+			Branch:        "branch",
+		}
+
+		require.Equal(t, "target", ctx.GetTargetBranch())
+	})
+
+	t.Run("For branches it must return branch", func(t *testing.T) {
+		ctx := &ScmContext{
+			ChangeRequest: false,
+			TargetBranch:  "target",
+			Branch:        "branch",
+		}
+
+		require.Equal(t, "branch", ctx.GetTargetBranch())
+	})
+}
@@ -0,0 +1,126 @@
+package junit2otlp
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// selfTelemetryScope instrumentation scope of the spans and metrics describing the conversion process,
+// so that they are not mixed with the ones describing the tests
+const selfTelemetryScope = "github.com/mdelapenya/junit2otlp/selftelemetry"
+
+// selfTelemetry emits spans and metrics about the conversion process itself: the parse duration,
+// the files processed, the spans generated and the export latency. A nil selfTelemetry emits nothing
+type selfTelemetry struct {
+	tracer         trace.Tracer
+	spans          *spanCounter
+	exportDuration metric.Float64Histogram
+	filesProcessed metric.Int64Counter
+	parseDuration  metric.Float64Histogram
+	spansGenerated metric.Int64Counter
+}
+
+// newSelfTelemetry returns the self-telemetry using the providers of the tool. The spans generated from the
+// reports are counted by the tracer provider returned by wrap, so concurrent conversions are not mixed
+func newSelfTelemetry(tracerProvider trace.TracerProvider, meterProvider metric.MeterProvider) *selfTelemetry {
+	meter := meterProvider.Meter(selfTelemetryScope)
+
+	// the instruments always return nil errors, as in createIntCounter
+	exportDuration, _ := meter.Float64Histogram(SelfExportDuration, metric.WithDescription("Duration of the export of the spans"), metric.WithUnit("s"))
+	parseDuration, _ := meter.Float64Histogram(SelfParseDuration, metric.WithDescription("Duration of the parse of the reports"), metric.WithUnit("s"))
+
+	return &selfTelemetry{
+		tracer:         tracerProvider.Tracer(selfTelemetryScope),
+		spans:          &spanCounter{},
+		exportDuration: exportDuration,
+		filesProcessed: createIntCounter(meter, SelfFilesProcessed, "Total number of reports processed"),
+		parseDuration:  parseDuration,
+		spansGenerated: createIntCounter(meter, SelfSpansGenerated, "Total number of spans generated from the reports"),
+	}
+}
+
+// wrap returns the tracer provider counting the spans started by its tracers
+func (st *selfTelemetry) wrap(tracerProvider trace.TracerProvider) trace.TracerProvider {
+	if st == nil {
+		return tracerProvider
+	}
+
+	return &countingTracerProvider{TracerProvider: tracerProvider, spans: st.spans}
+}
+
+// start starts the span wrapping the whole conversion, which began at the given time
+func (st *selfTelemetry) start(ctx context.Context, start time.Time) (context.Context, trace.Span) {
+	if st == nil {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+
+	// the conversion is not part of the trace of the tests, so it starts a new trace
+	return st.tracer.Start(ctx, Junit2otlp, trace.WithNewRoot(), trace.WithTimestamp(start))
+}
+
+// recordParse records the parse of the reports in the given format, which already happened
+func (st *selfTelemetry) recordParse(ctx context.Context, format string, reports int, start time.Time, end time.Time) {
+	if st == nil {
+		return
+	}
+
+	formatAttribute := attribute.Key(SelfFormat).String(format)
+
+	_, span := st.tracer.Start(ctx, "parse", trace.WithTimestamp(start), trace.WithAttributes(formatAttribute))
+	span.End(trace.WithTimestamp(end))
+
+	st.parseDuration.Record(ctx, end.Sub(start).Seconds(), metric.WithAttributes(formatAttribute))
+	st.filesProcessed.Add(ctx, int64(reports), metric.WithAttributes(formatAttribute))
+}
+
+// recordExport flushes the spans of the tests, recording how long the export took and how many
+// spans were generated
+func (st *selfTelemetry) recordExport(ctx context.Context, tracerProvider trace.TracerProvider) {
+	if st == nil {
+		return
+	}
+
+	st.spansGenerated.Add(ctx, st.spans.count.Load())
+
+	ctx, span := st.tracer.Start(ctx, "export")
+	defer span.End()
+
+	start := time.Now()
+	if flusher, ok := tracerProvider.(interface{ ForceFlush(context.Context) error }); ok {
+		if err := flusher.ForceFlush(ctx); err != nil {
+			span.RecordError(err)
+		}
+	}
+
+	st.exportDuration.Record(ctx, time.Since(start).Seconds())
+}
+
+// spanCounter counts the spans generated from the reports
+type spanCounter struct {
+	count atomic.Int64
+}
+
+// countingTracerProvider returns tracers counting the started spans, which are always ended
+type countingTracerProvider struct {
+	trace.TracerProvider
+	spans *spanCounter
+}
+
+func (tp *countingTracerProvider) Tracer(name string, options ...trace.TracerOption) trace.Tracer {
+	return &countingTracer{Tracer: tp.TracerProvider.Tracer(name, options...), spans: tp.spans}
+}
+
+type countingTracer struct {
+	trace.Tracer
+	spans *spanCounter
+}
+
+func (t *countingTracer) Start(ctx context.Context, spanName string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	t.spans.count.Add(1)
+	return t.Tracer.Start(ctx, spanName, opts...)
+}
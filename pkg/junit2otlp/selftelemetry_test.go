@@ -0,0 +1,88 @@
+package junit2otlp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSelfTelemetry(t *testing.T) {
+	suites, err := junit.IngestFile("../../TEST-sample.xml")
+	require.NoError(t, err)
+
+	t.Run("Enabled", func(t *testing.T) {
+		recorder := tracetest.NewSpanRecorder()
+		tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		reader := sdkmetric.NewManualReader()
+		meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+		cfg := config.NewConfigFromDefaults()
+		cfg.RepositoryPath = t.TempDir()
+
+		self := newSelfTelemetry(tracerProvider, meterProvider)
+
+		start := time.Now()
+		ctx, span := self.start(context.Background(), start)
+		self.recordParse(ctx, cfg.Format, 2, start, start.Add(time.Second))
+
+		_, err := createTracesAndSpans(context.Background(), cfg, "test-service", Providers{TracerProvider: self.wrap(tracerProvider)}, resolveRuntimeAttributes(context.Background(), cfg), nil, sendSuites(suites))
+		require.NoError(t, err)
+
+		generated := 0
+		for _, s := range recorder.Ended() {
+			if s.InstrumentationScope().Name != selfTelemetryScope {
+				generated++
+			}
+		}
+
+		self.recordExport(ctx, tracerProvider)
+		span.End()
+
+		selfSpans := map[string]sdktrace.ReadOnlySpan{}
+		for _, s := range recorder.Ended() {
+			if s.InstrumentationScope().Name == selfTelemetryScope {
+				selfSpans[s.Name()] = s
+			}
+		}
+		require.Len(t, selfSpans, 3)
+		require.Equal(t, time.Second, selfSpans["parse"].EndTime().Sub(selfSpans["parse"].StartTime()))
+		require.Equal(t, selfSpans[Junit2otlp].SpanContext().SpanID(), selfSpans["export"].Parent().SpanID())
+
+		var rm metricdata.ResourceMetrics
+		require.NoError(t, reader.Collect(context.Background(), &rm))
+
+		metrics := map[string]metricdata.Aggregation{}
+		for _, sm := range rm.ScopeMetrics {
+			if sm.Scope.Name != selfTelemetryScope {
+				continue
+			}
+			for _, m := range sm.Metrics {
+				metrics[m.Name] = m.Data
+			}
+		}
+
+		require.Equal(t, int64(2), metrics[SelfFilesProcessed].(metricdata.Sum[int64]).DataPoints[0].Value)
+		require.Equal(t, int64(generated), metrics[SelfSpansGenerated].(metricdata.Sum[int64]).DataPoints[0].Value)
+		require.Equal(t, 1.0, metrics[SelfParseDuration].(metricdata.Histogram[float64]).DataPoints[0].Sum)
+		require.Len(t, metrics[SelfExportDuration].(metricdata.Histogram[float64]).DataPoints, 1)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		var self *selfTelemetry
+
+		ctx, span := self.start(context.Background(), time.Now())
+		self.recordParse(ctx, "junit", 1, time.Now(), time.Now())
+		self.recordExport(ctx, nil)
+		span.End()
+
+		require.False(t, span.SpanContext().IsValid())
+	})
+}
@@ -0,0 +1,119 @@
+package junit2otlp
+
+const (
+	Junit2otlp = "junit2otlp"
+
+	// cicd keys, from the OpenTelemetry semantic conventions
+	CicdPipelineRunURLFull = "cicd.pipeline.run.url.full"
+
+	// code keys
+	CodeURL = "code.url" // permalink to the source of the test case, at the tested commit
+
+	// coverage keys
+	TestsCoverageLinesCovered = "tests.coverage.lines.covered"
+	TestsCoverageLinesValid   = "tests.coverage.lines.valid"
+	TestsCoveragePackage      = "tests.coverage.package"
+	TestsCoveragePercentage   = "tests.coverage.percentage"
+
+	// git keys
+	GitAdditions     = "scm.git.additions"
+	GitCloneDepth    = "scm.git.clone.depth"
+	GitCloneShallow  = "scm.git.clone.shallow"
+	GitDeletions     = "scm.git.deletions"
+	GitModifiedFiles = "scm.git.files.modified"
+
+	// github keys
+	GithubCheckRunID           = "scm.github.checkrun.id"
+	GithubPullRequestDraft     = "scm.github.pr.draft"
+	GithubPullRequestLabels    = "scm.github.pr.labels"
+	GithubPullRequestReviewers = "scm.github.pr.reviewers"
+
+	// gitlab keys
+	GitlabMergeRequestApprovers = "scm.gitlab.mr.approvers"
+	GitlabMergeRequestLabels    = "scm.gitlab.mr.labels"
+	GitlabMergeRequestMilestone = "scm.gitlab.mr.milestone"
+	GitlabPipelineURL           = "scm.gitlab.pipeline.url"
+
+	// scm keys
+	ScmAuthors    = "scm.authors"
+	ScmBaseRef    = "scm.baseRef"
+	ScmBranch     = "scm.branch"
+	ScmCommitters = "scm.committers"
+	ScmProvider   = "scm.provider"
+	ScmRepository = "scm.repository"
+	ScmType       = "scm.type"
+
+	// vcs keys, from the OpenTelemetry semantic conventions
+	VcsChangeID          = "vcs.change.id"
+	VcsProviderName      = "vcs.provider.name"
+	VcsRefBaseName       = "vcs.ref.base.name"
+	VcsRefBaseType       = "vcs.ref.base.type"
+	VcsRefHeadName       = "vcs.ref.head.name"
+	VcsRefHeadRevision   = "vcs.ref.head.revision"
+	VcsRefHeadType       = "vcs.ref.head.type"
+	VcsRepositoryURLFull = "vcs.repository.url.full"
+
+	// self-telemetry keys, describing the conversion process itself
+	SelfExportDuration = "junit2otlp.export.duration"
+	SelfFilesProcessed = "junit2otlp.files.processed"
+	SelfFormat         = "junit2otlp.format"
+	SelfParseDuration  = "junit2otlp.parse.duration"
+	SelfSpansGenerated = "junit2otlp.spans.generated"
+
+	// log keys
+	LogIOStream       = "log.iostream" // from the OpenTelemetry semantic conventions
+	TestsOutputChunk  = "tests.output.chunk"
+	TestsOutputChunks = "tests.output.chunks"
+
+	// matrix keys, a tests.matrix.<dimension> attribute per dimension of the matrix pattern
+	TestsMatrixPrefix = "tests.matrix."
+
+	// run keys
+	TestsRunDroppedSpans = "tests.run.spans.dropped"
+	TestsRunError        = "tests.run.error"
+	TestsRunFailed       = "tests.run.failed"
+	TestsRunPassed       = "tests.run.passed"
+	TestsRunReruns       = "tests.run.reruns"
+	TestsRunSkipped      = "tests.run.skipped"
+	TestsRunTotal        = "tests.run.total"
+	TestsRunTruncated    = "tests.run.truncated"
+
+	// suite keys
+	FailedTestsCount       = "tests.suite.failed"
+	ErrorTestsCount        = "tests.suite.error"
+	PassedTestsCount       = "tests.suite.passed"
+	SkippedTestsCount      = "tests.suite.skipped"
+	TestsDuration          = "tests.suite.duration"
+	TestsDurationHistogram = "tests.suite.duration.histogram"
+	TestsSuiteName         = "tests.suite.suitename"
+	TestsSuiteShard        = "tests.suite.shard"
+	TestsSystemErr         = "tests.suite.systemerr"
+	TestsSystemOut         = "tests.suite.systemout"
+	TestsSuiteTimestamp    = "tests.suite.timestamp"
+	TotalTestsCount        = "tests.suite.total"
+
+	// test keys, from the incubating OpenTelemetry semantic conventions
+	TestCaseName         = "test.case.name"
+	TestCaseResultStatus = "test.case.result.status"
+	TestSuiteName        = "test.suite.name"
+	TestSuiteRunStatus   = "test.suite.run.status"
+
+	// test case failure event keys, an event per failure or error element of the test case
+	TestFailureEvent   = "tests.case.failure"
+	TestFailureMessage = "tests.case.failure.message"
+	TestFailureOrdinal = "tests.case.failure.ordinal"
+
+	// test case keys
+	TestAnomaly           = "tests.case.anomaly"
+	TestClassName         = "tests.case.classname"
+	TestDuration          = "tests.case.duration"
+	TestDurationHistogram = "tests.case.duration.histogram"
+	TestError             = "tests.case.error"
+	TestFailureType       = "tests.case.failure.type"
+	TestFlaky             = "tests.case.flaky"
+	TestMessage           = "tests.case.message"
+	TestRetries           = "tests.case.retries"
+	TestStatus            = "tests.case.status"
+	TestSystemErr         = "tests.case.systemerr"
+	TestSystemOut         = "tests.case.systemout"
+)
@@ -0,0 +1,243 @@
+package junit2otlp
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"golang.org/x/mod/modfile"
+)
+
+// serviceNameDetectors detect the service name from the project at the given directory, in order
+var serviceNameDetectors = []struct {
+	source string
+	detect func(dir string) string
+}{
+	{source: "go.mod", detect: serviceNameFromGoMod},
+	{source: "package.json", detect: serviceNameFromPackageJSON},
+	{source: "pom.xml", detect: serviceNameFromPom},
+	{source: "git", detect: serviceNameFromGitRemote},
+}
+
+// detectServiceName detects the service name from the project manifests at the given directory: the module path
+// of the go.mod file, the name in the package.json file, the artifactId of the pom.xml file, or the name of the
+// repository from its origin remote. It returns an empty string if it's not possible to detect it
+func detectServiceName(dir string) string {
+	for _, detector := range serviceNameDetectors {
+		name := detector.detect(dir)
+		if name != "" {
+			slog.Debug("service name detected", "source", detector.source, "serviceName", name)
+			return name
+		}
+	}
+
+	return ""
+}
+
+// suiteServiceName returns the service owning the suite: the service mapped to the longest prefix of its package,
+// or of its name for the reports without packages, or the package itself if no prefix matches and the services are
+// derived from the suites. It returns an empty string for the suites of the service of the run
+func suiteServiceName(cfg *config.Config, suite junit.Suite) string {
+	name := suite.Package
+	if name == "" {
+		name = suite.Name
+	}
+
+	service, longest := "", -1
+	for prefix, mapped := range cfg.ServiceMapping {
+		if len(prefix) > longest && hasPathPrefix(name, prefix) {
+			service, longest = mapped, len(prefix)
+		}
+	}
+	if longest >= 0 {
+		return service
+	}
+
+	if cfg.ServicePerSuite {
+		return name
+	}
+
+	return ""
+}
+
+// hasPathPrefix returns true if the prefix is the name, or the leading segments of its path or dotted name, so
+// the github.com/acme/api prefix matches github.com/acme/api/users, but not github.com/acme/apigateway
+func hasPathPrefix(name string, prefix string) bool {
+	if !strings.HasPrefix(name, prefix) {
+		return false
+	}
+
+	if len(name) == len(prefix) || strings.HasSuffix(prefix, "/") || strings.HasSuffix(prefix, ".") {
+		return true
+	}
+
+	next := name[len(prefix)]
+	return next == '/' || next == '.'
+}
+
+func serviceNameFromGoMod(dir string) string {
+	content, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+
+	return modfile.ModulePath(content)
+}
+
+func serviceNameFromPackageJSON(dir string) string {
+	content, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return ""
+	}
+
+	var pkg struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		slog.Debug("not able to parse package.json", "error", err)
+		return ""
+	}
+
+	return pkg.Name
+}
+
+func serviceNameFromPom(dir string) string {
+	content, err := os.ReadFile(filepath.Join(dir, "pom.xml"))
+	if err != nil {
+		return ""
+	}
+
+	// only the artifactId of the project, not the one of the parent or the dependencies
+	var pom struct {
+		ArtifactID string `xml:"artifactId"`
+	}
+	if err := xml.Unmarshal(content, &pom); err != nil {
+		slog.Debug("not able to parse pom.xml", "error", err)
+		return ""
+	}
+
+	return strings.TrimSpace(pom.ArtifactID)
+}
+
+func serviceNameFromGitRemote(dir string) string {
+	repository, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return ""
+	}
+
+	remote, err := repository.Remote("origin")
+	if err != nil || len(remote.Config().URLs) == 0 {
+		return ""
+	}
+
+	url := strings.TrimSuffix(remote.Config().URLs[0], "/")
+	url = strings.TrimSuffix(url, ".git")
+
+	// works for both https://host/owner/name and git@host:owner/name remotes
+	if i := strings.LastIndexAny(url, "/:"); i >= 0 {
+		url = url[i+1:]
+	}
+
+	return url
+}
+
+// shortSHALength length of the abbreviated commit SHA, as used by git
+const shortSHALength = 7
+
+// detectServiceVersion detects the service version from the Git repository at the given directory, in the same
+// manner as 'git describe --tags': the tag pointing to HEAD, or the nearest tag followed by the number of commits
+// on top of it and the abbreviated SHA of HEAD, i.e. v1.2.0-3-g1a2b3c4. If there are no tags reachable from HEAD,
+// it uses the abbreviated SHA. It returns an empty string if the directory is not a Git repository
+func detectServiceVersion(dir string) string {
+	repository, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return ""
+	}
+
+	head, err := repository.Head()
+	if err != nil {
+		return ""
+	}
+
+	shortSHA := head.Hash().String()[:shortSHALength]
+
+	version, err := describeTags(repository, head.Hash())
+	if err != nil {
+		slog.Debug("not able to describe the tags, using the abbreviated SHA as the service version", "error", err)
+		return shortSHA
+	}
+
+	if version == "" {
+		return shortSHA
+	}
+
+	slog.Debug("service version detected", "source", "git", "serviceVersion", version)
+
+	return version
+}
+
+// describeTags walks the history from the given commit, returning the nearest tag, as described by
+// detectServiceVersion, or an empty string if there are no tags reachable from it
+func describeTags(repository *git.Repository, from plumbing.Hash) (string, error) {
+	tags, err := repository.Tags()
+	if err != nil {
+		return "", err
+	}
+
+	// commit hash -> tag name, resolving the annotated tags to the commits they point to
+	taggedCommits := map[plumbing.Hash]string{}
+	err = tags.ForEach(func(ref *plumbing.Reference) error {
+		hash := ref.Hash()
+		if tag, err := repository.TagObject(hash); err == nil {
+			hash = tag.Target
+		}
+
+		taggedCommits[hash] = ref.Name().Short()
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(taggedCommits) == 0 {
+		return "", nil
+	}
+
+	commits, err := repository.Log(&git.LogOptions{From: from})
+	if err != nil {
+		return "", err
+	}
+	defer commits.Close()
+
+	version := ""
+	distance := 0
+	err = commits.ForEach(func(c *object.Commit) error {
+		if tag, ok := taggedCommits[c.Hash]; ok {
+			version = tag
+			return storer.ErrStop
+		}
+
+		distance++
+		return nil
+	})
+	if err != nil && !errors.Is(err, storer.ErrStop) {
+		return "", err
+	}
+
+	if version == "" || distance == 0 {
+		return version, nil
+	}
+
+	return fmt.Sprintf("%s-%d-g%s", version, distance, from.String()[:shortSHALength]), nil
+}
@@ -0,0 +1,182 @@
+package junit2otlp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"github.com/stretchr/testify/require"
+)
+
+func writeProjectFile(t *testing.T, dir string, name string, content string) {
+	t.Helper()
+
+	err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600)
+	require.NoError(t, err)
+}
+
+func TestDetectServiceName(t *testing.T) {
+	t.Run("go.mod", func(t *testing.T) {
+		dir := t.TempDir()
+		writeProjectFile(t, dir, "go.mod", "module github.com/foo/bar\n\ngo 1.23\n")
+
+		require.Equal(t, "github.com/foo/bar", detectServiceName(dir))
+	})
+
+	t.Run("package.json", func(t *testing.T) {
+		dir := t.TempDir()
+		writeProjectFile(t, dir, "package.json", `{"name": "my-node-app", "version": "1.0.0"}`)
+
+		require.Equal(t, "my-node-app", detectServiceName(dir))
+	})
+
+	t.Run("pom.xml", func(t *testing.T) {
+		dir := t.TempDir()
+		writeProjectFile(t, dir, "pom.xml", `<project>
+  <parent><artifactId>my-parent</artifactId></parent>
+  <artifactId>my-java-app</artifactId>
+  <dependencies><dependency><artifactId>junit</artifactId></dependency></dependencies>
+</project>`)
+
+		require.Equal(t, "my-java-app", detectServiceName(dir))
+	})
+
+	t.Run("go.mod takes precedence", func(t *testing.T) {
+		dir := t.TempDir()
+		writeProjectFile(t, dir, "go.mod", "module github.com/foo/bar\n")
+		writeProjectFile(t, dir, "package.json", `{"name": "my-node-app"}`)
+
+		require.Equal(t, "github.com/foo/bar", detectServiceName(dir))
+	})
+
+	t.Run("Git remote", func(t *testing.T) {
+		for _, url := range []string{"https://github.com/foo/my-repo.git", "git@github.com:foo/my-repo.git"} {
+			dir := t.TempDir()
+			repository, err := git.PlainInit(dir, false)
+			require.NoError(t, err)
+
+			_, err = repository.CreateRemote(&gitconfig.RemoteConfig{Name: "origin", URLs: []string{url}})
+			require.NoError(t, err)
+
+			require.Equal(t, "my-repo", detectServiceName(dir))
+		}
+	})
+
+	t.Run("Nothing to detect", func(t *testing.T) {
+		require.Empty(t, detectServiceName(t.TempDir()))
+	})
+
+	t.Run("Used when neither the flag nor the env are set", func(t *testing.T) {
+		t.Setenv("OTEL_SERVICE_NAME", "")
+
+		dir := t.TempDir()
+		writeProjectFile(t, dir, "package.json", `{"name": "my-node-app"}`)
+
+		cfg := config.NewConfigFromDefaults()
+		cfg.RepositoryPath = dir
+
+		require.Equal(t, "my-node-app", getOtlpServiceName(cfg))
+	})
+}
+
+func TestDetectServiceVersion(t *testing.T) {
+	commit := func(t *testing.T, repository *git.Repository, dir string, message string) plumbing.Hash {
+		t.Helper()
+
+		writeProjectFile(t, dir, "README.md", message)
+
+		worktree, err := repository.Worktree()
+		require.NoError(t, err)
+
+		_, err = worktree.Add("README.md")
+		require.NoError(t, err)
+
+		hash, err := worktree.Commit(message, &git.CommitOptions{
+			Author: &object.Signature{Name: "junit2otlp", Email: "junit2otlp@example.com", When: time.Now()},
+		})
+		require.NoError(t, err)
+
+		return hash
+	}
+
+	t.Run("Not a Git repository", func(t *testing.T) {
+		require.Empty(t, detectServiceVersion(t.TempDir()))
+	})
+
+	t.Run("Without tags", func(t *testing.T) {
+		dir := t.TempDir()
+		repository, err := git.PlainInit(dir, false)
+		require.NoError(t, err)
+
+		hash := commit(t, repository, dir, "first")
+
+		require.Equal(t, hash.String()[:7], detectServiceVersion(dir))
+	})
+
+	t.Run("Tag pointing to HEAD", func(t *testing.T) {
+		dir := t.TempDir()
+		repository, err := git.PlainInit(dir, false)
+		require.NoError(t, err)
+
+		hash := commit(t, repository, dir, "first")
+		_, err = repository.CreateTag("v1.0.0", hash, nil)
+		require.NoError(t, err)
+
+		require.Equal(t, "v1.0.0", detectServiceVersion(dir))
+	})
+
+	t.Run("Commits on top of an annotated tag", func(t *testing.T) {
+		dir := t.TempDir()
+		repository, err := git.PlainInit(dir, false)
+		require.NoError(t, err)
+
+		hash := commit(t, repository, dir, "first")
+		_, err = repository.CreateTag("v1.0.0", hash, &git.CreateTagOptions{
+			Message: "v1.0.0",
+			Tagger:  &object.Signature{Name: "junit2otlp", Email: "junit2otlp@example.com", When: time.Now()},
+		})
+		require.NoError(t, err)
+
+		commit(t, repository, dir, "second")
+		head := commit(t, repository, dir, "third")
+
+		require.Equal(t, "v1.0.0-2-g"+head.String()[:7], detectServiceVersion(dir))
+	})
+}
+
+func TestSuiteServiceName(t *testing.T) {
+	cfg := config.NewConfigFromDefaults()
+	cfg.ServiceMapping = map[string]string{
+		"github.com/acme/mono":         "mono",
+		"github.com/acme/mono/billing": "billing",
+		"com.acme.users":               "users",
+	}
+
+	tests := []struct {
+		suite           junit.Suite
+		expected        string
+		servicePerSuite string
+	}{
+		{suite: junit.Suite{Name: "TestInvoices", Package: "github.com/acme/mono/billing/invoices"}, expected: "billing", servicePerSuite: "billing"},
+		{suite: junit.Suite{Name: "TestMono", Package: "github.com/acme/mono"}, expected: "mono", servicePerSuite: "mono"},
+		{suite: junit.Suite{Name: "com.acme.users.UsersTest"}, expected: "users", servicePerSuite: "users"},
+		{suite: junit.Suite{Name: "TestBillingV2", Package: "github.com/acme/mono/billingv2"}, expected: "mono", servicePerSuite: "mono"},
+		{suite: junit.Suite{Name: "TestGateway", Package: "github.com/acme/gateway"}, servicePerSuite: "github.com/acme/gateway"},
+		{suite: junit.Suite{Name: "com.acme.orders.OrdersTest"}, servicePerSuite: "com.acme.orders.OrdersTest"},
+	}
+
+	for _, tt := range tests {
+		cfg.ServicePerSuite = false
+		require.Equal(t, tt.expected, suiteServiceName(cfg, tt.suite), tt.suite.Name)
+
+		cfg.ServicePerSuite = true
+		require.Equal(t, tt.servicePerSuite, suiteServiceName(cfg, tt.suite), tt.suite.Name)
+	}
+}
@@ -0,0 +1,135 @@
+package junit2otlp
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime/metrics"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/internal/formats"
+)
+
+// heapObjectsMetric the runtime metric with the bytes of the heap occupied by objects, live or not yet collected
+const heapObjectsMetric = "/memory/classes/heap/objects:bytes"
+
+func init() {
+	// the error of the failed and errored tests is a junit.Error, or the Failures of the ones with several of them
+	gob.Register(junit.Error{})
+	gob.Register(formats.Failures{})
+}
+
+// suiteSpool retains the suites needed by the outputs processed after the spans are created. When the heap
+// exceeds three quarters of the memory limit, the retained suites are spilled to a temporary file, so the
+// memory used by huge reports is bounded. If the limit is zero, the suites are always kept in memory
+type suiteSpool struct {
+	limit   uint64
+	sample  []metrics.Sample
+	suites  []junit.Suite
+	file    *os.File
+	encoder *gob.Encoder
+	spilled int
+	err     error
+}
+
+// newSuiteSpool returns a spool for the memory limit, in bytes
+func newSuiteSpool(limit uint64) *suiteSpool {
+	return &suiteSpool{
+		limit:  limit,
+		sample: []metrics.Sample{{Name: heapObjectsMetric}},
+		suites: []junit.Suite{},
+	}
+}
+
+// add retains the suite, spilling the suites in memory to disk under memory pressure. The suites are kept
+// in memory if the spill fails, and the error is returned when loading them
+func (s *suiteSpool) add(suite junit.Suite) {
+	s.suites = append(s.suites, suite)
+
+	if s.err != nil || !s.underPressure() {
+		return
+	}
+
+	if err := s.spill(); err != nil {
+		s.err = fmt.Errorf("failed to spill the suites to disk: %w", err)
+	}
+}
+
+func (s *suiteSpool) underPressure() bool {
+	if s.limit == 0 {
+		return false
+	}
+
+	metrics.Read(s.sample)
+	if s.sample[0].Value.Kind() != metrics.KindUint64 {
+		return false
+	}
+
+	return s.sample[0].Value.Uint64() > s.limit/4*3
+}
+
+// spill encodes the suites in memory to the temporary file, so the memory always keeps the newest suites
+func (s *suiteSpool) spill() error {
+	if s.file == nil {
+		f, err := os.CreateTemp("", "junit2otlp-*.spool")
+		if err != nil {
+			return err
+		}
+
+		s.file = f
+		s.encoder = gob.NewEncoder(f)
+	}
+
+	for i := range s.suites {
+		if err := s.encoder.Encode(&s.suites[i]); err != nil {
+			return err
+		}
+		s.spilled++
+	}
+
+	s.suites = s.suites[:0:0]
+
+	return nil
+}
+
+// load returns all the retained suites, in the order they were added
+func (s *suiteSpool) load() ([]junit.Suite, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	if s.file == nil {
+		return s.suites, nil
+	}
+
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read the spilled suites: %w", err)
+	}
+
+	suites := make([]junit.Suite, 0, s.spilled+len(s.suites))
+	decoder := gob.NewDecoder(s.file)
+	for {
+		var suite junit.Suite
+		if err := decoder.Decode(&suite); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to read the spilled suites: %w", err)
+		}
+
+		suites = append(suites, suite)
+	}
+
+	return append(suites, s.suites...), nil
+}
+
+// close removes the temporary file, if any
+func (s *suiteSpool) close() error {
+	if s.file == nil {
+		return nil
+	}
+
+	return errors.Join(s.file.Close(), os.Remove(s.file.Name()))
+}
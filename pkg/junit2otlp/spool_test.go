@@ -0,0 +1,85 @@
+package junit2otlp
+
+import (
+	"os"
+	"testing"
+
+	"github.com/joshdk/go-junit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuiteSpool(t *testing.T) {
+	suites, err := junit.IngestFile("../../TEST-sample.xml")
+	require.NoError(t, err)
+
+	failed := junit.Suite{
+		Name:  "failed",
+		Tests: []junit.Test{{Name: "test", Status: junit.StatusFailed, Error: junit.Error{Message: "boom", Type: "AssertionError"}}},
+	}
+	suites = append(suites, failed)
+
+	t.Run("Without limit the suites are kept in memory", func(t *testing.T) {
+		spool := newSuiteSpool(0)
+		for _, suite := range suites {
+			spool.add(suite)
+		}
+
+		loaded, err := spool.load()
+		require.NoError(t, err)
+		require.Equal(t, suites, loaded)
+		require.Nil(t, spool.file)
+		require.NoError(t, spool.close())
+	})
+
+	t.Run("Under memory pressure the suites are spilled", func(t *testing.T) {
+		// any heap exceeds the limit
+		spool := newSuiteSpool(4)
+		for _, suite := range suites {
+			spool.add(suite)
+		}
+
+		require.NotNil(t, spool.file)
+		require.Equal(t, len(suites), spool.spilled)
+
+		loaded, err := spool.load()
+		require.NoError(t, err)
+		require.Len(t, loaded, len(suites))
+		for i := range suites {
+			require.Equal(t, suites[i].Name, loaded[i].Name)
+			require.Equal(t, suites[i].Totals, loaded[i].Totals)
+			require.Len(t, loaded[i].Tests, len(suites[i].Tests))
+		}
+		require.Equal(t, failed.Tests[0].Error, loaded[len(loaded)-1].Tests[0].Error)
+
+		path := spool.file.Name()
+		require.NoError(t, spool.close())
+		require.NoFileExists(t, path)
+	})
+
+	t.Run("Newest suites stay in memory", func(t *testing.T) {
+		spool := newSuiteSpool(4)
+		spool.add(suites[0])
+		spool.limit = 0
+		spool.add(failed)
+
+		require.Equal(t, 1, spool.spilled)
+
+		loaded, err := spool.load()
+		require.NoError(t, err)
+		require.Equal(t, []string{suites[0].Name, failed.Name}, []string{loaded[0].Name, loaded[1].Name})
+		require.NoError(t, spool.close())
+	})
+
+	t.Run("Spilled suites go to the temporary directory", func(t *testing.T) {
+		tmp := t.TempDir()
+		t.Setenv("TMPDIR", tmp)
+
+		spool := newSuiteSpool(4)
+		spool.add(failed)
+		defer spool.close()
+
+		entries, err := os.ReadDir(tmp)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+	})
+}
@@ -0,0 +1,365 @@
+package junit2otlp
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// slowestTestsCount number of slowest tests included in the run summary
+const slowestTestsCount = 10
+
+// the formats of the summary of the run, selected with the output format of the configuration
+const (
+	summaryFormatJSON = "json"
+	summaryFormatText = "text"
+)
+
+// ANSI escape sequences used to colorize the summary table. All of them have the same length,
+// so that the columns are still aligned by the tabwriter
+const (
+	colorBold   = "\x1b[01m"
+	colorGreen  = "\x1b[32m"
+	colorRed    = "\x1b[31m"
+	colorYellow = "\x1b[33m"
+	colorReset  = "\x1b[0m"
+)
+
+// isColorTerminal returns true if the file is attached to a terminal, and the NO_COLOR environment
+// variable is not set, as described in https://no-color.org
+func isColorTerminal(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	stat, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return (stat.Mode() & os.ModeCharDevice) != 0
+}
+
+// validateSummaryFormat checks the format of the summary is text or json, if set
+func validateSummaryFormat(format string) error {
+	switch format {
+	case "", summaryFormatText, summaryFormatJSON:
+		return nil
+	}
+
+	return fmt.Errorf("invalid output %q: the summary of the run is printed as %s or %s", format, summaryFormatText, summaryFormatJSON)
+}
+
+// summaryEnabled returns true if the summary of the run is printed: with the summary of the configuration, which
+// is enabled by default, or with the format of its output
+func summaryEnabled(cfg *config.Config) bool {
+	return cfg.Summary || cfg.OutputFormat != ""
+}
+
+// printRunSummary writes the summary of the run in the output format of the configuration: the JSON of the run
+// summary to stdout, so it can be piped, or the table of the suites to stderr, colorized when it's a terminal
+func printRunSummary(stdout *os.File, stderr *os.File, cfg *config.Config, suites []junit.Suite, traceID trace.TraceID) error {
+	if cfg.OutputFormat == summaryFormatJSON {
+		summary := newRunSummary(suites, traceID)
+		summary.Destination = exportDestination(cfg)
+
+		return encodeSummaryJSON(stdout, summary)
+	}
+
+	return printSummary(stderr, suites, traceID, exportDestination(cfg), isColorTerminal(stderr))
+}
+
+// printSummary writes a table with the results of each suite, and the totals of the run, followed by the number of
+// suites, the ID of the trace and where it was exported, if known. If color is true, the rows are colorized: red
+// for suites with failed or errored tests, yellow for suites with skipped tests, and green for the rest
+func printSummary(w io.Writer, suites []junit.Suite, traceID trace.TraceID, destination string, color bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	row := func(rowColor string, name string, totals junit.Totals) {
+		line := fmt.Sprintf("%s\t%d\t%d\t%d\t%d\t%d\t%s", name, totals.Tests, totals.Passed, totals.Failed, totals.Error, totals.Skipped, formatDuration(totals.Duration))
+		if color {
+			line = rowColor + line + colorReset
+		}
+
+		fmt.Fprintln(tw, line)
+	}
+
+	header := "SUITE\tTESTS\tPASSED\tFAILED\tERRORED\tSKIPPED\tDURATION"
+	if color {
+		header = colorBold + header + colorReset
+	}
+	fmt.Fprintln(tw, header)
+
+	total := junit.Totals{}
+	for _, suite := range suites {
+		totals := suite.Totals
+		row(summaryColor(totals), suite.Name, totals)
+
+		total.Tests += totals.Tests
+		total.Passed += totals.Passed
+		total.Failed += totals.Failed
+		total.Error += totals.Error
+		total.Skipped += totals.Skipped
+		total.Duration += totals.Duration
+	}
+
+	row(summaryColor(total), "TOTAL", total)
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	footer := fmt.Sprintf("\n%d suites", len(suites))
+	if traceID.IsValid() {
+		footer += ", trace " + traceID.String()
+	}
+	if destination != "" {
+		footer += ", exported to " + destination
+	}
+
+	_, err := fmt.Fprintln(w, footer)
+	return err
+}
+
+func summaryColor(totals junit.Totals) string {
+	switch {
+	case totals.Failed > 0 || totals.Error > 0:
+		return colorRed
+	case totals.Skipped > 0:
+		return colorYellow
+	default:
+		return colorGreen
+	}
+}
+
+func formatDuration(d time.Duration) string {
+	return d.Round(time.Millisecond).String()
+}
+
+// runSummary represents the results of the run, written as JSON so that the next steps of a pipeline,
+// such as the ones commenting on pull requests, can consume them
+type runSummary struct {
+	TraceID     string        `json:"traceId"`
+	Destination string        `json:"destination,omitempty"`
+	Totals      summaryTotals `json:"totals"`
+	Failures    []summaryTest `json:"failures"`
+	Slowest     []summaryTest `json:"slowest"`
+}
+
+type summaryTotals struct {
+	Suites     int   `json:"suites"`
+	Tests      int   `json:"tests"`
+	Passed     int   `json:"passed"`
+	Failed     int   `json:"failed"`
+	Errored    int   `json:"errored"`
+	Skipped    int   `json:"skipped"`
+	DurationMs int64 `json:"durationMs"`
+}
+
+type summaryTest struct {
+	Suite      string `json:"suite"`
+	Classname  string `json:"classname,omitempty"`
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Message    string `json:"message,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// newRunSummary returns the summary of the run: the totals, the failed and errored tests, and the
+// slowest tests, in descending order of duration
+func newRunSummary(suites []junit.Suite, traceID trace.TraceID) runSummary {
+	summary := runSummary{
+		Failures: []summaryTest{},
+		Slowest:  []summaryTest{},
+	}
+
+	if traceID.IsValid() {
+		summary.TraceID = traceID.String()
+	}
+
+	summary.Totals.Suites = len(suites)
+	for _, suite := range suites {
+		summary.Totals.Tests += suite.Totals.Tests
+		summary.Totals.Passed += suite.Totals.Passed
+		summary.Totals.Failed += suite.Totals.Failed
+		summary.Totals.Errored += suite.Totals.Error
+		summary.Totals.Skipped += suite.Totals.Skipped
+		summary.Totals.DurationMs += suite.Totals.Duration.Milliseconds()
+
+		for _, test := range suite.Tests {
+			st := summaryTest{
+				Suite:      suite.Name,
+				Classname:  test.Classname,
+				Name:       test.Name,
+				Status:     string(test.Status),
+				Message:    test.Message,
+				DurationMs: test.Duration.Milliseconds(),
+			}
+
+			if test.Status == junit.StatusFailed || test.Status == junit.StatusError {
+				summary.Failures = append(summary.Failures, st)
+			}
+
+			summary.Slowest = append(summary.Slowest, st)
+		}
+	}
+
+	slices.SortStableFunc(summary.Slowest, func(a, b summaryTest) int {
+		return cmp.Compare(b.DurationMs, a.DurationMs)
+	})
+
+	if len(summary.Slowest) > slowestTestsCount {
+		summary.Slowest = summary.Slowest[:slowestTestsCount]
+	}
+
+	return summary
+}
+
+// writeSummaryJSON writes the run summary as indented JSON to the file at path, or to stdout if the path is '-'
+func writeSummaryJSON(path string, summary runSummary) error {
+	if path == "-" {
+		return encodeSummaryJSON(os.Stdout, summary)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to write the run summary: %w", err)
+	}
+	defer f.Close()
+
+	if err := encodeSummaryJSON(f, summary); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+// encodeSummaryJSON writes the run summary as indented JSON to the writer
+func encodeSummaryJSON(w io.Writer, summary runSummary) error {
+	b, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal the run summary: %w", err)
+	}
+
+	if _, err := w.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("failed to write the run summary: %w", err)
+	}
+
+	return nil
+}
+
+// traceIDPlaceholder placeholder replaced by the trace ID in the trace URL template
+const traceIDPlaceholder = "{traceId}"
+
+// writeSummaryMarkdown appends the run summary as Markdown to the file at path, creating it if it does
+// not exist, so that it can be used with the $GITHUB_STEP_SUMMARY file of Github Actions
+func writeSummaryMarkdown(path string, title string, summary runSummary, traceURLTemplate string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open the Markdown summary: %w", err)
+	}
+	defer f.Close()
+
+	if err := printSummaryMarkdown(f, title, summary, traceURLTemplate); err != nil {
+		return fmt.Errorf("failed to write the Markdown summary: %w", err)
+	}
+
+	return nil
+}
+
+// printSummaryMarkdown writes the totals, the failed tests with their messages and the trace ID as Markdown.
+// The trace ID links to the trace if the template is not empty
+func printSummaryMarkdown(w io.Writer, title string, summary runSummary, traceURLTemplate string) error {
+	var sb strings.Builder
+
+	totals := summary.Totals
+	fmt.Fprintf(&sb, "## %s\n\n", escapeMarkdown(title))
+	sb.WriteString("| Tests | Passed | Failed | Errored | Skipped | Duration |\n")
+	sb.WriteString("| ---: | ---: | ---: | ---: | ---: | ---: |\n")
+	fmt.Fprintf(&sb, "| %d | %d | %d | %d | %d | %s |\n\n",
+		totals.Tests, totals.Passed, totals.Failed, totals.Errored, totals.Skipped,
+		formatDuration(time.Duration(totals.DurationMs)*time.Millisecond))
+
+	if len(summary.Failures) > 0 {
+		sb.WriteString("### Failed tests\n\n")
+		sb.WriteString("| Suite | Test | Status | Message |\n")
+		sb.WriteString("| --- | --- | --- | --- |\n")
+		for _, f := range summary.Failures {
+			name := f.Name
+			if f.Classname != "" {
+				name = f.Classname + "." + f.Name
+			}
+
+			fmt.Fprintf(&sb, "| %s | %s | %s | %s |\n", escapeMarkdown(f.Suite), escapeMarkdown(name), f.Status, escapeMarkdown(f.Message))
+		}
+		sb.WriteString("\n")
+	}
+
+	if summary.TraceID != "" {
+		if traceURLTemplate != "" {
+			traceURL := strings.ReplaceAll(traceURLTemplate, traceIDPlaceholder, summary.TraceID)
+			fmt.Fprintf(&sb, "Trace: [`%s`](%s)\n\n", summary.TraceID, traceURL)
+		} else {
+			fmt.Fprintf(&sb, "Trace: `%s`\n\n", summary.TraceID)
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+
+	return err
+}
+
+// escapeMarkdown escapes the characters breaking a Markdown table cell, joining the lines with <br>
+func escapeMarkdown(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.ReplaceAll(s, "|", "\\|")
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+// exportDestination describes where the spans of the run are exported, for the summaries: the endpoint and the
+// protocol of the OTLP exporter, stdout, the path of the file exporter, or none. It's empty if the exporters of the
+// configuration are not valid
+func exportDestination(cfg *config.Config) string {
+	exporter, err := signalExporter(cfg, "OTEL_TRACES_EXPORTER")
+	if err != nil {
+		return ""
+	}
+
+	switch exporter {
+	case exporterConsole:
+		return exporterStdout
+	case exporterFile:
+		return cfg.Exporter.File
+	case exporterNone:
+		return exporterNone
+	}
+
+	protocol, err := otlpProtocol(cfg, signalTraces)
+	if err != nil {
+		return ""
+	}
+
+	defaultEndpoint := defaultCollectorGRPCEndpoint
+	if protocol == protocolHTTPProtobuf {
+		defaultEndpoint = defaultCollectorHTTPEndpoint
+	}
+
+	target, _, ok := otlpTarget(cfg, defaultEndpoint)
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprintf("%s (%s)", target, protocol)
+}
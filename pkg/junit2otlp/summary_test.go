@@ -0,0 +1,249 @@
+package junit2otlp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joshdk/go-junit"
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestPrintSummary(t *testing.T) {
+	suites, err := junit.IngestFiles([]string{"../../TEST-sample.xml", "../../TEST-sample2.xml"})
+	require.NoError(t, err)
+
+	traceID, err := trace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	require.NoError(t, err)
+
+	t.Run("Without color", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := printSummary(&buf, suites, traceID, "localhost:4317 (grpc)", false)
+		require.NoError(t, err)
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, len(suites)+4) // header, totals, blank line and footer
+		require.Regexp(t, `^SUITE\s+TESTS\s+PASSED\s+FAILED\s+ERRORED\s+SKIPPED\s+DURATION$`, lines[0])
+		require.True(t, strings.HasPrefix(lines[len(lines)-3], "TOTAL"))
+		require.Equal(t, fmt.Sprintf("%d suites, trace 0102030405060708090a0b0c0d0e0f10, exported to localhost:4317 (grpc)", len(suites)), lines[len(lines)-1])
+		require.NotContains(t, buf.String(), "\x1b[")
+	})
+
+	t.Run("Without trace nor destination", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, printSummary(&buf, suites, trace.TraceID{}, "", false))
+		require.True(t, strings.HasSuffix(buf.String(), fmt.Sprintf("\n%d suites\n", len(suites))))
+	})
+
+	t.Run("With color", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := printSummary(&buf, suites, traceID, "", true)
+		require.NoError(t, err)
+
+		require.Contains(t, buf.String(), colorBold+"SUITE")
+		require.Contains(t, buf.String(), colorReset)
+	})
+
+	t.Run("Row colors", func(t *testing.T) {
+		require.Equal(t, colorRed, summaryColor(junit.Totals{Tests: 2, Failed: 1, Passed: 1}))
+		require.Equal(t, colorRed, summaryColor(junit.Totals{Tests: 1, Error: 1}))
+		require.Equal(t, colorYellow, summaryColor(junit.Totals{Tests: 2, Skipped: 1, Passed: 1}))
+		require.Equal(t, colorGreen, summaryColor(junit.Totals{Tests: 1, Passed: 1}))
+	})
+}
+
+func TestRunSummary(t *testing.T) {
+	suites, err := junit.IngestFile("../../TEST-sample.xml")
+	require.NoError(t, err)
+
+	traceID, err := trace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	require.NoError(t, err)
+
+	summary := newRunSummary(suites, traceID)
+	require.Equal(t, "0102030405060708090a0b0c0d0e0f10", summary.TraceID)
+	require.Equal(t, len(suites), summary.Totals.Suites)
+
+	tests := 0
+	for _, suite := range suites {
+		tests += suite.Totals.Tests
+	}
+	require.Equal(t, tests, summary.Totals.Tests)
+	require.LessOrEqual(t, len(summary.Slowest), slowestTestsCount)
+
+	for i := 1; i < len(summary.Slowest); i++ {
+		require.GreaterOrEqual(t, summary.Slowest[i-1].DurationMs, summary.Slowest[i].DurationMs)
+	}
+
+	t.Run("Written as JSON", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "summary.json")
+		require.NoError(t, writeSummaryJSON(path, summary))
+
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		var actual runSummary
+		require.NoError(t, json.Unmarshal(b, &actual))
+		require.Equal(t, summary, actual)
+	})
+
+	t.Run("Failures", func(t *testing.T) {
+		failed := []junit.Suite{{
+			Name: "suite",
+			Tests: []junit.Test{
+				{Name: "passed", Status: junit.StatusPassed},
+				{Name: "failed", Status: junit.StatusFailed, Message: "expected 1, got 2"},
+				{Name: "errored", Status: junit.StatusError},
+			},
+		}}
+
+		summary := newRunSummary(failed, trace.TraceID{})
+		require.Empty(t, summary.TraceID)
+		require.Len(t, summary.Failures, 2)
+		require.Equal(t, "expected 1, got 2", summary.Failures[0].Message)
+	})
+}
+
+func TestExportDestination(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "")
+
+	t.Run("Default collector", func(t *testing.T) {
+		require.Equal(t, "localhost:4317 (grpc)", exportDestination(config.NewConfigFromDefaults()))
+	})
+
+	t.Run("OTLP/HTTP endpoint", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.Exporter.Endpoint = "https://collector.example.com"
+		cfg.Exporter.Protocol = protocolHTTPProtobuf
+
+		require.Equal(t, "collector.example.com:4318 (http/protobuf)", exportDestination(cfg))
+	})
+
+	t.Run("File exporter", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.Exporter.Type = exporterFile
+		cfg.Exporter.File = "spans.json"
+
+		require.Equal(t, "spans.json", exportDestination(cfg))
+	})
+
+	t.Run("Stdout exporter", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.Exporter.Type = exporterStdout
+
+		require.Equal(t, "stdout", exportDestination(cfg))
+	})
+}
+
+func TestPrintSummaryMarkdown(t *testing.T) {
+	summary := runSummary{
+		TraceID: "0102030405060708090a0b0c0d0e0f10",
+		Totals:  summaryTotals{Tests: 3, Passed: 1, Failed: 1, Errored: 1, DurationMs: 1500},
+		Failures: []summaryTest{
+			{Suite: "suite", Classname: "pkg.Foo", Name: "TestBar", Status: "failed", Message: "expected a | b\ngot c"},
+		},
+	}
+
+	t.Run("With trace URL", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := printSummaryMarkdown(&buf, "junit2otlp", summary, "http://localhost:16686/trace/{traceId}")
+		require.NoError(t, err)
+
+		md := buf.String()
+		require.Contains(t, md, "## junit2otlp\n")
+		require.Contains(t, md, "| 3 | 1 | 1 | 1 | 0 | 1.5s |")
+		require.Contains(t, md, "| suite | pkg.Foo.TestBar | failed | expected a \\| b<br>got c |")
+		require.Contains(t, md, "Trace: [`0102030405060708090a0b0c0d0e0f10`](http://localhost:16686/trace/0102030405060708090a0b0c0d0e0f10)")
+	})
+
+	t.Run("Without trace URL", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := printSummaryMarkdown(&buf, "junit2otlp", summary, "")
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "Trace: `0102030405060708090a0b0c0d0e0f10`")
+	})
+
+	t.Run("Appended to the file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "step-summary.md")
+		require.NoError(t, os.WriteFile(path, []byte("# Previous step\n"), 0o600))
+
+		require.NoError(t, writeSummaryMarkdown(path, "junit2otlp", runSummary{}, ""))
+
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(string(b), "# Previous step\n## junit2otlp"))
+		require.NotContains(t, string(b), "Failed tests")
+	})
+}
+
+func TestPrintRunSummary(t *testing.T) {
+	suites, err := junit.IngestFiles([]string{"../../TEST-sample.xml"})
+	require.NoError(t, err)
+
+	traceID, err := trace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	require.NoError(t, err)
+
+	// printed returns what the summary wrote to stdout and to stderr
+	printed := func(t *testing.T, cfg *config.Config) (string, string) {
+		stdout, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+		require.NoError(t, err)
+		defer stdout.Close()
+
+		stderr, err := os.Create(filepath.Join(t.TempDir(), "stderr"))
+		require.NoError(t, err)
+		defer stderr.Close()
+
+		require.NoError(t, printRunSummary(stdout, stderr, cfg, suites, traceID))
+
+		out, err := os.ReadFile(stdout.Name())
+		require.NoError(t, err)
+		errOut, err := os.ReadFile(stderr.Name())
+		require.NoError(t, err)
+		return string(out), string(errOut)
+	}
+
+	t.Run("Enabled by default", func(t *testing.T) {
+		require.True(t, summaryEnabled(config.NewConfigFromDefaults()))
+
+		cfg := config.NewConfigFromDefaults()
+		cfg.Summary = false
+		require.False(t, summaryEnabled(cfg))
+	})
+
+	t.Run("Text to stderr", func(t *testing.T) {
+		out, errOut := printed(t, config.NewConfigFromDefaults())
+		require.Empty(t, out)
+		require.Contains(t, errOut, "TOTAL")
+		require.Contains(t, errOut, traceID.String())
+	})
+
+	t.Run("JSON to stdout", func(t *testing.T) {
+		cfg := config.NewConfigFromDefaults()
+		cfg.Summary = false
+		cfg.OutputFormat = summaryFormatJSON
+		require.True(t, summaryEnabled(cfg))
+
+		out, errOut := printed(t, cfg)
+		require.Empty(t, errOut)
+
+		var summary runSummary
+		require.NoError(t, json.Unmarshal([]byte(out), &summary))
+		require.Equal(t, traceID.String(), summary.TraceID)
+		require.Equal(t, len(suites), summary.Totals.Suites)
+		require.Equal(t, exportDestination(cfg), summary.Destination)
+	})
+
+	t.Run("Invalid format", func(t *testing.T) {
+		require.NoError(t, validateSummaryFormat(""))
+		require.NoError(t, validateSummaryFormat(summaryFormatText))
+		require.EqualError(t, validateSummaryFormat("yaml"), `invalid output "yaml": the summary of the run is printed as text or json`)
+	})
+}
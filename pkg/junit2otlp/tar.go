@@ -0,0 +1,126 @@
+package junit2otlp
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// inputTarPrefix reads every jUnit report inside a tar archive, i.e. tar:- for the standard input
+const inputTarPrefix = "tar:"
+
+// gzipMagic first bytes of the gzip streams, so the compressed tar archives are read transparently
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// isArchivedReport reports whether the file inside an archive is a report, i.e. an XML or a TRX file
+func isArchivedReport(name string) bool {
+	ext := filepath.Ext(name)
+	return strings.EqualFold(ext, ".xml") || strings.EqualFold(ext, ".trx")
+}
+
+// TarReader reads every jUnit report inside a tar archive, which is how the reports are usually
+// extracted from ephemeral containers, i.e. kubectl exec ... tar cf - reports. The gzip compressed
+// archives, i.e. the .tgz ones, are decompressed transparently
+type TarReader struct {
+	reader  io.Reader
+	maxSize int64
+}
+
+// NewTarReader returns a reader for the tar archive at path, using the standard input if path is '-'
+func NewTarReader(path string) (*TarReader, error) {
+	if path == inputStdin {
+		return &TarReader{reader: os.Stdin}, nil
+	}
+
+	if !isNamedPipe(path) {
+		path = normalizePath(path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the tar archive: %w", err)
+	}
+
+	return &TarReader{reader: f}, nil
+}
+
+// limitInputSize limits the size of each report inside the tar archive
+func (tr *TarReader) limitInputSize(maxSize int64) {
+	tr.maxSize = maxSize
+}
+
+// Read concatenates the XML files inside the tar archive, so that they are ingested at once.
+// The rest of the files are skipped
+func (tr *TarReader) Read() ([]byte, error) {
+	reports, err := tr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	for _, report := range reports {
+		buf.Write(report.Data)
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ReadAll reads each XML or TRX file inside the tar archive as a separate report. The rest of the files are skipped
+func (tr *TarReader) ReadAll() ([]InputReport, error) {
+	if closer, ok := tr.reader.(io.Closer); ok && tr.reader != os.Stdin {
+		defer closer.Close()
+	}
+
+	reports := []InputReport{}
+
+	r := bufio.NewReader(tr.reader)
+	var decompressed io.Reader = r
+	if magic, _ := r.Peek(len(gzipMagic)); bytes.Equal(magic, gzipMagic) {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the tar archive: %w", err)
+		}
+		defer gz.Close()
+
+		decompressed = gz
+	}
+
+	archive := tar.NewReader(decompressed)
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the tar archive: %w", err)
+		}
+
+		if header.Typeflag != tar.TypeReg || !isArchivedReport(header.Name) {
+			slog.Debug("skipping file in the tar archive", "name", header.Name)
+			continue
+		}
+
+		data, err := io.ReadAll(limitReadCloser(io.NopCloser(archive), header.Name, tr.maxSize))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from the tar archive: %w", header.Name, err)
+		}
+
+		reports = append(reports, InputReport{Name: header.Name, Data: data})
+		slog.Debug("jUnit report read from the tar archive", "name", header.Name, "bytes", header.Size)
+	}
+
+	if len(reports) == 0 {
+		return nil, fmt.Errorf("there are no XML files in the tar archive")
+	}
+
+	return reports, nil
+}
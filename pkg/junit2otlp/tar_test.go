@@ -0,0 +1,195 @@
+package junit2otlp
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/joshdk/go-junit"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTar(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+
+	// sorted by name, so the order of the archive is deterministic
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		content := files[name]
+		err := tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0o600,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		})
+		require.NoError(t, err)
+
+		_, err = tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+
+	return buf.Bytes()
+}
+
+func TestTarReader(t *testing.T) {
+	sample, err := os.ReadFile("../../TEST-sample.xml")
+	require.NoError(t, err)
+
+	sample2, err := os.ReadFile("../../TEST-sample2.xml")
+	require.NoError(t, err)
+
+	expected, err := junit.IngestFiles([]string{"../../TEST-sample.xml", "../../TEST-sample2.xml"})
+	require.NoError(t, err)
+
+	t.Run("Reads every XML file", func(t *testing.T) {
+		reader := &TarReader{reader: bytes.NewReader(writeTar(t, map[string]string{
+			"reports/TEST-sample.xml":  string(sample),
+			"reports/TEST-sample2.xml": string(sample2),
+			"reports/coverage.txt":     "mode: set",
+		}))}
+
+		b, err := reader.Read()
+		require.NoError(t, err)
+
+		suites, err := junit.Ingest(b)
+		require.NoError(t, err)
+		require.Len(t, suites, len(expected))
+	})
+
+	t.Run("Reads each XML file as a report", func(t *testing.T) {
+		reader := &TarReader{reader: bytes.NewReader(writeTar(t, map[string]string{
+			"reports/TEST-sample.xml":  string(sample),
+			"reports/TEST-sample2.xml": string(sample2),
+			"reports/coverage.txt":     "mode: set",
+		}))}
+
+		reports, err := reader.ReadAll()
+		require.NoError(t, err)
+		require.ElementsMatch(t, []InputReport{
+			{Name: "reports/TEST-sample.xml", Data: sample},
+			{Name: "reports/TEST-sample2.xml", Data: sample2},
+		}, reports)
+	})
+
+	t.Run("Without XML files", func(t *testing.T) {
+		reader := &TarReader{reader: bytes.NewReader(writeTar(t, map[string]string{
+			"reports/coverage.txt": "mode: set",
+		}))}
+
+		_, err := reader.Read()
+		require.ErrorContains(t, err, "there are no XML files in the tar archive")
+	})
+
+	t.Run("Compressed", func(t *testing.T) {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, err := gz.Write(writeTar(t, map[string]string{"TEST-sample.xml": string(sample)}))
+		require.NoError(t, err)
+		require.NoError(t, gz.Close())
+
+		path := filepath.Join(t.TempDir(), "reports.tgz")
+		require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+
+		// read as a tar archive by its extension
+		reader, err := NewInputReader(path)
+		require.NoError(t, err)
+		require.IsType(t, &TarReader{}, reader)
+
+		reports, err := reader.(MultiInputReader).ReadAll()
+		require.NoError(t, err)
+		require.Equal(t, []InputReport{{Name: "TEST-sample.xml", Data: sample}}, reports)
+	})
+
+	t.Run("From a file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "reports.tar")
+		err := os.WriteFile(path, writeTar(t, map[string]string{"TEST-sample.xml": string(sample)}), 0o600)
+		require.NoError(t, err)
+
+		reader, err := NewInputReader("tar:" + path)
+		require.NoError(t, err)
+
+		b, err := reader.Read()
+		require.NoError(t, err)
+		require.Equal(t, append(sample, '\n'), b)
+	})
+}
+
+func TestZipReader(t *testing.T) {
+	sample, err := os.ReadFile("../../TEST-sample.xml")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, file := range []struct{ name, content string }{
+		{name: "reports/TEST-sample.xml", content: string(sample)},
+		{name: "reports/coverage.txt", content: "mode: set"},
+		{name: "TestResults/results.trx", content: "<TestRun/>"},
+	} {
+		w, err := zw.Create(file.name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(file.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+
+	path := filepath.Join(t.TempDir(), "artifacts.zip")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+
+	t.Run("Reads each XML and TRX file as a report", func(t *testing.T) {
+		reader, err := NewInputReader(path)
+		require.NoError(t, err)
+		require.IsType(t, &ZipReader{}, reader)
+
+		reports, err := reader.(MultiInputReader).ReadAll()
+		require.NoError(t, err)
+		require.Equal(t, []InputReport{
+			{Name: "reports/TEST-sample.xml", Data: sample},
+			{Name: "TestResults/results.trx", Data: []byte("<TestRun/>")},
+		}, reports)
+	})
+
+	t.Run("With prefix", func(t *testing.T) {
+		reader, err := NewInputReader("zip:" + path)
+		require.NoError(t, err)
+		require.IsType(t, &ZipReader{}, reader)
+	})
+
+	t.Run("Not a zip archive", func(t *testing.T) {
+		_, err := NewZipReader("../../TEST-sample.xml").ReadAll()
+		require.ErrorContains(t, err, "failed to read the zip archive")
+	})
+}
+
+func TestNewInputReader(t *testing.T) {
+	t.Run("Standard input by default", func(t *testing.T) {
+		reader, err := NewInputReader("")
+		require.NoError(t, err)
+		require.IsType(t, &PipeReader{}, reader)
+
+		reader, err = NewInputReader("-")
+		require.NoError(t, err)
+		require.IsType(t, &PipeReader{}, reader)
+	})
+
+	t.Run("Tar from the standard input", func(t *testing.T) {
+		reader, err := NewInputReader("tar:-")
+		require.NoError(t, err)
+		require.IsType(t, &TarReader{}, reader)
+	})
+
+	t.Run("File", func(t *testing.T) {
+		reader, err := NewInputReader("../../TEST-sample.xml")
+		require.NoError(t, err)
+		require.IsType(t, &FileReader{}, reader)
+	})
+}
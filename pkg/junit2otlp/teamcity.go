@@ -0,0 +1,86 @@
+package junit2otlp
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// teamcityParameters returns the configuration parameters of the TeamCity build, i.e. teamcity.build.branch, which
+// are not exported as environment variables. TeamCity writes them in a properties file, whose path is in the build
+// properties file of the TEAMCITY_BUILD_PROPERTIES_FILE environment variable. It returns an empty map if any of them
+// cannot be read
+func teamcityParameters() map[string]string {
+	buildProperties := readProperties(os.Getenv("TEAMCITY_BUILD_PROPERTIES_FILE"))
+
+	return readProperties(buildProperties["teamcity.configuration.properties.file"])
+}
+
+// readProperties reads the key/value pairs of a Java properties file, as the ones written by TeamCity, removing the
+// escaping backslashes of the keys and values. It returns an empty map if the file cannot be read
+func readProperties(file string) map[string]string {
+	properties := map[string]string{}
+	if file == "" {
+		return properties
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return properties
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+
+		key, value := splitProperty(line)
+		properties[unescapeProperty(key)] = unescapeProperty(value)
+	}
+
+	return properties
+}
+
+// splitProperty splits a line of a properties file at its first unescaped separator, = or :
+func splitProperty(line string) (string, string) {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '=', ':':
+			return strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+		}
+	}
+
+	return line, ""
+}
+
+// unescapeProperty removes the escaping backslashes of a key or value of a properties file, i.e. C\:\\BuildAgent
+func unescapeProperty(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+
+	return b.String()
+}
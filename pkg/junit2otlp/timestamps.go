@@ -0,0 +1,73 @@
+package junit2otlp
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	// the timezone database is embedded, as the Docker image does not include it
+	_ "time/tzdata"
+)
+
+// defaultTimestampLayouts layouts of the timestamps found in the reports, tried in order after the
+// ones set in the configuration. The layouts without zone use the assumed timezone
+var defaultTimestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00", // NUnit
+	"2006-01-02T15:04:05 MST",             // TestNG
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// loadTimezone returns the location for the name of an IANA timezone, i.e. Europe/Madrid, UTC or Local
+func loadTimezone(name string) (*time.Location, error) {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone: %s. Use an IANA timezone name, i.e. UTC, Local or Europe/Madrid", name)
+	}
+
+	return loc, nil
+}
+
+// parseTimestamp parses the timestamp with the first matching layout, trying the layouts in order.
+// The timestamps without zone are interpreted in the given location
+func parseTimestamp(value string, layouts []string, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	for _, layout := range layouts {
+		t, err := time.ParseInLocation(layout, value, loc)
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unsupported timestamp: %s", value)
+}
+
+// resolveTimestamps parses the raw timestamps of the suites, using the zero time for the ones that are
+// empty or cannot be parsed
+func resolveTimestamps(raw []string, layouts []string, loc *time.Location) []time.Time {
+	layouts = append(append([]string{}, layouts...), defaultTimestampLayouts...)
+
+	timestamps := make([]time.Time, len(raw))
+	for i, value := range raw {
+		if value == "" {
+			continue
+		}
+
+		t, err := parseTimestamp(value, layouts, loc)
+		if err != nil {
+			slog.Debug("not able to parse the timestamp of the suite", "error", err)
+			continue
+		}
+
+		timestamps[i] = t
+	}
+
+	return timestamps
+}
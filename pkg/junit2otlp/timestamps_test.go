@@ -0,0 +1,49 @@
+package junit2otlp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	madrid, err := loadTimezone("Europe/Madrid")
+	require.NoError(t, err)
+
+	t.Run("With zone", func(t *testing.T) {
+		ts, err := parseTimestamp("2021-11-15T05:16:16Z", defaultTimestampLayouts, madrid)
+		require.NoError(t, err)
+		require.Equal(t, time.Date(2021, 11, 15, 5, 16, 16, 0, time.UTC), ts.UTC())
+	})
+
+	t.Run("Without zone uses the assumed timezone", func(t *testing.T) {
+		ts, err := parseTimestamp("2021-11-15T05:16:16", defaultTimestampLayouts, madrid)
+		require.NoError(t, err)
+		require.Equal(t, time.Date(2021, 11, 15, 4, 16, 16, 0, time.UTC), ts.UTC())
+	})
+
+	t.Run("Custom layout", func(t *testing.T) {
+		ts, err := parseTimestamp("15/11/2021 05:16", []string{"02/01/2006 15:04"}, time.UTC)
+		require.NoError(t, err)
+		require.Equal(t, time.Date(2021, 11, 15, 5, 16, 0, 0, time.UTC), ts)
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		_, err := parseTimestamp("yesterday", defaultTimestampLayouts, time.UTC)
+		require.ErrorContains(t, err, "unsupported timestamp: yesterday")
+	})
+}
+
+func TestResolveTimestamps(t *testing.T) {
+	timestamps := resolveTimestamps([]string{"2021-11-15 05:16:16", "", "yesterday"}, nil, time.UTC)
+	require.Len(t, timestamps, 3)
+	require.Equal(t, time.Date(2021, 11, 15, 5, 16, 16, 0, time.UTC), timestamps[0])
+	require.True(t, timestamps[1].IsZero())
+	require.True(t, timestamps[2].IsZero())
+}
+
+func TestLoadTimezone(t *testing.T) {
+	_, err := loadTimezone("Mars/Olympus_Mons")
+	require.ErrorContains(t, err, "invalid timezone: Mars/Olympus_Mons")
+}
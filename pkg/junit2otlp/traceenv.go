@@ -0,0 +1,114 @@
+package junit2otlp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	baggageHeader     = "baggage"
+	traceparentHeader = "traceparent"
+	tracestateHeader  = "tracestate"
+)
+
+// see https://github.com/moby/buildkit/pull/2572. The W3C baggage of the BAGGAGE environment variable is
+// propagated too, as the pipeline-level metadata, i.e. the team or the ID of the pipeline
+func initOtelContext(ctx context.Context) context.Context {
+	// open-telemetry/opentelemetry-specification#740
+	parent := os.Getenv("TRACEPARENT")
+	state := os.Getenv("TRACESTATE")
+	bag := os.Getenv("BAGGAGE")
+
+	if parent != "" || bag != "" {
+		propagator := propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+		return propagator.Extract(ctx, &textMap{parent: parent, state: state, baggage: bag})
+	}
+
+	return ctx
+}
+
+// baggageAttributes returns the members of the baggage of the context as attributes, named after their keys
+func baggageAttributes(ctx context.Context) []attribute.KeyValue {
+	members := baggage.FromContext(ctx).Members()
+
+	attributes := make([]attribute.KeyValue, 0, len(members))
+	for _, member := range members {
+		attributes = append(attributes, attribute.Key(member.Key()).String(member.Value()))
+	}
+
+	return attributes
+}
+
+type textMap struct {
+	parent  string
+	state   string
+	baggage string
+}
+
+func (tm *textMap) Get(key string) string {
+	switch key {
+	case traceparentHeader:
+		return tm.parent
+	case tracestateHeader:
+		return tm.state
+	case baggageHeader:
+		return tm.baggage
+	default:
+		return ""
+	}
+}
+
+func (tm *textMap) Set(key string, value string) {
+}
+
+func (tm *textMap) Keys() []string {
+	return []string{traceparentHeader, tracestateHeader, baggageHeader}
+}
+
+// formatTraceparent returns the W3C traceparent of the span, i.e. 00-<trace id>-<span id>-01, to continue its trace
+// in the next steps of the pipeline
+func formatTraceparent(sc trace.SpanContext) string {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(trace.ContextWithSpanContext(context.Background(), sc), carrier)
+
+	return carrier.Get(traceparentHeader)
+}
+
+// writeTraceContext exposes the span identifying the run to the next steps of the pipeline, so they continue its
+// trace: it's logged, written to the traceparent file, and set as the trace-id and traceparent outputs of the step
+// on Github Actions, if configured, with the baggage output if the context has baggage
+func writeTraceContext(ctx context.Context, cfg *config.Config, sc trace.SpanContext) error {
+	if !sc.IsValid() {
+		return nil
+	}
+
+	traceparent := formatTraceparent(sc)
+	slog.Info("the trace of the run", "traceId", sc.TraceID().String(), "traceparent", traceparent)
+
+	if cfg.TraceparentOut != "" {
+		if err := os.WriteFile(cfg.TraceparentOut, []byte(traceparent+"\n"), 0o644); err != nil {
+			return fmt.Errorf("failed to write the traceparent: %w", err)
+		}
+	}
+
+	if cfg.GithubOutput {
+		outputs := map[string]string{"trace-id": sc.TraceID().String(), "traceparent": traceparent}
+		if bag := baggage.FromContext(ctx); bag.Len() > 0 {
+			outputs[baggageHeader] = bag.String()
+		}
+
+		if err := writeGithubOutputs(outputs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package junit2otlp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestInitOtelContext(t *testing.T) {
+	t.Run("Traceparent and baggage", func(t *testing.T) {
+		t.Setenv("TRACEPARENT", "00-0102030405060708090a0b0c0d0e0f10-0102030405060708-01")
+		t.Setenv("TRACESTATE", "")
+		t.Setenv("BAGGAGE", "team=platform,pipeline.id=42")
+
+		ctx := initOtelContext(context.Background())
+		require.Equal(t, "0102030405060708090a0b0c0d0e0f10", trace.SpanContextFromContext(ctx).TraceID().String())
+		require.ElementsMatch(t, []attribute.KeyValue{
+			attribute.String("team", "platform"),
+			attribute.String("pipeline.id", "42"),
+		}, baggageAttributes(ctx))
+	})
+
+	t.Run("Baggage without traceparent", func(t *testing.T) {
+		t.Setenv("TRACEPARENT", "")
+		t.Setenv("BAGGAGE", "team=platform")
+
+		ctx := initOtelContext(context.Background())
+		require.False(t, trace.SpanContextFromContext(ctx).IsValid())
+		require.Equal(t, []attribute.KeyValue{attribute.String("team", "platform")}, baggageAttributes(ctx))
+	})
+
+	t.Run("Neither", func(t *testing.T) {
+		t.Setenv("TRACEPARENT", "")
+		t.Setenv("BAGGAGE", "")
+
+		require.Empty(t, baggageAttributes(initOtelContext(context.Background())))
+	})
+}
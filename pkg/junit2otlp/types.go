@@ -1,4 +1,4 @@
-package main
+package junit2otlp
 
 import (
 	"go.opentelemetry.io/otel/attribute"
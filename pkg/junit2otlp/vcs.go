@@ -0,0 +1,84 @@
+package junit2otlp
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// ScmAttributesSchemaLegacy emits the scm.* attributes
+	ScmAttributesSchemaLegacy = "legacy"
+	// ScmAttributesSchemaVcs emits the vcs.* attributes from the OpenTelemetry semantic conventions,
+	// keeping the scm.* attributes that have no vcs.* equivalent
+	ScmAttributesSchemaVcs = "vcs"
+	// ScmAttributesSchemaBoth emits both the scm.* and the vcs.* attributes, for compatibility
+	ScmAttributesSchemaBoth = "both"
+)
+
+var scmAttributesSchemas = []string{ScmAttributesSchemaLegacy, ScmAttributesSchemaVcs, ScmAttributesSchemaBoth}
+
+// validateScmAttributesSchema returns an error if the schema is not one of the supported ones
+func validateScmAttributesSchema(schema string) error {
+	if !slices.Contains(scmAttributesSchemas, schema) {
+		return fmt.Errorf("invalid SCM attributes schema: %s. Supported schemas: %s", schema, strings.Join(scmAttributesSchemas, ", "))
+	}
+
+	return nil
+}
+
+// legacyToVcsKeys the scm.* keys with an equivalent key in the OpenTelemetry VCS conventions
+var legacyToVcsKeys = map[attribute.Key]attribute.Key{
+	ScmBaseRef:    VcsRefBaseName,
+	ScmBranch:     VcsRefHeadName,
+	ScmProvider:   VcsProviderName,
+	ScmRepository: VcsRepositoryURLFull,
+}
+
+// toVcsAttributes converts the scm.* attributes with an equivalent in the OpenTelemetry VCS conventions
+// into vcs.* attributes, discarding the rest
+func toVcsAttributes(attributes []attribute.KeyValue) []attribute.KeyValue {
+	vcsAttributes := []attribute.KeyValue{}
+
+	for _, att := range attributes {
+		vcsKey, ok := legacyToVcsKeys[att.Key]
+		if !ok {
+			continue
+		}
+
+		switch att.Key {
+		case ScmBaseRef:
+			vcsAttributes = append(vcsAttributes, vcsKey.String(att.Value.AsString()), attribute.Key(VcsRefBaseType).String("branch"))
+		case ScmBranch:
+			vcsAttributes = append(vcsAttributes, vcsKey.String(att.Value.AsString()), attribute.Key(VcsRefHeadType).String("branch"))
+		case ScmProvider:
+			// the conventions use lowercase well-known values: github, gitlab, etc.
+			vcsAttributes = append(vcsAttributes, vcsKey.String(strings.ToLower(att.Value.AsString())))
+		case ScmRepository:
+			// the conventions use a single URL, so the first one is used
+			urls := att.Value.AsStringSlice()
+			if len(urls) > 0 {
+				vcsAttributes = append(vcsAttributes, vcsKey.String(urls[0]))
+			}
+		}
+	}
+
+	return vcsAttributes
+}
+
+// withoutLegacyScmAttributes removes the scm.* attributes with an equivalent in the OpenTelemetry VCS conventions
+func withoutLegacyScmAttributes(attributes []attribute.KeyValue) []attribute.KeyValue {
+	filtered := []attribute.KeyValue{}
+
+	for _, att := range attributes {
+		if _, ok := legacyToVcsKeys[att.Key]; ok {
+			continue
+		}
+
+		filtered = append(filtered, att)
+	}
+
+	return filtered
+}
@@ -0,0 +1,39 @@
+package junit2otlp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestToVcsAttributes(t *testing.T) {
+	legacyAttributes := []attribute.KeyValue{
+		attribute.Key(ScmType).String("git"),
+		attribute.Key(ScmProvider).String("Github"),
+		attribute.Key(ScmRepository).StringSlice([]string{"https://github.com/mdelapenya/junit2otlp", "git@github.com:mdelapenya/junit2otlp.git"}),
+		attribute.Key(ScmBranch).String("feature/pr-23"),
+		attribute.Key(ScmBaseRef).String("main"),
+		attribute.Key(ScmAuthors).StringSlice([]string{"octocat@github.com"}),
+	}
+
+	t.Run("Converts the keys with equivalent", func(t *testing.T) {
+		atts := toVcsAttributes(legacyAttributes)
+
+		require.Len(t, atts, 6)
+		require.True(t, keyExistsWithValue(t, atts, VcsProviderName, "github"))
+		require.True(t, keyExistsWithValue(t, atts, VcsRepositoryURLFull, "https://github.com/mdelapenya/junit2otlp"))
+		require.True(t, keyExistsWithValue(t, atts, VcsRefHeadName, "feature/pr-23"))
+		require.True(t, keyExistsWithValue(t, atts, VcsRefHeadType, "branch"))
+		require.True(t, keyExistsWithValue(t, atts, VcsRefBaseName, "main"))
+		require.True(t, keyExistsWithValue(t, atts, VcsRefBaseType, "branch"))
+	})
+
+	t.Run("Removes the legacy keys with equivalent", func(t *testing.T) {
+		atts := withoutLegacyScmAttributes(legacyAttributes)
+
+		require.Len(t, atts, 2)
+		require.True(t, keyExistsWithValue(t, atts, ScmType, "git"))
+		require.True(t, keyExistsWithValue(t, atts, ScmAuthors, "octocat@github.com"))
+	})
+}
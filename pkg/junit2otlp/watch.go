@@ -0,0 +1,173 @@
+package junit2otlp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"time"
+
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+)
+
+// watchedFile the state of a report in the watched directory, as seen in the last scan
+type watchedFile struct {
+	size      int64
+	modTime   time.Time
+	converted bool
+}
+
+// Watch converts the reports written to the watch directory of the configuration as they appear, exporting the
+// telemetry with OTLP, until the sentinel file is written. It's meant to run as a sidecar of the test workloads,
+// sharing a volume with them, i.e. an emptyDir in a Kubernetes Job. Each report is a run of its own, and the
+// errors of a report don't stop the watch: they are returned once it finishes, along with the failed tests
+func Watch(ctx context.Context, cfg *config.Config) error {
+	ctx = initOtelContext(ctx)
+
+	if err := validateScmAttributesSchema(cfg.ScmAttributesSchema); err != nil {
+		return err
+	}
+
+	if err := validateAttributeSchema(cfg.AttributeSchema); err != nil {
+		return err
+	}
+
+	if err := validateSummaryFormat(cfg.OutputFormat); err != nil {
+		return err
+	}
+
+	if cfg.MemoryLimit > 0 {
+		debug.SetMemoryLimit(int64(cfg.MemoryLimit) << 20)
+	}
+
+	providers, shutdown, err := newProviders(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer shutdown()
+
+	err = watchDir(ctx, cfg, func(path string) error {
+		// the errors and the outputs name the report
+		reportCfg := *cfg
+		reportCfg.Input = path
+
+		return Run(ctx, &reportCfg, providers, NewFileReader(path))
+	})
+
+	// the pending telemetry is pushed before returning, failing if the metrics or the logs were lost
+	return errors.Join(err, shutdown())
+}
+
+// watchDir scans the watch directory at each interval, calling convert with the path of each report once it's
+// complete, that is, when its size and modification time did not change since the previous scan. Once the
+// sentinel file exists, the remaining reports are converted, as the writer already finished, and it returns
+func watchDir(ctx context.Context, cfg *config.Config, convert func(path string) error) error {
+	if cfg.WatchInterval <= 0 {
+		return fmt.Errorf("invalid watch interval: %s. It must be greater than zero", cfg.WatchInterval)
+	}
+
+	if _, err := filepath.Match(cfg.WatchPattern, ""); err != nil {
+		return fmt.Errorf("invalid watch pattern %q: %w", cfg.WatchPattern, err)
+	}
+
+	slog.Info("watching the directory for reports", "dir", cfg.WatchDir, "pattern", cfg.WatchPattern, "sentinel", cfg.WatchSentinel)
+
+	files := map[string]*watchedFile{}
+	errs := []error{}
+
+	ticker := time.NewTicker(cfg.WatchInterval)
+	defer ticker.Stop()
+
+	for {
+		// checked before the scan, so the reports written before the sentinel are in it
+		_, err := os.Stat(filepath.Join(cfg.WatchDir, cfg.WatchSentinel))
+		done := err == nil
+
+		ready, err := scanWatchDir(cfg, files, done)
+		if err != nil {
+			return err
+		}
+
+		for _, path := range ready {
+			slog.Debug("converting the report", "path", path)
+
+			if err := convert(path); err != nil {
+				slog.Error("failed to convert the report", "path", path, "error", err)
+				errs = append(errs, err)
+			}
+		}
+
+		if done {
+			slog.Info("the sentinel file was written, stopping the watch", "reports", len(files))
+			return errors.Join(errs...)
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Join(append(errs, ctx.Err())...)
+		case <-ticker.C:
+		}
+	}
+}
+
+// scanWatchDir updates the state of the reports in the watch directory, returning the paths of the ones ready
+// to be converted, sorted by name, as the directory entries are. If all is set, every report not converted yet
+// is ready. A missing directory is not an error, as the volume can be mounted after the watch starts
+func scanWatchDir(cfg *config.Config, files map[string]*watchedFile, all bool) ([]string, error) {
+	entries, err := os.ReadDir(cfg.WatchDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the watch directory: %w", err)
+	}
+
+	ready := []string{}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || entry.Name() == cfg.WatchSentinel {
+			continue
+		}
+
+		if matched, _ := filepath.Match(cfg.WatchPattern, entry.Name()); !matched {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			// removed since the directory was read
+			continue
+		}
+
+		path := filepath.Join(cfg.WatchDir, entry.Name())
+
+		file, ok := files[path]
+		if !ok {
+			files[path] = &watchedFile{size: info.Size(), modTime: info.ModTime()}
+			if all {
+				files[path].converted = true
+				ready = append(ready, path)
+			}
+
+			continue
+		}
+
+		if file.converted {
+			continue
+		}
+
+		// still being written, or created but not written yet
+		if !all && (file.size != info.Size() || !file.modTime.Equal(info.ModTime()) || info.Size() == 0) {
+			file.size = info.Size()
+			file.modTime = info.ModTime()
+			continue
+		}
+
+		file.converted = true
+		ready = append(ready, path)
+	}
+
+	return ready, nil
+}
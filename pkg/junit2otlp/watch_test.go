@@ -0,0 +1,153 @@
+package junit2otlp
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mdelapenya/junit2otlp/pkg/junit2otlp/config"
+	"github.com/stretchr/testify/require"
+)
+
+func watchConfig(t *testing.T) *config.Config {
+	cfg := config.NewConfigFromDefaults()
+	cfg.WatchDir = t.TempDir()
+	cfg.WatchInterval = 10 * time.Millisecond
+
+	return cfg
+}
+
+func TestWatchDir(t *testing.T) {
+	t.Run("The reports written before the sentinel are converted", func(t *testing.T) {
+		cfg := watchConfig(t)
+		writeFile(t, filepath.Join(cfg.WatchDir, "TEST-b.xml"), "b")
+		writeFile(t, filepath.Join(cfg.WatchDir, "TEST-a.xml"), "a")
+		writeFile(t, filepath.Join(cfg.WatchDir, "coverage.out"), "not a report")
+		writeFile(t, filepath.Join(cfg.WatchDir, cfg.WatchSentinel), "")
+
+		converted := []string{}
+		err := watchDir(context.Background(), cfg, func(path string) error {
+			converted = append(converted, filepath.Base(path))
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"TEST-a.xml", "TEST-b.xml"}, converted)
+	})
+
+	t.Run("The reports are converted as they appear", func(t *testing.T) {
+		cfg := watchConfig(t)
+
+		converted := make(chan string, 2)
+		done := make(chan error)
+		go func() {
+			done <- watchDir(context.Background(), cfg, func(path string) error {
+				converted <- filepath.Base(path)
+				return nil
+			})
+		}()
+
+		writeFile(t, filepath.Join(cfg.WatchDir, "TEST-a.xml"), "a")
+		require.Equal(t, "TEST-a.xml", <-converted)
+
+		writeFile(t, filepath.Join(cfg.WatchDir, "TEST-b.xml"), "b")
+		writeFile(t, filepath.Join(cfg.WatchDir, cfg.WatchSentinel), "")
+		require.NoError(t, <-done)
+
+		// the report is converted once, before the watch stops
+		require.Equal(t, "TEST-b.xml", <-converted)
+		require.Empty(t, converted)
+	})
+
+	t.Run("The errors don't stop the watch", func(t *testing.T) {
+		cfg := watchConfig(t)
+		writeFile(t, filepath.Join(cfg.WatchDir, "TEST-a.xml"), "a")
+		writeFile(t, filepath.Join(cfg.WatchDir, "TEST-b.xml"), "b")
+		writeFile(t, filepath.Join(cfg.WatchDir, cfg.WatchSentinel), "")
+
+		converted := 0
+		err := watchDir(context.Background(), cfg, func(path string) error {
+			converted++
+			return errors.New("broken " + filepath.Base(path))
+		})
+		require.ErrorContains(t, err, "broken TEST-a.xml")
+		require.ErrorContains(t, err, "broken TEST-b.xml")
+		require.Equal(t, 2, converted)
+	})
+
+	t.Run("The directory can be created after the watch starts", func(t *testing.T) {
+		cfg := watchConfig(t)
+		cfg.WatchDir = filepath.Join(cfg.WatchDir, "reports")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		err := watchDir(ctx, cfg, func(path string) error { return nil })
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("Invalid interval", func(t *testing.T) {
+		cfg := watchConfig(t)
+		cfg.WatchInterval = 0
+
+		err := watchDir(context.Background(), cfg, func(path string) error { return nil })
+		require.ErrorContains(t, err, "invalid watch interval")
+	})
+
+	t.Run("Invalid pattern", func(t *testing.T) {
+		cfg := watchConfig(t)
+		cfg.WatchPattern = "[*.xml"
+
+		err := watchDir(context.Background(), cfg, func(path string) error { return nil })
+		require.ErrorContains(t, err, "invalid watch pattern")
+	})
+}
+
+func TestScanWatchDir(t *testing.T) {
+	cfg := watchConfig(t)
+	files := map[string]*watchedFile{}
+	path := filepath.Join(cfg.WatchDir, "TEST-a.xml")
+
+	writeFile(t, path, "<testsuite")
+	ready, err := scanWatchDir(cfg, files, false)
+	require.NoError(t, err)
+	require.Empty(t, ready)
+
+	// still being written
+	writeFile(t, path, "<testsuite></testsuite>")
+	ready, err = scanWatchDir(cfg, files, false)
+	require.NoError(t, err)
+	require.Empty(t, ready)
+
+	ready, err = scanWatchDir(cfg, files, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{path}, ready)
+
+	ready, err = scanWatchDir(cfg, files, true)
+	require.NoError(t, err)
+	require.Empty(t, ready)
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	err := os.WriteFile(path, []byte(content), 0o600)
+	require.NoError(t, err)
+}
+
+func TestWatch_MaxInputSize(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "none")
+	t.Setenv("OTEL_METRICS_EXPORTER", "none")
+	t.Setenv("OTEL_LOGS_EXPORTER", "none")
+
+	cfg := watchConfig(t)
+	cfg.MaxInputSize = 1
+	writeFile(t, filepath.Join(cfg.WatchDir, "TEST-large.xml"), "<testsuite>"+strings.Repeat(" ", 1<<20)+"</testsuite>")
+	writeFile(t, filepath.Join(cfg.WatchDir, cfg.WatchSentinel), "")
+
+	err := Watch(context.Background(), cfg)
+	require.ErrorContains(t, err, "TEST-large.xml is larger than the maximum input size of 1 MiB")
+}
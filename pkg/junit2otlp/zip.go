@@ -0,0 +1,131 @@
+package junit2otlp
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// inputZipPrefix reads every jUnit report inside a zip archive, i.e. zip:- for the standard input
+const inputZipPrefix = "zip:"
+
+// archiveExtensions extensions of the archives read transparently when they are passed as inputs, by their prefix
+var archiveExtensions = map[string]string{
+	".tar":    inputTarPrefix,
+	".tar.gz": inputTarPrefix,
+	".tgz":    inputTarPrefix,
+	".zip":    inputZipPrefix,
+}
+
+// archivePrefix returns the prefix of the input reading the archive at path, or an empty string if the path is not
+// an archive, by its extension
+func archivePrefix(path string) string {
+	lower := strings.ToLower(path)
+	for ext, prefix := range archiveExtensions {
+		if strings.HasSuffix(lower, ext) {
+			return prefix
+		}
+	}
+
+	return ""
+}
+
+// ZipReader reads every jUnit report inside a zip archive, which is how most CI systems bundle the
+// artifacts of a job, i.e. the test results downloaded from another job
+type ZipReader struct {
+	path    string
+	maxSize int64
+}
+
+// NewZipReader returns a reader for the zip archive at path, using the standard input if path is '-'.
+// As the zip archives are read from their end, the standard input is read into memory first
+func NewZipReader(path string) *ZipReader {
+	return &ZipReader{path: path}
+}
+
+// limitInputSize limits the size of each report inside the zip archive
+func (zr *ZipReader) limitInputSize(maxSize int64) {
+	zr.maxSize = maxSize
+}
+
+// Read concatenates the XML files inside the zip archive, so that they are ingested at once.
+// The rest of the files are skipped
+func (zr *ZipReader) Read() ([]byte, error) {
+	reports, err := zr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	for _, report := range reports {
+		buf.Write(report.Data)
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ReadAll reads each XML or TRX file inside the zip archive as a separate report, in the order of the archive.
+// The rest of the files are skipped
+func (zr *ZipReader) ReadAll() ([]InputReport, error) {
+	archive, err := zr.open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the zip archive: %w", err)
+	}
+
+	reports := []InputReport{}
+	for _, file := range archive.File {
+		if !file.Mode().IsRegular() || !isArchivedReport(file.Name) {
+			slog.Debug("skipping file in the zip archive", "name", file.Name)
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from the zip archive: %w", file.Name, err)
+		}
+
+		data, err := io.ReadAll(limitReadCloser(rc, file.Name, zr.maxSize))
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from the zip archive: %w", file.Name, err)
+		}
+
+		reports = append(reports, InputReport{Name: file.Name, Data: data})
+		slog.Debug("jUnit report read from the zip archive", "name", file.Name, "bytes", file.UncompressedSize64)
+	}
+
+	if len(reports) == 0 {
+		return nil, fmt.Errorf("there are no XML files in the zip archive")
+	}
+
+	return reports, nil
+}
+
+// open returns the zip archive, reading the standard input into memory if the path is '-'
+func (zr *ZipReader) open() (*zip.Reader, error) {
+	if zr.path == inputStdin {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+
+		return zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	}
+
+	path := zr.path
+	if !isNamedPipe(path) {
+		path = normalizePath(path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return zip.NewReader(bytes.NewReader(data), int64(len(data)))
+}